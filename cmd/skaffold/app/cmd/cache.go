@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/cache"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	kubectx "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/context"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
+)
+
+var cacheFlags = struct {
+	cacheFile   string
+	outFormat   string
+	kubeContext string
+	keep        int
+	olderThan   time.Duration
+}{
+	outFormat: "text",
+}
+
+// for testing
+var cacheNow = time.Now
+
+// NewCmdCache describes the CLI command to inspect and clean up the artifact cache.
+func NewCmdCache() *cobra.Command {
+	return NewCmd("cache").
+		WithDescription("Inspect and clean up the artifact cache (defaults to `$HOME/.skaffold/cache`)").
+		WithPersistentFlagAdder(cmdCacheFlags).
+		WithCommands(cmdCacheList(), cmdCachePrune(), cmdCacheClear())
+}
+
+func cmdCacheFlags(f *pflag.FlagSet) {
+	f.StringVar(&cacheFlags.cacheFile, "cache-file", "", "Specify the location of the cache file (default $HOME/.skaffold/cache)")
+	f.StringVarP(&cacheFlags.outFormat, "format", "o", "text", "Output format. One of: text(default), json")
+}
+
+func cmdCacheList() *cobra.Command {
+	return NewCmd("list").
+		WithDescription("List the entries of the artifact cache").
+		WithExample("List the images built for each artifact hash", "cache list").
+		NoArgs(listCache)
+}
+
+func cmdCachePrune() *cobra.Command {
+	return NewCmd("prune").
+		WithDescription("Remove stale entries from the artifact cache and delete the local images beyond a retention count or age").
+		WithLongDescription("Remove the entries of the artifact cache whose images can't be found, neither in the local Docker daemon nor in a registry. With --keep or --older-than, also remove the older entries of each artifact and delete their images from the local Docker daemon, unless another entry uses them. Images are never deleted from registries.").
+		WithExample("Remove the entries whose images don't exist anymore", "cache prune").
+		WithExample("Only keep the 3 most recent images of each artifact", "cache prune --keep=3").
+		WithExample("Remove the images built more than a week ago", "cache prune --older-than=168h").
+		WithFlagAdder(func(f *pflag.FlagSet) {
+			f.IntVar(&cacheFlags.keep, "keep", 0, "Number of entries to keep for each artifact. 0 keeps them all")
+			f.DurationVar(&cacheFlags.olderThan, "older-than", 0, "Remove the entries older than this duration. 0 keeps them all")
+			f.StringVarP(&cacheFlags.kubeContext, "kube-context", "k", "", "Kubernetes context used to find the Docker daemon, for example on minikube (defaults to the current context)")
+		}).
+		NoArgs(pruneCache)
+}
+
+func cmdCacheClear() *cobra.Command {
+	return NewCmd("clear").
+		WithDescription("Remove all the entries of the artifact cache. Images are not deleted").
+		NoArgs(clearCache)
+}
+
+func listCache(_ context.Context, out io.Writer) error {
+	if err := checkCacheOutFormat(); err != nil {
+		return err
+	}
+
+	entries, err := cache.ListEntries(cacheFlags.cacheFile)
+	if err != nil {
+		return fmt.Errorf("reading artifact cache: %w", err)
+	}
+
+	if cacheFlags.outFormat == "json" {
+		if entries == nil {
+			entries = []cache.Entry{}
+		}
+		return json.NewEncoder(out).Encode(entries)
+	}
+	return printCacheEntries(out, entries)
+}
+
+func pruneCache(ctx context.Context, out io.Writer) error {
+	if err := checkCacheOutFormat(); err != nil {
+		return err
+	}
+	if cacheFlags.keep < 0 {
+		return fmt.Errorf("invalid value %d for --keep: should be 0 or more", cacheFlags.keep)
+	}
+
+	cfg := &runcontext.RunContext{KubeContext: cacheFlags.kubeContext}
+	if cfg.KubeContext == "" {
+		if kubeConfig, err := kubectx.CurrentConfig(); err == nil {
+			cfg.KubeContext = kubeConfig.CurrentContext
+		}
+	}
+	client, err := docker.NewAPIClient(cfg)
+	if err != nil {
+		logrus.Warnf("Local images won't be checked nor deleted, Docker daemon not reachable: %v", err)
+		client = nil
+	}
+
+	result, err := cache.Prune(ctx, cacheFlags.cacheFile, cfg, client, cache.PruneOptions{
+		Keep:      cacheFlags.keep,
+		OlderThan: cacheFlags.olderThan,
+	})
+	if err != nil {
+		return fmt.Errorf("pruning artifact cache: %w", err)
+	}
+
+	if cacheFlags.outFormat == "json" {
+		if result.Removed == nil {
+			result.Removed = []cache.Entry{}
+		}
+		if result.DeletedImages == nil {
+			result.DeletedImages = []string{}
+		}
+		return json.NewEncoder(out).Encode(result)
+	}
+
+	fmt.Fprintf(out, "Removed %d entries from the artifact cache\n", len(result.Removed))
+	if len(result.Removed) > 0 {
+		if err := printCacheEntries(out, result.Removed); err != nil {
+			return err
+		}
+	}
+	if len(result.DeletedImages) > 0 {
+		fmt.Fprintf(out, "Deleted %d local images:\n", len(result.DeletedImages))
+		for _, id := range result.DeletedImages {
+			fmt.Fprintf(out, " - %s\n", id)
+		}
+	}
+	return nil
+}
+
+func clearCache(_ context.Context, out io.Writer) error {
+	if err := checkCacheOutFormat(); err != nil {
+		return err
+	}
+
+	removed, err := cache.Clear(cacheFlags.cacheFile)
+	if err != nil {
+		return fmt.Errorf("clearing artifact cache: %w", err)
+	}
+
+	if cacheFlags.outFormat == "json" {
+		if removed == nil {
+			removed = []cache.Entry{}
+		}
+		return json.NewEncoder(out).Encode(struct {
+			Removed []cache.Entry `json:"removed"`
+		}{removed})
+	}
+	fmt.Fprintf(out, "Removed %d entries from the artifact cache\n", len(removed))
+	return nil
+}
+
+func checkCacheOutFormat() error {
+	switch cacheFlags.outFormat {
+	case "text", "json":
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q: should be one of text, json", cacheFlags.outFormat)
+	}
+}
+
+func printCacheEntries(out io.Writer, entries []cache.Entry) error {
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "ARTIFACT\tHASH\tDIGEST\tIMAGE ID\tAGE")
+	for _, e := range entries {
+		age := "-"
+		if e.Created != nil {
+			age = duration.HumanDuration(cacheNow().Sub(*e.Created))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", orDash(e.Artifact), shortHash(e.Hash), orDash(shortHash(e.Digest)), orDash(shortHash(e.ID)), age)
+	}
+	return w.Flush()
+}
+
+// shortHash truncates hashes and digests the same way `docker images` does.
+func shortHash(hash string) string {
+	hash = strings.TrimPrefix(hash, "sha256:")
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+const testCacheFile = `hash1111111111111111:
+  id: sha256:aaaaaaaaaaaaaaaaaaaaaaaa
+  artifact: app
+  tag: app:v1
+  created: 2021-06-01T07:00:00Z
+hash2222222222222222:
+  digest: sha256:bbbbbbbbbbbbbbbbbbbbbbbb
+  artifact: web
+  tag: gcr.io/project/web:v1@sha256:bbbbbbbbbbbbbbbbbbbbbbbb
+  created: 2021-05-30T12:00:00Z
+hash3333333333333333:
+  id: sha256:cccccccccccccccccccccccc
+`
+
+func TestListCache(t *testing.T) {
+	tests := []struct {
+		description string
+		format      string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "text",
+			format:      "text",
+			expected: `ARTIFACT   HASH           DIGEST         IMAGE ID       AGE
+-          hash33333333   -              cccccccccccc   -
+app        hash11111111   -              aaaaaaaaaaaa   5h
+web        hash22222222   bbbbbbbbbbbb   -              2d
+`,
+		},
+		{
+			description: "json",
+			format:      "json",
+			expected: `[{"hash":"hash3333333333333333","id":"sha256:cccccccccccccccccccccccc"},` +
+				`{"hash":"hash1111111111111111","artifact":"app","tag":"app:v1","id":"sha256:aaaaaaaaaaaaaaaaaaaaaaaa","created":"2021-06-01T07:00:00Z"},` +
+				`{"hash":"hash2222222222222222","artifact":"web","tag":"gcr.io/project/web:v1@sha256:bbbbbbbbbbbbbbbbbbbbbbbb","digest":"sha256:bbbbbbbbbbbbbbbbbbbbbbbb","created":"2021-05-30T12:00:00Z"}]` + "\n",
+		},
+		{
+			description: "invalid format",
+			format:      "yaml",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			tmpDir := t.NewTempDir().Write("cache", testCacheFile)
+			t.Override(&cacheFlags.cacheFile, tmpDir.Path("cache"))
+			t.Override(&cacheFlags.outFormat, test.format)
+			t.Override(&cacheNow, func() time.Time { return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC) })
+
+			var out bytes.Buffer
+			err := listCache(context.Background(), &out)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, out.String())
+		})
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		tmpDir := t.NewTempDir().Write("cache", testCacheFile)
+		t.Override(&cacheFlags.cacheFile, tmpDir.Path("cache"))
+		t.Override(&cacheFlags.outFormat, "text")
+
+		var out bytes.Buffer
+		err := clearCache(context.Background(), &out)
+		t.CheckNoError(err)
+		t.CheckDeepEqual("Removed 3 entries from the artifact cache\n", out.String())
+
+		out.Reset()
+		err = listCache(context.Background(), &out)
+		t.CheckNoError(err)
+		t.CheckDeepEqual("ARTIFACT   HASH   DIGEST   IMAGE ID   AGE\n", out.String())
+	})
+}
@@ -167,6 +167,7 @@ func NewSkaffoldCommand(out, errOut io.Writer) *cobra.Command {
 				NewCmdDeploy(),
 				NewCmdDelete(),
 				NewCmdRender(),
+				NewCmdDiff(),
 				NewCmdApply(),
 			},
 		},
@@ -184,6 +185,7 @@ func NewSkaffoldCommand(out, errOut io.Writer) *cobra.Command {
 	rootCmd.AddCommand(NewCmdVersion())
 	rootCmd.AddCommand(NewCmdCompletion())
 	rootCmd.AddCommand(NewCmdConfig())
+	rootCmd.AddCommand(NewCmdCache())
 	rootCmd.AddCommand(NewCmdFindConfigs())
 	rootCmd.AddCommand(NewCmdDiagnose())
 	rootCmd.AddCommand(NewCmdOptions())
@@ -32,6 +32,7 @@ func NewCmdConfig() *cobra.Command {
 	cmd.AddCommand(NewCmdSet())
 	cmd.AddCommand(NewCmdUnset())
 	cmd.AddCommand(NewCmdList())
+	cmd.AddCommand(NewCmdPublish())
 	return cmd
 }
 
@@ -67,3 +68,12 @@ func NewCmdList() *cobra.Command {
 		}).
 		NoArgs(config.List)
 }
+
+func NewCmdPublish() *cobra.Command {
+	return NewCmd("publish").
+		WithDescription("Package a Skaffold config set into a bundle that can be used as a remote config dependency").
+		WithExample("Push the config set to an OCI registry", "config publish gcr.io/my-project/skaffold-modules:v1").
+		WithExample("Write the config set to a tarball and print its checksum", "config publish --output modules.tar.gz").
+		WithFlagAdder(config.AddPublishFlags).
+		WithArgs(cobra.MaximumNArgs(1), config.Publish)
+}
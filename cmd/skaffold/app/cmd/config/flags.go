@@ -23,6 +23,9 @@ import (
 var (
 	configFile, kubecontext, surveyID string
 	showAll, global, survey           bool
+
+	bundleFile, bundleOutput string
+	bundleInsecureRegistries []string
 )
 
 func AddCommonFlags(f *pflag.FlagSet) {
@@ -42,3 +45,9 @@ func AddSetUnsetFlags(f *pflag.FlagSet) {
 	f.MarkHidden("survey")
 	f.MarkHidden("id")
 }
+
+func AddPublishFlags(f *pflag.FlagSet) {
+	f.StringVarP(&bundleFile, "filename", "f", "skaffold.yaml", "Path to the Skaffold config to package")
+	f.StringVarP(&bundleOutput, "output", "o", "", "Write the bundle to a tarball at this path instead of, or in addition to, pushing it")
+	f.StringSliceVar(&bundleInsecureRegistries, "insecure-registry", nil, "Target registries for the bundle that are allowed to be plain HTTP")
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/bundle"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/parser"
+)
+
+var getConfigSet = parser.GetConfigSet
+
+// Publish packages a skaffold config set into a bundle that other projects can use as a config dependency.
+func Publish(ctx context.Context, out io.Writer, args []string) error {
+	if len(args) == 0 && bundleOutput == "" {
+		return fmt.Errorf("either an image reference or the --output flag is required")
+	}
+
+	root, err := bundleRoot()
+	if err != nil {
+		return err
+	}
+
+	if bundleOutput != "" {
+		output, err := filepath.Abs(bundleOutput)
+		if err != nil {
+			return err
+		}
+		// the tarball itself must not end up in the bundle when written to the bundled directory.
+		var buf bytes.Buffer
+		checksum, err := bundle.WriteArchive(root, &buf, output)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(output, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", bundleOutput, err)
+		}
+		fmt.Fprintf(out, "Wrote config bundle %s\nsha256: %s\n", bundleOutput, checksum)
+	}
+
+	if len(args) == 1 {
+		digest, err := bundle.PushOCI(root, args[0], bundleInsecureRegistries)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Pushed config bundle %s@%s\n", args[0], digest)
+	}
+	return nil
+}
+
+// bundleRoot validates the config set and returns the directory to package.
+// All local configs of the set must be located in that directory so that they can be resolved from the bundle.
+func bundleRoot() (string, error) {
+	abs, err := filepath.Abs(bundleFile)
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Dir(abs)
+
+	set, err := getConfigSet(config.SkaffoldOptions{
+		ConfigurationFile:  abs,
+		InsecureRegistries: bundleInsecureRegistries,
+	})
+	if err != nil {
+		return "", fmt.Errorf("validating config set: %w", err)
+	}
+	for _, cfg := range set {
+		if cfg.IsRemote {
+			continue
+		}
+		src, err := filepath.Abs(cfg.SourceFile)
+		if err != nil {
+			return "", err
+		}
+		if src != abs && !strings.HasPrefix(src, root+string(filepath.Separator)) {
+			return "", fmt.Errorf("config %s is outside of %s: local config dependencies must be located in the bundled directory", cfg.SourceFile, root)
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/parser"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		description string
+		args        []string
+		output      string
+		sources     []string
+		expectedErr string
+	}{
+		{
+			description: "write tarball",
+			output:      "bundle.tar.gz",
+			sources:     []string{"skaffold.yaml", "modules/web/skaffold.yaml"},
+		},
+		{
+			description: "no destination",
+			expectedErr: "either an image reference or the --output flag is required",
+		},
+		{
+			description: "local dependency outside of the bundle",
+			output:      "bundle.tar.gz",
+			sources:     []string{"skaffold.yaml", "../shared/skaffold.yaml"},
+			expectedErr: "local config dependencies must be located in the bundled directory",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			tmp := t.NewTempDir().Write("skaffold.yaml", "").Chdir()
+			t.Override(&bundleFile, "skaffold.yaml")
+			t.Override(&bundleOutput, test.output)
+			t.Override(&getConfigSet, func(config.SkaffoldOptions) (parser.SkaffoldConfigSet, error) {
+				var set parser.SkaffoldConfigSet
+				for _, s := range test.sources {
+					set = append(set, &parser.SkaffoldConfigEntry{SourceFile: s})
+				}
+				return set, nil
+			})
+
+			var out bytes.Buffer
+			err := Publish(context.Background(), &out, test.args)
+
+			if test.expectedErr != "" {
+				t.CheckErrorContains(test.expectedErr, err)
+				return
+			}
+			t.CheckNoError(err)
+			t.CheckContains("sha256: ", out.String())
+			_, err = os.Stat(tmp.Path(test.output))
+			t.CheckNoError(err)
+		})
+	}
+}
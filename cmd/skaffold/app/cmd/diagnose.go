@@ -88,6 +88,7 @@ func printArtifactDiagnostics(ctx context.Context, out io.Writer, configs []sche
 		fmt.Fprintln(out, "Skaffold version:", version.Get().GitCommit)
 		fmt.Fprintln(out, "Configuration version:", config.APIVersion)
 		fmt.Fprintln(out, "Number of artifacts:", len(config.Build.Artifacts))
+		fmt.Fprintln(out, "Image loader:", runCtx.ImageLoader())
 
 		if err := diagnose.CheckArtifacts(ctx, runCtx, out); err != nil {
 			return fmt.Errorf("running diagnostic on artifacts: %w", err)
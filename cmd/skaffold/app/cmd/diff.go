@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
+)
+
+// NewCmdDiff describes the CLI command to diff rendered manifests against the live cluster.
+func NewCmdDiff() *cobra.Command {
+	return NewCmd("diff").
+		WithDescription("[alpha] Show how deploying would change the resources on the cluster").
+		WithLongDescription("Build the artifacts, render the Kubernetes manifests and compare them with the resources that are live on the cluster. Nothing is deployed.").
+		WithExample("Show the changes as a diff", "diff").
+		WithExample("Compare with the cluster without building the images", "diff --build-artifacts=tags.json").
+		WithExample("Output the changes as json, for example to comment on a pull request", "diff --diff-output=json").
+		WithCommonFlags().
+		WithFlags([]*Flag{
+			{Value: &showBuild, Name: "loud", DefValue: false, Usage: "Show the build logs and output", IsEnum: true},
+			{Value: &renderFromBuildOutputFile, Name: "build-artifacts", Shorthand: "a", Usage: "File containing build result from a previous 'skaffold build --file-output'"},
+		}).
+		NoArgs(doDiff)
+}
+
+func doDiff(ctx context.Context, out io.Writer) error {
+	buildOut := ioutil.Discard
+	if showBuild {
+		buildOut = out
+	}
+
+	return withRunner(ctx, out, func(r runner.Runner, configs []util.VersionedConfig) error {
+		var bRes []graph.Artifact
+
+		if renderFromBuildOutputFile.String() != "" {
+			bRes = renderFromBuildOutputFile.BuildArtifacts()
+		} else {
+			var err error
+			bRes, err = r.Build(ctx, buildOut, targetArtifacts(opts, configs))
+			if err != nil {
+				return fmt.Errorf("executing build: %w", err)
+			}
+		}
+
+		return r.Diff(ctx, out, bRes)
+	})
+}
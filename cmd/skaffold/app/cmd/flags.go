@@ -28,6 +28,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/cmd/skaffold/app/flags"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 )
 
 var (
@@ -139,6 +140,23 @@ var flagRegistry = []Flag{
 		FlagAddMethod: "StringVar",
 		DefinedOn:     []string{"dev", "build", "run", "debug"},
 	},
+	{
+		Name:          "cache-repo",
+		Usage:         "Specify an image repository where the images built for each artifact hash are shared across machines",
+		Value:         &opts.CacheRepo,
+		DefValue:      "",
+		FlagAddMethod: "StringVar",
+		DefinedOn:     []string{"dev", "build", "run", "debug"},
+	},
+	{
+		Name:          "cache-repo-readonly",
+		Usage:         "Only reuse images from the cache repository, without pushing new ones to it",
+		Value:         &opts.CacheRepoReadOnly,
+		DefValue:      false,
+		FlagAddMethod: "BoolVar",
+		DefinedOn:     []string{"dev", "build", "run", "debug"},
+		IsEnum:        true,
+	},
 	{
 		Name:          "remote-cache-dir",
 		Usage:         "Specify the location of the git repositories cache (default $HOME/.skaffold/repos)",
@@ -283,6 +301,24 @@ var flagRegistry = []Flag{
 		DefinedOn:     []string{"dev", "run", "deploy", "debug"},
 		IsEnum:        true,
 	},
+	{
+		Name:          "file-syncer",
+		Usage:         "File sync implementation: 'kubectl' runs `kubectl exec` processes, 'native' streams the changes to each container through the Kubernetes API in a single round trip (kubectl, native)",
+		Value:         &opts.FileSyncer,
+		DefValue:      "kubectl",
+		FlagAddMethod: "StringVar",
+		DefinedOn:     []string{"dev", "debug"},
+		IsEnum:        true,
+	},
+	{
+		Name:          "port-forwarder",
+		Usage:         "Port forwarding implementation: 'kubectl' runs a `kubectl port-forward` process per port, 'native' forwards ports through the Kubernetes API and fails over to the newest pod when the forwarded pod is deleted (kubectl, native)",
+		Value:         &opts.PortForwarder,
+		DefValue:      "kubectl",
+		FlagAddMethod: "StringVar",
+		DefinedOn:     []string{"dev", "run", "deploy", "debug"},
+		IsEnum:        true,
+	},
 	{
 		Name:          "status-check",
 		Usage:         "Wait for deployed resources to stabilize",
@@ -293,6 +329,16 @@ var flagRegistry = []Flag{
 		IsEnum:        true,
 		NoOptDefVal:   "true",
 	},
+	{
+		Name:          "rollback-on-failure",
+		Usage:         "Revert the deployed resources to their previous state if the deployment fails or they fail to stabilize",
+		Value:         &opts.RollbackOnFailure,
+		DefValue:      nil,
+		FlagAddMethod: "Var",
+		DefinedOn:     []string{"deploy", "run", "apply"},
+		IsEnum:        true,
+		NoOptDefVal:   "true",
+	},
 	{
 		Name:          "iterative-status-check",
 		Usage:         "Run `status-check` iteratively after each deploy step, instead of all-together at the end of all deploys (default).",
@@ -302,6 +348,23 @@ var flagRegistry = []Flag{
 		DefinedOn:     []string{"dev", "debug", "deploy", "run", "apply"},
 		IsEnum:        true,
 	},
+	{
+		Name:          "preview",
+		Usage:         "Show how the rendered manifests would change the resources on the cluster, instead of deploying them",
+		Value:         &opts.Preview,
+		DefValue:      false,
+		FlagAddMethod: "BoolVar",
+		DefinedOn:     []string{"deploy", "run"},
+		IsEnum:        true,
+	},
+	{
+		Name:          "diff-output",
+		Usage:         "Format of the changes shown by `skaffold diff` and `--preview`. One of [text json]",
+		Value:         &opts.DiffFormat,
+		DefValue:      diff.TextFormat,
+		FlagAddMethod: "StringVar",
+		DefinedOn:     []string{"diff", "deploy", "run"},
+	},
 	{
 		Name:          "render-only",
 		Usage:         "Print rendered Kubernetes manifests instead of deploying them",
@@ -543,6 +606,14 @@ var flagRegistry = []Flag{
 		DefinedOn:     []string{"debug", "dev", "run"},
 		IsEnum:        true,
 	},
+	{
+		Name:          "platform",
+		Usage:         "The platforms to build images for, of the form os/arch[/variant] (overrides the `platforms` set in skaffold.yaml). For example: --platform=linux/amd64,linux/arm64",
+		Value:         &opts.Platforms,
+		DefValue:      []string{},
+		FlagAddMethod: "StringSliceVar",
+		DefinedOn:     []string{"dev", "build", "run", "debug"},
+	},
 	{
 		Name:          "build-concurrency",
 		Usage:         "Number of concurrently running builds. Set to 0 to run all builds in parallel. Doesn't violate build order among dependencies.",
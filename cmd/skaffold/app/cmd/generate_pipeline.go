@@ -20,35 +20,47 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	pipeline "github.com/GoogleContainerTools/skaffold/pkg/skaffold/generate_pipeline"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
 )
 
 var (
-	configFiles []string
+	configFiles    []string
+	pipelineTarget string
+	pipelineFile   string
 )
 
 func NewCmdGeneratePipeline() *cobra.Command {
 	return NewCmd("generate-pipeline").
 		Hidden().
-		WithDescription("[ALPHA] Generate tekton pipeline from skaffold.yaml").
+		WithDescription("[ALPHA] Generate a Tekton, GitHub Actions or GitLab CI pipeline from skaffold.yaml").
+		WithExample("Generate a Tekton pipeline", "generate-pipeline").
+		WithExample("Generate a GitHub Actions workflow for the given modules and profile", "generate-pipeline --target=github-actions -m frontend,backend -p prod").
 		WithCommonFlags().
 		WithFlags([]*Flag{
 			{Value: &configFiles, Name: "config-files", DefValue: []string{}, Usage: "Select additional files whose artifacts to use when generating pipeline."},
+			{Value: &pipelineTarget, Name: "target", DefValue: pipeline.TargetTekton, Usage: fmt.Sprintf("CI system to generate the pipeline for. One of: %s.", strings.Join(pipeline.Targets, ", "))},
+			{Value: &pipelineFile, Name: "pipeline-file", DefValue: "", Usage: "File to write the pipeline to. Defaults to pipeline.yaml for Tekton, .github/workflows/skaffold.yaml for GitHub Actions and .gitlab-ci.yml for GitLab CI."},
 		}).
 		NoArgs(doGeneratePipeline)
 }
 
 func doGeneratePipeline(ctx context.Context, out io.Writer) error {
 	return withRunner(ctx, out, func(r runner.Runner, configs []util.VersionedConfig) error {
-		if err := r.GeneratePipeline(ctx, out, configs, configFiles, "pipeline.yaml"); err != nil {
+		fileOut := pipelineFile
+		if fileOut == "" {
+			fileOut = pipeline.OutputFile(pipelineTarget)
+		}
+		if err := r.GeneratePipeline(ctx, out, configs, configFiles, pipelineTarget, fileOut); err != nil {
 			return fmt.Errorf("generating : %w", err)
 		}
-		output.Default.Fprintln(out, "Pipeline config written to pipeline.yaml!")
+		output.Default.Fprintf(out, "Pipeline config written to %s!\n", fileOut)
 		return nil
 	})
 }
@@ -20,10 +20,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 
 	"github.com/spf13/cobra"
 
 	"github.com/GoogleContainerTools/skaffold/cmd/skaffold/app/tips"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
 )
@@ -41,14 +43,20 @@ func NewCmdRun() *cobra.Command {
 }
 
 func doRun(ctx context.Context, out io.Writer) error {
+	buildOut := out
+	if opts.Preview && opts.DiffFormat == diff.JSONFormat {
+		// keep the output machine-readable
+		buildOut = ioutil.Discard
+	}
+
 	return withRunner(ctx, out, func(r runner.Runner, configs []util.VersionedConfig) error {
-		bRes, err := r.Build(ctx, out, targetArtifacts(opts, configs))
+		bRes, err := r.Build(ctx, buildOut, targetArtifacts(opts, configs))
 		if err != nil {
 			return fmt.Errorf("failed to build: %w", err)
 		}
 
 		if !opts.SkipTests {
-			err = r.Test(ctx, out, bRes)
+			err = r.Test(ctx, buildOut, bRes)
 			if err != nil {
 				return fmt.Errorf("failed to test: %w", err)
 			}
@@ -59,7 +67,9 @@ func doRun(ctx context.Context, out io.Writer) error {
 			return fmt.Errorf("failed to deploy: %w", err)
 		}
 
-		tips.PrintForRun(out, opts)
+		if !opts.Preview {
+			tips.PrintForRun(out, opts)
+		}
 
 		return nil
 	})
@@ -17,7 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"testing"
@@ -53,7 +55,8 @@ type mockRunRunner struct {
 	artifactImageNames []string
 }
 
-func (r *mockRunRunner) Build(_ context.Context, _ io.Writer, artifacts []*latestV1.Artifact) ([]graph.Artifact, error) {
+func (r *mockRunRunner) Build(_ context.Context, out io.Writer, artifacts []*latestV1.Artifact) ([]graph.Artifact, error) {
+	fmt.Fprintln(out, "Building...")
 	var result []graph.Artifact
 	for _, artifact := range artifacts {
 		imageName := artifact.ImageName
@@ -71,7 +74,8 @@ func (r *mockRunRunner) Test(context.Context, io.Writer, []graph.Artifact) error
 	return nil
 }
 
-func (r *mockRunRunner) DeployAndLog(context.Context, io.Writer, []graph.Artifact) error {
+func (r *mockRunRunner) DeployAndLog(_ context.Context, out io.Writer, _ []graph.Artifact) error {
+	fmt.Fprintln(out, "Deploying...")
 	r.deployRan = true
 	return nil
 }
@@ -121,3 +125,39 @@ func TestDoRun(t *testing.T) {
 		})
 	}
 }
+
+func TestDoRunPreview(t *testing.T) {
+	tests := []struct {
+		description string
+		format      string
+		expected    string
+	}{
+		{
+			description: "text diff",
+			format:      "text",
+			expected:    "Building...\nDeploying...\n",
+		},
+		{
+			description: "json diff excludes build output",
+			format:      "json",
+			expected:    "Deploying...\n",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&createRunner, func(io.Writer, config.SkaffoldOptions) (runner.Runner, []util.VersionedConfig, *runcontext.RunContext, error) {
+				return &mockRunRunner{}, []util.VersionedConfig{&latestV1.SkaffoldConfig{}}, nil, nil
+			})
+			t.Override(&opts, config.SkaffoldOptions{
+				Preview:    true,
+				DiffFormat: test.format,
+				SkipTests:  true,
+			})
+
+			var out bytes.Buffer
+			err := doRun(context.Background(), &out)
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, out.String())
+		})
+	}
+}
@@ -69,7 +69,7 @@ func createNewRunner(out io.Writer, opts config.SkaffoldOptions) (runner.Runner,
 	for _, c := range configs {
 		v1Configs = append(v1Configs, c.(*latestV1.SkaffoldConfig))
 	}
-	instrumentation.Init(v1Configs, opts.User)
+	instrumentation.Init(v1Configs, opts.User, opts.GlobalConfig)
 	hooks.SetupStaticEnvOptions(runCtx)
 	runner, err := v1.NewForConfig(runCtx)
 	if err != nil {
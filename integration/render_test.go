@@ -78,7 +78,7 @@ spec:
 		t.NewTempDir().
 			Write("deployment.yaml", test.input).
 			Chdir()
-		deployer, err := kubectl.NewDeployer(&runcontext.RunContext{
+		deployer, err := kubectl.NewDeployer(deployerConfig{&runcontext.RunContext{
 			WorkingDir: ".",
 			Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{
 				Deploy: latestV1.DeployConfig{
@@ -89,7 +89,7 @@ spec:
 					},
 				},
 			}}),
-		}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+		}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 			Manifests: []string{"deployment.yaml"},
 		})
 		t.RequireNoError(err)
@@ -235,7 +235,7 @@ spec:
 				Write("deployment.yaml", test.input).
 				Chdir()
 
-			deployer, err := kubectl.NewDeployer(&runcontext.RunContext{
+			deployer, err := kubectl.NewDeployer(deployerConfig{&runcontext.RunContext{
 				WorkingDir: ".",
 				Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{
 					Deploy: latestV1.DeployConfig{
@@ -249,7 +249,7 @@ spec:
 				Opts: config.SkaffoldOptions{
 					AddSkaffoldLabels: true,
 				},
-			}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+			}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 				Manifests: []string{"deployment.yaml"},
 			})
 			t.RequireNoError(err)
@@ -425,7 +425,7 @@ spec:
 	}
 	for _, test := range tests {
 		testutil.Run(t, test.description, func(t *testutil.T) {
-			deployer, err := helm.NewDeployer(&runcontext.RunContext{
+			deployer, err := helm.NewDeployer(deployerConfig{&runcontext.RunContext{
 				Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{
 					Deploy: latestV1.DeployConfig{
 						DeployType: latestV1.DeployType{
@@ -435,7 +435,7 @@ spec:
 						},
 					},
 				}}),
-			}, &label.DefaultLabeller{}, &latestV1.HelmDeploy{
+			}}, &label.DefaultLabeller{}, &latestV1.HelmDeploy{
 				Releases: test.helmReleases,
 			})
 			t.RequireNoError(err)
@@ -742,3 +742,10 @@ spec:
 		})
 	}
 }
+
+// deployerConfig adds the per-config deploy settings that a RunContext doesn't provide.
+type deployerConfig struct {
+	*runcontext.RunContext
+}
+
+func (deployerConfig) RollbackOnFailure() bool { return false }
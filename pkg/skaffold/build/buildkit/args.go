@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// Address returns the `buildctl` address of a BuildKit daemon running in a pod,
+// reached through `kubectl exec`.
+func Address(kubeContext, namespace, pod string) string {
+	query := url.Values{}
+	if kubeContext != "" {
+		query.Set("context", kubeContext)
+	}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	query.Set("container", DaemonContainerName)
+	return fmt.Sprintf("kube-pod://%s?%s", pod, query.Encode())
+}
+
+// Args returns the `buildctl` arguments to build an artifact, push it as the given tag,
+// and write the build metadata, including the image digest, to metadataFile.
+func Args(artifact *latestV1.BuildKitArtifact, workspace, tag, address, metadataFile string, platforms []string, insecureRegistries map[string]bool) ([]string, error) {
+	dockerfile := artifact.DockerfilePath
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(workspace, dockerfile)
+	}
+
+	args := []string{
+		"--addr", address,
+		"build",
+		"--progress=plain",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + workspace,
+		"--local", "dockerfile=" + filepath.Dir(dockerfile),
+		"--opt", "filename=" + filepath.Base(dockerfile),
+	}
+
+	if artifact.Target != "" {
+		args = append(args, "--opt", "target="+artifact.Target)
+	}
+
+	var keys []string
+	for k := range artifact.BuildArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v := artifact.BuildArgs[k]; v != nil {
+			args = append(args, "--opt", fmt.Sprintf("build-arg:%s=%s", k, *v))
+		}
+	}
+
+	if len(platforms) > 0 {
+		args = append(args, "--opt", "platform="+strings.Join(platforms, ","))
+	}
+
+	for _, secret := range artifact.Secrets {
+		s := "id=" + secret.ID
+		if secret.Source != "" {
+			s += ",src=" + secret.Source
+		}
+		args = append(args, "--secret", s)
+	}
+
+	for _, ssh := range artifact.SSH {
+		args = append(args, "--ssh", ssh)
+	}
+
+	for _, ref := range artifact.CacheFrom {
+		args = append(args, "--import-cache", "type=registry,ref="+ref)
+	}
+	if artifact.CacheTo != "" {
+		args = append(args, "--export-cache", "type=registry,mode=max,ref="+artifact.CacheTo)
+	}
+
+	output := "type=image,name=" + tag + ",push=true"
+	ref, err := name.ParseReference(tag)
+	if err != nil {
+		return nil, fmt.Errorf("parsing tag %q: %w", tag, err)
+	}
+	if insecureRegistries[ref.Context().RegistryStr()] {
+		output += ",registry.insecure=true"
+	}
+	args = append(args, "--output", output, "--metadata-file", metadataFile)
+
+	return args, nil
+}
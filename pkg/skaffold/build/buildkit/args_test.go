@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"path/filepath"
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestAddress(t *testing.T) {
+	testutil.CheckDeepEqual(t, "kube-pod://skaffold-buildkitd-abcde?container=buildkitd&context=kind-kind&namespace=builds", Address("kind-kind", "builds", "skaffold-buildkitd-abcde"))
+	testutil.CheckDeepEqual(t, "kube-pod://skaffold-buildkitd-abcde?container=buildkitd", Address("", "", "skaffold-buildkitd-abcde"))
+}
+
+func TestArgs(t *testing.T) {
+	workspace := filepath.Join("path", "to", "app")
+	baseArgs := []string{
+		"--addr", "kube-pod://buildkitd",
+		"build",
+		"--progress=plain",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + workspace,
+		"--local", "dockerfile=" + workspace,
+		"--opt", "filename=Dockerfile",
+	}
+
+	tests := []struct {
+		description        string
+		artifact           *latestV1.BuildKitArtifact
+		tag                string
+		platforms          []string
+		insecureRegistries map[string]bool
+		expectedArgs       []string
+		shouldErr          bool
+	}{
+		{
+			description:  "simple build",
+			artifact:     &latestV1.BuildKitArtifact{DockerfilePath: "Dockerfile"},
+			tag:          "gcr.io/project/app:v1",
+			expectedArgs: []string{"--output", "type=image,name=gcr.io/project/app:v1,push=true", "--metadata-file", "metadata.json"},
+		},
+		{
+			description: "dockerfile in a sub-directory",
+			artifact:    &latestV1.BuildKitArtifact{DockerfilePath: filepath.Join("build", "app.Dockerfile")},
+			tag:         "gcr.io/project/app:v1",
+			expectedArgs: []string{
+				"--addr", "kube-pod://buildkitd",
+				"build",
+				"--progress=plain",
+				"--frontend", "dockerfile.v0",
+				"--local", "context=" + workspace,
+				"--local", "dockerfile=" + filepath.Join(workspace, "build"),
+				"--opt", "filename=app.Dockerfile",
+				"--output", "type=image,name=gcr.io/project/app:v1,push=true", "--metadata-file", "metadata.json",
+			},
+		},
+		{
+			description: "target, build args, platforms, secrets, ssh and cache",
+			artifact: &latestV1.BuildKitArtifact{
+				DockerfilePath: "Dockerfile",
+				Target:         "release",
+				BuildArgs: map[string]*string{
+					"VERSION": util.StringPtr("1.0"),
+					"ARCH":    util.StringPtr("amd64"),
+					"UNSET":   nil,
+				},
+				Secrets:   []*latestV1.DockerSecret{{ID: "npmrc", Source: "/home/user/.npmrc"}, {ID: "token"}},
+				SSH:       []string{"default", "github=/home/user/.ssh/id_rsa"},
+				CacheFrom: []string{"gcr.io/project/app:buildcache", "gcr.io/project/base:buildcache"},
+				CacheTo:   "gcr.io/project/app:buildcache",
+			},
+			tag:       "gcr.io/project/app:v1",
+			platforms: []string{"linux/amd64", "linux/arm64"},
+			expectedArgs: []string{
+				"--opt", "target=release",
+				"--opt", "build-arg:ARCH=amd64",
+				"--opt", "build-arg:VERSION=1.0",
+				"--opt", "platform=linux/amd64,linux/arm64",
+				"--secret", "id=npmrc,src=/home/user/.npmrc",
+				"--secret", "id=token",
+				"--ssh", "default",
+				"--ssh", "github=/home/user/.ssh/id_rsa",
+				"--import-cache", "type=registry,ref=gcr.io/project/app:buildcache",
+				"--import-cache", "type=registry,ref=gcr.io/project/base:buildcache",
+				"--export-cache", "type=registry,mode=max,ref=gcr.io/project/app:buildcache",
+				"--output", "type=image,name=gcr.io/project/app:v1,push=true", "--metadata-file", "metadata.json",
+			},
+		},
+		{
+			description:        "insecure registry",
+			artifact:           &latestV1.BuildKitArtifact{DockerfilePath: "Dockerfile"},
+			tag:                "registry.local:5000/app:v1",
+			insecureRegistries: map[string]bool{"registry.local:5000": true},
+			expectedArgs:       []string{"--output", "type=image,name=registry.local:5000/app:v1,push=true,registry.insecure=true", "--metadata-file", "metadata.json"},
+		},
+		{
+			description: "invalid tag",
+			artifact:    &latestV1.BuildKitArtifact{DockerfilePath: "Dockerfile"},
+			tag:         "INVALID:::",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			args, err := Args(test.artifact, workspace, test.tag, "kube-pod://buildkitd", "metadata.json", test.platforms, test.insecureRegistries)
+
+			t.CheckError(test.shouldErr, err)
+			if !test.shouldErr {
+				expected := test.expectedArgs
+				if expected[0] != "--addr" {
+					expected = append(append([]string{}, baseArgs...), expected...)
+				}
+				t.CheckDeepEqual(expected, args)
+			}
+		})
+	}
+}
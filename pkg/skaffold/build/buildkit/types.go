@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+const (
+	// DefaultImage is the default image of the BuildKit daemon.
+	DefaultImage = "moby/buildkit:v0.9.0"
+	// DaemonName is the name of the BuildKit daemon pod, which is reused across builds.
+	DaemonName = "skaffold-buildkitd"
+	// DaemonContainerName is the name of the BuildKit daemon container.
+	DaemonContainerName = "buildkitd"
+	// DaemonSpecAnnotation records a hash of the daemon pod's spec, to recreate the pod when it changes.
+	DaemonSpecAnnotation = "skaffold.dev/buildkitd-spec"
+	// DaemonLabel labels the BuildKit daemon pods created by Skaffold.
+	DaemonLabel = "skaffold-buildkitd"
+	// DaemonOwnerLabel identifies the user and the machine that created a BuildKit daemon pod.
+	DaemonOwnerLabel = "skaffold.dev/buildkitd-owner"
+	// DaemonCacheDirName is the name of the volume holding the daemon's local cache.
+	DaemonCacheDirName = "buildkit-cache"
+	// DaemonCacheDirMountPath is where the daemon's local cache is stored.
+	DaemonCacheDirMountPath = "/var/lib/buildkit"
+)
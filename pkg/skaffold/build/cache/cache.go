@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/sirupsen/logrus"
@@ -40,8 +41,17 @@ import (
 type ImageDetails struct {
 	Digest string `yaml:"digest,omitempty"`
 	ID     string `yaml:"id,omitempty"`
+
+	// Artifact, Tag and Created describe the build that produced the image,
+	// so that `skaffold cache` can list and prune the entries.
+	Artifact string    `yaml:"artifact,omitempty"`
+	Tag      string    `yaml:"tag,omitempty"`
+	Created  time.Time `yaml:"created,omitempty"`
 }
 
+// for testing
+var timeNow = time.Now
+
 // ArtifactCache is a map of [artifact dependencies hash : ImageDetails]
 type ArtifactCache map[string]ImageDetails
 
@@ -54,6 +64,7 @@ type cache struct {
 	client             docker.LocalDaemon
 	cfg                Config
 	cacheFile          string
+	cacheRepo          string
 	isLocalImage       func(imageName string) (bool, error)
 	importMissingImage func(imageName string) (bool, error)
 	lister             DependencyLister
@@ -70,6 +81,8 @@ type Config interface {
 	GetCluster() config.Cluster
 	CacheArtifacts() bool
 	CacheFile() string
+	CacheRepo() string
+	CacheRepoReadOnly() bool
 	Mode() config.RunMode
 }
 
@@ -122,6 +135,7 @@ func NewCache(cfg Config, isLocalImage func(imageName string) (bool, error), dep
 		client:             client,
 		cfg:                cfg,
 		cacheFile:          cacheFile,
+		cacheRepo:          cfg.CacheRepo(),
 		isLocalImage:       isLocalImage,
 		importMissingImage: importMissingImage,
 		lister:             dependencies,
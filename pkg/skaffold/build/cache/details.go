@@ -19,8 +19,6 @@ package cache
 import (
 	"context"
 	"io"
-
-	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
 )
 
 type cacheDetails interface {
@@ -86,7 +84,54 @@ func (d needsRemoteTagging) Hash() string {
 
 func (d needsRemoteTagging) Tag(ctx context.Context, c *cache) error {
 	fqn := d.tag + "@" + d.digest // Tag is not important. We just need the registry and the digest to locate the image.
-	return docker.AddRemoteTag(fqn, d.tag, c.cfg)
+	return addRemoteTag(fqn, d.tag, c.cfg)
+}
+
+// Found in the cache repository. Needs copying to the artifact's repository
+type needsCopying struct {
+	hash string
+	tag  string
+	src  string
+}
+
+func (d needsCopying) Hash() string {
+	return d.hash
+}
+
+func (d needsCopying) Copy(ctx context.Context, c *cache) error {
+	return addRemoteTag(d.src, d.tag, c.cfg)
+}
+
+// Found in the cache repository. Needs pulling into the local Docker daemon
+type needsPulling struct {
+	hash     string
+	tag      string
+	src      string
+	artifact string
+}
+
+func (d needsPulling) Hash() string {
+	return d.hash
+}
+
+func (d needsPulling) Pull(ctx context.Context, out io.Writer, c *cache) error {
+	if err := c.client.Pull(ctx, out, d.src); err != nil {
+		return err
+	}
+
+	imageID, err := c.client.ImageID(ctx, d.src)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Tag(ctx, imageID, d.tag); err != nil {
+		return err
+	}
+
+	// Update cache
+	c.cacheMutex.Lock()
+	c.artifactCache[d.hash] = ImageDetails{ID: imageID, Artifact: d.artifact, Tag: d.tag, Created: timeNow()}
+	c.cacheMutex.Unlock()
+	return nil
 }
 
 // Found locally. Needs pushing
@@ -26,10 +26,12 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/buildpacks"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/misc"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
@@ -123,6 +125,11 @@ func singleArtifactHash(ctx context.Context, depLister DependencyLister, a *late
 	}
 	inputs = append(inputs, config)
 
+	// Append the target platforms
+	if len(a.Platforms) > 0 {
+		inputs = append(inputs, strings.Join(a.Platforms, ","))
+	}
+
 	// Append the digest of each input file
 	deps, err := depLister(ctx, a)
 	if err != nil {
@@ -183,8 +190,14 @@ func hashBuildArgs(artifact *latestV1.Artifact, mode config.RunMode) ([]string,
 		args, err = docker.EvalBuildArgs(mode, artifact.Workspace, artifact.DockerArtifact.DockerfilePath, artifact.DockerArtifact.BuildArgs, nil)
 	case artifact.KanikoArtifact != nil:
 		args, err = docker.EvalBuildArgs(mode, artifact.Workspace, artifact.KanikoArtifact.DockerfilePath, artifact.KanikoArtifact.BuildArgs, nil)
+	case artifact.BuildKitArtifact != nil:
+		args, err = docker.EvalBuildArgs(mode, artifact.Workspace, artifact.BuildKitArtifact.DockerfilePath, artifact.BuildKitArtifact.BuildArgs, nil)
 	case artifact.BuildpackArtifact != nil:
 		env, err = buildpacks.GetEnv(artifact, mode)
+	case artifact.KoArtifact != nil:
+		var evaluated []string
+		evaluated, err = misc.EvaluateEnv(artifact.KoArtifact.Env)
+		env = util.EnvSliceToMap(evaluated, "=")
 	case artifact.CustomArtifact != nil && artifact.CustomArtifact.Dependencies.Dockerfile != nil:
 		args, err = util.EvaluateEnvTemplateMap(artifact.CustomArtifact.Dependencies.Dockerfile.BuildArgs)
 	default:
@@ -524,6 +524,21 @@ func TestHashBuildArgs(t *testing.T) {
 			},
 			mode:     config.RunModes.Debug,
 			expected: []string{"GOOGLE_GOGCFLAGS=all=-N -l"},
+		}, {
+			description: "ko artifact with env",
+			artifactType: latestV1.ArtifactType{
+				KoArtifact: &latestV1.KoArtifact{
+					Env: []string{"foo=bar", "CGO_ENABLED=0"},
+				},
+			},
+			mode:     config.RunModes.Dev,
+			expected: []string{"CGO_ENABLED=0", "foo=bar"},
+		}, {
+			description: "ko artifact without env",
+			artifactType: latestV1.ArtifactType{
+				KoArtifact: &latestV1.KoArtifact{},
+			},
+			mode: config.RunModes.Dev,
 		}, {
 			description: "custom artifact, dockerfile dependency, with build args",
 			artifactType: latestV1.ArtifactType{
@@ -69,17 +69,24 @@ func (c *cache) lookup(ctx context.Context, a *latestV1.Artifact, tag string, h
 	c.cacheMutex.RUnlock()
 	if !cacheHit {
 		if entry, err = c.tryImport(ctx, a, tag, hash); err != nil {
-			logrus.Debugf("Could not import artifact from Docker, building instead (%s)", err)
-			return needsBuilding{hash: hash}
+			logrus.Debugf("Could not import artifact from Docker, looking in the cache repository (%s)", err)
+			return c.lookupCacheRepo(ctx, a, tag, hash)
 		}
 	}
 
+	var details cacheDetails
 	if isLocal, err := c.isLocalImage(a.ImageName); err != nil {
 		return failed{err}
 	} else if isLocal {
-		return c.lookupLocal(ctx, hash, tag, entry)
+		details = c.lookupLocal(ctx, hash, tag, entry)
+	} else {
+		details = c.lookupRemote(ctx, hash, tag, entry)
+	}
+
+	if _, needsBuilding := details.(needsBuilding); needsBuilding {
+		return c.lookupCacheRepo(ctx, a, tag, hash)
 	}
-	return c.lookupRemote(ctx, hash, tag, entry)
+	return details
 }
 
 func (c *cache) lookupLocal(ctx context.Context, hash, tag string, entry ImageDetails) cacheDetails {
@@ -132,7 +139,7 @@ func (c *cache) lookupRemote(ctx context.Context, hash, tag string, entry ImageD
 }
 
 func (c *cache) tryImport(ctx context.Context, a *latestV1.Artifact, tag string, hash string) (ImageDetails, error) {
-	entry := ImageDetails{}
+	entry := ImageDetails{Artifact: a.ImageName, Tag: tag, Created: timeNow()}
 
 	if importMissing, err := c.importMissingImage(a.ImageName); err != nil {
 		return entry, err
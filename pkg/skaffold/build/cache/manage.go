@@ -0,0 +1,205 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+)
+
+// Entry is an entry of the artifact cache, as listed by `skaffold cache list`.
+type Entry struct {
+	Hash     string     `json:"hash"`
+	Artifact string     `json:"artifact,omitempty"`
+	Tag      string     `json:"tag,omitempty"`
+	Digest   string     `json:"digest,omitempty"`
+	ID       string     `json:"id,omitempty"`
+	Created  *time.Time `json:"created,omitempty"`
+}
+
+// PruneOptions configures which entries of the artifact cache are removed by Prune.
+type PruneOptions struct {
+	// Keep is the number of entries to keep for each artifact. Zero keeps them all.
+	Keep int
+	// OlderThan is the age beyond which entries are removed. Zero keeps them all.
+	OlderThan time.Duration
+}
+
+// PruneResult lists the entries removed from the artifact cache and the local images that were deleted.
+type PruneResult struct {
+	Removed       []Entry  `json:"removed"`
+	DeletedImages []string `json:"deletedImages"`
+}
+
+// ListEntries returns the entries of the artifact cache, sorted by artifact and most recent first.
+func ListEntries(cacheFile string) ([]Entry, error) {
+	cacheFile, artifactCache, err := readCacheFile(cacheFile)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Listing artifact cache %s", cacheFile)
+
+	return sortedEntries(artifactCache), nil
+}
+
+// Prune removes the entries of the artifact cache whose images don't exist anymore, either in the local
+// Docker daemon or in a registry. Then, for each artifact, it removes the entries beyond the retention count or age,
+// and deletes their images from the local Docker daemon, unless they are still used by another entry.
+// Images are never deleted from registries.
+// The Docker client can be nil, in which case local images are neither checked nor deleted.
+func Prune(ctx context.Context, cacheFile string, cfg docker.Config, client docker.LocalDaemon, opts PruneOptions) (PruneResult, error) {
+	cacheFile, artifactCache, err := readCacheFile(cacheFile)
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	var result PruneResult
+	kept := map[string]int{}
+	now := timeNow()
+	for _, e := range sortedEntries(artifactCache) {
+		stale := !imageExists(ctx, cfg, client, e)
+		expired := (opts.Keep > 0 && kept[e.Artifact] >= opts.Keep) ||
+			(opts.OlderThan > 0 && e.Created != nil && now.Sub(*e.Created) > opts.OlderThan)
+		if !stale && !expired {
+			kept[e.Artifact]++
+			continue
+		}
+
+		delete(artifactCache, e.Hash)
+		result.Removed = append(result.Removed, e)
+	}
+
+	// Delete the local images that no remaining entry references.
+	if client != nil {
+		referenced := map[string]bool{}
+		for _, details := range artifactCache {
+			referenced[details.ID] = true
+		}
+		var unreferenced []string
+		for _, e := range result.Removed {
+			if e.ID != "" && !referenced[e.ID] && client.ImageExists(ctx, e.ID) {
+				referenced[e.ID] = true
+				unreferenced = append(unreferenced, e.ID)
+			}
+		}
+		if len(unreferenced) > 0 {
+			result.DeletedImages, err = client.Prune(ctx, unreferenced, true)
+			if err != nil {
+				logrus.Warnf("Some images could not be deleted: %v", err)
+			}
+		}
+	}
+
+	return result, saveArtifactCache(cacheFile, artifactCache)
+}
+
+// Clear removes all the entries of the artifact cache and returns them.
+func Clear(cacheFile string) ([]Entry, error) {
+	cacheFile, artifactCache, err := readCacheFile(cacheFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedEntries(artifactCache), saveArtifactCache(cacheFile, ArtifactCache{})
+}
+
+func readCacheFile(cacheFile string) (string, ArtifactCache, error) {
+	cacheFile, err := resolveCacheFile(cacheFile)
+	if err != nil {
+		return "", nil, err
+	}
+
+	artifactCache, err := retrieveArtifactCache(cacheFile)
+	return cacheFile, artifactCache, err
+}
+
+func sortedEntries(artifactCache ArtifactCache) []Entry {
+	var entries []Entry
+	for hash, details := range artifactCache {
+		e := Entry{
+			Hash:     hash,
+			Artifact: details.Artifact,
+			Tag:      details.Tag,
+			Digest:   details.Digest,
+			ID:       details.ID,
+		}
+		if !details.Created.IsZero() {
+			created := details.Created
+			e.Created = &created
+		}
+		entries = append(entries, e)
+	}
+
+	// Entries created before their creation time was recorded come last.
+	sort.Slice(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if ei.Artifact != ej.Artifact {
+			return ei.Artifact < ej.Artifact
+		}
+		if (ei.Created == nil) != (ej.Created == nil) {
+			return ei.Created != nil
+		}
+		if ei.Created != nil && !ei.Created.Equal(*ej.Created) {
+			return ei.Created.After(*ej.Created)
+		}
+		return ei.Hash < ej.Hash
+	})
+	return entries
+}
+
+// imageExists checks whether the image of an entry can still be found. Entries
+// whose image locations can't be checked are considered to exist.
+func imageExists(ctx context.Context, cfg docker.Config, client docker.LocalDaemon, e Entry) bool {
+	checked := false
+
+	if e.ID != "" && client != nil {
+		if client.ImageExists(ctx, e.ID) {
+			return true
+		}
+		checked = true
+	}
+
+	if e.Digest != "" && e.Tag != "" {
+		if ref, err := docker.ParseReference(e.Tag); err == nil {
+			_, err := docker.RemoteDigest(ref.BaseName+"@"+e.Digest, cfg)
+			if err == nil {
+				return true
+			}
+			if !isNotFound(err) {
+				logrus.Debugf("Could not check if %s@%s exists: %v", ref.BaseName, e.Digest, err)
+				return true
+			}
+			checked = true
+		}
+	}
+
+	return !checked
+}
+
+// isNotFound tells if a registry error means that an image doesn't exist, rather than it can't be reached.
+func isNotFound(err error) bool {
+	var transportErr *transport.Error
+	return errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+var (
+	now      = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	hourAgo  = now.Add(-time.Hour)
+	hoursAgo = now.Add(-2 * time.Hour)
+	daysAgo  = now.Add(-48 * time.Hour)
+)
+
+const (
+	digest4 = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+	digest5 = "sha256:5555555555555555555555555555555555555555555555555555555555555555"
+	digest6 = "sha256:6666666666666666666666666666666666666666666666666666666666666666"
+)
+
+func testArtifactCache() ArtifactCache {
+	return ArtifactCache{
+		"h1": {ID: "img1", Artifact: "app", Tag: "app:v1", Created: hourAgo},
+		"h2": {ID: "img2", Artifact: "app", Tag: "app:v2", Created: daysAgo},
+		"h3": {ID: "img3", Artifact: "app", Tag: "app:v3", Created: hoursAgo},
+		"h4": {Digest: digest4, Artifact: "web", Tag: "gcr.io/project/web:v1@" + digest4, Created: hourAgo},
+		"h5": {Digest: digest5, Artifact: "web", Tag: "gcr.io/project/web:v2@" + digest5, Created: daysAgo},
+		"h6": {Digest: digest6, Artifact: "web", Tag: "gcr.io/project/web:v3@" + digest6, Created: hoursAgo},
+		"h7": {ID: "img1"},
+	}
+}
+
+func TestListEntries(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		cacheFile := t.NewTempDir().Path("cache")
+		t.CheckNoError(saveArtifactCache(cacheFile, testArtifactCache()))
+
+		entries, err := ListEntries(cacheFile)
+
+		t.CheckNoError(err)
+		t.CheckDeepEqual([]Entry{
+			{Hash: "h7", ID: "img1"},
+			{Hash: "h1", ID: "img1", Artifact: "app", Tag: "app:v1", Created: &hourAgo},
+			{Hash: "h3", ID: "img3", Artifact: "app", Tag: "app:v3", Created: &hoursAgo},
+			{Hash: "h2", ID: "img2", Artifact: "app", Tag: "app:v2", Created: &daysAgo},
+			{Hash: "h4", Digest: digest4, Artifact: "web", Tag: "gcr.io/project/web:v1@" + digest4, Created: &hourAgo},
+			{Hash: "h6", Digest: digest6, Artifact: "web", Tag: "gcr.io/project/web:v3@" + digest6, Created: &hoursAgo},
+			{Hash: "h5", Digest: digest5, Artifact: "web", Tag: "gcr.io/project/web:v2@" + digest5, Created: &daysAgo},
+		}, entries)
+	})
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		description     string
+		opts            PruneOptions
+		expectedRemoved []string
+		expectedDeleted []string
+		expectedKept    []string
+	}{
+		{
+			description:     "stale entries",
+			expectedRemoved: []string{"h3", "h5"},
+			expectedKept:    []string{"h1", "h2", "h4", "h6", "h7"},
+		},
+		{
+			description:     "retention count",
+			opts:            PruneOptions{Keep: 1},
+			expectedRemoved: []string{"h2", "h3", "h5", "h6"},
+			expectedDeleted: []string{"img2"},
+			expectedKept:    []string{"h1", "h4", "h7"},
+		},
+		{
+			description:     "retention age",
+			opts:            PruneOptions{OlderThan: 24 * time.Hour},
+			expectedRemoved: []string{"h2", "h3", "h5"},
+			expectedDeleted: []string{"img2"},
+			expectedKept:    []string{"h1", "h4", "h6", "h7"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&timeNow, func() time.Time { return now })
+			t.Override(&docker.RemoteDigest, func(identifier string, _ docker.Config) (string, error) {
+				switch identifier {
+				case "gcr.io/project/web@" + digest4:
+					return digest4, nil
+				case "gcr.io/project/web@" + digest5:
+					return "", fmt.Errorf("getting image: %w", &transport.Error{StatusCode: http.StatusNotFound})
+				default:
+					return "", errors.New("registry unreachable")
+				}
+			})
+			cacheFile := t.NewTempDir().Path("cache")
+			t.CheckNoError(saveArtifactCache(cacheFile, testArtifactCache()))
+			client := fakeLocalDaemon((&testutil.FakeAPIClient{}).Add("app:v1", "img1").Add("app:v2", "img2"))
+
+			result, err := Prune(context.Background(), cacheFile, &mockConfig{}, client, test.opts)
+			t.CheckNoError(err)
+
+			var removed []string
+			for _, e := range result.Removed {
+				removed = append(removed, e.Hash)
+			}
+			t.CheckElementsMatch(test.expectedRemoved, removed)
+			t.CheckDeepEqual(test.expectedDeleted, result.DeletedImages)
+
+			entries, err := ListEntries(cacheFile)
+			t.CheckNoError(err)
+			var kept []string
+			for _, e := range entries {
+				kept = append(kept, e.Hash)
+			}
+			t.CheckElementsMatch(test.expectedKept, kept)
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		cacheFile := t.NewTempDir().Path("cache")
+		t.CheckNoError(saveArtifactCache(cacheFile, testArtifactCache()))
+
+		removed, err := Clear(cacheFile)
+		t.CheckNoError(err)
+		t.CheckDeepEqual(7, len(removed))
+
+		entries, err := ListEntries(cacheFile)
+		t.CheckNoError(err)
+		t.CheckEmpty(entries)
+	})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// for testing
+var addRemoteTag = docker.AddRemoteTag
+
+// cacheRepoTag returns the tag that points, in the cache repository, to the image built for an artifact hash.
+func cacheRepoTag(repo, hash string) string {
+	return repo + ":" + hash
+}
+
+// lookupCacheRepo looks for an artifact hash in the cache repository, shared across machines.
+// Images found there are either retagged in the registry or pulled into the local Docker daemon.
+func (c *cache) lookupCacheRepo(ctx context.Context, a *latestV1.Artifact, tag string, hash string) cacheDetails {
+	if c.cacheRepo == "" {
+		return needsBuilding{hash: hash}
+	}
+
+	src := cacheRepoTag(c.cacheRepo, hash)
+	digest, err := docker.RemoteDigest(src, c.cfg)
+	if err != nil {
+		logrus.Debugf("Artifact %s not found in cache repository (%s)", a.ImageName, err)
+		return needsBuilding{hash: hash}
+	}
+
+	isLocal, err := c.isLocalImage(a.ImageName)
+	if err != nil {
+		return failed{err}
+	}
+	if isLocal {
+		return needsPulling{hash: hash, tag: tag, src: src + "@" + digest, artifact: a.ImageName}
+	}
+
+	c.cacheMutex.Lock()
+	c.artifactCache[hash] = ImageDetails{Digest: digest, Artifact: a.ImageName, Tag: tag + "@" + digest, Created: timeNow()}
+	c.cacheMutex.Unlock()
+
+	// Image exists in the artifact's repository with a different tag
+	fqn := tag + "@" + digest
+	if remoteDigest, err := docker.RemoteDigest(fqn, c.cfg); err == nil && remoteDigest == digest {
+		return needsRemoteTagging{hash: hash, tag: tag, digest: digest}
+	}
+	return needsCopying{hash: hash, tag: tag, src: src + "@" + digest}
+}
+
+// uploadToCacheRepo tags the images that were just built in the cache repository, so that they can be reused on other machines.
+func (c *cache) uploadToCacheRepo(ctx context.Context, out io.Writer, bRes []graph.Artifact, hashByName map[string]string) error {
+	if c.cacheRepo == "" || c.cfg.CacheRepoReadOnly() {
+		return nil
+	}
+
+	for _, a := range bRes {
+		hash, found := hashByName[a.ImageName]
+		if !found {
+			continue
+		}
+		target := cacheRepoTag(c.cacheRepo, hash)
+
+		isLocal, err := c.isLocalImage(a.ImageName)
+		if err != nil {
+			return err
+		}
+		if !isLocal {
+			if err := addRemoteTag(a.Tag, target, c.cfg); err != nil {
+				return fmt.Errorf("tagging %s in cache repository: %w", a.ImageName, err)
+			}
+			continue
+		}
+
+		c.cacheMutex.RLock()
+		entry := c.artifactCache[hash]
+		c.cacheMutex.RUnlock()
+		if entry.ID == "" {
+			continue
+		}
+		output.Default.Fprintf(out, "Pushing %s to cache repository\n", a.ImageName)
+		if err := c.client.Tag(ctx, entry.ID, target); err != nil {
+			return fmt.Errorf("tagging %s for cache repository: %w", a.ImageName, err)
+		}
+		if _, err := c.client.Push(ctx, ioutil.Discard, target); err != nil {
+			return fmt.Errorf("pushing %s to cache repository: %w", a.ImageName, err)
+		}
+	}
+	return nil
+}
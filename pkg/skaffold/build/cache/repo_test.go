@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestLookupCacheRepo(t *testing.T) {
+	tests := []struct {
+		description string
+		cacheRepo   string
+		isLocal     bool
+		cache       map[string]ImageDetails
+		api         *testutil.FakeAPIClient
+		expected    cacheDetails
+	}{
+		{
+			description: "no cache repository",
+			cache:       map[string]ImageDetails{},
+			expected:    needsBuilding{hash: "hash"},
+		},
+		{
+			description: "miss",
+			cacheRepo:   "gcr.io/project/missing",
+			cache:       map[string]ImageDetails{},
+			expected:    needsBuilding{hash: "hash"},
+		},
+		{
+			description: "hit, image in the artifact's repository",
+			cacheRepo:   "gcr.io/project/cache",
+			cache:       map[string]ImageDetails{},
+			expected:    needsRemoteTagging{hash: "hash", tag: "gcr.io/project/app:tag", digest: "digest"},
+		},
+		{
+			description: "hit, image only in the cache repository",
+			cacheRepo:   "gcr.io/other/cache",
+			cache:       map[string]ImageDetails{},
+			expected:    needsCopying{hash: "hash", tag: "gcr.io/project/app:tag", src: "gcr.io/other/cache:hash@otherdigest"},
+		},
+		{
+			description: "hit for a local image",
+			cacheRepo:   "gcr.io/project/cache",
+			isLocal:     true,
+			cache:       map[string]ImageDetails{},
+			api:         &testutil.FakeAPIClient{},
+			expected:    needsPulling{hash: "hash", tag: "gcr.io/project/app:tag", src: "gcr.io/project/cache:hash@digest", artifact: "artifact"},
+		},
+		{
+			description: "local image removed from the Docker daemon",
+			cacheRepo:   "gcr.io/project/cache",
+			isLocal:     true,
+			cache: map[string]ImageDetails{
+				"hash": {ID: "imageID"},
+			},
+			api:      &testutil.FakeAPIClient{},
+			expected: needsPulling{hash: "hash", tag: "gcr.io/project/app:tag", src: "gcr.io/project/cache:hash@digest", artifact: "artifact"},
+		},
+		{
+			description: "local cache hit",
+			cacheRepo:   "gcr.io/project/cache",
+			isLocal:     true,
+			cache: map[string]ImageDetails{
+				"hash": {ID: "imageID"},
+			},
+			api:      (&testutil.FakeAPIClient{}).Add("gcr.io/project/app:tag", "imageID"),
+			expected: found{hash: "hash"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&docker.RemoteDigest, func(identifier string, _ docker.Config) (string, error) {
+				switch identifier {
+				case "gcr.io/project/cache:hash":
+					return "digest", nil
+				case "gcr.io/project/app:tag@digest":
+					return "digest", nil
+				case "gcr.io/other/cache:hash":
+					return "otherdigest", nil
+				default:
+					return "", errors.New("unknown remote tag")
+				}
+			})
+
+			cache := &cache{
+				isLocalImage:       func(string) (bool, error) { return test.isLocal, nil },
+				importMissingImage: func(imageName string) (bool, error) { return false, nil },
+				artifactCache:      test.cache,
+				cacheRepo:          test.cacheRepo,
+				client:             fakeLocalDaemon(test.api),
+				cfg:                &mockConfig{mode: config.RunModes.Build},
+			}
+			t.Override(&newArtifactHasherFunc, func(_ graph.ArtifactGraph, _ DependencyLister, _ config.RunMode) artifactHasher {
+				return mockHasher{"hash"}
+			})
+			details := cache.lookupArtifacts(context.Background(), map[string]string{"artifact": "gcr.io/project/app:tag"}, []*latestV1.Artifact{{
+				ImageName: "artifact",
+			}})
+
+			if !reflect.DeepEqual(test.expected, details[0]) {
+				t.Errorf("Expected result different from actual result. Expected: \n%v, \nActual: \n%v", test.expected, details)
+			}
+		})
+	}
+}
+
+func TestPullFromCacheRepo(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		now := time.Now()
+		t.Override(&timeNow, func() time.Time { return now })
+		api := (&testutil.FakeAPIClient{}).Add("gcr.io/project/cache:hash@digest", "imageID")
+		cache := &cache{
+			artifactCache: map[string]ImageDetails{},
+			client:        fakeLocalDaemon(api),
+		}
+
+		err := needsPulling{hash: "hash", tag: "app:tag", src: "gcr.io/project/cache:hash@digest", artifact: "app"}.Pull(context.Background(), ioutil.Discard, cache)
+
+		t.CheckNoError(err)
+		t.CheckDeepEqual([]string{"gcr.io/project/cache:hash@digest"}, api.Pulled())
+		t.CheckDeepEqual(ArtifactCache{"hash": {ID: "imageID", Artifact: "app", Tag: "app:tag", Created: now}}, cache.artifactCache)
+		imageID, err := cache.client.ImageID(context.Background(), "app:tag")
+		t.CheckNoError(err)
+		t.CheckDeepEqual("imageID", imageID)
+	})
+}
+
+func TestUploadToCacheRepo(t *testing.T) {
+	tests := []struct {
+		description    string
+		cacheRepo      string
+		readOnly       bool
+		isLocal        bool
+		expectedTagged map[string]string
+		expectedPushed []string
+	}{
+		{
+			description: "no cache repository",
+		},
+		{
+			description: "read-only cache repository",
+			cacheRepo:   "gcr.io/project/cache",
+			readOnly:    true,
+		},
+		{
+			description:    "remote image",
+			cacheRepo:      "gcr.io/project/cache",
+			expectedTagged: map[string]string{"gcr.io/project/cache:hash": "gcr.io/project/app:tag@sha256:abac"},
+		},
+		{
+			description:    "local image",
+			cacheRepo:      "gcr.io/project/cache",
+			isLocal:        true,
+			expectedPushed: []string{"gcr.io/project/cache:hash"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			tagged := map[string]string{}
+			t.Override(&addRemoteTag, func(src, target string, _ docker.Config) error {
+				tagged[target] = src
+				return nil
+			})
+			api := (&testutil.FakeAPIClient{}).Add("gcr.io/project/app:tag", "imageID")
+			cfg := &mockConfig{}
+			cfg.Opts.CacheRepoReadOnly = test.readOnly
+			cache := &cache{
+				isLocalImage:  func(string) (bool, error) { return test.isLocal, nil },
+				artifactCache: map[string]ImageDetails{"hash": {ID: "imageID"}},
+				cacheRepo:     test.cacheRepo,
+				client:        fakeLocalDaemon(api),
+				cfg:           cfg,
+			}
+
+			err := cache.uploadToCacheRepo(context.Background(), ioutil.Discard, []graph.Artifact{
+				{ImageName: "artifact", Tag: "gcr.io/project/app:tag@sha256:abac"},
+			}, map[string]string{"artifact": "hash"})
+
+			t.CheckNoError(err)
+			if test.expectedTagged == nil {
+				test.expectedTagged = map[string]string{}
+			}
+			t.CheckDeepEqual(test.expectedTagged, tagged)
+			var pushed []string
+			for ref := range api.Pushed() {
+				pushed = append(pushed, ref)
+			}
+			t.CheckDeepEqual(test.expectedPushed, pushed)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -77,6 +78,22 @@ func (c *cache) Build(ctx context.Context, out io.Writer, tags tag.ImageTags, ar
 			needToBuild = append(needToBuild, artifact)
 			continue
 
+		case needsCopying:
+			eventV2.CacheCheckHit(artifact.ImageName)
+			output.Green.Fprintln(out, "Found in cache repository. Copying")
+			if err := result.Copy(ctx, c); err != nil {
+				endTrace(instrumentation.TraceEndError(err))
+				return nil, fmt.Errorf("copying image from cache repository: %w", err)
+			}
+
+		case needsPulling:
+			eventV2.CacheCheckHit(artifact.ImageName)
+			output.Green.Fprintln(out, "Found in cache repository. Pulling")
+			if err := result.Pull(ctx, ioutil.Discard, c); err != nil {
+				endTrace(instrumentation.TraceEndError(err))
+				return nil, fmt.Errorf("pulling image from cache repository: %w", err)
+			}
+
 		case needsTagging:
 			eventV2.CacheCheckHit(artifact.ImageName)
 			output.Green.Fprintln(out, "Found. Tagging")
@@ -132,8 +149,9 @@ func (c *cache) Build(ctx context.Context, out io.Writer, tags tag.ImageTags, ar
 		}
 		c.artifactStore.Record(artifact, uniqueTag)
 		alreadyBuilt = append(alreadyBuilt, graph.Artifact{
-			ImageName: artifact.ImageName,
-			Tag:       uniqueTag,
+			ImageName:   artifact.ImageName,
+			Tag:         uniqueTag,
+			IndexDigest: build.IndexDigest(artifact, uniqueTag),
 		})
 	}
 
@@ -150,6 +168,10 @@ func (c *cache) Build(ctx context.Context, out io.Writer, tags tag.ImageTags, ar
 		return append(bRes, alreadyBuilt...), nil
 	}
 
+	if err := c.uploadToCacheRepo(ctx, out, bRes, hashByName); err != nil {
+		logrus.Warnf("error uploading artifacts to cache repository; other machines may not reuse them: %v", err)
+	}
+
 	if err := saveArtifactCache(c.cacheFile, c.artifactCache); err != nil {
 		logrus.Warnf("error saving cache file; caching may not work as expected: %v", err)
 		return append(bRes, alreadyBuilt...), nil
@@ -175,7 +197,7 @@ func maintainArtifactOrder(built []graph.Artifact, artifacts []*latestV1.Artifac
 
 func (c *cache) addArtifacts(ctx context.Context, bRes []graph.Artifact, hashByName map[string]string) error {
 	for _, a := range bRes {
-		entry := ImageDetails{}
+		entry := ImageDetails{Artifact: a.ImageName, Tag: a.Tag, Created: timeNow()}
 		isLocal, err := c.isLocalImage(a.ImageName)
 		if err != nil {
 			return err
@@ -0,0 +1,368 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/buildkit"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/kaniko"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes"
+	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// buildMetadata is the part of the metadata file written by `buildctl` that holds the image digest.
+type buildMetadata struct {
+	Digest string `json:"containerimage.digest"`
+}
+
+// buildWithBuildKit builds an artifact with `buildctl`, on a BuildKit daemon running in the cluster.
+// The build context, the secrets and the SSH agent are forwarded to the daemon through `kubectl exec`.
+func (b *Builder) buildWithBuildKit(ctx context.Context, out io.Writer, a *latestV1.Artifact, tag string, requiredImages map[string]*string) (string, error) {
+	generatedEnvs, err := generateEnvFromImage(tag)
+	if err != nil {
+		return "", fmt.Errorf("error processing generated env variables from image uri: %w", err)
+	}
+	artifact := *a.BuildKitArtifact
+	artifact.BuildArgs, err = docker.EvalBuildArgsWithEnv(b.cfg.Mode(), a.Workspace, artifact.DockerfilePath, artifact.BuildArgs, requiredImages, envMapFromVars(generatedEnvs))
+	if err != nil {
+		return "", fmt.Errorf("unable to evaluate build args: %w", err)
+	}
+
+	pod, err := b.buildKitDaemon(ctx, out)
+	if err != nil {
+		return "", fmt.Errorf("starting BuildKit daemon: %w", err)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "buildkit")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	metadataFile := filepath.Join(tmpDir, "metadata.json")
+
+	address := buildkit.Address(b.cfg.GetKubeContext(), b.Namespace, pod)
+	args, err := buildkit.Args(&artifact, a.Workspace, tag, address, metadataFile, a.Platforms, b.cfg.GetInsecureRegistries())
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	dockerConfig, err := b.buildKitDockerConfig(ctx, filepath.Join(tmpDir, "docker"), tag)
+	if err != nil {
+		return "", fmt.Errorf("setting up registry credentials: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "buildctl", args...)
+	var env []string
+	if kubeConfig := b.cfg.GetKubeConfig(); kubeConfig != "" {
+		env = append(env, "KUBECONFIG="+kubeConfig)
+	}
+	if dockerConfig != "" {
+		env = append(env, "DOCKER_CONFIG="+dockerConfig)
+	}
+	if len(env) > 0 {
+		cmd.Env = append(util.OSEnviron(), env...)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := util.RunCmd(cmd); err != nil {
+		return "", fmt.Errorf("running buildctl: %w", err)
+	}
+
+	buf, err := ioutil.ReadFile(metadataFile)
+	if err != nil {
+		return "", fmt.Errorf("reading build metadata: %w", err)
+	}
+	var metadata buildMetadata
+	if err := json.Unmarshal(buf, &metadata); err != nil {
+		return "", fmt.Errorf("parsing build metadata: %w", err)
+	}
+	if metadata.Digest == "" {
+		return "", fmt.Errorf("no image digest found in build metadata")
+	}
+	return metadata.Digest, nil
+}
+
+// buildKitDockerConfig writes, in the given directory, the Docker configuration that `buildctl` authenticates to registries with.
+// It is made of the `dockerConfig` secret, and of the Google Cloud service account key of the pull secret, used for the registry of the image.
+// Without them, no directory is returned and `buildctl` uses the local Docker configuration.
+func (b *Builder) buildKitDockerConfig(ctx context.Context, dir string, tag string) (string, error) {
+	if b.PullSecretName == "" && b.DockerConfig == nil {
+		return "", nil
+	}
+
+	client, err := kubernetesclient.Client()
+	if err != nil {
+		return "", fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+	secrets := client.CoreV1().Secrets(b.Namespace)
+
+	config := map[string]interface{}{}
+	if b.DockerConfig != nil {
+		secret, err := secrets.Get(ctx, b.DockerConfig.SecretName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("reading docker config secret %q: %w", b.DockerConfig.SecretName, err)
+		}
+		if err := json.Unmarshal(secret.Data["config.json"], &config); err != nil {
+			return "", fmt.Errorf("parsing docker config secret %q: %w", b.DockerConfig.SecretName, err)
+		}
+	}
+
+	if b.PullSecretName != "" {
+		secret, err := secrets.Get(ctx, b.PullSecretName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("reading pull secret %q: %w", b.PullSecretName, err)
+		}
+		key, found := secret.Data[kaniko.DefaultSecretName]
+		if !found {
+			return "", fmt.Errorf("pull secret %q has no %q key", b.PullSecretName, kaniko.DefaultSecretName)
+		}
+		registry, err := registryOf(tag)
+		if err != nil {
+			return "", err
+		}
+		auths, _ := config["auths"].(map[string]interface{})
+		if auths == nil {
+			auths = map[string]interface{}{}
+		}
+		auths[registry] = map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte("_json_key:" + string(key)))}
+		config["auths"] = auths
+	}
+
+	buf, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), buf, 0600); err != nil {
+		return "", fmt.Errorf("writing docker config: %w", err)
+	}
+	return dir, nil
+}
+
+// registryOf returns the key under which the Docker configuration holds the credentials of an image's registry.
+func registryOf(tag string) (string, error) {
+	ref, err := docker.ParseReference(tag)
+	if err != nil {
+		return "", fmt.Errorf("parsing image %q: %w", tag, err)
+	}
+	if ref.Domain == "" || ref.Domain == "docker.io" {
+		return "https://index.docker.io/v1/", nil
+	}
+	return ref.Domain, nil
+}
+
+// for testing
+var buildKitDaemonOwner = localOwner
+
+// buildKitDaemon returns the name of a ready BuildKit daemon pod. A daemon started by a previous build
+// is reused, so that its local cache is kept warm, unless its configuration changed.
+func (b *Builder) buildKitDaemon(ctx context.Context, out io.Writer) (string, error) {
+	b.buildKitMutex.Lock()
+	defer b.buildKitMutex.Unlock()
+
+	client, err := kubernetesclient.Client()
+	if err != nil {
+		return "", fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+	pods := client.CoreV1().Pods(b.Namespace)
+
+	if b.buildKitPod != "" {
+		// the daemon pod might have been deleted or evicted since the last build.
+		pod, err := pods.Get(ctx, b.buildKitPod, metav1.GetOptions{})
+		if err == nil && pod.DeletionTimestamp == nil && isPodReady(*pod) {
+			return b.buildKitPod, nil
+		}
+		logrus.Debugf("BuildKit daemon pod %s is gone or not ready anymore", b.buildKitPod)
+		b.buildKitPod = ""
+	}
+
+	spec, err := b.buildKitDaemonPodSpec()
+	if err != nil {
+		return "", err
+	}
+
+	name, ready, err := reuseBuildKitDaemon(ctx, pods, spec.Labels[buildkit.DaemonOwnerLabel], spec.Annotations[buildkit.DaemonSpecAnnotation])
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		output.Default.Fprintf(out, "Starting BuildKit daemon in namespace %s\n", b.Namespace)
+		pod, err := pods.Create(ctx, spec, metav1.CreateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("creating BuildKit daemon pod: %w", err)
+		}
+		name = pod.Name
+	}
+	if !ready {
+		if err := kubernetes.WaitForPodReady(ctx, pods, name, b.timeout); err != nil {
+			return "", fmt.Errorf("waiting for BuildKit daemon pod %s: %w", name, err)
+		}
+	}
+
+	b.buildKitPod = name
+	return name, nil
+}
+
+// reuseBuildKitDaemon finds a running BuildKit daemon pod of the given owner, with the given spec hash.
+// The owner's daemon pods with an outdated spec, or that have terminated, are deleted.
+// Daemon pods of other owners are left untouched.
+func reuseBuildKitDaemon(ctx context.Context, pods corev1.PodInterface, owner, specHash string) (string, bool, error) {
+	selector := fmt.Sprintf("%s,%s=%s", buildkit.DaemonLabel, buildkit.DaemonOwnerLabel, owner)
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return "", false, fmt.Errorf("listing BuildKit daemon pods: %w", err)
+	}
+
+	var name string
+	var ready bool
+	for _, pod := range list.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		running := pod.Status.Phase == v1.PodRunning || pod.Status.Phase == v1.PodPending
+		if name == "" && running && pod.Annotations[buildkit.DaemonSpecAnnotation] == specHash {
+			logrus.Debugf("Reusing BuildKit daemon pod %s", pod.Name)
+			name = pod.Name
+			ready = isPodReady(pod)
+			continue
+		}
+
+		logrus.Debugf("Deleting outdated BuildKit daemon pod %s", pod.Name)
+		if err := pods.Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: new(int64)}); err != nil {
+			return "", false, fmt.Errorf("deleting outdated BuildKit daemon pod %s: %w", pod.Name, err)
+		}
+	}
+	return name, ready, nil
+}
+
+// localOwner identifies the local user and machine, so that a daemon is only reused by the Skaffold runs that started it.
+func localOwner() string {
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	hostname, _ := os.Hostname()
+	sum := sha256.Sum256([]byte(username + "@" + hostname))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func isPodReady(pod v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// buildKitDaemonPodSpec returns the spec of the BuildKit daemon pod, annotated with a hash of its configuration.
+func (b *Builder) buildKitDaemonPodSpec() (*v1.Pod, error) {
+	var env []v1.EnvVar
+	if b.ClusterDetails.HTTPProxy != "" {
+		env = append(env, v1.EnvVar{Name: "HTTP_PROXY", Value: b.ClusterDetails.HTTPProxy})
+	}
+	if b.ClusterDetails.HTTPSProxy != "" {
+		env = append(env, v1.EnvVar{Name: "HTTPS_PROXY", Value: b.ClusterDetails.HTTPSProxy})
+	}
+
+	privileged := true
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations:  map[string]string{},
+			GenerateName: buildkit.DaemonName + "-",
+			Labels:       map[string]string{buildkit.DaemonLabel: buildkit.DaemonLabel, buildkit.DaemonOwnerLabel: buildKitDaemonOwner()},
+			Namespace:    b.ClusterDetails.Namespace,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:            buildkit.DaemonContainerName,
+				Image:           b.ClusterDetails.BuildKitImage,
+				ImagePullPolicy: v1.PullIfNotPresent,
+				Env:             env,
+				ReadinessProbe: &v1.Probe{
+					Handler: v1.Handler{
+						Exec: &v1.ExecAction{Command: []string{"buildctl", "debug", "workers"}},
+					},
+					PeriodSeconds: 2,
+				},
+				SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+				VolumeMounts: []v1.VolumeMount{{
+					Name:      buildkit.DaemonCacheDirName,
+					MountPath: buildkit.DaemonCacheDirMountPath,
+				}},
+				Resources: resourceRequirements(b.ClusterDetails.Resources),
+			}},
+			Volumes: []v1.Volume{{
+				Name: buildkit.DaemonCacheDirName,
+				VolumeSource: v1.VolumeSource{
+					EmptyDir: &v1.EmptyDirVolumeSource{},
+				},
+			}},
+			ServiceAccountName: b.ClusterDetails.ServiceAccountName,
+			Tolerations:        b.ClusterDetails.Tolerations,
+			NodeSelector:       b.ClusterDetails.NodeSelector,
+		},
+	}
+
+	hash, err := specHash(pod)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range b.ClusterDetails.Annotations {
+		pod.Annotations[k] = v
+	}
+	pod.Annotations[buildkit.DaemonSpecAnnotation] = hash
+
+	return pod, nil
+}
+
+func specHash(pod *v1.Pod) (string, error) {
+	buf, err := json.Marshal(pod.Spec)
+	if err != nil {
+		return "", fmt.Errorf("hashing BuildKit daemon spec: %w", err)
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
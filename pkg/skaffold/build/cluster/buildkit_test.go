@@ -0,0 +1,267 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/buildkit"
+	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestBuildKitDaemonPodSpec(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&buildKitDaemonOwner, func() string { return "owner" })
+		builder := &Builder{
+			ClusterDetails: &latestV1.ClusterDetails{
+				Namespace:          "builds",
+				BuildKitImage:      buildkit.DefaultImage,
+				HTTPProxy:          "http://proxy",
+				ServiceAccountName: "builder",
+				NodeSelector:       map[string]string{"pool": "builds"},
+				Annotations:        map[string]string{"team": "platform"},
+			},
+		}
+
+		pod, err := builder.buildKitDaemonPodSpec()
+		t.CheckNoError(err)
+
+		t.CheckDeepEqual("skaffold-buildkitd-", pod.GenerateName)
+		t.CheckDeepEqual("builds", pod.Namespace)
+		t.CheckDeepEqual(map[string]string{"skaffold-buildkitd": "skaffold-buildkitd", "skaffold.dev/buildkitd-owner": "owner"}, pod.Labels)
+		t.CheckDeepEqual("platform", pod.Annotations["team"])
+		t.CheckDeepEqual(16, len(pod.Annotations[buildkit.DaemonSpecAnnotation]))
+		t.CheckDeepEqual("builder", pod.Spec.ServiceAccountName)
+		t.CheckDeepEqual(map[string]string{"pool": "builds"}, pod.Spec.NodeSelector)
+
+		container := pod.Spec.Containers[0]
+		t.CheckDeepEqual(buildkit.DefaultImage, container.Image)
+		t.CheckDeepEqual(true, *container.SecurityContext.Privileged)
+		t.CheckDeepEqual([]v1.EnvVar{{Name: "HTTP_PROXY", Value: "http://proxy"}}, container.Env)
+		t.CheckDeepEqual("/var/lib/buildkit", container.VolumeMounts[0].MountPath)
+
+		// Annotations don't change the spec hash, but the image does.
+		builder.ClusterDetails.Annotations = nil
+		same, err := builder.buildKitDaemonPodSpec()
+		t.CheckNoError(err)
+		t.CheckDeepEqual(pod.Annotations[buildkit.DaemonSpecAnnotation], same.Annotations[buildkit.DaemonSpecAnnotation])
+
+		builder.ClusterDetails.BuildKitImage = "moby/buildkit:latest"
+		other, err := builder.buildKitDaemonPodSpec()
+		t.CheckNoError(err)
+		t.CheckFalse(pod.Annotations[buildkit.DaemonSpecAnnotation] == other.Annotations[buildkit.DaemonSpecAnnotation])
+	})
+}
+
+func TestReuseBuildKitDaemon(t *testing.T) {
+	daemonPod := func(name, owner, hash string, phase v1.PodPhase, ready bool) *v1.Pod {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "ns",
+				Labels:      map[string]string{buildkit.DaemonLabel: buildkit.DaemonLabel, buildkit.DaemonOwnerLabel: owner},
+				Annotations: map[string]string{buildkit.DaemonSpecAnnotation: hash},
+			},
+			Status: v1.PodStatus{
+				Phase:      phase,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	tests := []struct {
+		description   string
+		pods          []*v1.Pod
+		expectedName  string
+		expectedReady bool
+		expectedPods  []string
+	}{
+		{
+			description: "no daemon",
+		},
+		{
+			description:   "ready daemon with the same spec",
+			pods:          []*v1.Pod{daemonPod("daemon", "me", "hash", v1.PodRunning, true)},
+			expectedName:  "daemon",
+			expectedReady: true,
+			expectedPods:  []string{"daemon"},
+		},
+		{
+			description:  "pending daemon with the same spec",
+			pods:         []*v1.Pod{daemonPod("daemon", "me", "hash", v1.PodPending, false)},
+			expectedName: "daemon",
+			expectedPods: []string{"daemon"},
+		},
+		{
+			description: "outdated and terminated daemons are deleted",
+			pods: []*v1.Pod{
+				daemonPod("outdated", "me", "other", v1.PodRunning, true),
+				daemonPod("failed", "me", "hash", v1.PodFailed, false),
+				daemonPod("daemon", "me", "hash", v1.PodRunning, true),
+				daemonPod("duplicate", "me", "hash", v1.PodRunning, true),
+			},
+			expectedName:  "daemon",
+			expectedReady: true,
+			expectedPods:  []string{"daemon"},
+		},
+		{
+			description: "daemons of other owners are left untouched",
+			pods: []*v1.Pod{
+				daemonPod("other-outdated", "other", "other", v1.PodRunning, true),
+				daemonPod("other-daemon", "other", "hash", v1.PodRunning, true),
+			},
+			expectedPods: []string{"other-daemon", "other-outdated"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			client := fake.NewSimpleClientset()
+			pods := client.CoreV1().Pods("ns")
+			for _, pod := range test.pods {
+				_, err := pods.Create(context.Background(), pod, metav1.CreateOptions{})
+				t.RequireNoError(err)
+			}
+
+			name, ready, err := reuseBuildKitDaemon(context.Background(), pods, "me", "hash")
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expectedName, name)
+			t.CheckDeepEqual(test.expectedReady, ready)
+
+			list, err := pods.List(context.Background(), metav1.ListOptions{})
+			t.CheckNoError(err)
+			var remaining []string
+			for _, pod := range list.Items {
+				remaining = append(remaining, pod.Name)
+			}
+			t.CheckDeepEqual(test.expectedPods, remaining)
+		})
+	}
+}
+
+func TestBuildKitDaemonChecksCachedPod(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&buildKitDaemonOwner, func() string { return "me" })
+		builder := &Builder{
+			ClusterDetails: &latestV1.ClusterDetails{Namespace: "ns", BuildKitImage: buildkit.DefaultImage},
+			buildKitPod:    "deleted",
+		}
+		spec, err := builder.buildKitDaemonPodSpec()
+		t.RequireNoError(err)
+		daemon := spec.DeepCopy()
+		daemon.Name = "daemon"
+		daemon.Status = v1.PodStatus{Phase: v1.PodRunning, Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}}
+		client := fake.NewSimpleClientset(daemon)
+		t.Override(&kubernetesclient.Client, func() (kubernetes.Interface, error) { return client, nil })
+
+		name, err := builder.buildKitDaemon(context.Background(), ioutil.Discard)
+
+		t.CheckNoError(err)
+		t.CheckDeepEqual("daemon", name)
+		t.CheckDeepEqual("daemon", builder.buildKitPod)
+	})
+}
+
+func TestBuildKitDockerConfig(t *testing.T) {
+	secrets := []runtime.Object{
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kaniko-secret", Namespace: "ns"},
+			Data:       map[string][]byte{"kaniko-secret": []byte(`{"type":"service_account"}`)},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "docker-cfg", Namespace: "ns"},
+			Data:       map[string][]byte{"config.json": []byte(`{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`)},
+		},
+	}
+	gcrAuth := base64.StdEncoding.EncodeToString([]byte(`_json_key:{"type":"service_account"}`))
+
+	tests := []struct {
+		description string
+		cluster     latestV1.ClusterDetails
+		tag         string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "local credentials",
+			cluster:     latestV1.ClusterDetails{Namespace: "ns"},
+			tag:         "gcr.io/project/img:tag",
+		},
+		{
+			description: "docker config secret",
+			cluster:     latestV1.ClusterDetails{Namespace: "ns", DockerConfig: &latestV1.DockerConfig{SecretName: "docker-cfg"}},
+			tag:         "gcr.io/project/img:tag",
+			expected:    `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`,
+		},
+		{
+			description: "pull secret is used for the registry of the image",
+			cluster:     latestV1.ClusterDetails{Namespace: "ns", PullSecretName: "kaniko-secret", DockerConfig: &latestV1.DockerConfig{SecretName: "docker-cfg"}},
+			tag:         "gcr.io/project/img:tag",
+			expected:    `{"auths":{"gcr.io":{"auth":"` + gcrAuth + `"},"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`,
+		},
+		{
+			description: "pull secret for a Docker Hub image",
+			cluster:     latestV1.ClusterDetails{Namespace: "ns", PullSecretName: "kaniko-secret"},
+			tag:         "img:tag",
+			expected:    `{"auths":{"https://index.docker.io/v1/":{"auth":"` + gcrAuth + `"}}}`,
+		},
+		{
+			description: "missing pull secret",
+			cluster:     latestV1.ClusterDetails{Namespace: "ns", PullSecretName: "unknown"},
+			tag:         "gcr.io/project/img:tag",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			client := fake.NewSimpleClientset(secrets...)
+			t.Override(&kubernetesclient.Client, func() (kubernetes.Interface, error) { return client, nil })
+			dir := t.NewTempDir()
+			builder := &Builder{ClusterDetails: &test.cluster}
+
+			dockerConfig, err := builder.buildKitDockerConfig(context.Background(), dir.Path("docker"), test.tag)
+			t.CheckError(test.shouldErr, err)
+			if test.shouldErr {
+				return
+			}
+			if test.expected == "" {
+				t.CheckEmpty(dockerConfig)
+				return
+			}
+
+			t.CheckDeepEqual(dir.Path("docker"), dockerConfig)
+			buf, err := ioutil.ReadFile(filepath.Join(dockerConfig, "config.json"))
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, string(buf))
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/misc"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
 )
@@ -61,8 +62,15 @@ func (b *Builder) PostBuild(_ context.Context, _ io.Writer) error {
 }
 
 func (b *Builder) buildArtifact(ctx context.Context, out io.Writer, artifact *latestV1.Artifact, tag string) (string, error) {
-	// TODO: [#4922] Implement required artifact resolution from the `artifactStore`
-	digest, err := b.runBuildForArtifact(ctx, out, artifact, tag)
+	var digest string
+	var err error
+	if platform.IsMultiPlatform(artifact.Platforms) && artifact.KanikoArtifact != nil {
+		// kaniko builds a single platform per pod.
+		digest, err = build.BuildMultiPlatformImage(ctx, out, artifact, tag, b.cfg, b.runBuildForArtifact)
+	} else {
+		// TODO: [#4922] Implement required artifact resolution from the `artifactStore`
+		digest, err = b.runBuildForArtifact(ctx, out, artifact, tag)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -79,7 +87,10 @@ func (b *Builder) runBuildForArtifact(ctx context.Context, out io.Writer, a *lat
 	requiredImages := docker.ResolveDependencyImages(a.Dependencies, b.artifactStore, true)
 	switch {
 	case a.KanikoArtifact != nil:
-		return b.buildWithKaniko(ctx, out, a.Workspace, a.ImageName, a.KanikoArtifact, tag, requiredImages)
+		return b.buildWithKaniko(ctx, out, a.Workspace, a.ImageName, a.KanikoArtifact, a.Platforms, tag, requiredImages)
+
+	case a.BuildKitArtifact != nil:
+		return b.buildWithBuildKit(ctx, out, a, tag, requiredImages)
 
 	case a.CustomArtifact != nil:
 		return custom.NewArtifactBuilder(nil, b.cfg, true, append(b.retrieveExtraEnv(), util.EnvPtrMapToSlice(requiredImages, "=")...)).Build(ctx, out, a, tag)
@@ -26,15 +26,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/kaniko"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/version"
 )
 
-func (b *Builder) kanikoPodSpec(artifact *latestV1.KanikoArtifact, tag string) (*v1.Pod, error) {
+func (b *Builder) kanikoPodSpec(artifact *latestV1.KanikoArtifact, tag string, platforms []string) (*v1.Pod, error) {
 	args, err := kanikoArgs(artifact, tag, b.cfg.GetInsecureRegistries())
 	if err != nil {
 		return nil, fmt.Errorf("building args list: %w", err)
 	}
+	if len(platforms) > 1 {
+		return nil, fmt.Errorf("a kaniko pod can only build a single platform, got %s", strings.Join(platforms, ","))
+	}
 
 	vm := v1.VolumeMount{
 		Name:      kaniko.DefaultEmptyDirName,
@@ -114,6 +118,23 @@ func (b *Builder) kanikoPodSpec(artifact *latestV1.KanikoArtifact, tag string) (
 		pod.Spec.NodeSelector = b.ClusterDetails.NodeSelector
 	}
 
+	// Kaniko doesn't cross-compile: schedule the pod on a node of the target platform
+	if len(platforms) == 1 {
+		pl, err := platform.Parse(platforms[0])
+		if err != nil {
+			return nil, err
+		}
+		nodeSelector := map[string]string{
+			v1.LabelOSStable:   pl.OS,
+			v1.LabelArchStable: pl.Architecture,
+		}
+		for k, v := range b.ClusterDetails.NodeSelector {
+			nodeSelector[k] = v
+		}
+		pod.Spec.NodeSelector = nodeSelector
+		pod.Spec.Containers[0].Args = append(pod.Spec.Containers[0].Args, fmt.Sprintf("--custom-platform=%s", platforms[0]))
+	}
+
 	// Add used-defines Volumes
 	pod.Spec.Volumes = append(pod.Spec.Volumes, b.Volumes...)
 
@@ -241,7 +241,7 @@ func TestKanikoPodSpec(t *testing.T) {
 			NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
 		},
 	}
-	pod, _ := builder.kanikoPodSpec(artifact, "tag")
+	pod, _ := builder.kanikoPodSpec(artifact, "tag", nil)
 
 	expectedPod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -385,6 +385,57 @@ func TestKanikoPodSpec(t *testing.T) {
 	testutil.CheckDeepEqual(t, expectedPod.Spec.Containers[0].Env, pod.Spec.Containers[0].Env)
 }
 
+func TestKanikoPodSpecPlatform(t *testing.T) {
+	tests := []struct {
+		description          string
+		platforms            []string
+		nodeSelector         map[string]string
+		expectedNodeSelector map[string]string
+		expectedPlatformArg  bool
+		shouldErr            bool
+	}{
+		{
+			description: "no platform",
+		},
+		{
+			description:          "single platform",
+			platforms:            []string{"linux/arm64"},
+			expectedNodeSelector: map[string]string{"kubernetes.io/os": "linux", "kubernetes.io/arch": "arm64"},
+			expectedPlatformArg:  true,
+		},
+		{
+			description:          "user-defined node selector wins",
+			platforms:            []string{"linux/arm64"},
+			nodeSelector:         map[string]string{"kubernetes.io/arch": "amd64", "pool": "builders"},
+			expectedNodeSelector: map[string]string{"kubernetes.io/os": "linux", "kubernetes.io/arch": "amd64", "pool": "builders"},
+			expectedPlatformArg:  true,
+		},
+		{
+			description: "multiple platforms",
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			builder := &Builder{
+				cfg:            &mockBuilderContext{},
+				ClusterDetails: &latestV1.ClusterDetails{Namespace: "ns", NodeSelector: test.nodeSelector},
+			}
+			pod, err := builder.kanikoPodSpec(&latestV1.KanikoArtifact{Image: "image", DockerfilePath: "Dockerfile"}, "tag", test.platforms)
+			t.CheckError(test.shouldErr, err)
+			if test.shouldErr {
+				return
+			}
+			if test.expectedNodeSelector != nil {
+				t.CheckDeepEqual(test.expectedNodeSelector, pod.Spec.NodeSelector)
+			}
+			args := pod.Spec.Containers[0].Args
+			t.CheckDeepEqual(test.expectedPlatformArg, args[len(args)-1] == "--custom-platform=linux/arm64")
+		})
+	}
+}
+
 func TestResourceRequirements(t *testing.T) {
 	tests := []struct {
 		description string
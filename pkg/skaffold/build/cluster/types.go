@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
@@ -39,6 +40,9 @@ type Builder struct {
 	timeout       time.Duration
 	artifactStore build.ArtifactStore
 	teardownFunc  []func()
+
+	buildKitMutex sync.Mutex
+	buildKitPod   string // name of the BuildKit daemon pod, once it's ready
 }
 
 type Config interface {
@@ -23,6 +23,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -98,6 +99,9 @@ func (b *Builder) retrieveEnv(a *latestV1.Artifact, tag string) ([]string, error
 		fmt.Sprintf("%s=%t", constants.PushImage, b.pushImages),
 		fmt.Sprintf("%s=%s", constants.BuildContext, buildContext),
 	}
+	if len(a.Platforms) > 0 {
+		envs = append(envs, fmt.Sprintf("%s=%s", constants.Platforms, strings.Join(a.Platforms, ",")))
+	}
 
 	ref, err := docker.ParseReference(tag)
 	if err != nil {
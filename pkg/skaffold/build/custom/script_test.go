@@ -33,6 +33,7 @@ func TestRetrieveEnv(t *testing.T) {
 		description   string
 		tag           string
 		pushImages    bool
+		platforms     []string
 		buildContext  string
 		additionalEnv []string
 		environ       []string
@@ -62,6 +63,12 @@ func TestRetrieveEnv(t *testing.T) {
 			pushImages:    true,
 			additionalEnv: []string{"KUBECONTEXT=mycluster"},
 			expected:      []string{"IMAGE=gcr.io/image/push:tag", "PUSH_IMAGE=true", "BUILD_CONTEXT=", "IMAGE_REPO=gcr.io/image/push", "IMAGE_TAG=tag", "KUBECONTEXT=mycluster"},
+		}, {
+			description: "platforms",
+			tag:         "gcr.io/image/push:tag",
+			pushImages:  true,
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			expected:    []string{"IMAGE=gcr.io/image/push:tag", "PUSH_IMAGE=true", "BUILD_CONTEXT=", "PLATFORMS=linux/amd64,linux/arm64", "IMAGE_REPO=gcr.io/image/push", "IMAGE_TAG=tag"},
 		},
 	}
 	for _, test := range tests {
@@ -70,7 +77,7 @@ func TestRetrieveEnv(t *testing.T) {
 			t.Override(&buildContext, func(string) (string, error) { return test.buildContext, nil })
 
 			builder := NewArtifactBuilder(nil, nil, test.pushImages, test.additionalEnv)
-			actual, err := builder.retrieveEnv(&latestV1.Artifact{}, test.tag)
+			actual, err := builder.retrieveEnv(&latestV1.Artifact{Platforms: test.platforms}, test.tag)
 
 			t.CheckNoError(err)
 			t.CheckDeepEqual(test.expected, actual)
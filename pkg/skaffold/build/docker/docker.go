@@ -51,6 +51,9 @@ func (b *Builder) Build(ctx context.Context, out io.Writer, a *latestV1.Artifact
 		return "", cacheFromPullErr(err, a.ImageName)
 	}
 	opts := docker.BuildOptions{Tag: tag, Mode: b.cfg.Mode(), ExtraBuildArgs: docker.ResolveDependencyImages(a.Dependencies, b.artifacts, true)}
+	if len(a.Platforms) == 1 {
+		opts.Platform = a.Platforms[0]
+	}
 
 	var imageID string
 
@@ -85,6 +88,10 @@ func (b *Builder) dockerCLIBuild(ctx context.Context, out io.Writer, workspace s
 	}
 	args = append(args, cliArgs...)
 
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+
 	if b.cfg.Prune() {
 		args = append(args, "--force-rm")
 	}
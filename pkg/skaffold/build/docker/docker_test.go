@@ -40,6 +40,7 @@ func TestDockerCLIBuild(t *testing.T) {
 		description     string
 		localBuild      latestV1.LocalBuild
 		cfg             mockConfig
+		platforms       []string
 		extraEnv        []string
 		expectedEnv     []string
 		err             error
@@ -69,6 +70,12 @@ func TestDockerCLIBuild(t *testing.T) {
 			extraEnv:    []string{"OTHER=VALUE"},
 			expectedEnv: []string{"KEY=VALUE", "OTHER=VALUE", "DOCKER_BUILDKIT=1"},
 		},
+		{
+			description: "buildkit with target platform",
+			localBuild:  latestV1.LocalBuild{UseBuildkit: true},
+			platforms:   []string{"linux/arm64"},
+			expectedEnv: []string{"KEY=VALUE", "DOCKER_BUILDKIT=1"},
+		},
 		{
 			description: "env var collisions",
 			localBuild:  latestV1.LocalBuild{UseBuildkit: true},
@@ -136,8 +143,12 @@ func TestDockerCLIBuild(t *testing.T) {
 				)
 				t.Override(&util.DefaultExecCommand, mockCmd)
 			} else if test.localBuild.UseBuildkit || test.localBuild.UseDockerCLI {
+				var platformFlag string
+				if len(test.platforms) > 0 {
+					platformFlag = " --platform " + test.platforms[0]
+				}
 				mockCmd = testutil.CmdRunEnv(
-					"docker build . --file "+dockerfilePath+" -t tag",
+					"docker build . --file "+dockerfilePath+" -t tag"+platformFlag,
 					test.expectedEnv,
 				)
 				t.Override(&util.DefaultExecCommand, mockCmd)
@@ -153,6 +164,7 @@ func TestDockerCLIBuild(t *testing.T) {
 						DockerfilePath: "Dockerfile",
 					},
 				},
+				Platforms: test.platforms,
 			}
 
 			_, err := builder.Build(context.Background(), ioutil.Discard, artifact, "tag")
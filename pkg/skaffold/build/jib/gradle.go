@@ -43,8 +43,11 @@ const MinimumJibGradleVersionForSync = "2.0.0"
 // GradleCommand stores Gradle executable and wrapper name
 var GradleCommand = util.CommandWrapper{Executable: "gradle", Wrapper: "gradlew"}
 
-func (b *Builder) buildJibGradleToDocker(ctx context.Context, out io.Writer, workspace string, artifact *latestV1.JibArtifact, deps []*latestV1.ArtifactDependency, tag string) (string, error) {
+func (b *Builder) buildJibGradleToDocker(ctx context.Context, out io.Writer, workspace string, artifact *latestV1.JibArtifact, deps []*latestV1.ArtifactDependency, platforms []string, tag string) (string, error) {
 	args := GenerateGradleBuildArgs("jibDockerBuild", tag, artifact, b.skipTests, b.pushImages, deps, b.artifacts, b.cfg.GetInsecureRegistries(), output.IsColorable(out))
+	if platformsArg, found := platformsArg(platforms); found {
+		args = append(args, platformsArg)
+	}
 	if err := b.runGradleCommand(ctx, out, workspace, args); err != nil {
 		return "", jibToolErr(err)
 	}
@@ -52,8 +55,11 @@ func (b *Builder) buildJibGradleToDocker(ctx context.Context, out io.Writer, wor
 	return b.localDocker.ImageID(ctx, tag)
 }
 
-func (b *Builder) buildJibGradleToRegistry(ctx context.Context, out io.Writer, workspace string, artifact *latestV1.JibArtifact, deps []*latestV1.ArtifactDependency, tag string) (string, error) {
+func (b *Builder) buildJibGradleToRegistry(ctx context.Context, out io.Writer, workspace string, artifact *latestV1.JibArtifact, deps []*latestV1.ArtifactDependency, platforms []string, tag string) (string, error) {
 	args := GenerateGradleBuildArgs("jib", tag, artifact, b.skipTests, b.pushImages, deps, b.artifacts, b.cfg.GetInsecureRegistries(), output.IsColorable(out))
+	if platformsArg, found := platformsArg(platforms); found {
+		args = append(args, platformsArg)
+	}
 	if err := b.runGradleCommand(ctx, out, workspace, args); err != nil {
 		return "", jibToolErr(err)
 	}
@@ -352,3 +352,11 @@ func baseImageArg(a *latestV1.JibArtifact, r ArtifactResolver, deps []*latestV1.
 	}
 	return fmt.Sprintf("-Djib.from.image=%s", a.BaseImage), true
 }
+
+// platformsArg formats the target platforms as a build argument.
+func platformsArg(platforms []string) (string, bool) {
+	if len(platforms) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("-Djib.from.platforms=%s", strings.Join(platforms, ",")), true
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ko
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	kobuild "github.com/google/ko/pkg/build"
+	"github.com/google/ko/pkg/publish"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/misc"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+const (
+	// defaultBaseImage is the base image used by ko when none is configured.
+	defaultBaseImage = "gcr.io/distroless/static:nonroot"
+
+	// defaultPlatform is the platform built by ko when none is configured.
+	defaultPlatform = "linux/amd64"
+)
+
+// for testing
+var (
+	newGoBuilder = kobuild.NewGo
+	remoteGet    = remote.Get
+	publishImage = publishToRegistry
+)
+
+// Build builds an artifact with ko, and either pushes it to a registry or loads it into the local Docker daemon.
+func (b *Builder) Build(ctx context.Context, out io.Writer, a *latestV1.Artifact, tag string) (string, error) {
+	env, err := buildEnv(a.KoArtifact)
+	if err != nil {
+		return "", err
+	}
+
+	koBuilder, err := b.newKoBuilder(ctx, a, env)
+	if err != nil {
+		return "", fmt.Errorf("creating ko builder: %w", err)
+	}
+
+	importPath, err := koBuilder.QualifyImport(mainPackage(a))
+	if err != nil {
+		return "", fmt.Errorf("resolving main package of %q: %w", a.ImageName, err)
+	}
+	if err := koBuilder.IsSupportedReference(importPath); err != nil {
+		return "", fmt.Errorf("ko cannot build %q: %w", strings.TrimPrefix(importPath, KoScheme), err)
+	}
+
+	fmt.Fprintf(out, "Building %s with ko\n", strings.TrimPrefix(importPath, KoScheme))
+	result, err := koBuilder.Build(ctx, importPath)
+	if err != nil {
+		return "", fmt.Errorf("building %q with ko: %w", strings.TrimPrefix(importPath, KoScheme), err)
+	}
+
+	if b.pushImages {
+		return publishImage(ctx, result, tag, b.cfg)
+	}
+	return b.loadImage(ctx, out, a, result, tag)
+}
+
+func (b *Builder) newKoBuilder(ctx context.Context, a *latestV1.Artifact, env []string) (kobuild.Interface, error) {
+	opts := []kobuild.Option{
+		kobuild.WithBaseImages(b.getBase(a.KoArtifact)),
+		kobuild.WithPlatforms(platforms(a)),
+		withGoBuilder(goBuild(env)),
+	}
+	if b.mode == config.RunModes.Debug {
+		opts = append(opts, kobuild.WithDisabledOptimizations())
+	}
+	return newGoBuilder(ctx, a.Workspace, opts...)
+}
+
+// mainPackage returns the import path of the package to build.
+// It defaults to the image name when it uses the `ko://` prefix, and to the workspace otherwise.
+func mainPackage(a *latestV1.Artifact) string {
+	if a.KoArtifact.Main != "" {
+		return a.KoArtifact.Main
+	}
+	if strings.HasPrefix(a.ImageName, KoScheme) {
+		return a.ImageName
+	}
+	return "."
+}
+
+func platforms(a *latestV1.Artifact) string {
+	if len(a.Platforms) == 0 {
+		return defaultPlatform
+	}
+	return strings.Join(a.Platforms, ",")
+}
+
+func (b *Builder) getBase(a *latestV1.KoArtifact) kobuild.GetBase {
+	return func(ctx context.Context, _ string) (kobuild.Result, error) {
+		baseImage := a.BaseImage
+		if baseImage == "" {
+			baseImage = defaultBaseImage
+		}
+		return remoteBaseImage(ctx, baseImage, b.cfg)
+	}
+}
+
+// remoteBaseImage retrieves a base image, or a base image index, from its registry.
+func remoteBaseImage(ctx context.Context, image string, cfg docker.Config) (kobuild.Result, error) {
+	ref, err := parseReference(image, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := remoteGet(ref, remote.WithAuthFromKeychain(docker.PrimaryKeychain()), remote.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("getting base image %q: %w", image, err)
+	}
+
+	switch desc.MediaType {
+	case types.OCIImageIndex, types.DockerManifestList:
+		return desc.ImageIndex()
+	default:
+		return desc.Image()
+	}
+}
+
+// buildEnv returns the environment of `go build` for an artifact.
+// Build flags are passed with GOFLAGS, since ko doesn't let users add arguments to `go build`.
+func buildEnv(a *latestV1.KoArtifact) ([]string, error) {
+	env, err := misc.EvaluateEnv(a.Env)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate env variables: %w", err)
+	}
+
+	flags, err := goFlags(a)
+	if err != nil {
+		return nil, err
+	}
+	if len(flags) == 0 {
+		return env, nil
+	}
+
+	goflags := os.Getenv("GOFLAGS")
+	var result []string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GOFLAGS=") {
+			goflags = strings.TrimPrefix(kv, "GOFLAGS=")
+			continue
+		}
+		result = append(result, kv)
+	}
+	if goflags != "" {
+		flags = append([]string{goflags}, flags...)
+	}
+	return append(result, "GOFLAGS="+strings.Join(flags, " ")), nil
+}
+
+func goFlags(a *latestV1.KoArtifact) ([]string, error) {
+	var flags []string
+	for _, flag := range a.Flags {
+		flags = append(flags, quoteGoFlag(flag))
+	}
+
+	if len(a.Ldflags) > 0 {
+		var ldflags []string
+		for _, ldflag := range a.Ldflags {
+			value, err := util.ExpandEnvTemplate(ldflag, nil)
+			if err != nil {
+				return nil, fmt.Errorf("unable to evaluate ldflags %q: %w", ldflag, err)
+			}
+			ldflags = append(ldflags, value)
+		}
+		flags = append(flags, quoteGoFlag("-ldflags="+strings.Join(ldflags, " ")))
+	}
+
+	return flags, nil
+}
+
+// quoteGoFlag quotes a flag that contains spaces, so that it's read as a single flag from GOFLAGS.
+func quoteGoFlag(flag string) string {
+	if !strings.ContainsAny(flag, " \t\n") {
+		return flag
+	}
+	if !strings.Contains(flag, "'") {
+		return "'" + flag + "'"
+	}
+	return `"` + flag + `"`
+}
+
+// publishToRegistry pushes the result of a ko build to a registry, and returns its digest.
+func publishToRegistry(ctx context.Context, result kobuild.Result, tag string, cfg docker.Config) (string, error) {
+	ref, err := name.NewTag(tag, name.WeakValidation)
+	if err != nil {
+		return "", fmt.Errorf("parsing tag %q: %w", tag, err)
+	}
+
+	publisher, err := publish.NewDefault(ref.Context().Name(),
+		// the repository is fully determined by the tag, not by the import path.
+		publish.WithNamer(func(base, _ string) string { return base }),
+		publish.WithTags([]string{ref.TagStr()}),
+		publish.WithAuthFromKeychain(docker.PrimaryKeychain()),
+		publish.Insecure(docker.IsInsecure(ref, cfg.GetInsecureRegistries())))
+	if err != nil {
+		return "", fmt.Errorf("creating ko publisher: %w", err)
+	}
+	defer publisher.Close()
+
+	if _, err := publisher.Publish(ctx, result, ref.Context().Name()); err != nil {
+		return "", fmt.Errorf("%s %q: %w", sErrors.PushImageErr, tag, err)
+	}
+
+	digest, err := result.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+// loadImage loads the result of a ko build into the local Docker daemon, and returns the image ID.
+func (b *Builder) loadImage(ctx context.Context, out io.Writer, a *latestV1.Artifact, result kobuild.Result, tag string) (string, error) {
+	preferred, err := b.loadPlatforms(ctx, a)
+	if err != nil {
+		return "", err
+	}
+	img, err := singleImage(result, preferred)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := name.NewTag(tag, name.WeakValidation)
+	if err != nil {
+		return "", fmt.Errorf("parsing tag %q: %w", tag, err)
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(tarball.Write(ref, img, w))
+	}()
+	defer r.Close()
+
+	imageID, err := b.localDocker.Load(ctx, out, r, tag)
+	if err != nil {
+		return "", fmt.Errorf("loading image into docker daemon: %w", err)
+	}
+	return imageID, nil
+}
+
+// loadPlatforms returns the platforms of the image to load into the local Docker daemon, by order of preference:
+// the platform of the Docker daemon, then the platforms targeted by the artifact.
+func (b *Builder) loadPlatforms(ctx context.Context, a *latestV1.Artifact) ([]v1.Platform, error) {
+	var preferred []v1.Platform
+	if version, err := b.localDocker.ServerVersion(ctx); err != nil {
+		logrus.Debugf("unable to get the platform of the docker daemon: %v", err)
+	} else {
+		preferred = append(preferred, v1.Platform{OS: version.Os, Architecture: version.Arch})
+	}
+
+	targets, err := platform.ParseAll(strings.Split(platforms(a), ","))
+	if err != nil {
+		return nil, err
+	}
+	return append(preferred, targets...), nil
+}
+
+// singleImage returns the image built by ko. For an image index, it picks the image of the first matching preferred platform.
+func singleImage(result kobuild.Result, preferred []v1.Platform) (v1.Image, error) {
+	switch r := result.(type) {
+	case v1.Image:
+		return r, nil
+	case v1.ImageIndex:
+		manifest, err := r.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range preferred {
+			for _, desc := range manifest.Manifests {
+				if matchesPlatform(desc.Platform, p) {
+					return r.Image(desc.Digest)
+				}
+			}
+		}
+		var formatted []string
+		for _, p := range preferred {
+			formatted = append(formatted, platform.Format(p))
+		}
+		return nil, fmt.Errorf("ko built no image for platforms %s", strings.Join(formatted, ","))
+	default:
+		return nil, fmt.Errorf("unexpected ko build result: %T", result)
+	}
+}
+
+// matchesPlatform tells whether the platform of an image matches the wanted platform.
+// The variant is only compared when the wanted platform has one.
+func matchesPlatform(actual *v1.Platform, wanted v1.Platform) bool {
+	if actual == nil || actual.OS != wanted.OS || actual.Architecture != wanted.Architecture {
+		return false
+	}
+	return wanted.Variant == "" || actual.Variant == wanted.Variant
+}
+
+func parseReference(image string, cfg docker.Config) (name.Reference, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", image, err)
+	}
+	if docker.IsInsecure(ref, cfg.GetInsecureRegistries()) {
+		return name.ParseReference(image, name.Insecure)
+	}
+	return ref, nil
+}
@@ -0,0 +1,399 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ko
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/docker/docker/api/types"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	kobuild "github.com/google/ko/pkg/build"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		description     string
+		artifact        *latestV1.Artifact
+		pushImages      bool
+		expected        string
+		expectedImport  string
+		expectedGoFlags string
+	}{
+		{
+			description: "load into local daemon",
+			artifact: &latestV1.Artifact{
+				ImageName:    "example.com/app",
+				Workspace:    ".",
+				ArtifactType: latestV1.ArtifactType{KoArtifact: &latestV1.KoArtifact{}},
+			},
+			expected:       "sha256:loaded",
+			expectedImport: "ko://example.com/app",
+		},
+		{
+			description: "push with flags",
+			artifact: &latestV1.Artifact{
+				ImageName: "ko://example.com/app/cmd/server",
+				Workspace: ".",
+				ArtifactType: latestV1.ArtifactType{KoArtifact: &latestV1.KoArtifact{
+					Flags:   []string{"-tags=netgo"},
+					Ldflags: []string{"-s", "-w"},
+				}},
+			},
+			pushImages:      true,
+			expected:        "sha256:pushed",
+			expectedImport:  "ko://example.com/app/cmd/server",
+			expectedGoFlags: "-tags=netgo '-ldflags=-s -w'",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.SetEnvs(map[string]string{"GOFLAGS": ""})
+			koBuilder := &fakeKoBuilder{}
+			t.Override(&newGoBuilder, func(_ context.Context, _ string, opts ...kobuild.Option) (kobuild.Interface, error) {
+				koBuilder.goBuild = appliedGoBuilder(t, opts)
+				return koBuilder, nil
+			})
+			goCmd := &recordingCmd{}
+			t.Override(&util.DefaultExecCommand, goCmd)
+			t.Override(&publishImage, func(context.Context, kobuild.Result, string, docker.Config) (string, error) {
+				return "sha256:pushed", nil
+			})
+			localDocker := &fakeLocalDaemon{}
+
+			builder := NewArtifactBuilder(localDocker, &mockConfig{}, test.pushImages, config.RunModes.Dev)
+			digestOrImageID, err := builder.Build(context.Background(), ioutil.Discard, test.artifact, "example.com/app:tag")
+
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, digestOrImageID)
+			t.CheckDeepEqual(test.expectedImport, koBuilder.built)
+			t.CheckDeepEqual(test.expectedGoFlags, lastGoFlags(goCmd.env))
+			t.CheckDeepEqual(!test.pushImages, localDocker.loaded)
+			t.CheckDeepEqual("", os.Getenv("GOFLAGS"))
+		})
+	}
+}
+
+func TestMainPackage(t *testing.T) {
+	tests := []struct {
+		description string
+		imageName   string
+		main        string
+		expected    string
+	}{
+		{
+			description: "default to workspace",
+			imageName:   "example.com/app",
+			expected:    ".",
+		},
+		{
+			description: "default to ko image name",
+			imageName:   "ko://example.com/app/cmd/server",
+			expected:    "ko://example.com/app/cmd/server",
+		},
+		{
+			description: "main package",
+			imageName:   "ko://example.com/app",
+			main:        "./cmd/server",
+			expected:    "./cmd/server",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			a := &latestV1.Artifact{
+				ImageName:    test.imageName,
+				ArtifactType: latestV1.ArtifactType{KoArtifact: &latestV1.KoArtifact{Main: test.main}},
+			}
+
+			t.CheckDeepEqual(test.expected, mainPackage(a))
+		})
+	}
+}
+
+func TestBuildEnv(t *testing.T) {
+	tests := []struct {
+		description string
+		artifact    *latestV1.KoArtifact
+		goflags     string
+		expected    []string
+		shouldErr   bool
+	}{
+		{
+			description: "no env",
+			artifact:    &latestV1.KoArtifact{},
+		},
+		{
+			description: "env",
+			artifact:    &latestV1.KoArtifact{Env: []string{"CGO_ENABLED=1", "GOPRIVATE={{.PRIVATE}}"}},
+			expected:    []string{"CGO_ENABLED=1", "GOPRIVATE=example.com"},
+		},
+		{
+			description: "flags and ldflags",
+			artifact: &latestV1.KoArtifact{
+				Flags:   []string{"-v", "-tags=netgo"},
+				Ldflags: []string{"-s", "-X main.version={{.VERSION}}"},
+			},
+			expected: []string{"GOFLAGS=-v -tags=netgo '-ldflags=-s -X main.version=1.0'"},
+		},
+		{
+			description: "append to GOFLAGS",
+			artifact: &latestV1.KoArtifact{
+				Env:   []string{"GOFLAGS=-mod=vendor", "CGO_ENABLED=0"},
+				Flags: []string{"-v"},
+			},
+			goflags:  "-mod=readonly",
+			expected: []string{"CGO_ENABLED=0", "GOFLAGS=-mod=vendor -v"},
+		},
+		{
+			description: "append to GOFLAGS from environment",
+			artifact:    &latestV1.KoArtifact{Flags: []string{"-v"}},
+			goflags:     "-mod=readonly",
+			expected:    []string{"GOFLAGS=-mod=readonly -v"},
+		},
+		{
+			description: "invalid env",
+			artifact:    &latestV1.KoArtifact{Env: []string{"invalid"}},
+			shouldErr:   true,
+		},
+		{
+			description: "invalid ldflags template",
+			artifact:    &latestV1.KoArtifact{Ldflags: []string{"{{"}},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.SetEnvs(map[string]string{"PRIVATE": "example.com", "VERSION": "1.0", "GOFLAGS": test.goflags})
+
+			env, err := buildEnv(test.artifact)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, env)
+		})
+	}
+}
+
+func TestQuoteGoFlag(t *testing.T) {
+	testutil.CheckDeepEqual(t, "-v", quoteGoFlag("-v"))
+	testutil.CheckDeepEqual(t, "'-ldflags=-s -w'", quoteGoFlag("-ldflags=-s -w"))
+	testutil.CheckDeepEqual(t, `"-ldflags=-X 'main.name=a b'"`, quoteGoFlag("-ldflags=-X 'main.name=a b'"))
+}
+
+func TestGoBuild(t *testing.T) {
+	tests := []struct {
+		description          string
+		platform             v1.Platform
+		disableOptimizations bool
+		expectedArgs         []string
+		expectedEnv          []string
+	}{
+		{
+			description:  "linux/amd64",
+			platform:     v1.Platform{OS: "linux", Architecture: "amd64"},
+			expectedArgs: []string{"go", "build", "-o", "<out>", "-trimpath", "example.com/app"},
+			expectedEnv:  []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=amd64", "KO_TEST=value"},
+		},
+		{
+			description:          "debug build for linux/arm/v7",
+			platform:             v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+			disableOptimizations: true,
+			expectedArgs:         []string{"go", "build", "-gcflags", "all=-N -l", "-o", "<out>", "-trimpath", "example.com/app"},
+			expectedEnv:          []string{"CGO_ENABLED=0", "GOOS=linux", "GOARCH=arm", "GOARM=7", "KO_TEST=value"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			goCmd := &recordingCmd{}
+			t.Override(&util.DefaultExecCommand, goCmd)
+
+			file, err := goBuild([]string{"KO_TEST=value"})(context.Background(), "example.com/app", "workspace", test.platform, test.disableOptimizations)
+			defer os.RemoveAll(filepath.Dir(file))
+
+			t.CheckNoError(err)
+			t.CheckDeepEqual("workspace", goCmd.dir)
+			args := append([]string{}, goCmd.args...)
+			for i := 1; i < len(args); i++ {
+				if args[i-1] == "-o" {
+					t.CheckDeepEqual(file, args[i])
+					args[i] = "<out>"
+				}
+			}
+			t.CheckDeepEqual(test.expectedArgs, args)
+			for _, kv := range test.expectedEnv {
+				t.CheckContains(kv, strings.Join(goCmd.env, "\n"))
+			}
+			t.CheckDeepEqual("KO_TEST=value", goCmd.env[len(goCmd.env)-1])
+			_, found := os.LookupEnv("KO_TEST")
+			t.CheckFalse(found)
+		})
+	}
+}
+
+func TestSingleImage(t *testing.T) {
+	amd64 := v1.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := v1.Platform{OS: "linux", Architecture: "arm64"}
+	armv7 := v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}
+
+	testutil.Run(t, "", func(t *testutil.T) {
+		img, err := random.Image(1024, 1)
+		t.CheckNoError(err)
+		fromImage, err := singleImage(img, []v1.Platform{arm64})
+		t.CheckNoError(err)
+		t.CheckTrue(fromImage == img)
+
+		var index v1.ImageIndex = empty.Index
+		digests := map[string]v1.Hash{}
+		for _, p := range []v1.Platform{amd64, arm64, armv7} {
+			p := p
+			img, err := random.Image(1024, 1)
+			t.CheckNoError(err)
+			index = mutate.AppendManifests(index, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: &p}})
+			digests[platform.Format(p)], err = img.Digest()
+			t.CheckNoError(err)
+		}
+
+		for _, test := range []struct {
+			preferred []v1.Platform
+			expected  string
+		}{
+			{preferred: []v1.Platform{arm64, amd64}, expected: "linux/arm64"},
+			{preferred: []v1.Platform{{OS: "linux", Architecture: "s390x"}, amd64}, expected: "linux/amd64"},
+			{preferred: []v1.Platform{{OS: "linux", Architecture: "arm"}}, expected: "linux/arm/v7"},
+		} {
+			fromIndex, err := singleImage(index, test.preferred)
+			t.CheckNoError(err)
+			digest, err := fromIndex.Digest()
+			t.CheckNoError(err)
+			t.CheckDeepEqual(digests[test.expected], digest)
+		}
+
+		_, err = singleImage(index, []v1.Platform{{OS: "linux", Architecture: "s390x"}})
+		t.CheckErrorContains("ko built no image for platforms linux/s390x", err)
+	})
+}
+
+type fakeKoBuilder struct {
+	kobuild.Interface
+	goBuild goBuilder
+	built   string
+}
+
+func (b *fakeKoBuilder) QualifyImport(importpath string) (string, error) {
+	if importpath == "." {
+		return KoScheme + "example.com/app", nil
+	}
+	if strings.HasPrefix(importpath, KoScheme) {
+		return importpath, nil
+	}
+	return KoScheme + importpath, nil
+}
+
+func (b *fakeKoBuilder) IsSupportedReference(string) error { return nil }
+
+func (b *fakeKoBuilder) Build(ctx context.Context, importpath string) (kobuild.Result, error) {
+	b.built = importpath
+	file, err := b.goBuild(ctx, strings.TrimPrefix(importpath, KoScheme), ".", v1.Platform{OS: "linux", Architecture: "amd64"}, false)
+	if err != nil {
+		return nil, err
+	}
+	os.RemoveAll(filepath.Dir(file))
+	return random.Image(1024, 1)
+}
+
+type fakeLocalDaemon struct {
+	docker.LocalDaemon
+	loaded bool
+}
+
+func (d *fakeLocalDaemon) Load(_ context.Context, _ io.Writer, input io.Reader, _ string) (string, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, input); err != nil {
+		return "", err
+	}
+	d.loaded = buf.Len() > 0
+	return "sha256:loaded", nil
+}
+
+func (d *fakeLocalDaemon) ServerVersion(context.Context) (types.Version, error) {
+	return types.Version{Os: "linux", Arch: "amd64"}, nil
+}
+
+type mockConfig struct {
+	docker.Config
+}
+
+func (c *mockConfig) GetInsecureRegistries() map[string]bool { return nil }
+
+// appliedGoBuilder returns the go builder set by ko options.
+func appliedGoBuilder(t *testutil.T, opts []kobuild.Option) goBuilder {
+	opener := reflect.New(reflect.TypeOf(kobuild.Option(nil)).In(0).Elem())
+	for _, opt := range opts {
+		if err := reflect.ValueOf(opt).Call([]reflect.Value{opener})[0].Interface(); err != nil {
+			t.Fatalf("applying ko option: %v", err)
+		}
+	}
+	field := opener.Elem().FieldByName("build")
+	field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+	return field.Convert(reflect.TypeOf(goBuilder(nil))).Interface().(goBuilder)
+}
+
+// recordingCmd records the last command it was asked to run.
+type recordingCmd struct {
+	args []string
+	dir  string
+	env  []string
+}
+
+func (c *recordingCmd) RunCmdOut(cmd *exec.Cmd) ([]byte, error) {
+	return nil, c.RunCmd(cmd)
+}
+
+func (c *recordingCmd) RunCmd(cmd *exec.Cmd) error {
+	c.args = cmd.Args
+	c.dir = cmd.Dir
+	c.env = cmd.Env
+	return nil
+}
+
+// lastGoFlags returns the value of GOFLAGS that applies in a command's environment.
+func lastGoFlags(env []string) string {
+	goflags := ""
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GOFLAGS=") {
+			goflags = strings.TrimPrefix(kv, "GOFLAGS=")
+		}
+	}
+	return goflags
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ko
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/list"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// GetDependencies returns dependencies listed for a ko artifact.
+// Unless paths are configured, these are the Go sources, the `go.mod` and `go.sum` files, and the content of `kodata` directories.
+func GetDependencies(ctx context.Context, workspace string, a *latestV1.KoArtifact) ([]string, error) {
+	var paths, ignore []string
+	if a.Dependencies != nil {
+		paths = a.Dependencies.Paths
+		ignore = a.Dependencies.Ignore
+	}
+	if len(paths) > 0 {
+		return list.Files(workspace, paths, ignore)
+	}
+
+	files, err := list.Files(workspace, []string{"."}, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencies []string
+	for _, file := range files {
+		if isGoDependency(file) {
+			dependencies = append(dependencies, file)
+		}
+	}
+	return dependencies, nil
+}
+
+func isGoDependency(path string) bool {
+	switch filepath.Base(path) {
+	case "go.mod", "go.sum":
+		return true
+	}
+	if filepath.Ext(path) == ".go" {
+		return !strings.HasSuffix(path, "_test.go")
+	}
+	for _, dir := range strings.Split(filepath.Dir(path), string(filepath.Separator)) {
+		if dir == "kodata" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ko
+
+import (
+	"context"
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestGetDependencies(t *testing.T) {
+	tests := []struct {
+		description  string
+		dependencies *latestV1.KoDependencies
+		expected     []string
+	}{
+		{
+			description: "go sources by default",
+			expected:    []string{"cmd/app/kodata/index.html", "cmd/app/main.go", "go.mod", "go.sum", "pkg/lib.go"},
+		},
+		{
+			description:  "ignore",
+			dependencies: &latestV1.KoDependencies{Ignore: []string{"cmd"}},
+			expected:     []string{"go.mod", "go.sum", "pkg/lib.go"},
+		},
+		{
+			description:  "paths",
+			dependencies: &latestV1.KoDependencies{Paths: []string{"pkg", "README.md"}},
+			expected:     []string{"README.md", "pkg/lib.go", "pkg/lib_test.go"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			tmpDir := t.NewTempDir().
+				Touch("go.mod", "go.sum", "README.md", "cmd/app/main.go", "cmd/app/kodata/index.html", "pkg/lib.go", "pkg/lib_test.go")
+
+			deps, err := GetDependencies(context.Background(), tmpDir.Root(), &latestV1.KoArtifact{Dependencies: test.dependencies})
+
+			t.CheckErrorAndDeepEqual(false, err, test.expected, deps)
+		})
+	}
+}
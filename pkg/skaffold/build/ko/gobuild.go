@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ko
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	kobuild "github.com/google/ko/pkg/build"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// goBuilder has the signature of the functions ko uses to compile a binary.
+type goBuilder func(ctx context.Context, importPath string, dir string, platform v1.Platform, disableOptimizations bool) (string, error)
+
+// withGoBuilder replaces the way ko compiles binaries.
+// This version of ko has no option to set the environment of `go build`, which it reads
+// from the Skaffold process. The option sets ko's unexported builder field instead.
+func withGoBuilder(build goBuilder) kobuild.Option {
+	optionType := reflect.TypeOf(kobuild.Option(nil))
+	errType := optionType.Out(0)
+
+	option := reflect.MakeFunc(optionType, func(args []reflect.Value) []reflect.Value {
+		field := args[0].Elem().FieldByName("build")
+		if !field.IsValid() || !reflect.TypeOf(build).ConvertibleTo(field.Type()) {
+			err := fmt.Errorf("unsupported version of ko: cannot set the environment of go builds")
+			return []reflect.Value{reflect.ValueOf(&err).Elem()}
+		}
+		field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+		field.Set(reflect.ValueOf(build).Convert(field.Type()))
+		return []reflect.Value{reflect.Zero(errType)}
+	})
+	return option.Interface().(kobuild.Option)
+}
+
+// goBuild returns a builder that compiles binaries like ko does, with additional environment variables.
+// The variables are set on the `go build` command only, so concurrent builds don't see each other's environment.
+func goBuild(env []string) goBuilder {
+	return func(ctx context.Context, importPath string, dir string, platform v1.Platform, disableOptimizations bool) (string, error) {
+		tmpDir, err := ioutil.TempDir("", "ko")
+		if err != nil {
+			return "", err
+		}
+		file := filepath.Join(tmpDir, "out")
+
+		args := []string{"build"}
+		if disableOptimizations {
+			// Disable optimizations (-N) and inlining (-l).
+			args = append(args, "-gcflags", "all=-N -l")
+		}
+		args = append(args, "-o", file, "-trimpath", importPath)
+
+		cmd := exec.CommandContext(ctx, "go", args...)
+		cmd.Dir = dir
+		// Last one wins: the artifact's env overrides the process env, which overrides the platform.
+		cmd.Env = append(append(platformEnv(platform), os.Environ()...), env...)
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+
+		if err := util.RunCmd(cmd); err != nil {
+			os.RemoveAll(tmpDir)
+			return "", fmt.Errorf("running go build: %w\n%s", err, output.String())
+		}
+		return file, nil
+	}
+}
+
+// platformEnv returns the environment variables that make `go build` target a platform.
+func platformEnv(platform v1.Platform) []string {
+	env := []string{
+		"CGO_ENABLED=0",
+		"GOOS=" + platform.OS,
+		"GOARCH=" + platform.Architecture,
+	}
+	if strings.HasPrefix(platform.Architecture, "arm") && strings.HasPrefix(platform.Variant, "v") {
+		if variant, err := strconv.Atoi(strings.TrimPrefix(platform.Variant, "v")); err == nil && variant >= 5 {
+			if variant > 7 {
+				variant = 7
+			}
+			env = append(env, "GOARM="+strconv.Itoa(variant))
+		}
+	}
+	return env
+}
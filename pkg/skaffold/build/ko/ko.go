@@ -21,5 +21,4 @@ import (
 )
 
 // KoScheme is the prefix used to disambiguate image references and Go import paths.
-// Adding the const here to force import of a ko package.
 const KoScheme = kobuild.StrictScheme
@@ -18,13 +18,16 @@ package local
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 )
 
@@ -69,6 +72,18 @@ func (b *Builder) PushImages() bool {
 }
 
 func (b *Builder) buildArtifact(ctx context.Context, out io.Writer, a *latestV1.Artifact, tag string) (string, error) {
+	if err := b.checkPlatforms(a); err != nil {
+		return "", err
+	}
+	if platform.IsMultiPlatform(a.Platforms) && a.DockerArtifact != nil {
+		// docker builds a single platform at a time.
+		digest, err := build.BuildMultiPlatformImage(ctx, out, a, tag, b.cfg, b.runBuildForArtifact)
+		if err != nil {
+			return "", err
+		}
+		return build.TagWithDigest(tag, digest), nil
+	}
+
 	digestOrImageID, err := b.runBuildForArtifact(ctx, out, a, tag)
 	if err != nil {
 		return "", err
@@ -96,6 +111,23 @@ func (b *Builder) buildArtifact(ctx context.Context, out io.Writer, a *latestV1.
 	return build.TagWithImageID(ctx, tag, imageID, b.localDocker)
 }
 
+// checkPlatforms fails fast if the artifact's builder can't build the artifact's platforms.
+func (b *Builder) checkPlatforms(a *latestV1.Artifact) error {
+	if len(a.Platforms) == 0 {
+		return nil
+	}
+	switch {
+	case a.BazelArtifact != nil:
+		return platform.UnsupportedPlatformsErr("bazel", a.ImageName, a.Platforms)
+	case a.BuildpackArtifact != nil:
+		return platform.UnsupportedPlatformsErr("buildpacks", a.ImageName, a.Platforms)
+	}
+	if platform.IsMultiPlatform(a.Platforms) && !b.pushImages {
+		return fmt.Errorf("building image %q for platforms %s requires pushing the image to a registry: use `--push` or build a single platform", a.ImageName, strings.Join(a.Platforms, ","))
+	}
+	return nil
+}
+
 func (b *Builder) runBuildForArtifact(ctx context.Context, out io.Writer, a *latestV1.Artifact, tag string) (string, error) {
 	if !b.pushImages {
 		// All of the builders will rely on a local Docker:
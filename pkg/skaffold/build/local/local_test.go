@@ -257,6 +257,58 @@ type dummyLocalDaemon struct {
 	docker.LocalDaemon
 }
 
+func TestCheckPlatforms(t *testing.T) {
+	tests := []struct {
+		description string
+		artifact    latestV1.ArtifactType
+		platforms   []string
+		pushImages  bool
+		shouldErr   bool
+	}{
+		{
+			description: "no platforms",
+			artifact:    latestV1.ArtifactType{BazelArtifact: &latestV1.BazelArtifact{}},
+		},
+		{
+			description: "docker single platform",
+			artifact:    latestV1.ArtifactType{DockerArtifact: &latestV1.DockerArtifact{}},
+			platforms:   []string{"linux/arm64"},
+		},
+		{
+			description: "docker multiple platforms",
+			artifact:    latestV1.ArtifactType{DockerArtifact: &latestV1.DockerArtifact{}},
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			pushImages:  true,
+		},
+		{
+			description: "multiple platforms without push",
+			artifact:    latestV1.ArtifactType{JibArtifact: &latestV1.JibArtifact{}},
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			shouldErr:   true,
+		},
+		{
+			description: "bazel",
+			artifact:    latestV1.ArtifactType{BazelArtifact: &latestV1.BazelArtifact{}},
+			platforms:   []string{"linux/arm64"},
+			shouldErr:   true,
+		},
+		{
+			description: "buildpacks",
+			artifact:    latestV1.ArtifactType{BuildpackArtifact: &latestV1.BuildpackArtifact{}},
+			platforms:   []string{"linux/amd64", "linux/arm64"},
+			pushImages:  true,
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			b := &Builder{pushImages: test.pushImages}
+			err := b.checkPlatforms(&latestV1.Artifact{ImageName: "img", ArtifactType: test.artifact, Platforms: test.platforms})
+			t.CheckError(test.shouldErr, err)
+		})
+	}
+}
+
 func TestNewBuilder(t *testing.T) {
 	dummyDaemon := dummyLocalDaemon{}
 
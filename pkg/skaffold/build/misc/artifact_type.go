@@ -31,6 +31,8 @@ const (
 	Jib       = "jib"
 	Custom    = "custom"
 	Buildpack = "buildpack"
+	Ko        = "ko"
+	BuildKit  = "buildkit"
 )
 
 // ArtifactType returns a string representing the type found in an artifact. Used for error messages.
@@ -49,6 +51,10 @@ func ArtifactType(a *latestV1.Artifact) string {
 		return Custom
 	case a.BuildpackArtifact != nil:
 		return Buildpack
+	case a.KoArtifact != nil:
+		return Ko
+	case a.BuildKitArtifact != nil:
+		return BuildKit
 	default:
 		return ""
 	}
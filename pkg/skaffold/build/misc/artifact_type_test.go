@@ -39,6 +39,16 @@ func TestArtifactType(t *testing.T) {
 				KanikoArtifact: &latestV1.KanikoArtifact{},
 			},
 		}},
+		{"buildkit", "buildkit", &latestV1.Artifact{
+			ArtifactType: latestV1.ArtifactType{
+				BuildKitArtifact: &latestV1.BuildKitArtifact{},
+			},
+		}},
+		{"ko", "ko", &latestV1.Artifact{
+			ArtifactType: latestV1.ArtifactType{
+				KoArtifact: &latestV1.KoArtifact{},
+			},
+		}},
 		{"docker+kaniko", "docker", &latestV1.Artifact{
 			ArtifactType: latestV1.ArtifactType{
 				DockerArtifact: &latestV1.DockerArtifact{},
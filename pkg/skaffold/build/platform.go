@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// for testing
+var (
+	createManifestList = docker.CreateManifestList
+)
+
+// BuildMultiPlatformImage builds and pushes an image for each platform of the artifact with a builder that only supports a single platform,
+// then assembles the images into an image index pushed with the given tag.
+// The single platform builder is expected to return the digest of the pushed image. The digest of the image index is returned.
+func BuildMultiPlatformImage(ctx context.Context, out io.Writer, a *latestV1.Artifact, tag string, cfg docker.Config, buildSinglePlatform ArtifactBuilder) (string, error) {
+	var images []docker.SinglePlatformImage
+	for _, p := range a.Platforms {
+		pl, err := platform.Parse(p)
+		if err != nil {
+			return "", err
+		}
+		// build a copy of the artifact that only targets the current platform
+		single := *a
+		single.Platforms = []string{p}
+		platformTag := tag + platform.TagSuffix(p)
+
+		output.Default.Fprintf(out, "Building [%s] for platform %s...\n", a.ImageName, p)
+		digest, err := buildSinglePlatform(ctx, out, &single, platformTag)
+		if err != nil {
+			return "", fmt.Errorf("building image %q for platform %s: %w", a.ImageName, p, err)
+		}
+		images = append(images, docker.SinglePlatformImage{Platform: pl, Image: TagWithDigest(platformTag, digest)})
+	}
+
+	output.Default.Fprintf(out, "Pushing image index for [%s] with %d platforms\n", a.ImageName, len(images))
+	return createManifestList(images, tag, cfg)
+}
+
+// IndexDigest returns the digest of the image index built for an artifact with multiple platforms, or an empty string.
+func IndexDigest(a *latestV1.Artifact, tag string) string {
+	if !platform.IsMultiPlatform(a.Platforms) {
+		return ""
+	}
+	ref, err := docker.ParseReference(tag)
+	if err != nil {
+		return ""
+	}
+	return ref.Digest
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestBuildMultiPlatformImage(t *testing.T) {
+	tests := []struct {
+		description    string
+		buildErr       error
+		expectedImages []docker.SinglePlatformImage
+		expected       string
+		shouldErr      bool
+	}{
+		{
+			description: "build each platform and create image index",
+			expected:    "sha256:index",
+			expectedImages: []docker.SinglePlatformImage{
+				{Platform: v1.Platform{OS: "linux", Architecture: "amd64"}, Image: "img:tag_linux_amd64@sha256:linux_amd64"},
+				{Platform: v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, Image: "img:tag_linux_arm_v7@sha256:linux_arm_v7"},
+			},
+		},
+		{
+			description: "single platform build fails",
+			buildErr:    errors.New("unsupported"),
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			var images []docker.SinglePlatformImage
+			t.Override(&createManifestList, func(i []docker.SinglePlatformImage, tag string, _ docker.Config) (string, error) {
+				images = i
+				t.CheckDeepEqual("img:tag", tag)
+				return "sha256:index", nil
+			})
+			artifact := &latestV1.Artifact{ImageName: "img", Platforms: []string{"linux/amd64", "linux/arm/v7"}}
+
+			var out bytes.Buffer
+			digest, err := BuildMultiPlatformImage(context.Background(), &out, artifact, "img:tag", nil, func(_ context.Context, _ io.Writer, a *latestV1.Artifact, tag string) (string, error) {
+				if test.buildErr != nil {
+					return "", test.buildErr
+				}
+				t.CheckDeepEqual(1, len(a.Platforms))
+				return "sha256:" + tag[len("img:tag_"):], nil
+			})
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, digest)
+			t.CheckDeepEqual(test.expectedImages, images)
+			// the artifact itself is left untouched
+			t.CheckDeepEqual([]string{"linux/amd64", "linux/arm/v7"}, artifact.Platforms)
+		})
+	}
+}
+
+func TestIndexDigest(t *testing.T) {
+	tests := []struct {
+		description string
+		platforms   []string
+		tag         string
+		expected    string
+	}{
+		{description: "multi-platform", platforms: []string{"linux/amd64", "linux/arm64"}, tag: "img:tag@sha256:abababababababababababababababababababababababababababababababab", expected: "sha256:abababababababababababababababababababababababababababababababab"},
+		{description: "single platform", platforms: []string{"linux/arm64"}, tag: "img:tag@sha256:abababababababababababababababababababababababababababababababab"},
+		{description: "no digest", platforms: []string{"linux/amd64", "linux/arm64"}, tag: "img:tag"},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.CheckDeepEqual(test.expected, IndexDigest(&latestV1.Artifact{Platforms: test.platforms}, test.tag))
+		})
+	}
+}
@@ -170,7 +170,7 @@ func (ba *artifactStoreImpl) GetArtifacts(s []*latestV1.Artifact) ([]graph.Artif
 		if !found {
 			return nil, fmt.Errorf("failed to retrieve build result for image %s", a.ImageName)
 		}
-		builds = append(builds, graph.Artifact{ImageName: a.ImageName, Tag: t})
+		builds = append(builds, graph.Artifact{ImageName: a.ImageName, Tag: t, IndexDigest: IndexDigest(a, t)})
 	}
 	return builds, nil
 }
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestCreateIsReproducible(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		tmp := t.NewTempDir().
+			Write("skaffold.yaml", "apiVersion: skaffold/v2beta20\nkind: Config\n").
+			Write("k8s/deployment.yaml", "kind: Deployment").
+			Write(".git/HEAD", "ref: refs/heads/main")
+
+		var first, second bytes.Buffer
+		t.CheckNoError(Create(tmp.Root(), &first))
+		t.CheckNoError(Create(tmp.Root(), &second))
+		t.CheckDeepEqual(first.Bytes(), second.Bytes())
+
+		out := t.NewTempDir()
+		t.CheckNoError(extract(&first, out.Root()))
+		content, err := ioutil.ReadFile(out.Path("k8s/deployment.yaml"))
+		t.CheckNoError(err)
+		t.CheckDeepEqual("kind: Deployment", string(content))
+		_, err = os.Stat(out.Path(".git"))
+		t.CheckDeepEqual(true, os.IsNotExist(err))
+	})
+}
+
+func TestExtractRejectsEscapingPaths(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		content := []byte("evil")
+		t.CheckNoError(tw.WriteHeader(&tar.Header{Name: "../evil.yaml", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		t.CheckNoError(err)
+		t.CheckNoError(tw.Close())
+		t.CheckNoError(gw.Close())
+
+		err = extract(&buf, t.NewTempDir().Root())
+		t.CheckErrorContains("outside of the bundle root", err)
+	})
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+)
+
+// PushOCI packages the `root` directory and pushes it to `ref` as an OCI config bundle.
+// It returns the digest of the pushed image.
+func PushOCI(root, ref string, insecureRegistries []string) (string, error) {
+	var buf bytes.Buffer
+	if err := Create(root, &buf); err != nil {
+		return "", fmt.Errorf("packaging %s: %w", root, err)
+	}
+	content := buf.Bytes()
+
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	img, err := mutate.Append(empty.Image, mutate.Addendum{Layer: layer, MediaType: LayerMediaType})
+	if err != nil {
+		return "", err
+	}
+	img = mutate.MediaType(img, types.OCIManifestSchema1)
+
+	parsed, err := parseReference(ref, insecureRegistries)
+	if err != nil {
+		return "", fmt.Errorf("invalid reference %q: %w", ref, err)
+	}
+	if err := docker.WriteRemoteImage(parsed, img); err != nil {
+		return "", fmt.Errorf("pushing config bundle to %s: %w", ref, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
+}
+
+// WriteArchive packages the `root` directory into a tarball written to `w`.
+// It returns the sha256 checksum of the tarball.
+func WriteArchive(root string, w io.Writer, exclude ...string) (string, error) {
+	hasher := sha256.New()
+	if err := Create(root, io.MultiWriter(w, hasher), exclude...); err != nil {
+		return "", fmt.Errorf("packaging %s: %w", root, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/git"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// digestFile records the digest of the image a cached OCI bundle was extracted from.
+const digestFile = ".skaffold-bundle-digest"
+
+var (
+	// SyncOCI syncs the target OCI config bundle with skaffold's local cache and returns the path to the bundle root directory.
+	SyncOCI = syncOCI
+
+	// SyncArchive syncs the target config archive with skaffold's local cache and returns the path to the archive root directory.
+	SyncArchive = syncArchive
+
+	// for testing
+	httpClient = http.DefaultClient
+)
+
+func syncOCI(b latestV1.OCIBundleInfo, opts config.SkaffoldOptions) (string, error) {
+	cacheDir, err := bundleCacheDir(opts, "oci", b.Ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+	}
+
+	// if sync property is false, then reuse the cached bundle without checking the registry.
+	if b.Sync != nil && !*b.Sync && exists(cacheDir) {
+		return cacheDir, nil
+	}
+
+	ref, err := parseReference(b.Ref, opts.InsecureRegistries)
+	if err != nil {
+		return "", fmt.Errorf("failed to pull config bundle %s: invalid reference: %w", b.Ref, err)
+	}
+	img, err := docker.RemoteImage(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+	}
+
+	if cached, err := ioutil.ReadFile(filepath.Join(cacheDir, digestFile)); err == nil && string(cached) == digest.String() {
+		return cacheDir, nil
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+	}
+	for _, layer := range layers {
+		mediaType, err := layer.MediaType()
+		if err != nil {
+			return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+		}
+		if mediaType != LayerMediaType {
+			continue
+		}
+
+		rc, err := layer.Compressed()
+		if err != nil {
+			return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+		}
+		defer rc.Close()
+
+		if err := replaceDir(cacheDir, rc, digest.String()); err != nil {
+			return "", fmt.Errorf("failed to pull config bundle %s: %w", b.Ref, err)
+		}
+		return cacheDir, nil
+	}
+
+	return "", fmt.Errorf("failed to pull config bundle %s: image has no layer of type %q; use `skaffold config publish` to create config bundles", b.Ref, LayerMediaType)
+}
+
+func syncArchive(a latestV1.ArchiveInfo, opts config.SkaffoldOptions) (string, error) {
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download config archive %s: %w", a.URL, err)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("failed to download config archive %s: only https URLs are supported", a.URL)
+	}
+	expected := strings.ToLower(strings.TrimPrefix(a.SHA256, "sha256:"))
+
+	// the checksum is part of the cache key, so a cached archive never needs to be downloaded again.
+	cacheDir, err := bundleCacheDir(opts, "archive", a.URL, expected)
+	if err != nil {
+		return "", fmt.Errorf("failed to download config archive %s: %w", a.URL, err)
+	}
+	if exists(cacheDir) {
+		return cacheDir, nil
+	}
+
+	resp, err := httpClient.Get(a.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download config archive %s: %w", a.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download config archive %s: %s", a.URL, resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to download config archive %s: %w", a.URL, err)
+	}
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return "", fmt.Errorf("failed to download config archive %s: checksum mismatch: expected sha256 %s, got %s", a.URL, expected, actual)
+	}
+
+	if err := replaceDir(cacheDir, bytes.NewReader(content), ""); err != nil {
+		return "", fmt.Errorf("failed to download config archive %s: %w", a.URL, err)
+	}
+	return cacheDir, nil
+}
+
+func parseReference(s string, insecureRegistries []string) (name.Reference, error) {
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		return nil, err
+	}
+	insecure := map[string]bool{}
+	for _, r := range insecureRegistries {
+		insecure[r] = true
+	}
+	if docker.IsInsecure(ref, insecure) {
+		return name.ParseReference(s, name.Insecure)
+	}
+	return ref, nil
+}
+
+// bundleCacheDir returns the cache directory for a remote bundle.
+// Bundles share the cache with remote git repositories.
+func bundleCacheDir(opts config.SkaffoldOptions, inputs ...string) (string, error) {
+	root, err := git.GetRepoCacheDir(opts)
+	if err != nil {
+		return "", err
+	}
+
+	hasher := sha256.New()
+	if err := json.NewEncoder(hasher).Encode(inputs); err != nil {
+		return "", err
+	}
+	// UrlEncoding supports '-' as a 63rd character, which can cause dir name issues
+	hash := strings.ReplaceAll(base64.StdEncoding.EncodeToString(hasher.Sum(nil))[:32], "/", "_")
+	return filepath.Join(root, hash), nil
+}
+
+// replaceDir extracts a bundle into a temporary directory and moves it to `dir` once complete,
+// so that an interrupted download never leaves a partial bundle in the cache.
+func replaceDir(dir string, r io.Reader, digest string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), filepath.Base(dir)+".tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extract(r, tmp); err != nil {
+		return err
+	}
+	if digest != "" {
+		if err := ioutil.WriteFile(filepath.Join(tmp, digestFile), []byte(digest), 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing stale bundle: %w", err)
+	}
+	return os.Rename(tmp, dir)
+}
+
+func exists(dir string) bool {
+	_, err := os.Stat(dir)
+	return err == nil
+}
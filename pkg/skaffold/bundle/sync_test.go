@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestSyncOCI(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		server := httptest.NewServer(registry.New())
+		defer server.Close()
+		ref := strings.TrimPrefix(server.URL, "http://") + "/platform/modules:v1"
+
+		src := t.NewTempDir().Write("skaffold.yaml", "v1")
+		digest, err := PushOCI(src.Root(), ref, nil)
+		t.CheckNoError(err)
+
+		opts := config.SkaffoldOptions{RepoCacheDir: t.NewTempDir().Root()}
+		dir, err := SyncOCI(latestV1.OCIBundleInfo{Ref: ref}, opts)
+		t.CheckNoError(err)
+		t.CheckFileExistAndContent(filepath.Join(dir, "skaffold.yaml"), []byte("v1"))
+		t.CheckFileExistAndContent(filepath.Join(dir, digestFile), []byte(digest))
+
+		// a new version of the bundle is pulled unless sync is disabled.
+		src.Write("skaffold.yaml", "v2")
+		_, err = PushOCI(src.Root(), ref, nil)
+		t.CheckNoError(err)
+
+		_, err = SyncOCI(latestV1.OCIBundleInfo{Ref: ref, Sync: util.BoolPtr(false)}, opts)
+		t.CheckNoError(err)
+		t.CheckFileExistAndContent(filepath.Join(dir, "skaffold.yaml"), []byte("v1"))
+
+		_, err = SyncOCI(latestV1.OCIBundleInfo{Ref: ref}, opts)
+		t.CheckNoError(err)
+		t.CheckFileExistAndContent(filepath.Join(dir, "skaffold.yaml"), []byte("v2"))
+	})
+}
+
+func TestSyncArchive(t *testing.T) {
+	var archive bytes.Buffer
+	src := testutil.NewTempDir(t).Write("skaffold.yaml", "archived")
+	checksum, err := WriteArchive(src.Root(), &archive)
+	testutil.CheckError(t, false, err)
+
+	tests := []struct {
+		description string
+		url         string
+		sha256      string
+		shouldErr   bool
+		expectedErr string
+	}{
+		{
+			description: "valid archive",
+			url:         "/bundle.tar.gz",
+			sha256:      checksum,
+		},
+		{
+			description: "checksum with algorithm prefix",
+			url:         "/bundle.tar.gz",
+			sha256:      "sha256:" + checksum,
+		},
+		{
+			description: "checksum mismatch",
+			url:         "/bundle.tar.gz",
+			sha256:      strings.Repeat("0", 64),
+			shouldErr:   true,
+			expectedErr: "checksum mismatch",
+		},
+		{
+			description: "not found",
+			url:         "/missing.tar.gz",
+			sha256:      checksum,
+			shouldErr:   true,
+			expectedErr: "404 Not Found",
+		},
+		{
+			description: "plain http",
+			url:         "http://example.com/bundle.tar.gz",
+			sha256:      checksum,
+			shouldErr:   true,
+			expectedErr: "only https URLs are supported",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/bundle.tar.gz" {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write(archive.Bytes())
+			}))
+			defer server.Close()
+			t.Override(&httpClient, server.Client())
+
+			url := test.url
+			if strings.HasPrefix(url, "/") {
+				url = server.URL + url
+			}
+			opts := config.SkaffoldOptions{RepoCacheDir: t.NewTempDir().Root()}
+			dir, err := SyncArchive(latestV1.ArchiveInfo{URL: url, SHA256: test.sha256}, opts)
+
+			if test.shouldErr {
+				t.CheckErrorContains(test.expectedErr, err)
+				return
+			}
+			t.CheckNoError(err)
+			t.CheckFileExistAndContent(filepath.Join(dir, "skaffold.yaml"), []byte("archived"))
+		})
+	}
+}
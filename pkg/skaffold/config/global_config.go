@@ -36,8 +36,18 @@ type ContextConfig struct {
 	Survey               *SurveyConfig `yaml:"survey,omitempty"`
 	KindDisableLoad      *bool         `yaml:"kind-disable-load,omitempty"`
 	K3dDisableLoad       *bool         `yaml:"k3d-disable-load,omitempty"`
-	CollectMetrics       *bool         `yaml:"collect-metrics,omitempty"`
-	UpdateCheckConfig    *UpdateConfig `yaml:"update,omitempty"`
+	// ImageLoader overrides the image loader detected from the kube-context.
+	ImageLoader       string        `yaml:"image-loader,omitempty"`
+	CollectMetrics    *bool         `yaml:"collect-metrics,omitempty"`
+	UpdateCheckConfig *UpdateConfig `yaml:"update,omitempty"`
+	// OTLPEndpoint is the OpenTelemetry collector to which Skaffold exports its own traces and metrics.
+	OTLPEndpoint string `yaml:"otlp-endpoint,omitempty"`
+	// OTLPProtocol is the OTLP transport: `grpc` or `http/protobuf`.
+	OTLPProtocol string `yaml:"otlp-protocol,omitempty"`
+	// OTLPHeaders are `key=value` headers sent with every OTLP export.
+	OTLPHeaders []string `yaml:"otlp-headers,omitempty"`
+	// OTLPInsecure disables TLS when connecting to the OTLP collector.
+	OTLPInsecure *bool `yaml:"otlp-insecure,omitempty"`
 }
 
 // SurveyConfig is the survey config information
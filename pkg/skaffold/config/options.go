@@ -46,6 +46,7 @@ type SkaffoldOptions struct {
 	Tail                  bool
 	SkipTests             bool
 	CacheArtifacts        bool
+	CacheRepoReadOnly     bool
 	EnableRPC             bool
 	Force                 bool
 	NoPrune               bool
@@ -54,6 +55,7 @@ type SkaffoldOptions struct {
 	AutoSync              bool
 	AutoDeploy            bool
 	RenderOnly            bool
+	Preview               bool
 	AutoCreateConfig      bool
 	AssumeYes             bool
 	ProfileAutoActivation bool
@@ -71,16 +73,21 @@ type SkaffoldOptions struct {
 	// Experimental is the entrypoint to run skaffold v3 before it's fully implemented.
 	Experimental         bool
 	StatusCheck          BoolOrUndefined
+	RollbackOnFailure    BoolOrUndefined
 	IterativeStatusCheck bool
 
 	PortForward        PortForwardOptions
+	PortForwarder      string
+	FileSyncer         string
 	CustomTag          string
 	Namespace          string
 	CacheFile          string
+	CacheRepo          string
 	Trigger            string
 	KubeContext        string
 	KubeConfig         string
 	DigestSource       string
+	DiffFormat         string
 	WatchPollInterval  int
 	DefaultRepo        StringOrUndefined
 	PushImages         BoolOrUndefined
@@ -88,6 +95,7 @@ type SkaffoldOptions struct {
 	TargetImages       []string
 	Profiles           []string
 	InsecureRegistries []string
+	Platforms          []string
 	Muted              Muted
 	Command            string
 	RPCPort            int
@@ -113,6 +121,7 @@ var RunModes = struct {
 	Render   RunMode
 	Delete   RunMode
 	Diagnose RunMode
+	Apply    RunMode
 }{
 	Build:    "build",
 	Dev:      "dev",
@@ -122,6 +131,7 @@ var RunModes = struct {
 	Render:   "render",
 	Delete:   "delete",
 	Diagnose: "diagnose",
+	Apply:    "apply",
 }
 
 // Prune returns true iff the user did NOT specify the --no-prune flag,
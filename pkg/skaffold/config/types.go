@@ -118,4 +118,23 @@ type Cluster struct {
 	Local      bool
 	PushImages bool
 	LoadImages bool
-}
+	// ImageLoader is the mechanism used to make locally built images available to the cluster.
+	ImageLoader string
+}
+
+// Image loaders that can be set with the `image-loader` global config.
+const (
+	ImageLoaderKind     = "kind"
+	ImageLoaderK3d      = "k3d"
+	ImageLoaderMinikube = "minikube"
+	ImageLoaderNerdctl  = "nerdctl"
+	ImageLoaderCtr      = "ctr"
+	ImageLoaderRegistry = "registry"
+	ImageLoaderNone     = "none"
+)
+
+// ImageLoaders lists all the supported image loaders.
+var ImageLoaders = []string{ImageLoaderKind, ImageLoaderK3d, ImageLoaderMinikube, ImageLoaderNerdctl, ImageLoaderCtr, ImageLoaderRegistry, ImageLoaderNone}
+
+// LocalRegistry is the address of the registry container that Skaffold runs for the `registry` image loader.
+const LocalRegistry = "localhost:5001"
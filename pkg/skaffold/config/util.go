@@ -170,8 +170,13 @@ func GetDefaultRepo(configFile string, cliValue *string) (string, error) {
 	}
 	if cfg.DefaultRepo != "" {
 		logrus.Infof("Using default-repo=%s from config", cfg.DefaultRepo)
+		return cfg.DefaultRepo, nil
 	}
-	return cfg.DefaultRepo, nil
+	if cfg.ImageLoader == ImageLoaderRegistry {
+		logrus.Infof("Using default-repo=%s for image-loader=%s", LocalRegistry, ImageLoaderRegistry)
+		return LocalRegistry, nil
+	}
+	return "", nil
 }
 
 func GetInsecureRegistries(configFile string) ([]string, error) {
@@ -206,6 +211,7 @@ func GetCluster(configFile string, minikubeProfile string, detectMinikube bool)
 
 	kubeContext := cfg.Kubecontext
 	isKindCluster, isK3dCluster := IsKindCluster(kubeContext), IsK3dCluster(kubeContext)
+	isMinikube := minikubeProfile != "" || kubeContext == constants.DefaultMinikubeContext
 
 	var local bool
 	switch {
@@ -219,11 +225,13 @@ func GetCluster(configFile string, minikubeProfile string, detectMinikube bool)
 	case kubeContext == constants.DefaultMinikubeContext ||
 		kubeContext == constants.DefaultDockerForDesktopContext ||
 		kubeContext == constants.DefaultDockerDesktopContext ||
+		kubeContext == constants.DefaultRancherDesktopContext ||
 		isKindCluster || isK3dCluster:
 		local = true
 
 	case detectMinikube:
-		local = cluster.GetClient().IsMinikube(kubeContext)
+		isMinikube = cluster.GetClient().IsMinikube(kubeContext)
+		local = isMinikube
 
 	default:
 		local = false
@@ -232,16 +240,47 @@ func GetCluster(configFile string, minikubeProfile string, detectMinikube bool)
 	kindDisableLoad := cfg.KindDisableLoad != nil && *cfg.KindDisableLoad
 	k3dDisableLoad := cfg.K3dDisableLoad != nil && *cfg.K3dDisableLoad
 
+	var imageLoader string
+	switch {
+	case cfg.ImageLoader != "":
+		logrus.Infof("Using image-loader=%s from config", cfg.ImageLoader)
+		if !util.StrSliceContains(ImageLoaders, cfg.ImageLoader) {
+			return Cluster{}, fmt.Errorf("invalid image-loader %q, must be one of %v", cfg.ImageLoader, ImageLoaders)
+		}
+		imageLoader = cfg.ImageLoader
+
+	case !local:
+		imageLoader = ImageLoaderNone
+
 	// load images for local kind/k3d cluster unless explicitly disabled
-	loadImages := local && ((isKindCluster && !kindDisableLoad) || (isK3dCluster && !k3dDisableLoad))
+	case isKindCluster && !kindDisableLoad:
+		imageLoader = ImageLoaderKind
+	case isK3dCluster && !k3dDisableLoad:
+		imageLoader = ImageLoaderK3d
+
+	case isMinikube:
+		imageLoader = ImageLoaderMinikube
+	case kubeContext == constants.DefaultRancherDesktopContext:
+		imageLoader = ImageLoaderNerdctl
+
+	default:
+		imageLoader = ImageLoaderNone
+	}
+
+	var loadImages bool
+	switch imageLoader {
+	case ImageLoaderKind, ImageLoaderK3d, ImageLoaderMinikube, ImageLoaderNerdctl, ImageLoaderCtr:
+		loadImages = local
+	}
 
-	// push images for remote cluster or local kind/k3d cluster with image loading disabled
-	pushImages := !local || (isKindCluster && kindDisableLoad) || (isK3dCluster && k3dDisableLoad)
+	// push images for remote cluster, local kind/k3d cluster with image loading disabled or when using a local registry
+	pushImages := !local || (isKindCluster && kindDisableLoad) || (isK3dCluster && k3dDisableLoad) || imageLoader == ImageLoaderRegistry
 
 	return Cluster{
-		Local:      local,
-		LoadImages: loadImages,
-		PushImages: pushImages,
+		Local:       local,
+		LoadImages:  loadImages,
+		PushImages:  pushImages,
+		ImageLoader: imageLoader,
 	}, nil
 }
 
@@ -276,87 +276,113 @@ func TestGetCluster(t *testing.T) {
 		cfg         *ContextConfig
 		profile     string
 		expected    Cluster
+		shouldErr   bool
 	}{
 		{
 			description: "kind",
 			cfg:         &ContextConfig{Kubecontext: "kind-other"},
-			expected:    Cluster{Local: true, LoadImages: true, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderKind},
 		},
 		{
 			description: "kind with local-cluster=false",
 			cfg:         &ContextConfig{Kubecontext: "kind-other", LocalCluster: util.BoolPtr(false)},
-			expected:    Cluster{Local: false, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: false, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "kind with kind-disable-load=true",
 			cfg:         &ContextConfig{Kubecontext: "kind-other", KindDisableLoad: util.BoolPtr(true)},
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "kind with legacy name",
 			cfg:         &ContextConfig{Kubecontext: "kind@kind"},
-			expected:    Cluster{Local: true, LoadImages: true, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderKind},
 		},
 		{
 			description: "k3d",
 			cfg:         &ContextConfig{Kubecontext: "k3d-k3s-default"},
-			expected:    Cluster{Local: true, LoadImages: true, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderK3d},
 		},
 		{
 			description: "k3d with local-cluster=false",
 			cfg:         &ContextConfig{Kubecontext: "k3d-k3s-default", LocalCluster: util.BoolPtr(false)},
-			expected:    Cluster{Local: false, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: false, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "k3d with disable-load=true",
 			cfg:         &ContextConfig{Kubecontext: "k3d-k3s-default", K3dDisableLoad: util.BoolPtr(true)},
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "docker-for-desktop",
 			cfg:         &ContextConfig{Kubecontext: "docker-for-desktop"},
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: false, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "minikube",
 			cfg:         &ContextConfig{Kubecontext: "minikube"},
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderMinikube},
 		},
 		{
 			description: "docker-desktop",
 			cfg:         &ContextConfig{Kubecontext: "docker-desktop"},
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: false, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "generic cluster with local-cluster=true",
 			cfg:         &ContextConfig{Kubecontext: "some-cluster", LocalCluster: util.BoolPtr(true)},
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: false, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "generic cluster with minikube profile",
 			cfg:         &ContextConfig{Kubecontext: "some-cluster"},
 			profile:     "someprofile",
-			expected:    Cluster{Local: true, LoadImages: false, PushImages: false},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderMinikube},
 		},
 		{
 			description: "generic cluster",
 			cfg:         &ContextConfig{Kubecontext: "anything-else"},
-			expected:    Cluster{Local: false, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: false, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "not a legacy kind cluster",
 			cfg:         &ContextConfig{Kubecontext: "kind@blah"},
-			expected:    Cluster{Local: false, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: false, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "not a kind cluster",
 			cfg:         &ContextConfig{Kubecontext: "other-kind"},
-			expected:    Cluster{Local: false, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: false, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
 		},
 		{
 			description: "not a k3d cluster",
 			cfg:         &ContextConfig{Kubecontext: "not-k3d"},
-			expected:    Cluster{Local: false, LoadImages: false, PushImages: true},
+			expected:    Cluster{Local: false, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderNone},
+		},
+		{
+			description: "rancher-desktop",
+			cfg:         &ContextConfig{Kubecontext: "rancher-desktop"},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderNerdctl},
+		},
+		{
+			description: "image-loader=ctr",
+			cfg:         &ContextConfig{Kubecontext: "some-cluster", LocalCluster: util.BoolPtr(true), ImageLoader: "ctr"},
+			expected:    Cluster{Local: true, LoadImages: true, PushImages: false, ImageLoader: ImageLoaderCtr},
+		},
+		{
+			description: "image-loader=registry",
+			cfg:         &ContextConfig{Kubecontext: "docker-desktop", ImageLoader: "registry"},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: true, ImageLoader: ImageLoaderRegistry},
+		},
+		{
+			description: "image-loader=none",
+			cfg:         &ContextConfig{Kubecontext: "kind-other", ImageLoader: "none"},
+			expected:    Cluster{Local: true, LoadImages: false, PushImages: false, ImageLoader: ImageLoaderNone},
+		},
+		{
+			description: "invalid image-loader",
+			cfg:         &ContextConfig{Kubecontext: "minikube", ImageLoader: "unknown"},
+			shouldErr:   true,
 		},
 	}
 	for _, test := range tests {
@@ -364,11 +390,11 @@ func TestGetCluster(t *testing.T) {
 			t.Override(&GetConfigForCurrentKubectx, func(string) (*ContextConfig, error) { return test.cfg, nil })
 			t.Override(&cluster.GetClient, func() cluster.Client { return fakeClient{} })
 
-			cluster, _ := GetCluster("dummyname", test.profile, true)
-			t.CheckDeepEqual(test.expected, cluster)
+			cluster, err := GetCluster("dummyname", test.profile, true)
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, cluster)
 
-			cluster, _ = GetCluster("dummyname", test.profile, false)
-			t.CheckDeepEqual(test.expected, cluster)
+			cluster, err = GetCluster("dummyname", test.profile, false)
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, cluster)
 		})
 	}
 }
@@ -477,6 +503,18 @@ func TestGetDefaultRepo(t *testing.T) {
 			cliValue:     nil,
 			expectedRepo: "global/repo",
 		},
+		{
+			description:  "local registry",
+			cfg:          &ContextConfig{ImageLoader: "registry"},
+			cliValue:     nil,
+			expectedRepo: "localhost:5001",
+		},
+		{
+			description:  "local registry with global config",
+			cfg:          &ContextConfig{DefaultRepo: "global/repo", ImageLoader: "registry"},
+			cliValue:     nil,
+			expectedRepo: "global/repo",
+		},
 		{
 			description:  "cancel global config with cli",
 			cfg:          &ContextConfig{DefaultRepo: "global/repo"},
@@ -35,6 +35,7 @@ const (
 	Sync        = Phase("Sync")
 	DevInit     = Phase("DevInit")
 	Cleanup     = Phase("Cleanup")
+	Rollback    = Phase("Rollback")
 
 	// DefaultLogLevel is the default global verbosity
 	DefaultLogLevel = logrus.WarnLevel
@@ -46,6 +47,7 @@ const (
 	DefaultMinikubeContext         = "minikube"
 	DefaultDockerForDesktopContext = "docker-for-desktop"
 	DefaultDockerDesktopContext    = "docker-desktop"
+	DefaultRancherDesktopContext   = "rancher-desktop"
 	GCSBucketSuffix                = "_cloudbuild"
 
 	HelmOverridesFilename = "skaffold-overrides.yaml"
@@ -81,8 +83,9 @@ const (
 type Phase string
 
 var (
-	Pod     latestV1.ResourceType = "pod"
-	Service latestV1.ResourceType = "service"
+	Pod       latestV1.ResourceType = "pod"
+	Service   latestV1.ResourceType = "service"
+	Container latestV1.ResourceType = "container"
 
 	DefaultLocalConcurrency = 1
 )
@@ -94,6 +97,9 @@ var (
 	// PushImage lets the custom build script know if the image is expected to be pushed to a remote registry
 	PushImage = "PUSH_IMAGE"
 
+	// Platforms lets the custom build script know the comma-separated platforms the image is expected to be built for
+	Platforms = "PLATFORMS"
+
 	// BuildContext is the absolute path to a directory this artifact is meant to be built from for custom artifacts
 	BuildContext = "BUILD_CONTEXT"
 
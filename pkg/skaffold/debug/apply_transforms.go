@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -32,6 +33,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
 )
 
 var (
@@ -67,7 +69,15 @@ func applyDebuggingTransforms(l manifest.ManifestList, retriever configurationRe
 	for _, manifest := range l {
 		obj, _, err := decodeFromYaml(manifest, nil, nil)
 		if err != nil {
-			logrus.Debugf("Unable to interpret manifest for debugging: %v\n", err)
+			transformed, changed, crErr := transformCustomResource(manifest, retriever, debugHelpersRegistry)
+			switch {
+			case crErr != nil:
+				return nil, crErr
+			case changed:
+				manifest = transformed
+			default:
+				logrus.Debugf("Unable to interpret manifest for debugging: %v\n", err)
+			}
 		} else if transformManifest(obj, retriever, debugHelpersRegistry) {
 			manifest, err = encodeAsYaml(obj)
 			if err != nil {
@@ -83,6 +93,54 @@ func applyDebuggingTransforms(l manifest.ManifestList, retriever configurationRe
 	return updated, nil
 }
 
+// transformCustomResource configures the pod templates of a user-declared custom resource for debugging.
+// Returns true if changed, false otherwise.
+func transformCustomResource(m []byte, retriever configurationRetriever, debugHelpersRegistry string) ([]byte, bool, error) {
+	obj := make(map[string]interface{})
+	if err := yaml.Unmarshal(m, &obj); err != nil {
+		return nil, false, nil
+	}
+	cr, found := manifest.GetCustomResource(obj)
+	if !found {
+		return nil, false, nil
+	}
+
+	changed := false
+	for _, template := range cr.PodTemplates(obj) {
+		var podTemplate v1.PodTemplateSpec
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, &podTemplate); err != nil {
+			logrus.Debugf("Unable to interpret pod template for debugging: %v\n", err)
+			continue
+		}
+		if !transformPodSpec(&podTemplate.ObjectMeta, &podTemplate.Spec, retriever, debugHelpersRegistry) {
+			continue
+		}
+		transformed, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podTemplate)
+		if err != nil {
+			return nil, false, fmt.Errorf("converting pod template: %w", err)
+		}
+		for k := range template {
+			delete(template, k)
+		}
+		for k, v := range transformed {
+			template[k] = v
+		}
+		changed = true
+	}
+	if !changed {
+		return nil, false, nil
+	}
+
+	updated, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshalling yaml: %w", err)
+	}
+	if logrus.IsLevelEnabled(logrus.DebugLevel) {
+		logrus.Debugln("Applied debugging transform:\n", string(updated))
+	}
+	return updated, true, nil
+}
+
 // findArtifact finds the corresponding artifact for the given image.
 // If `builds` is empty, then treat all `image` images as a build artifact.
 func findArtifact(image string, builds []graph.Artifact) *graph.Artifact {
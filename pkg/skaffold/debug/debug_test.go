@@ -27,6 +27,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug/annotations"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/testutil"
 )
 
@@ -602,6 +603,98 @@ status: {}`,
 	}
 }
 
+func TestApplyDebuggingTransformsToCustomResources(t *testing.T) {
+	defer func(c []containerTransformer) { containerTransforms = c }(containerTransforms)
+	containerTransforms = append(containerTransforms, testTransformer{})
+
+	tests := []struct {
+		description string
+		filters     []latestV1.ResourceFilter
+		in          string
+		out         string
+	}{
+		{
+			description: "declared pod template",
+			filters:     []latestV1.ResourceFilter{{GroupKind: "Workflow.argoproj.io", PodTemplate: []string{".spec.template"}}},
+			in: `apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  template:
+    metadata:
+      labels:
+        app: build
+    spec:
+      containers:
+      - image: gcr.io/k8s-debug/debug-example:latest
+        name: example
+`,
+			out: `apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  template:
+    metadata:
+      annotations:
+        debug.cloud.google.com/config: '{"example":{"runtime":"test"}}'
+      creationTimestamp: null
+      labels:
+        app: build
+    spec:
+      containers:
+      - env:
+        - name: KEY
+          value: value
+        image: gcr.io/k8s-debug/debug-example:latest
+        name: example
+        ports:
+        - containerPort: 9999
+          name: test
+        resources: {}`,
+		},
+		{
+			description: "undeclared kind",
+			in: `apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  template:
+    spec:
+      containers:
+      - image: gcr.io/k8s-debug/debug-example:latest
+        name: example`,
+			out: `apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  template:
+    spec:
+      containers:
+      - image: gcr.io/k8s-debug/debug-example:latest
+        name: example`,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.CheckNoError(manifest.SetCustomResources(test.filters))
+			defer manifest.SetCustomResources(nil)
+			retriever := func(image string) (imageConfiguration, error) {
+				return imageConfiguration{}, nil
+			}
+
+			l, err := manifest.Load(bytes.NewReader([]byte(test.in)))
+			t.CheckError(false, err)
+			result, err := applyDebuggingTransforms(l, retriever, "HELPERS")
+
+			t.CheckErrorAndDeepEqual(false, err, test.out, result.String())
+		})
+	}
+}
+
 func TestWorkingDir(t *testing.T) {
 	defer func(c []containerTransformer) { containerTransforms = c }(containerTransforms)
 	containerTransforms = append(containerTransforms, testTransformer{})
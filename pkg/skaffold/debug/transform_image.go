@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug/annotations"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+)
+
+// TransformImage applies the language-platform-specific transforms to a container running the given
+// artifact outside of Kubernetes, such as the containers created by the Docker deployer.
+// Returns a container definition holding the rewritten command, arguments, environment variables and
+// debugging ports, the debugging configuration description, and the ID of the debugging support image
+// that must populate `/dbg` in the container, if any.
+func TransformImage(ctx context.Context, artifact graph.Artifact, insecureRegistries map[string]bool) (*v1.Container, annotations.ContainerDebugConfiguration, string, error) {
+	imageConfig, err := retrieveImageConfiguration(ctx, &artifact, insecureRegistries)
+	if err != nil {
+		return nil, annotations.ContainerDebugConfiguration{}, "", err
+	}
+	return transformImage(artifact, imageConfig)
+}
+
+func transformImage(artifact graph.Artifact, imageConfig imageConfiguration) (*v1.Container, annotations.ContainerDebugConfiguration, string, error) {
+	container := &v1.Container{Name: artifact.ImageName, Image: artifact.Tag}
+	portAlloc := func(desiredPort int32) int32 {
+		return allocatePort(&v1.PodSpec{Containers: []v1.Container{*container}}, desiredPort)
+	}
+
+	configuration, requiredImage, err := transformContainer(container, imageConfig, portAlloc)
+	if err != nil {
+		return nil, annotations.ContainerDebugConfiguration{}, "", err
+	}
+	configuration.Artifact = imageConfig.artifact
+	if configuration.WorkingDir == "" {
+		configuration.WorkingDir = imageConfig.workingDir
+	}
+	return container, configuration, requiredImage, nil
+}
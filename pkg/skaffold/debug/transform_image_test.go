@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug/annotations"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestTransformImage(t *testing.T) {
+	tests := []struct {
+		description   string
+		configuration imageConfiguration
+		shouldErr     bool
+		result        *v1.Container
+		debugConfig   annotations.ContainerDebugConfiguration
+		image         string
+	}{
+		{
+			description:   "not debuggable",
+			configuration: imageConfiguration{artifact: "image", entrypoint: []string{"app"}},
+			shouldErr:     true,
+		},
+		{
+			description:   "jvm",
+			configuration: imageConfiguration{artifact: "image", env: map[string]string{"JAVA_VERSION": "8"}, workingDir: "/app"},
+			result: &v1.Container{
+				Name:  "image",
+				Image: "image:tag",
+				Env:   []v1.EnvVar{{Name: "JAVA_TOOL_OPTIONS", Value: "-agentlib:jdwp=transport=dt_socket,server=y,address=5005,suspend=n,quiet=y"}},
+				Ports: []v1.ContainerPort{{Name: "jdwp", ContainerPort: 5005}},
+			},
+			debugConfig: annotations.ContainerDebugConfiguration{Artifact: "image", Runtime: "jvm", WorkingDir: "/app", Ports: map[string]uint32{"jdwp": 5005}},
+		},
+		{
+			description:   "go",
+			configuration: imageConfiguration{artifact: "image", env: map[string]string{"GOTRACEBACK": "all"}, entrypoint: []string{"app", "arg"}},
+			result: &v1.Container{
+				Name:    "image",
+				Image:   "image:tag",
+				Command: []string{"/dbg/go/bin/dlv", "exec", "--headless", "--continue", "--accept-multiclient", "--listen=:56268", "--api-version=2", "app", "--", "arg"},
+				Ports:   []v1.ContainerPort{{Name: "dlv", ContainerPort: 56268}},
+			},
+			debugConfig: annotations.ContainerDebugConfiguration{Artifact: "image", Runtime: "go", Ports: map[string]uint32{"dlv": 56268}},
+			image:       "go",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			container, debugConfig, image, err := transformImage(graph.Artifact{ImageName: "image", Tag: "image:tag"}, test.configuration)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.result, container)
+			t.CheckDeepEqual(test.debugConfig, debugConfig)
+			t.CheckDeepEqual(test.image, image)
+		})
+	}
+}
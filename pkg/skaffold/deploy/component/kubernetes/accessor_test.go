@@ -39,6 +39,8 @@ func (m mockAccessConfig) Mode() config.RunMode { return "" }
 
 func (m mockAccessConfig) PortForwardOptions() config.PortForwardOptions { return m.opts }
 
+func (m mockAccessConfig) PortForwarder() string { return "" }
+
 func (m mockAccessConfig) PortForwardResources() []*v1.PortForwardResource { return nil }
 
 func TestGetAccessor(t *testing.T) {
@@ -19,6 +19,8 @@ package kubernetes
 import (
 	gosync "sync"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/access"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug"
@@ -62,7 +64,7 @@ func newAccessor(cfg portforward.Config, kubeContext string, cli *kubectl.CLI, p
 		if !cfg.PortForwardOptions().Enabled() {
 			k8sAccessor[kubeContext] = &access.NoopAccessor{}
 		}
-		m := portforward.NewForwarderManager(cli, podSelector, labeller.RunIDSelector(), cfg.Mode(), namespaces, cfg.PortForwardOptions(), cfg.PortForwardResources())
+		m := portforward.NewForwarderManager(cli, podSelector, labeller.RunIDSelector(), cfg.Mode(), namespaces, cfg.PortForwardOptions(), cfg.PortForwarder(), cfg.PortForwardResources())
 		if m == nil {
 			k8sAccessor[kubeContext] = &access.NoopAccessor{}
 		} else {
@@ -83,7 +85,7 @@ func newDebugger(mode config.RunMode, podSelector kubernetes.PodSelector, namesp
 
 func newImageLoader(cfg k8sloader.Config, cli *kubectl.CLI) loader.ImageLoader {
 	if cfg.LoadImages() {
-		return k8sloader.NewImageLoader(cfg.GetKubeContext(), cli)
+		return k8sloader.NewImageLoader(cfg, cli)
 	}
 	return &loader.NoopImageLoader{}
 }
@@ -109,6 +111,13 @@ func newMonitor(cfg k8sstatus.Config, kubeContext string, labeller *label.Defaul
 	return k8sMonitor[kubeContext]
 }
 
-func newSyncer(cli *kubectl.CLI, namespaces *[]string) sync.Syncer {
-	return sync.NewPodSyncer(cli, namespaces)
+func newSyncer(cfg sync.Config, cli *kubectl.CLI, namespaces *[]string) sync.Syncer {
+	switch cfg.FileSyncer() {
+	case sync.NativeSyncerType:
+		return sync.NewNativePodSyncer(cli, namespaces, cfg.GetRunID())
+	case sync.KubectlSyncerType, "":
+	default:
+		logrus.Warnf("unknown file syncer %q, using %q instead", cfg.FileSyncer(), sync.KubectlSyncerType)
+	}
+	return sync.NewPodSyncer(cli, namespaces, cfg.GetRunID())
 }
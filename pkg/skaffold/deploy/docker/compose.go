@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	tagutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/tag/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
+)
+
+const (
+	defaultComposeFile    = "docker-compose.yml"
+	alternateComposeFile  = "docker-compose.yaml"
+	composeOverrideFile   = "skaffold-override.yml"
+	defaultComposeNetwork = "default"
+)
+
+// composeFile holds the parts of a docker-compose file that Skaffold needs to inspect.
+type composeFile struct {
+	Version  string                    `yaml:"version,omitempty"`
+	Services map[string]composeService `yaml:"services,omitempty"`
+	Networks map[string]interface{}    `yaml:"networks,omitempty"`
+}
+
+type composeService struct {
+	Image string `yaml:"image,omitempty"`
+}
+
+// composeOverride is merged by docker-compose on top of the user's compose file
+// to substitute the images built by Skaffold and attach the stack to the skaffold network.
+type composeOverride struct {
+	Version  string                            `yaml:"version,omitempty"`
+	Services map[string]composeService         `yaml:"services,omitempty"`
+	Networks map[string]composeExternalNetwork `yaml:"networks,omitempty"`
+}
+
+type composeExternalNetwork struct {
+	External composeNetworkName `yaml:"external"`
+}
+
+type composeNetworkName struct {
+	Name string `yaml:"name"`
+}
+
+// deployWithCompose brings up the compose stack with the built images. docker-compose only
+// recreates the services whose configuration changed, i.e. the services whose image was rebuilt.
+func (d *Deployer) deployWithCompose(ctx context.Context, out io.Writer, builds []graph.Artifact) error {
+	path := d.composeFilePath()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading compose file %q: %w", path, err)
+	}
+	var cf composeFile
+	if err := yaml.Unmarshal(b, &cf); err != nil {
+		return fmt.Errorf("parsing compose file %q: %w", path, err)
+	}
+
+	if d.debugManager != nil {
+		logrus.Warnf("debugging is not supported for services deployed with docker-compose")
+	}
+	override, services := overrideForBuilds(cf, builds, d.cfg.Images, d.network)
+	overrideBytes, err := yaml.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("generating compose override: %w", err)
+	}
+	if d.composeDir == "" {
+		if d.composeDir, err = ioutil.TempDir("", "skaffold-compose"); err != nil {
+			return fmt.Errorf("creating compose override directory: %w", err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(d.composeDir, composeOverrideFile), overrideBytes, 0644); err != nil {
+		return fmt.Errorf("writing compose override: %w", err)
+	}
+
+	if err := d.compose(ctx, out, "up", "--detach", "--remove-orphans"); err != nil {
+		return fmt.Errorf("running docker-compose up: %w", err)
+	}
+
+	for _, service := range sortedKeys(services) {
+		artifact := services[service]
+		var buf bytes.Buffer
+		if err := d.compose(ctx, &buf, "ps", "-q", service); err != nil {
+			return fmt.Errorf("retrieving container for service %s: %w", service, err)
+		}
+		ids := strings.Fields(buf.String())
+		if len(ids) == 0 {
+			logrus.Warnf("no container found for compose service %s", service)
+			continue
+		}
+		// unchanged services keep their container, so they're already tracked.
+		if c, found := d.tracker.DeployedContainerForImage(artifact.ImageName); found && c.ID == ids[0] {
+			continue
+		}
+		d.tracker.Add(artifact, tracker.Container{Name: service, ID: ids[0]})
+	}
+
+	return nil
+}
+
+// cleanupCompose tears down the compose stack.
+func (d *Deployer) cleanupCompose(ctx context.Context, out io.Writer) error {
+	if d.composeDir == "" {
+		// nothing was deployed
+		return nil
+	}
+	defer os.RemoveAll(d.composeDir)
+
+	if err := d.compose(ctx, out, "down", "--remove-orphans"); err != nil {
+		return fmt.Errorf("running docker-compose down: %w", err)
+	}
+	for image := range d.tracker.DeployedContainers() {
+		d.tracker.Remove(image)
+	}
+	return nil
+}
+
+func (d *Deployer) compose(ctx context.Context, out io.Writer, args ...string) error {
+	path := d.composeFilePath()
+	args = append([]string{
+		"--project-name", d.project,
+		"--project-directory", filepath.Dir(path),
+		"--file", path,
+		"--file", filepath.Join(d.composeDir, composeOverrideFile),
+	}, args...)
+
+	cmd := exec.CommandContext(ctx, "docker-compose", args...)
+	cmd.Env = append(os.Environ(), d.client.ExtraEnv()...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return util.RunCmd(cmd)
+}
+
+func (d *Deployer) composeFilePath() string {
+	if d.cfg.ComposeFile != "" {
+		return d.cfg.ComposeFile
+	}
+	if _, err := os.Stat(defaultComposeFile); os.IsNotExist(err) {
+		if _, err := os.Stat(alternateComposeFile); err == nil {
+			return alternateComposeFile
+		}
+	}
+	return defaultComposeFile
+}
+
+// overrideForBuilds creates the compose override that substitutes the built tags in the services
+// using one of the deployed images, and returns the artifact deployed by each of these services.
+func overrideForBuilds(cf composeFile, builds []graph.Artifact, images []string, network string) (composeOverride, map[string]graph.Artifact) {
+	override := composeOverride{
+		Version:  cf.Version,
+		Services: map[string]composeService{},
+	}
+	services := map[string]graph.Artifact{}
+	for name, s := range cf.Services {
+		if s.Image == "" {
+			continue
+		}
+		image := tagutil.StripTag(s.Image, false)
+		for _, b := range builds {
+			if b.ImageName != image || !util.StrSliceContains(images, b.ImageName) {
+				continue
+			}
+			override.Services[name] = composeService{Image: b.Tag}
+			services[name] = b
+		}
+	}
+	if _, found := cf.Networks[defaultComposeNetwork]; !found {
+		override.Networks = map[string]composeExternalNetwork{
+			defaultComposeNetwork: {External: composeNetworkName{Name: network}},
+		}
+	}
+	return override, services
+}
+
+func sortedKeys(m map[string]graph.Artifact) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
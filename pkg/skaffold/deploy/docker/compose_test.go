@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestOverrideForBuilds(t *testing.T) {
+	tests := []struct {
+		description      string
+		compose          string
+		images           []string
+		expectedOverride string
+		expectedServices map[string]graph.Artifact
+	}{
+		{
+			description: "substitute built images",
+			compose: `version: "3.8"
+services:
+  web:
+    image: web:latest
+    ports: ["8080:8080"]
+  db:
+    image: postgres:13
+  worker:
+    build: ./worker
+`,
+			images: []string{"web"},
+			expectedOverride: `version: "3.8"
+services:
+  web:
+    image: web:abcdef
+networks:
+  default:
+    external:
+      name: skaffold-network
+`,
+			expectedServices: map[string]graph.Artifact{"web": {ImageName: "web", Tag: "web:abcdef"}},
+		},
+		{
+			description: "images not declared in the deployer are ignored",
+			compose: `services:
+  web:
+    image: web
+`,
+			expectedOverride: `networks:
+  default:
+    external:
+      name: skaffold-network
+`,
+			expectedServices: map[string]graph.Artifact{},
+		},
+		{
+			description: "user defined default network is kept",
+			compose: `services:
+  web:
+    image: web
+networks:
+  default:
+    driver: bridge
+`,
+			images: []string{"web"},
+			expectedOverride: `services:
+  web:
+    image: web:abcdef
+`,
+			expectedServices: map[string]graph.Artifact{"web": {ImageName: "web", Tag: "web:abcdef"}},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			var cf composeFile
+			t.CheckNoError(yaml.Unmarshal([]byte(test.compose), &cf))
+			builds := []graph.Artifact{{ImageName: "web", Tag: "web:abcdef"}, {ImageName: "other", Tag: "other:abcdef"}}
+
+			override, services := overrideForBuilds(cf, builds, test.images, "skaffold-network")
+			b, err := yaml.Marshal(override)
+
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expectedOverride, string(b))
+			t.CheckDeepEqual(test.expectedServices, services)
+		})
+	}
+}
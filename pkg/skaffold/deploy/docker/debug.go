@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug/annotations"
+	dockerutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+)
+
+// setupDebugging rewrites the options used to create the container for the given artifact to enable
+// debugging, and mounts the debugging support files at `/dbg` when the language runtime requires them.
+// Returns nil if the container is not debugged.
+func (d *Deployer) setupDebugging(ctx context.Context, out io.Writer, artifact graph.Artifact, opts *dockerutil.ContainerCreateOpts) (*annotations.ContainerDebugConfiguration, error) {
+	if d.debugManager == nil {
+		return nil, nil
+	}
+
+	debugOpts := *opts
+	configuration, supportImageID, err := d.debugManager.TransformImage(ctx, artifact, &debugOpts)
+	if err != nil {
+		logrus.Warnf("Image %q not configured for debugging: %v", artifact.ImageName, err)
+		return nil, nil
+	}
+	if supportImageID != "" {
+		volume, err := d.debugSupportVolume(ctx, out, supportImageID)
+		if err != nil {
+			return nil, err
+		}
+		debugOpts.Mounts = append(debugOpts.Mounts, mount.Mount{Type: mount.TypeVolume, Source: volume, Target: "/dbg"})
+	}
+	*opts = debugOpts
+	return &configuration, nil
+}
+
+// debugSupportVolume returns the name of a volume populated with the files of the given debugging support image.
+// The support image is run once per deployer, and the volume is shared by all the containers requiring it.
+func (d *Deployer) debugSupportVolume(ctx context.Context, out io.Writer, supportImageID string) (string, error) {
+	d.supportLock.Lock()
+	defer d.supportLock.Unlock()
+
+	if volume, found := d.supportVolumes[supportImageID]; found {
+		return volume, nil
+	}
+
+	image := d.debugManager.SupportImage(supportImageID)
+	volume := fmt.Sprintf("%s-debug-%s", d.project, supportImageID)
+	logrus.Infof("Installing debugging support files from %q", image)
+	if !d.client.ImageExists(ctx, image) {
+		if err := d.client.Pull(ctx, out, image); err != nil {
+			return "", fmt.Errorf("pulling debugging support image %q: %w", image, err)
+		}
+	}
+	id, err := d.client.Run(ctx, out, dockerutil.ContainerCreateOpts{
+		Name:   volume,
+		Image:  image,
+		Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: volume, Target: "/dbg"}},
+		Wait:   true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("installing debugging support files from %q: %w", image, err)
+	}
+	// the files remain in the volume once the support container has completed
+	if err := d.client.Delete(ctx, out, id); err != nil {
+		logrus.Warnf("unable to remove debugging support container %s: %v", id, err)
+	}
+	d.supportVolumes[supportImageID] = volume
+	return volume, nil
+}
+
+// cleanupDebugSupport removes the volumes holding the debugging support files.
+func (d *Deployer) cleanupDebugSupport(ctx context.Context) error {
+	d.supportLock.Lock()
+	defer d.supportLock.Unlock()
+
+	for id, volume := range d.supportVolumes {
+		if err := d.client.VolumeRemove(ctx, volume); err != nil {
+			return fmt.Errorf("removing debugging support volume %s: %w", volume, err)
+		}
+		delete(d.supportVolumes, id)
+	}
+	return nil
+}
@@ -27,9 +27,15 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/access"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/label"
 	dockerutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	dockerdebugger "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/debugger"
+	dockerlogger "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/logger"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/portforward"
+	dockerstatus "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/status"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/log"
 	v1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
@@ -45,35 +51,72 @@ type Deployer struct {
 	monitor  status.Monitor
 	syncer   pkgsync.Syncer
 
-	cfg                *v1.DockerDeploy
-	client             dockerutil.LocalDaemon
-	deployedContainers map[string]string // imageName -> containerID
-	network            string
-	once               sync.Once
+	cfg         *v1.DockerDeploy
+	client      dockerutil.LocalDaemon
+	tracker     *tracker.ContainerTracker
+	portManager *portforward.PortManager
+	network     string
+	project     string
+	composeDir  string
+	once        sync.Once
+
+	debugManager   *dockerdebugger.DebugManager // nil unless running in debug mode
+	supportLock    sync.Mutex
+	supportVolumes map[string]string // debugging support image ID -> volume holding its files
 }
 
-func NewDeployer(cfg dockerutil.Config, labeller *label.DefaultLabeller, d *v1.DockerDeploy, resources []*v1.PortForwardResource) (*Deployer, error) {
+type Config interface {
+	dockerutil.Config
+	dockerlogger.Config
+	portforward.Config
+	dockerstatus.Config
+	StatusCheck() *bool
+	GetInsecureRegistries() map[string]bool
+	GlobalConfig() string
+}
+
+func NewDeployer(cfg Config, labeller *label.DefaultLabeller, d *v1.DockerDeploy, resources []*v1.PortForwardResource) (*Deployer, error) {
 	client, err := dockerutil.NewAPIClient(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	containerTracker := tracker.NewContainerTracker()
+	portManager := portforward.NewPortManager(cfg, resources)
+	id := uuid.New().String()
+	var monitor status.Monitor = &status.NoopMonitor{}
+	if enabled := cfg.StatusCheck(); enabled == nil || *enabled { // assume disabled only if explicitly set to false
+		monitor = dockerstatus.NewStatusMonitor(cfg, client, containerTracker)
+	}
+	var debugger debug.Debugger = &debug.NoopDebugger{}
+	var debugManager *dockerdebugger.DebugManager
+	if cfg.Mode() == config.RunModes.Debug {
+		debugHelpersRegistry, err := config.GetDebugHelpersRegistry(cfg.GlobalConfig())
+		if err != nil {
+			return nil, fmt.Errorf("retrieving debug helpers registry: %w", err)
+		}
+		debugManager = dockerdebugger.NewDebugManager(cfg.GetInsecureRegistries(), debugHelpersRegistry)
+		debugger = debugManager
+	}
 	return &Deployer{
-		cfg:                d,
-		client:             client,
-		deployedContainers: make(map[string]string),
-		network:            fmt.Sprintf("skaffold-network-%s", uuid.New().String()),
-		// TODO(nkubala): implement components
-		accessor: &access.NoopAccessor{},
-		debugger: &debug.NoopDebugger{},
-		logger:   &log.NoopLogger{},
-		monitor:  &status.NoopMonitor{},
-		syncer:   &pkgsync.NoopSyncer{},
+		cfg:            d,
+		client:         client,
+		tracker:        containerTracker,
+		portManager:    portManager,
+		network:        fmt.Sprintf("skaffold-network-%s", id),
+		project:        fmt.Sprintf("skaffold-%s", id),
+		debugManager:   debugManager,
+		supportVolumes: make(map[string]string),
+		accessor:       portManager,
+		debugger:       debugger,
+		logger:         dockerlogger.NewLogger(client, containerTracker, cfg),
+		monitor:        monitor,
+		syncer:         pkgsync.NewContainerSyncer(client, containerTracker),
 	}, nil
 }
 
 func (d *Deployer) TrackBuildArtifacts(artifacts []graph.Artifact) {
-	// TODO(nkubala): implement with components
+	d.logger.RegisterArtifacts(artifacts)
 }
 
 func (d *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Artifact) error {
@@ -84,47 +127,87 @@ func (d *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	if err != nil {
 		return fmt.Errorf("creating skaffold network %s: %w", d.network, err)
 	}
+	d.TrackBuildArtifacts(builds)
+	if d.cfg.UseCompose {
+		return d.deployWithCompose(ctx, out, builds)
+	}
 	for _, b := range builds {
 		// TODO(nkubala): parallelize this
 		if !util.StrSliceContains(d.cfg.Images, b.ImageName) {
 			continue
 		}
-		if containerID, found := d.deployedContainers[b.ImageName]; found {
-			logrus.Debugf("removing old container %s for image %s", containerID, b.ImageName)
-			if err := d.client.Delete(ctx, out, containerID); err != nil {
-				return fmt.Errorf("failed to remove old container %s for image %s: %w", containerID, b.ImageName, err)
+		if container, found := d.tracker.DeployedContainerForImage(b.ImageName); found {
+			logrus.Debugf("removing old container %s for image %s", container.ID, b.ImageName)
+			if err := d.client.Delete(ctx, out, container.ID); err != nil {
+				return fmt.Errorf("failed to remove old container %s for image %s: %w", container.ID, b.ImageName, err)
+			}
+			d.tracker.Remove(b.ImageName)
+			d.portManager.RelinquishPorts(b.ImageName)
+			if d.debugManager != nil {
+				d.debugManager.RemoveContainer(b.ImageName)
 			}
-		}
-		if d.cfg.UseCompose {
-			// TODO(nkubala): implement
-			return fmt.Errorf("docker compose not yet supported by skaffold")
 		}
 		opts := dockerutil.ContainerCreateOpts{
 			Name:    b.ImageName,
 			Image:   b.Tag,
 			Network: d.network,
 		}
+		debugConfig, err := d.setupDebugging(ctx, out, b, &opts)
+		if err != nil {
+			return fmt.Errorf("setting up debugging for image %s: %w", b.ImageName, err)
+		}
+		var debugPorts map[string]uint32
+		if debugConfig != nil {
+			debugPorts = debugConfig.Ports
+		}
+		var exposedPorts map[string]struct{}
+		if imageCfg, err := d.client.ConfigFile(ctx, b.Tag); err != nil {
+			logrus.Warnf("unable to retrieve image config for %s, only user-defined ports will be published: %v", b.Tag, err)
+		} else {
+			exposedPorts = imageCfg.Config.ExposedPorts
+		}
+		opts.Ports, opts.Bindings = d.portManager.AllocatePorts(b.ImageName, exposedPorts, debugPorts)
 		id, err := d.client.Run(ctx, out, opts)
 		if err != nil {
+			d.portManager.RelinquishPorts(b.ImageName)
 			return errors.Wrap(err, "creating container in local docker")
 		}
-		d.deployedContainers[b.ImageName] = id
+		d.tracker.Add(b, tracker.Container{Name: b.ImageName, ID: id})
+		if debugConfig != nil {
+			d.debugManager.AddContainer(b.ImageName, *debugConfig)
+		}
 	}
 
 	return nil
 }
 
 func (d *Deployer) Dependencies() ([]string, error) {
+	if d.cfg.UseCompose {
+		return []string{d.composeFilePath()}, nil
+	}
 	// noop since there is no deploy config
 	return nil, nil
 }
 
 func (d *Deployer) Cleanup(ctx context.Context, out io.Writer) error {
-	for _, id := range d.deployedContainers {
-		if err := d.client.Delete(ctx, out, id); err != nil {
+	if d.cfg.UseCompose {
+		if err := d.cleanupCompose(ctx, out); err != nil {
+			return err
+		}
+	}
+	for image, container := range d.tracker.DeployedContainers() {
+		if err := d.client.Delete(ctx, out, container.ID); err != nil {
 			// TODO(nkubala): replace with actionable error
 			return errors.Wrap(err, "cleaning up deployed container")
 		}
+		d.tracker.Remove(image)
+		d.portManager.RelinquishPorts(image)
+		if d.debugManager != nil {
+			d.debugManager.RemoveContainer(image)
+		}
+	}
+	if err := d.cleanupDebugSupport(ctx); err != nil {
+		return err
 	}
 
 	err := d.client.NetworkRemove(ctx, d.network)
@@ -25,7 +25,6 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/sirupsen/logrus"
 
-	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
@@ -33,16 +32,17 @@ import (
 
 // installOpts are options to be passed to "helm install"
 type installOpts struct {
-	flags        []string
-	releaseName  string
-	namespace    string
-	chartPath    string
-	upgrade      bool
-	force        bool
-	helmVersion  semver.Version
-	postRenderer string
-	repo         string
-	version      string
+	flags         []string
+	releaseName   string
+	namespace     string
+	chartPath     string
+	upgrade       bool
+	force         bool
+	helmVersion   semver.Version
+	postRenderer  string
+	repo          string
+	version       string
+	overridesFile string
 }
 
 // constructOverrideArgs creates the command line arguments for overrides
@@ -196,7 +196,7 @@ func (h *Deployer) installArgs(r latestV1.HelmRelease, builds []graph.Artifact,
 	}
 
 	if len(r.Overrides.Values) != 0 {
-		args = append(args, "-f", constants.HelmOverridesFilename)
+		args = append(args, "-f", o.overridesFile)
 	}
 
 	if r.Wait {
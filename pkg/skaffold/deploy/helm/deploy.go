@@ -28,12 +28,14 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	gosync "sync"
 	"time"
 
 	"github.com/blang/semver"
 	backoff "github.com/cenkalti/backoff/v4"
 	shell "github.com/kballard/go-shellquote"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/access"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
@@ -46,9 +48,11 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/types"
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/hooks"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
 	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	kloader "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/loader"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/portforward"
@@ -93,6 +97,7 @@ type Deployer struct {
 	logger        log.Logger
 	statusMonitor status.Monitor
 	syncer        sync.Syncer
+	hookRunner    hooks.Runner
 
 	podSelector    *kubernetes.ImageList
 	originalImages []graph.Artifact // the set of images defined in ArtifactOverrides
@@ -105,14 +110,24 @@ type Deployer struct {
 
 	namespaces *[]string
 
+	// order is the topological order of the releases, when releases have dependencies
+	order []int
+	// readyMutex serializes the status checks of releases that other releases depend on
+	readyMutex gosync.Mutex
+
 	// packaging temporary directory, used for predictable test output
 	pkgTmpDir string
 
 	labels map[string]string
 
-	forceDeploy   bool
-	enableDebug   bool
-	isMultiConfig bool
+	// deployedReleases are the releases of the last deployment, recorded when rollback is enabled
+	deployedReleases []deployedRelease
+	releasesMutex    gosync.Mutex
+
+	forceDeploy       bool
+	rollbackOnFailure bool
+	enableDebug       bool
+	isMultiConfig     bool
 	// bV is the helm binary version
 	bV semver.Version
 }
@@ -122,6 +137,7 @@ type Config interface {
 	kstatus.Config
 	kloader.Config
 	portforward.Config
+	sync.Config
 	IsMultiConfig() bool
 }
 
@@ -136,6 +152,13 @@ func NewDeployer(cfg Config, labeller *label.DefaultLabeller, h *latestV1.HelmDe
 		return nil, minVersionErr()
 	}
 
+	var order []int
+	if hasDependencies(h.Releases) {
+		if order, err = releaseOrder(h.Releases); err != nil {
+			return nil, userErr("ordering releases", err)
+		}
+	}
+
 	originalImages := []graph.Artifact{}
 	for _, release := range h.Releases {
 		for _, v := range release.ArtifactOverrides {
@@ -150,25 +173,28 @@ func NewDeployer(cfg Config, labeller *label.DefaultLabeller, h *latestV1.HelmDe
 	namespaces := []string{}
 
 	return &Deployer{
-		HelmDeploy:     h,
-		podSelector:    podSelector,
-		namespaces:     &namespaces,
-		accessor:       component.NewAccessor(cfg, cfg.GetKubeContext(), kubectl, podSelector, labeller, &namespaces),
-		debugger:       component.NewDebugger(cfg.Mode(), podSelector, &namespaces),
-		imageLoader:    component.NewImageLoader(cfg, kubectl),
-		logger:         component.NewLogger(cfg, kubectl, podSelector, &namespaces),
-		statusMonitor:  component.NewMonitor(cfg, cfg.GetKubeContext(), labeller, &namespaces),
-		syncer:         component.NewSyncer(kubectl, &namespaces),
-		originalImages: originalImages,
-		kubeContext:    cfg.GetKubeContext(),
-		kubeConfig:     cfg.GetKubeConfig(),
-		namespace:      cfg.GetKubeNamespace(),
-		forceDeploy:    cfg.ForceDeploy(),
-		configFile:     cfg.ConfigurationFile(),
-		labels:         labeller.Labels(),
-		bV:             hv,
-		enableDebug:    cfg.Mode() == config.RunModes.Debug,
-		isMultiConfig:  cfg.IsMultiConfig(),
+		HelmDeploy:        h,
+		podSelector:       podSelector,
+		namespaces:        &namespaces,
+		accessor:          component.NewAccessor(cfg, cfg.GetKubeContext(), kubectl, podSelector, labeller, &namespaces),
+		debugger:          component.NewDebugger(cfg.Mode(), podSelector, &namespaces),
+		imageLoader:       component.NewImageLoader(cfg, kubectl),
+		logger:            component.NewLogger(cfg, kubectl, podSelector, &namespaces),
+		statusMonitor:     component.NewMonitor(cfg, cfg.GetKubeContext(), labeller, &namespaces),
+		syncer:            component.NewSyncer(cfg, kubectl, &namespaces),
+		hookRunner:        hooks.DeployRunner(kubectl, h.LifecycleHooks, &namespaces, hooks.NewDeployEnvOpts(labeller.GetRunID(), kubectl.KubeContext, namespaces)),
+		originalImages:    originalImages,
+		order:             order,
+		kubeContext:       cfg.GetKubeContext(),
+		kubeConfig:        cfg.GetKubeConfig(),
+		namespace:         cfg.GetKubeNamespace(),
+		forceDeploy:       cfg.ForceDeploy(),
+		rollbackOnFailure: cfg.RollbackOnFailure(),
+		configFile:        cfg.ConfigurationFile(),
+		labels:            labeller.Labels(),
+		bV:                hv,
+		enableDebug:       cfg.Mode() == config.RunModes.Debug,
+		isMultiConfig:     cfg.IsMultiConfig(),
 	}, nil
 }
 
@@ -219,6 +245,10 @@ func (h *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 		return fmt.Errorf("unable to connect to Kubernetes: %w", err)
 	}
 
+	if err := h.hookRunner.RunPreHooks(ctx, out); err != nil {
+		return fmt.Errorf("running pre-deploy hooks: %w", err)
+	}
+
 	childCtx, endTrace := instrumentation.StartTrace(ctx, "Deploy_LoadImages")
 	if err := h.imageLoader.LoadImages(childCtx, out, h.localImages, h.originalImages, builds); err != nil {
 		endTrace(instrumentation.TraceEndError(err))
@@ -227,26 +257,15 @@ func (h *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	endTrace()
 
 	logrus.Infof("Deploying with helm v%s ...", h.bV)
+	h.releasesMutex.Lock()
+	h.deployedReleases = nil
+	h.releasesMutex.Unlock()
 
 	var dRes []types.Artifact
 	nsMap := map[string]struct{}{}
 	valuesSet := map[string]bool{}
 
-	// Deploy every release
-	for _, r := range h.Releases {
-		releaseName, err := util.ExpandEnvTemplateOrFail(r.Name, nil)
-		if err != nil {
-			return userErr(fmt.Sprintf("cannot expand release name %q", r.Name), err)
-		}
-		chartVersion, err := util.ExpandEnvTemplateOrFail(r.Version, nil)
-		if err != nil {
-			return userErr(fmt.Sprintf("cannot expand chart version %q", r.Version), err)
-		}
-		results, err := h.deployRelease(ctx, out, releaseName, r, builds, valuesSet, h.bV, chartVersion)
-		if err != nil {
-			return userErr(fmt.Sprintf("deploying %q", releaseName), err)
-		}
-
+	collect := func(results []types.Artifact) {
 		// collect namespaces
 		for _, r := range results {
 			if trimmed := strings.TrimSpace(r.Namespace); trimmed != "" {
@@ -257,6 +276,21 @@ func (h *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 		dRes = append(dRes, results...)
 	}
 
+	if h.order != nil {
+		if err := h.deployReleaseGraph(ctx, out, builds, valuesSet, collect); err != nil {
+			return err
+		}
+	} else {
+		// Deploy every release
+		for _, r := range h.Releases {
+			results, err := h.deployNamedRelease(ctx, out, r, builds, valuesSet, constants.HelmOverridesFilename)
+			if err != nil {
+				return err
+			}
+			collect(results)
+		}
+	}
+
 	// Let's make sure that every image tag is set with `--set`.
 	// Otherwise, templates have no way to use the images that were built.
 	// Skip warning for multi-config projects as there can be artifacts without any usage in the current deployer.
@@ -276,6 +310,10 @@ func (h *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 
 	h.TrackBuildArtifacts(builds)
 	h.trackNamespaces(namespaces)
+
+	if err := h.hookRunner.RunPostHooks(ctx, out); err != nil {
+		return fmt.Errorf("running post-deploy hooks: %w", err)
+	}
 	return nil
 }
 
@@ -344,7 +382,16 @@ func (h *Deployer) Cleanup(ctx context.Context, out io.Writer) error {
 		"DeployerType": "helm",
 	})
 
-	for _, r := range h.Releases {
+	releases := h.Releases
+	if h.order != nil {
+		// dependents are deleted before their dependencies
+		releases = make([]latestV1.HelmRelease, len(h.order))
+		for i, j := range h.order {
+			releases[len(h.order)-1-i] = h.Releases[j]
+		}
+	}
+
+	for _, r := range releases {
 		releaseName, err := util.ExpandEnvTemplateOrFail(r.Name, nil)
 		if err != nil {
 			return fmt.Errorf("cannot parse the release name template: %w", err)
@@ -373,75 +420,254 @@ func (h *Deployer) Render(ctx context.Context, out io.Writer, builds []graph.Art
 	})
 	renderedManifests := new(bytes.Buffer)
 
-	for _, r := range h.Releases {
-		releaseName, err := util.ExpandEnvTemplateOrFail(r.Name, nil)
-		if err != nil {
-			return userErr(fmt.Sprintf("cannot expand release name %q", r.Name), err)
+	releases := h.Releases
+	if h.order != nil {
+		// dependencies are rendered before their dependents
+		releases = make([]latestV1.HelmRelease, len(h.order))
+		for i, j := range h.order {
+			releases[i] = h.Releases[j]
 		}
+	}
 
-		args := []string{"template", releaseName, chartSource(r)}
-		if r.Packaged == nil && r.Version != "" {
-			args = append(args, "--version", r.Version)
+	for _, r := range releases {
+		_, _, rendered, err := h.renderRelease(ctx, r, builds)
+		if err != nil {
+			return err
 		}
+		renderedManifests.Write(rendered)
+	}
+
+	return manifest.Write(renderedManifests.String(), filepath, out)
+}
+
+// renderRelease runs `helm template` for a release, and returns the expanded release name, its namespace and its manifests.
+func (h *Deployer) renderRelease(ctx context.Context, r latestV1.HelmRelease, builds []graph.Artifact) (string, string, []byte, error) {
+	releaseName, err := util.ExpandEnvTemplateOrFail(r.Name, nil)
+	if err != nil {
+		return "", "", nil, userErr(fmt.Sprintf("cannot expand release name %q", r.Name), err)
+	}
+
+	args := []string{"template", releaseName, chartSource(r)}
+	if r.Packaged == nil && r.Version != "" {
+		args = append(args, "--version", r.Version)
+	}
+
+	params, err := pairParamsToArtifacts(builds, r.ArtifactOverrides)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	for k, v := range params {
+		var value string
 
-		params, err := pairParamsToArtifacts(builds, r.ArtifactOverrides)
+		cfg := r.ImageStrategy.HelmImageConfig.HelmConventionConfig
+
+		value, err = imageSetFromConfig(cfg, k, v.Tag)
 		if err != nil {
-			return err
+			return "", "", nil, err
 		}
 
-		for k, v := range params {
-			var value string
+		args = append(args, "--set-string", value)
+	}
 
-			cfg := r.ImageStrategy.HelmImageConfig.HelmConventionConfig
+	args, err = constructOverrideArgs(&r, builds, args, func(string) {})
+	if err != nil {
+		return "", "", nil, userErr("construct override args", err)
+	}
 
-			value, err = imageSetFromConfig(cfg, k, v.Tag)
-			if err != nil {
-				return err
-			}
+	namespace, err := h.releaseNamespace(r)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+
+	if r.Repo != "" {
+		args = append(args, "--repo")
+		args = append(args, r.Repo)
+	}
+
+	outBuffer := new(bytes.Buffer)
+	if err := h.exec(ctx, outBuffer, false, nil, args...); err != nil {
+		return "", "", nil, userErr("std out err", fmt.Errorf(outBuffer.String()))
+	}
+	return releaseName, namespace, outBuffer.Bytes(), nil
+}
 
-			args = append(args, "--set-string", value)
+// Pruned returns the resources that deploying the build results deletes: `helm upgrade` deletes
+// the resources of the deployed release that the chart doesn't render anymore.
+func (h *Deployer) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	var pruned []diff.Resource
+	for _, r := range h.Releases {
+		releaseName, namespace, rendered, err := h.renderRelease(ctx, r, builds)
+		if err != nil {
+			return nil, err
+		}
+
+		var deployed bytes.Buffer
+		args := append(getArgs(releaseName, namespace), "--template", "{{.Release.Manifest}}")
+		if err := h.exec(ctx, &deployed, false, nil, args...); err != nil {
+			logrus.Debugf("release %s is not deployed: %v", releaseName, err)
+			continue
 		}
 
-		args, err = constructOverrideArgs(&r, builds, args, func(string) {})
+		renderedManifests, err := manifest.Load(bytes.NewReader(rendered))
 		if err != nil {
-			return userErr("construct override args", err)
+			return nil, err
+		}
+		deployedManifests, err := manifest.Load(&deployed)
+		if err != nil {
+			return nil, err
 		}
 
-		namespace, err := h.releaseNamespace(r)
+		resources, err := diff.ResourcesOf(deployedManifests.Removed(renderedManifests))
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if namespace != "" {
-			args = append(args, "--namespace", namespace)
+		for _, resource := range resources {
+			if resource.Namespace == "" {
+				resource.Namespace = namespace
+			}
+			pruned = append(pruned, resource)
 		}
+	}
+	return pruned, nil
+}
 
-		if r.Repo != "" {
-			args = append(args, "--repo")
-			args = append(args, r.Repo)
+// deployNamedRelease expands the name and the chart version of a release, and deploys it.
+func (h *Deployer) deployNamedRelease(ctx context.Context, out io.Writer, r latestV1.HelmRelease, builds []graph.Artifact, valuesSet map[string]bool, overridesFile string) ([]types.Artifact, error) {
+	releaseName, err := util.ExpandEnvTemplateOrFail(r.Name, nil)
+	if err != nil {
+		return nil, userErr(fmt.Sprintf("cannot expand release name %q", r.Name), err)
+	}
+	chartVersion, err := util.ExpandEnvTemplateOrFail(r.Version, nil)
+	if err != nil {
+		return nil, userErr(fmt.Sprintf("cannot expand chart version %q", r.Version), err)
+	}
+	results, err := h.deployRelease(ctx, out, releaseName, r, builds, valuesSet, h.bV, chartVersion, overridesFile)
+	if err != nil {
+		return nil, userErr(fmt.Sprintf("deploying %q", releaseName), err)
+	}
+	return results, nil
+}
+
+// deployReleaseGraph deploys the releases concurrently, following their dependencies.
+// A release is deployed once all its dependencies are deployed and ready.
+func (h *Deployer) deployReleaseGraph(ctx context.Context, out io.Writer, builds []graph.Artifact, valuesSet map[string]bool, collect func([]types.Artifact)) error {
+	deps, err := releaseDependencies(h.Releases)
+	if err != nil {
+		return userErr("ordering releases", err)
+	}
+	hasDependents := make([]bool, len(h.Releases))
+	for _, d := range deps {
+		for _, j := range d {
+			hasDependents[j] = true
 		}
+	}
+
+	done := make([]chan struct{}, len(h.Releases))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	// guards valuesSet and the collected results
+	var mutex gosync.Mutex
+	g, gCtx := errgroup.WithContext(ctx)
+	for i := range h.Releases {
+		i := i
+		g.Go(func() error {
+			for _, j := range deps[i] {
+				select {
+				case <-done[j]:
+				case <-gCtx.Done():
+					return gCtx.Err()
+				}
+			}
+
+			r := h.Releases[i]
+			overridesFile, cleanup, err := tempOverridesFile(r)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			releaseValues := map[string]bool{}
+			results, err := h.deployNamedRelease(gCtx, out, r, builds, releaseValues, overridesFile)
+			if err != nil {
+				return err
+			}
+
+			mutex.Lock()
+			for k, v := range releaseValues {
+				valuesSet[k] = v
+			}
+			collect(results)
+			mutex.Unlock()
+
+			if hasDependents[i] {
+				if err := h.waitForRelease(gCtx, out, r.Name, results); err != nil {
+					return err
+				}
+			}
+			close(done[i])
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// waitForRelease labels the resources of a release, and waits for them to be ready.
+func (h *Deployer) waitForRelease(ctx context.Context, out io.Writer, name string, results []types.Artifact) error {
+	if err := label.Apply(ctx, h.labels, results); err != nil {
+		return helmLabelErr(fmt.Errorf("adding labels: %w", err))
+	}
 
-		outBuffer := new(bytes.Buffer)
-		if err := h.exec(ctx, outBuffer, false, nil, args...); err != nil {
-			return userErr("std out err", fmt.Errorf(outBuffer.String()))
+	var namespaces []string
+	for _, r := range results {
+		if trimmed := strings.TrimSpace(r.Namespace); trimmed != "" {
+			namespaces = append(namespaces, trimmed)
 		}
-		renderedManifests.Write(outBuffer.Bytes())
 	}
 
-	return manifest.Write(renderedManifests.String(), filepath, out)
+	// The status monitor checks the resources it hasn't seen yet, and concurrent checks are
+	// merged into a single one, so they're serialized to check the resources of every release.
+	h.readyMutex.Lock()
+	defer h.readyMutex.Unlock()
+	h.trackNamespaces(namespaces)
+	output.Default.Fprintf(out, "Waiting for release %s to be ready before deploying its dependents...\n", name)
+	if err := h.statusMonitor.Check(ctx, out); err != nil {
+		return fmt.Errorf("waiting for release %q: %w", name, err)
+	}
+	return nil
+}
+
+// tempOverridesFile returns a unique path for the overrides of a release, since releases can be deployed concurrently.
+func tempOverridesFile(r latestV1.HelmRelease) (string, func(), error) {
+	if len(r.Overrides.Values) == 0 {
+		return "", func() {}, nil
+	}
+	f, err := ioutil.TempFile("", "skaffold-overrides-*.yaml")
+	if err != nil {
+		return "", nil, userErr("cannot create overrides file", err)
+	}
+	f.Close()
+	return f.Name(), func() { os.Remove(f.Name()) }, nil
 }
 
 // deployRelease deploys a single release
-func (h *Deployer) deployRelease(ctx context.Context, out io.Writer, releaseName string, r latestV1.HelmRelease, builds []graph.Artifact, valuesSet map[string]bool, helmVersion semver.Version, chartVersion string) ([]types.Artifact, error) {
+func (h *Deployer) deployRelease(ctx context.Context, out io.Writer, releaseName string, r latestV1.HelmRelease, builds []graph.Artifact, valuesSet map[string]bool, helmVersion semver.Version, chartVersion string, overridesFile string) ([]types.Artifact, error) {
 	var err error
 	opts := installOpts{
-		releaseName: releaseName,
-		upgrade:     true,
-		flags:       h.Flags.Upgrade,
-		force:       h.forceDeploy,
-		chartPath:   chartSource(r),
-		helmVersion: helmVersion,
-		repo:        r.Repo,
-		version:     chartVersion,
+		releaseName:   releaseName,
+		overridesFile: overridesFile,
+		upgrade:       true,
+		flags:         h.Flags.Upgrade,
+		force:         h.forceDeploy,
+		chartPath:     chartSource(r),
+		helmVersion:   helmVersion,
+		repo:          r.Repo,
+		version:       chartVersion,
 	}
 
 	var installEnv []string
@@ -510,12 +736,12 @@ func (h *Deployer) deployRelease(ctx context.Context, out io.Writer, releaseName
 			return nil, userErr("cannot marshal overrides to create overrides values.yaml", err)
 		}
 
-		if err := ioutil.WriteFile(constants.HelmOverridesFilename, overrides, 0666); err != nil {
-			return nil, userErr(fmt.Sprintf("cannot create file %q", constants.HelmOverridesFilename), err)
+		if err := ioutil.WriteFile(overridesFile, overrides, 0666); err != nil {
+			return nil, userErr(fmt.Sprintf("cannot create file %q", overridesFile), err)
 		}
 
 		defer func() {
-			os.Remove(constants.HelmOverridesFilename)
+			os.Remove(overridesFile)
 		}()
 	}
 
@@ -533,6 +759,12 @@ func (h *Deployer) deployRelease(ctx context.Context, out io.Writer, releaseName
 		return nil, userErr("release args", err)
 	}
 
+	if h.rollbackOnFailure {
+		if err := h.recordRelease(ctx, releaseName, opts.namespace, opts.upgrade); err != nil {
+			return nil, userErr("recording release", err)
+		}
+	}
+
 	err = h.exec(ctx, out, r.UseHelmSecrets, installEnv, args...)
 	if err != nil {
 		return nil, userErr("install", err)
@@ -543,6 +775,14 @@ func (h *Deployer) deployRelease(ctx context.Context, out io.Writer, releaseName
 		return nil, userErr("get release", err)
 	}
 
+	if manifests, err := manifest.Load(bytes.NewReader(b.Bytes())); err != nil {
+		logrus.Debugf("could not read release manifest: %v", err)
+	} else if kinds, err := manifests.CollectGroupKinds(); err != nil {
+		logrus.Debugf("could not collect deployed resource kinds: %v", err)
+	} else {
+		kstatus.TrackDeployedKinds(h.statusMonitor, kinds)
+	}
+
 	artifacts := parseReleaseInfo(opts.namespace, bufio.NewReader(&b))
 	return artifacts, nil
 }
@@ -18,7 +18,9 @@ package helm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -31,9 +33,11 @@ import (
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	schemautil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/status"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/warnings"
 	"github.com/GoogleContainerTools/skaffold/testutil"
@@ -63,6 +67,22 @@ var testDeployConfig = latestV1.HelmDeploy{
 	}},
 }
 
+var testDeployDependsOnConfig = latestV1.HelmDeploy{
+	Releases: []latestV1.HelmRelease{{
+		Name:      "skaffold-helm",
+		ChartPath: "examples/test",
+		ArtifactOverrides: map[string]string{
+			"image": "skaffold-helm",
+		},
+		SkipBuildDependencies: true,
+		DependsOn:             []string{"skaffold-crds"},
+	}, {
+		Name:                  "skaffold-crds",
+		ChartPath:             "examples/crds",
+		SkipBuildDependencies: true,
+	}},
+}
+
 var testDeployNamespacedConfig = latestV1.HelmDeploy{
 	Releases: []latestV1.HelmRelease{{
 		Name:      "skaffold-helm",
@@ -1059,6 +1079,38 @@ func TestHelmDeploy(t *testing.T) {
 			builds:             testBuilds,
 			expectedNamespaces: []string{"testReleaseNamespace"},
 		},
+		{
+			description: "deploy dependencies first and wait for them to be ready",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRun("helm --kube-context kubecontext get all skaffold-crds --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext upgrade skaffold-crds examples/crds --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all skaffold-crds --template {{.Release.Manifest}} --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all skaffold-helm --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext upgrade skaffold-helm examples/test --set-string image=docker.io:5000/skaffold-helm:3605e7bc17cf46e53f4d81c4cbc24e5b4c495184 --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all skaffold-helm --template {{.Release.Manifest}} --kubeconfig kubeconfig"),
+			helm: testDeployDependsOnConfig,
+			configure: func(deployer *Deployer) {
+				deployer.statusMonitor = &mockMonitor{}
+			},
+			builds:             testBuilds,
+			expectedNamespaces: []string{},
+		},
+		{
+			description: "don't deploy dependents when a dependency isn't ready",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRun("helm --kube-context kubecontext get all skaffold-crds --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext upgrade skaffold-crds examples/crds --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all skaffold-crds --template {{.Release.Manifest}} --kubeconfig kubeconfig"),
+			helm: testDeployDependsOnConfig,
+			configure: func(deployer *Deployer) {
+				deployer.statusMonitor = &mockMonitor{err: errors.New("deployment/crds failed")}
+			},
+			builds:             testBuilds,
+			shouldErr:          true,
+			expectedNamespaces: []string{},
+		},
 	}
 
 	for _, test := range tests {
@@ -1094,6 +1146,15 @@ func TestHelmDeploy(t *testing.T) {
 	}
 }
 
+type mockMonitor struct {
+	status.NoopMonitor
+	err error
+}
+
+func (m *mockMonitor) Check(context.Context, io.Writer) error {
+	return m.err
+}
+
 func TestHelmCleanup(t *testing.T) {
 	tests := []struct {
 		description      string
@@ -1136,6 +1197,15 @@ func TestHelmCleanup(t *testing.T) {
 			namespace: kubectl.TestNamespace,
 			builds:    testBuilds,
 		},
+		{
+			description: "cleanup dependents first",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRun("helm --kube-context kubecontext delete skaffold-helm --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext delete skaffold-crds --kubeconfig kubeconfig"),
+			helm:   testDeployDependsOnConfig,
+			builds: testBuilds,
+		},
 		{
 			description: "helm3 namespaced context cleanup success overriding release namespace",
 			commands: testutil.
@@ -1536,6 +1606,65 @@ func TestHelmRender(t *testing.T) {
 	}
 }
 
+func TestHelmPruned(t *testing.T) {
+	const deployedManifest = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+  namespace: jobs`
+
+	tests := []struct {
+		description string
+		commands    util.Command
+		helm        latestV1.HelmDeploy
+		expected    []diff.Resource
+	}{
+		{
+			description: "resources that are not rendered anymore are pruned",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRunWithOutput("helm --kube-context kubecontext template skaffold-helm examples/test --set-string image=skaffold-helm:tag1 --set some.key=somevalue --namespace testReleaseNamespace --kubeconfig kubeconfig",
+					"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web").
+				AndRunWithOutput("helm --kube-context kubecontext get all --namespace testReleaseNamespace skaffold-helm --template {{.Release.Manifest}} --kubeconfig kubeconfig", deployedManifest),
+			helm: testDeployNamespacedConfig,
+			expected: []diff.Resource{
+				{Kind: "Service", Namespace: "testReleaseNamespace", Name: "web"},
+				{Group: "batch", Kind: "CronJob", Namespace: "jobs", Name: "cleanup"},
+			},
+		},
+		{
+			description: "release not deployed yet",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRunWithOutput("helm --kube-context kubecontext template skaffold-helm examples/test --set-string image=skaffold-helm:tag1 --set some.key=somevalue --kubeconfig kubeconfig",
+					"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web").
+				AndRunErr("helm --kube-context kubecontext get all skaffold-helm --template {{.Release.Manifest}} --kubeconfig kubeconfig", fmt.Errorf("release: not found")),
+			helm: testDeployConfig,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&util.DefaultExecCommand, test.commands)
+			deployer, err := NewDeployer(&helmConfig{}, &label.DefaultLabeller{}, &test.helm)
+			t.RequireNoError(err)
+
+			pruned, err := deployer.Pruned(context.Background(), []graph.Artifact{{ImageName: "skaffold-helm", Tag: "skaffold-helm:tag1"}})
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, pruned)
+		})
+	}
+}
+
 func TestWriteBuildArtifacts(t *testing.T) {
 	tests := []struct {
 		description string
@@ -1604,10 +1733,12 @@ type helmConfig struct {
 	runcontext.RunContext // Embedded to provide the default values.
 	namespace             string
 	force                 bool
+	rollbackOnFailure     bool
 	configFile            string
 }
 
 func (c *helmConfig) ForceDeploy() bool                                     { return c.force }
+func (c *helmConfig) RollbackOnFailure() bool                               { return c.rollbackOnFailure }
 func (c *helmConfig) GetKubeConfig() string                                 { return kubectl.TestKubeConfig }
 func (c *helmConfig) GetKubeContext() string                                { return kubectl.TestKubeContext }
 func (c *helmConfig) GetKubeNamespace() string                              { return c.namespace }
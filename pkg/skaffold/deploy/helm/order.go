@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"fmt"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// hasDependencies returns true when any release depends on other releases.
+func hasDependencies(releases []latestV1.HelmRelease) bool {
+	for _, r := range releases {
+		if len(r.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseDependencies returns the indices of the dependencies of each release.
+// Release names and dependencies are matched after their templates are expanded.
+func releaseDependencies(releases []latestV1.HelmRelease) ([][]int, error) {
+	indices := map[string]int{}
+	for i, r := range releases {
+		name, err := util.ExpandEnvTemplateOrFail(r.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot expand release name %q: %w", r.Name, err)
+		}
+		indices[name] = i
+	}
+
+	deps := make([][]int, len(releases))
+	for i, r := range releases {
+		for _, d := range r.DependsOn {
+			dependency, err := util.ExpandEnvTemplateOrFail(d, nil)
+			if err != nil {
+				return nil, fmt.Errorf("cannot expand dependency %q of release %q: %w", d, r.Name, err)
+			}
+			j, found := indices[dependency]
+			if !found {
+				return nil, fmt.Errorf("unknown release %q in the dependencies of release %q", d, r.Name)
+			}
+			if j == i {
+				return nil, fmt.Errorf("release %q depends on itself", r.Name)
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+	return deps, nil
+}
+
+// releaseOrder sorts the releases topologically: each release comes after its dependencies.
+// Independent releases keep their order from the configuration.
+func releaseOrder(releases []latestV1.HelmRelease) ([]int, error) {
+	deps, err := releaseDependencies(releases)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []int
+	done := make([]bool, len(releases))
+	for len(order) < len(releases) {
+		progress := false
+		for i := range releases {
+			if done[i] || !allDone(deps[i], done) {
+				continue
+			}
+			order = append(order, i)
+			done[i] = true
+			progress = true
+			break
+		}
+		if !progress {
+			for i := range releases {
+				if !done[i] {
+					return nil, fmt.Errorf("cycle detected in the dependencies of release %q", releases[i].Name)
+				}
+			}
+		}
+	}
+	return order, nil
+}
+
+func allDone(indices []int, done []bool) bool {
+	for _, i := range indices {
+		if !done[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestReleaseOrder(t *testing.T) {
+	tests := []struct {
+		description string
+		releases    []latestV1.HelmRelease
+		expected    []int
+		shouldErr   bool
+	}{
+		{
+			description: "no dependencies",
+			releases:    []latestV1.HelmRelease{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			expected:    []int{0, 1, 2},
+		},
+		{
+			description: "dependencies first",
+			releases: []latestV1.HelmRelease{
+				{Name: "app", DependsOn: []string{"db", "crds"}},
+				{Name: "db", DependsOn: []string{"crds"}},
+				{Name: "other"},
+				{Name: "crds"},
+			},
+			expected: []int{2, 3, 1, 0},
+		},
+		{
+			description: "templated names",
+			releases: []latestV1.HelmRelease{
+				{Name: "app-{{.USER}}", DependsOn: []string{"db-alice"}},
+				{Name: "db-{{.USER}}"},
+				{Name: "other", DependsOn: []string{"app-{{.USER}}"}},
+			},
+			expected: []int{1, 0, 2},
+		},
+		{
+			description: "missing template value",
+			releases:    []latestV1.HelmRelease{{Name: "app", DependsOn: []string{"db-{{.MISSING}}"}}, {Name: "db"}},
+			shouldErr:   true,
+		},
+		{
+			description: "unknown dependency",
+			releases:    []latestV1.HelmRelease{{Name: "app", DependsOn: []string{"db"}}},
+			shouldErr:   true,
+		},
+		{
+			description: "self dependency",
+			releases:    []latestV1.HelmRelease{{Name: "app", DependsOn: []string{"app"}}},
+			shouldErr:   true,
+		},
+		{
+			description: "cycle",
+			releases: []latestV1.HelmRelease{
+				{Name: "a", DependsOn: []string{"c"}},
+				{Name: "b", DependsOn: []string{"a"}},
+				{Name: "c", DependsOn: []string{"b"}},
+				{Name: "d"},
+			},
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&util.OSEnviron, func() []string { return []string{"USER=alice"} })
+
+			order, err := releaseOrder(test.releases)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, order)
+		})
+	}
+}
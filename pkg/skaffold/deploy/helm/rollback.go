@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	deployerr "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/error"
+)
+
+// deployedRelease records the revision of a release before it was deployed, to be able to roll it back.
+type deployedRelease struct {
+	name      string
+	namespace string
+	// revision is the previous revision of the release, or 0 if it was installed.
+	revision int
+}
+
+func (r deployedRelease) namespaceArgs() []string {
+	if r.namespace == "" {
+		return nil
+	}
+	return []string{"--namespace", r.namespace}
+}
+
+// recordRelease records the current revision of a release that is about to be installed or upgraded.
+func (h *Deployer) recordRelease(ctx context.Context, releaseName, namespace string, upgrade bool) error {
+	release := deployedRelease{name: releaseName, namespace: namespace}
+	if upgrade {
+		var b bytes.Buffer
+		args := append(getArgs(releaseName, namespace), "--template", "{{.Release.Version}}")
+		if err := h.exec(ctx, &b, false, nil, args...); err != nil {
+			return fmt.Errorf("getting the revision of release %s: %w", releaseName, err)
+		}
+		revision, err := strconv.Atoi(strings.TrimSpace(b.String()))
+		if err != nil {
+			return fmt.Errorf("parsing the revision of release %s: %w", releaseName, err)
+		}
+		release.revision = revision
+	}
+
+	h.releasesMutex.Lock()
+	h.deployedReleases = append(h.deployedReleases, release)
+	h.releasesMutex.Unlock()
+	return nil
+}
+
+// RollbackOnFailure tells whether the deployer's config enables rollback on failure.
+func (h *Deployer) RollbackOnFailure() bool {
+	return h.rollbackOnFailure
+}
+
+// Rollback reverts the releases of the last deployment: upgraded releases are rolled back
+// to their previous revision, and installed releases are uninstalled.
+func (h *Deployer) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	h.releasesMutex.Lock()
+	releases := h.deployedReleases
+	h.deployedReleases = nil
+	h.releasesMutex.Unlock()
+
+	var reverted []string
+	// releases are reverted in the reverse order of their deployment
+	for i := len(releases) - 1; i >= 0; i-- {
+		r := releases[i]
+		if r.revision == 0 {
+			args := append([]string{"uninstall", r.name}, r.namespaceArgs()...)
+			if err := h.exec(ctx, out, false, nil, args...); err != nil {
+				return reverted, deployerr.CleanupErr(fmt.Errorf("uninstalling release %s: %w", r.name, err))
+			}
+			reverted = append(reverted, fmt.Sprintf("release %s uninstalled", r.name))
+			continue
+		}
+
+		args := append([]string{"rollback", r.name, strconv.Itoa(r.revision)}, r.namespaceArgs()...)
+		if err := h.exec(ctx, out, false, nil, args...); err != nil {
+			return reverted, fmt.Errorf("rolling back release %s: %w", r.name, err)
+		}
+		reverted = append(reverted, fmt.Sprintf("release %s rolled back to revision %d", r.name, r.revision))
+	}
+	return reverted, nil
+}
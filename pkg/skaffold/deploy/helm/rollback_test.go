@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/label"
+	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestHelmRollback(t *testing.T) {
+	tests := []struct {
+		description      string
+		commands         util.Command
+		namespace        string
+		expectedReverted []string
+		shouldErr        bool
+	}{
+		{
+			description: "upgraded release is rolled back to its previous revision",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRun("helm --kube-context kubecontext get all skaffold-helm --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext dep build examples/test --kubeconfig kubeconfig").
+				AndRunWithOutput("helm --kube-context kubecontext get all skaffold-helm --template {{.Release.Version}} --kubeconfig kubeconfig", "3\n").
+				AndRun("helm --kube-context kubecontext upgrade skaffold-helm examples/test --set-string image=docker.io:5000/skaffold-helm:3605e7bc17cf46e53f4d81c4cbc24e5b4c495184 --set some.key=somevalue -f skaffold-overrides.yaml --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all skaffold-helm --template {{.Release.Manifest}} --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext rollback skaffold-helm 3 --kubeconfig kubeconfig"),
+			expectedReverted: []string{"release skaffold-helm rolled back to revision 3"},
+		},
+		{
+			description: "installed release is uninstalled",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRunErr("helm --kube-context kubecontext get all --namespace testNamespace skaffold-helm --kubeconfig kubeconfig", fmt.Errorf("not found")).
+				AndRun("helm --kube-context kubecontext dep build examples/test --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext install skaffold-helm examples/test --namespace testNamespace --set-string image=docker.io:5000/skaffold-helm:3605e7bc17cf46e53f4d81c4cbc24e5b4c495184 --set some.key=somevalue -f skaffold-overrides.yaml --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all --namespace testNamespace skaffold-helm --template {{.Release.Manifest}} --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext uninstall skaffold-helm --namespace testNamespace --kubeconfig kubeconfig"),
+			namespace:        "testNamespace",
+			expectedReverted: []string{"release skaffold-helm uninstalled"},
+		},
+		{
+			description: "rollback fails",
+			commands: testutil.
+				CmdRunWithOutput("helm version --client", version31).
+				AndRun("helm --kube-context kubecontext get all skaffold-helm --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext dep build examples/test --kubeconfig kubeconfig").
+				AndRunWithOutput("helm --kube-context kubecontext get all skaffold-helm --template {{.Release.Version}} --kubeconfig kubeconfig", "3\n").
+				AndRun("helm --kube-context kubecontext upgrade skaffold-helm examples/test --set-string image=docker.io:5000/skaffold-helm:3605e7bc17cf46e53f4d81c4cbc24e5b4c495184 --set some.key=somevalue -f skaffold-overrides.yaml --kubeconfig kubeconfig").
+				AndRun("helm --kube-context kubecontext get all skaffold-helm --template {{.Release.Manifest}} --kubeconfig kubeconfig").
+				AndRunErr("helm --kube-context kubecontext rollback skaffold-helm 3 --kubeconfig kubeconfig", fmt.Errorf("release not found")),
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&client.Client, deployutil.MockK8sClient)
+			t.Override(&util.DefaultExecCommand, test.commands)
+
+			deployer, err := NewDeployer(&helmConfig{
+				namespace:         test.namespace,
+				rollbackOnFailure: true,
+			}, &label.DefaultLabeller{}, &testDeployConfig)
+			t.RequireNoError(err)
+
+			err = deployer.Deploy(context.Background(), ioutil.Discard, testBuilds)
+			t.RequireNoError(err)
+
+			reverted, err := deployer.Rollback(context.Background(), ioutil.Discard)
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expectedReverted, reverted)
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/sirupsen/logrus"
 	"golang.org/x/mod/semver"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8syaml "sigs.k8s.io/yaml"
@@ -42,6 +43,7 @@ import (
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/hooks"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
 	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes"
@@ -84,6 +86,7 @@ type Deployer struct {
 	imageLoader   loader.ImageLoader
 	statusMonitor status.Monitor
 	syncer        sync.Syncer
+	hookRunner    hooks.Runner
 
 	podSelector    *kubernetes.ImageList
 	originalImages []graph.Artifact // the set of images parsed from the Deployer's manifest set
@@ -96,8 +99,11 @@ type Deployer struct {
 	kubeContext        string
 	kubeConfig         string
 	namespace          string
+	rollbackOnFailure  bool
 
 	namespaces *[]string
+	// applyDirBackup is the content of the applyDir before the last deployment, when rollback is enabled
+	applyDirBackup *applyDirBackup
 }
 
 type Config interface {
@@ -105,6 +111,7 @@ type Config interface {
 	kstatus.Config
 	portforward.Config
 	kloader.Config
+	sync.Config
 }
 
 // NewDeployer generates a new Deployer object contains the kptDeploy schema.
@@ -112,6 +119,10 @@ func NewDeployer(cfg Config, labeller *label.DefaultLabeller, d *latestV1.KptDep
 	podSelector := kubernetes.NewImageList()
 	kubectl := pkgkubectl.NewCLI(cfg, cfg.GetKubeNamespace())
 	namespaces := []string{}
+	var hooksCfg latestV1.DeployHooks
+	if d != nil {
+		hooksCfg = d.LifecycleHooks
+	}
 
 	return &Deployer{
 		KptDeploy:          d,
@@ -122,7 +133,8 @@ func NewDeployer(cfg Config, labeller *label.DefaultLabeller, d *latestV1.KptDep
 		imageLoader:        component.NewImageLoader(cfg, kubectl),
 		logger:             component.NewLogger(cfg, kubectl, podSelector, &namespaces),
 		statusMonitor:      component.NewMonitor(cfg, cfg.GetKubeContext(), labeller, &namespaces),
-		syncer:             component.NewSyncer(kubectl, &namespaces),
+		syncer:             component.NewSyncer(cfg, kubectl, &namespaces),
+		hookRunner:         hooks.DeployRunner(kubectl, hooksCfg, &namespaces, hooks.NewDeployEnvOpts(labeller.GetRunID(), kubectl.KubeContext, namespaces)),
 		insecureRegistries: cfg.GetInsecureRegistries(),
 		labels:             labeller.Labels(),
 		globalConfig:       cfg.GlobalConfig(),
@@ -130,6 +142,7 @@ func NewDeployer(cfg Config, labeller *label.DefaultLabeller, d *latestV1.KptDep
 		kubeContext:        cfg.GetKubeContext(),
 		kubeConfig:         cfg.GetKubeConfig(),
 		namespace:          cfg.GetKubeNamespace(),
+		rollbackOnFailure:  cfg.RollbackOnFailure(),
 	}
 }
 
@@ -232,6 +245,10 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 		return fmt.Errorf("unable to connect to Kubernetes: %w", err)
 	}
 
+	if err := k.hookRunner.RunPreHooks(ctx, out); err != nil {
+		return fmt.Errorf("running pre-deploy hooks: %w", err)
+	}
+
 	_, endTrace := instrumentation.StartTrace(ctx, "Deploy_sanityCheck")
 	if err := sanityCheck(k.Dir, out); err != nil {
 		endTrace(instrumentation.TraceEndError(err))
@@ -265,6 +282,11 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 			"This might cause port-forward and deploy health-check to fail: %w", err))
 	}
 	endTrace()
+	if kinds, err := manifests.CollectGroupKinds(); err != nil {
+		logrus.Debugf("could not collect deployed resource kinds: %v", err)
+	} else {
+		kstatus.TrackDeployedKinds(k.statusMonitor, kinds)
+	}
 
 	childCtx, endTrace = instrumentation.StartTrace(ctx, "Deploy_getApplyDir")
 	applyDir, err := k.getApplyDir(childCtx)
@@ -273,6 +295,13 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	}
 	endTrace()
 
+	k.applyDirBackup = nil
+	if k.rollbackOnFailure {
+		if k.applyDirBackup, err = backupApplyDir(applyDir); err != nil {
+			return err
+		}
+	}
+
 	_, endTrace = instrumentation.StartTrace(ctx, "Deploy_manifest.Write")
 	if err = sink(ctx, []byte(manifests.String()), applyDir); err != nil {
 		return err
@@ -291,6 +320,10 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	k.TrackBuildArtifacts(builds)
 	endTrace()
 	k.trackNamespaces(namespaces)
+
+	if err := k.hookRunner.RunPostHooks(ctx, out); err != nil {
+		return fmt.Errorf("running post-deploy hooks: %w", err)
+	}
 	return nil
 }
 
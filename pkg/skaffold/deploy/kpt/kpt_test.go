@@ -32,6 +32,7 @@ import (
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
@@ -53,11 +54,12 @@ spec:
 
 // Test that kpt deployer manipulate manifests in the given order and no intermediate data is
 // stored after each step:
-//	Step 1. `kpt fn source` (read in the manifest as stdin),
-//  Step 2. `kpt fn run` (validate, transform or generate the manifests via kpt functions),
-//  Step 3. `kpt fn sink` (to temp dir to run kuustomize build on),
-//  Step 4. `kustomize build` (if the temp dir from step 3 has a Kustomization hydrate the manifest),
-//  Step 5. `kpt fn sink` (store the stdout in a given dir).
+//
+//		Step 1. `kpt fn source` (read in the manifest as stdin),
+//	 Step 2. `kpt fn run` (validate, transform or generate the manifests via kpt functions),
+//	 Step 3. `kpt fn sink` (to temp dir to run kuustomize build on),
+//	 Step 4. `kustomize build` (if the temp dir from step 3 has a Kustomization hydrate the manifest),
+//	 Step 5. `kpt fn sink` (store the stdout in a given dir).
 func TestKpt_Deploy(t *testing.T) {
 	sanityCheck = func(dir string, buf io.Writer) error { return nil }
 	tests := []struct {
@@ -880,6 +882,67 @@ func TestKpt_GetApplyDir(t *testing.T) {
 	}
 }
 
+func TestKpt_Pruned(t *testing.T) {
+	sanityCheck = func(dir string, buf io.Writer) error { return nil }
+	inventoryTemplate := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: inventory-12345
+  namespace: inventory
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: 1a23bcde
+`
+	rendered := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: default
+`
+	inventory := `{"items": [{"data": {
+  "default_web__Pod": "",
+  "default_app_apps_Deployment": "",
+  "_system____reader_rbac.authorization.k8s.io_ClusterRole": ""
+}}]}`
+
+	tests := []struct {
+		description    string
+		inventory      string
+		commands       util.Command
+		expectedPruned []diff.Resource
+	}{
+		{
+			description: "live inventory resources that are not rendered anymore",
+			inventory:   inventoryTemplate,
+			commands: testutil.
+				CmdRunOut("kpt fn source .", ``).
+				AndRunOut("kpt fn run", rendered).
+				AndRunOut(fmt.Sprintf("kpt fn sink %v", tmpKustomizeDir), ``).
+				AndRunOut("kubectl get configmap --namespace inventory -l cli-utils.sigs.k8s.io/inventory-id=1a23bcde -o json --context kubecontext", inventory),
+			expectedPruned: []diff.Resource{
+				{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "system::reader"},
+				{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "app"},
+			},
+		},
+		{
+			description: "package never applied",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&util.DefaultExecCommand, test.commands)
+			tmpDir := t.NewTempDir().Chdir()
+			if test.inventory != "" {
+				tmpDir.Write(".kpt-hydrated/inventory-template.yaml", test.inventory)
+			}
+
+			k := NewDeployer(&kptConfig{workingDir: "."}, &label.DefaultLabeller{}, &latestV1.KptDeploy{Dir: "."})
+			pruned, err := k.Pruned(context.Background(), nil)
+
+			t.CheckErrorAndDeepEqual(false, err, test.expectedPruned, pruned)
+		})
+	}
+}
+
 func TestKpt_KptCommandArgs(t *testing.T) {
 	tests := []struct {
 		description string
@@ -1201,3 +1264,4 @@ func (c *kptConfig) GetKubeContext() string                                { ret
 func (c *kptConfig) GetKubeNamespace() string                              { return kubectl.TestNamespace }
 func (c *kptConfig) GetKubeConfig() string                                 { return c.config }
 func (c *kptConfig) PortForwardResources() []*latestV1.PortForwardResource { return nil }
+func (c *kptConfig) RollbackOnFailure() bool                               { return false }
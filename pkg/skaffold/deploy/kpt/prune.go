@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// inventoryIDLabel is the label that identifies the inventory ConfigMap of a kpt package.
+const inventoryIDLabel = "cli-utils.sigs.k8s.io/inventory-id"
+
+// Pruned returns the resources that `kpt live apply` would delete: those of the live inventory
+// that are not rendered anymore. Nothing is pruned if the package was never applied.
+func (k *Deployer) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	namespace, id, err := k.inventory()
+	if err != nil || id == "" {
+		return nil, err
+	}
+
+	manifests, err := k.renderManifests(ctx, builds)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := diff.ResourcesOf(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"get", "configmap", "--namespace", namespace, "-l", inventoryIDLabel + "=" + id, "-o", "json"}, k.kubectlGlobalFlags()...)
+	out, err := util.RunCmdOut(exec.CommandContext(ctx, "kubectl", args...))
+	if err != nil {
+		logrus.Warnf("unable to read the live inventory of kpt package %s: %v", k.Dir, err)
+		return nil, nil
+	}
+	inventory, err := parseInventory(out)
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []diff.Resource
+	for _, r := range inventory {
+		if !isRendered(r, rendered) {
+			pruned = append(pruned, r)
+		}
+	}
+	return pruned, nil
+}
+
+// inventory returns the namespace and the id of the inventory that the applyDir points to.
+// Unlike getApplyDir, it doesn't initialize a missing applyDir.
+func (k *Deployer) inventory() (string, string, error) {
+	dir := k.Live.Apply.Dir
+	if dir == "" {
+		dir = kptHydrated
+	}
+	buf, err := ioutil.ReadFile(filepath.Join(dir, inventoryTemplate))
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("reading kpt inventory: %w", err)
+	}
+
+	var template struct {
+		Metadata struct {
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := k8syaml.Unmarshal(buf, &template); err != nil {
+		return "", "", fmt.Errorf("parsing kpt inventory: %w", err)
+	}
+	return template.Metadata.Namespace, template.Metadata.Labels[inventoryIDLabel], nil
+}
+
+// kubectlGlobalFlags are the flags to run kubectl against the cluster kpt deploys to.
+func (k *Deployer) kubectlGlobalFlags() []string {
+	var flags []string
+	if k.kubeContext != "" {
+		flags = append(flags, "--context", k.kubeContext)
+	}
+	if k.kubeConfig != "" {
+		flags = append(flags, "--kubeconfig", k.kubeConfig)
+	}
+	return flags
+}
+
+// parseInventory lists the resources recorded in the inventory ConfigMaps returned by `kubectl get -o json`.
+// Each resource is a data key of the form `<namespace>_<name>_<group>_<kind>`, with colons in names encoded as `__`.
+func parseInventory(buf []byte) ([]diff.Resource, error) {
+	var list struct {
+		Items []struct {
+			Data map[string]string `json:"data"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, fmt.Errorf("parsing kpt inventory: %w", err)
+	}
+
+	var resources []diff.Resource
+	for _, item := range list.Items {
+		var keys []string
+		for key := range item.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			parts := strings.Split(key, "_")
+			if len(parts) < 4 {
+				logrus.Debugf("ignoring invalid inventory entry %q", key)
+				continue
+			}
+			last := len(parts) - 1
+			resources = append(resources, diff.Resource{
+				Namespace: parts[0],
+				Name:      strings.ReplaceAll(strings.Join(parts[1:last-1], "_"), "__", ":"),
+				Group:     parts[last-1],
+				Kind:      parts[last],
+			})
+		}
+	}
+	return resources, nil
+}
+
+// isRendered checks whether a live resource is part of the rendered resources.
+// Rendered resources without a namespace are deployed to the default namespace, whichever it is.
+func isRendered(r diff.Resource, rendered []diff.Resource) bool {
+	for _, c := range rendered {
+		if c.Group == r.Group && c.Kind == r.Kind && c.Name == r.Name && (c.Namespace == "" || c.Namespace == r.Namespace) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// applyDirBackup is the content of the applyDir before a deployment, to be able to roll it back.
+type applyDirBackup struct {
+	dir   string
+	files map[string][]byte // relative path -> content
+}
+
+// backupApplyDir saves the content of the applyDir, which describes the previously deployed inventory.
+func backupApplyDir(dir string) (*applyDirBackup, error) {
+	backup := &applyDirBackup{dir: dir, files: map[string][]byte{}}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		backup.files[rel], err = ioutil.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backing up applyDir %s: %w", dir, err)
+	}
+	return backup, nil
+}
+
+// restore replaces the content of the applyDir with the backed up files.
+func (b *applyDirBackup) restore() error {
+	if err := os.RemoveAll(b.dir); err != nil {
+		return fmt.Errorf("deleting applyDir %s: %w", b.dir, err)
+	}
+	for rel, content := range b.files {
+		path := filepath.Join(b.dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return fmt.Errorf("restoring applyDir %s: %w", b.dir, err)
+		}
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("restoring applyDir %s: %w", b.dir, err)
+		}
+	}
+	return nil
+}
+
+// RollbackOnFailure tells whether the deployer's config enables rollback on failure.
+func (k *Deployer) RollbackOnFailure() bool {
+	return k.rollbackOnFailure
+}
+
+// Rollback restores the applyDir to its content before the last deployment, and re-applies it.
+// `kpt live apply` then reverts the resources to the previous inventory, and prunes those that are not part of it.
+func (k *Deployer) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	backup := k.applyDirBackup
+	k.applyDirBackup = nil
+	if backup == nil {
+		return nil, nil
+	}
+
+	if err := backup.restore(); err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "kpt", kptCommandArgs(backup.dir, []string{"live", "apply"}, k.getKptLiveApplyArgs(), nil)...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := util.RunCmd(cmd); err != nil {
+		return nil, fmt.Errorf("re-applying the previous inventory of %s: %w", backup.dir, err)
+	}
+	return []string{fmt.Sprintf("resources of %s reverted to the previous inventory", backup.dir)}, nil
+}
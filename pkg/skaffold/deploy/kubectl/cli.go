@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 	"time"
 
@@ -36,16 +37,25 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/portforward"
 	kstatus "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/status"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/sync"
 )
 
+// FieldManager is the name of the field manager used for server-side apply.
+const FieldManager = "skaffold"
+
+// applyConflictRegex matches the errors of server-side apply when fields are owned by other field managers.
+var applyConflictRegex = regexp.MustCompile(`Apply failed with \d+ conflicts?`)
+
 // CLI holds parameters to run kubectl.
 type CLI struct {
 	*kubectl.CLI
 	Flags latestV1.KubectlFlags
 
-	forceDeploy      bool
-	waitForDeletions config.WaitForDeletions
-	previousApply    manifest.ManifestList
+	forceDeploy       bool
+	rollbackOnFailure bool
+	waitForDeletions  config.WaitForDeletions
+	previousApply     manifest.ManifestList
+	previousState     []resourceState // state of the resources before the last `Apply`, when rollback is enabled
 }
 
 type Config interface {
@@ -54,7 +64,9 @@ type Config interface {
 	kloader.Config
 	portforward.Config
 	deploy.Config
+	sync.Config
 	ForceDeploy() bool
+	RollbackOnFailure() bool
 	WaitForDeletions() config.WaitForDeletions
 	Mode() config.RunMode
 	HydratedManifests() []string
@@ -65,10 +77,11 @@ type Config interface {
 
 func NewCLI(cfg Config, flags latestV1.KubectlFlags, defaultNamespace string) CLI {
 	return CLI{
-		CLI:              kubectl.NewCLI(cfg, defaultNamespace),
-		Flags:            flags,
-		forceDeploy:      cfg.ForceDeploy(),
-		waitForDeletions: cfg.WaitForDeletions(),
+		CLI:               kubectl.NewCLI(cfg, defaultNamespace),
+		Flags:             flags,
+		forceDeploy:       cfg.ForceDeploy(),
+		rollbackOnFailure: cfg.RollbackOnFailure(),
+		waitForDeletions:  cfg.WaitForDeletions(),
 	}
 }
 
@@ -92,11 +105,50 @@ func (c *CLI) Apply(ctx context.Context, out io.Writer, manifests manifest.Manif
 	// TODO(dgageot): should we delete a manifest that was deployed and is not anymore?
 	updated := c.previousApply.Diff(manifests)
 	logrus.Debugln(len(manifests), "manifests to deploy.", len(updated), "are updated or new")
+	removed := c.Pruned(manifests)
+	if c.rollbackOnFailure {
+		c.recordPreviousState(ctx, updated, removed)
+	}
+	if c.Flags.ServerSideApply {
+		// `kubectl apply --prune` ignores resources that were applied server-side,
+		// so resources that were applied before and are not part of the manifests anymore are deleted here.
+		if len(removed) > 0 {
+			logrus.Debugln(len(removed), "manifests were removed and are pruned")
+			if err := c.Delete(ctx, out, removed); err != nil {
+				endTrace(instrumentation.TraceEndError(err))
+				return err
+			}
+		}
+	}
 	c.previousApply = manifests
 	if len(updated) == 0 {
 		return nil
 	}
 
+	var err error
+	if c.Flags.ServerSideApply {
+		err = c.serverSideApply(ctx, out, updated, c.forceDeploy || c.Flags.ForceConflicts)
+	} else {
+		err = c.clientSideApply(ctx, out, updated)
+	}
+	if err != nil {
+		endTrace(instrumentation.TraceEndError(err))
+		return err
+	}
+	return nil
+}
+
+// Pruned returns the manifests of the resources that applying the given manifests deletes.
+// With server-side apply, these are the resources that were applied before and are not part of the manifests anymore.
+func (c *CLI) Pruned(manifests manifest.ManifestList) manifest.ManifestList {
+	if !c.Flags.ServerSideApply {
+		return nil
+	}
+	return c.previousApply.Removed(manifests)
+}
+
+// clientSideApply runs `kubectl apply` on a list of manifests.
+func (c *CLI) clientSideApply(ctx context.Context, out io.Writer, manifests manifest.ManifestList) error {
 	args := []string{"-f", "-"}
 	if c.forceDeploy {
 		args = append(args, "--force", "--grace-period=0")
@@ -106,8 +158,30 @@ func (c *CLI) Apply(ctx context.Context, out io.Writer, manifests manifest.Manif
 		args = append(args, "--validate=false")
 	}
 
-	if err := c.Run(ctx, updated.Reader(), out, "apply", c.args(c.Flags.Apply, args...)...); err != nil {
-		endTrace(instrumentation.TraceEndError(err))
+	if err := c.Run(ctx, manifests.Reader(), out, "apply", c.args(c.Flags.Apply, args...)...); err != nil {
+		return userErr(fmt.Errorf("kubectl apply: %w", err))
+	}
+
+	return nil
+}
+
+// serverSideApply runs `kubectl apply --server-side` on a list of manifests, with Skaffold as the field manager.
+func (c *CLI) serverSideApply(ctx context.Context, out io.Writer, manifests manifest.ManifestList, forceConflicts bool) error {
+	args := []string{"-f", "-", "--server-side", "--field-manager=" + FieldManager}
+	if forceConflicts {
+		args = append(args, "--force-conflicts")
+	}
+
+	if c.Flags.DisableValidation {
+		args = append(args, "--validate=false")
+	}
+
+	buf, err := c.RunOutInput(ctx, manifests.Reader(), "apply", c.args(c.Flags.Apply, args...)...)
+	out.Write(buf)
+	if err != nil {
+		if applyConflictRegex.MatchString(err.Error()) {
+			return applyConflictErr(fmt.Errorf("kubectl apply: %w", err))
+		}
 		return userErr(fmt.Errorf("kubectl apply: %w", err))
 	}
 
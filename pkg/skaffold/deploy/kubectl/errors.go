@@ -89,6 +89,20 @@ func listManifestErr(err error) error {
 		})
 }
 
+func applyConflictErr(err error) error {
+	return sErrors.NewError(err,
+		proto.ActionableErr{
+			Message: fmt.Sprintf("server-side apply failed because fields are managed by other field managers: %s", err),
+			ErrCode: proto.StatusCode_DEPLOY_KUBECTL_USER_ERR,
+			Suggestions: []*proto.Suggestion{
+				{
+					SuggestionCode: proto.SuggestionCode_NIL,
+					Action:         "Remove the conflicting fields from the manifests, or take ownership of them by setting `forceConflicts: true` in the kubectl flags or by running with `--force`",
+				},
+			},
+		})
+}
+
 func userErr(err error) error {
 	return deployerr.UserError(err, proto.StatusCode_DEPLOY_KUBECTL_USER_ERR)
 }
@@ -27,7 +27,6 @@ import (
 
 	"github.com/segmentio/textio"
 	"github.com/sirupsen/logrus"
-	"go.opentelemetry.io/otel/trace"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/access"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
@@ -38,9 +37,12 @@ import (
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/hooks"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	kstatus "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/status"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/loader"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/log"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
@@ -60,6 +62,7 @@ type Deployer struct {
 	debugger      debug.Debugger
 	statusMonitor status.Monitor
 	syncer        sync.Syncer
+	hookRunner    hooks.Runner
 
 	originalImages     []graph.Artifact // the set of images marked as "local" by the Runner
 	localImages        []graph.Artifact // the set of images parsed from the Deployer's manifest set
@@ -102,7 +105,8 @@ func NewDeployer(cfg Config, labeller *label.DefaultLabeller, d *latestV1.Kubect
 		imageLoader:        component.NewImageLoader(cfg, kubectl.CLI),
 		logger:             component.NewLogger(cfg, kubectl.CLI, podSelector, &namespaces),
 		statusMonitor:      component.NewMonitor(cfg, cfg.GetKubeContext(), labeller, &namespaces),
-		syncer:             component.NewSyncer(kubectl.CLI, &namespaces),
+		syncer:             component.NewSyncer(cfg, kubectl.CLI, &namespaces),
+		hookRunner:         hooks.DeployRunner(kubectl.CLI, d.LifecycleHooks, &namespaces, hooks.NewDeployEnvOpts(labeller.GetRunID(), kubectl.KubeContext, namespaces)),
 		workingDir:         cfg.GetWorkingDir(),
 		globalConfig:       cfg.GlobalConfig(),
 		defaultRepo:        cfg.DefaultRepo(),
@@ -150,12 +154,6 @@ func (k *Deployer) trackNamespaces(namespaces []string) {
 // Deploy templates the provided manifests with a simple `find and replace` and
 // runs `kubectl apply` on those manifests
 func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Artifact) error {
-	var (
-		manifests manifest.ManifestList
-		err       error
-		childCtx  context.Context
-		endTrace  func(...trace.SpanOption)
-	)
 	instrumentation.AddAttributesToCurrentSpanFromContext(ctx, map[string]string{
 		"DeployerType": "kubectl",
 	})
@@ -167,28 +165,11 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 		return fmt.Errorf("unable to connect to Kubernetes: %w", err)
 	}
 
-	// if any hydrated manifests are passed to `skaffold apply`, only deploy these
-	// also, manually set the labels to ensure the runID is added
-	switch {
-	case len(k.hydratedManifests) > 0:
-		_, endTrace = instrumentation.StartTrace(ctx, "Deploy_createManifestList")
-		manifests, err = createManifestList(k.hydratedManifests)
-		if err != nil {
-			endTrace(instrumentation.TraceEndError(err))
-			return err
-		}
-		manifests, err = manifests.SetLabels(k.labeller.Labels())
-		endTrace()
-	case k.skipRender:
-		childCtx, endTrace = instrumentation.StartTrace(ctx, "Deploy_readManifests")
-		manifests, err = k.readManifests(childCtx, false)
-		endTrace()
-	default:
-		childCtx, endTrace = instrumentation.StartTrace(ctx, "Deploy_renderManifests")
-		manifests, err = k.renderManifests(childCtx, out, builds, false)
-		endTrace()
+	if err := k.hookRunner.RunPreHooks(ctx, out); err != nil {
+		return fmt.Errorf("running pre-deploy hooks: %w", err)
 	}
 
+	manifests, err := k.manifestsToDeploy(ctx, out, builds)
 	if err != nil {
 		return err
 	}
@@ -196,9 +177,8 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	if len(manifests) == 0 {
 		return nil
 	}
-	endTrace()
 
-	_, endTrace = instrumentation.StartTrace(ctx, "Deploy_LoadImages")
+	childCtx, endTrace := instrumentation.StartTrace(ctx, "Deploy_LoadImages")
 	if err := k.imageLoader.LoadImages(childCtx, out, k.localImages, k.originalImages, builds); err != nil {
 		endTrace(instrumentation.TraceEndError(err))
 		return err
@@ -212,6 +192,11 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 			"This might cause port-forward and deploy health-check to fail: %w", err))
 	}
 	endTrace()
+	if kinds, err := manifests.CollectGroupKinds(); err != nil {
+		logrus.Debugf("could not collect deployed resource kinds: %v", err)
+	} else {
+		kstatus.TrackDeployedKinds(k.statusMonitor, kinds)
+	}
 
 	childCtx, endTrace = instrumentation.StartTrace(ctx, "Deploy_WaitForDeletions")
 	if err := k.kubectl.WaitForDeletions(childCtx, textio.NewPrefixWriter(out, " - "), manifests); err != nil {
@@ -229,6 +214,10 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	k.TrackBuildArtifacts(builds)
 	endTrace()
 	k.trackNamespaces(namespaces)
+
+	if err := k.hookRunner.RunPostHooks(ctx, out); err != nil {
+		return fmt.Errorf("running post-deploy hooks: %w", err)
+	}
 	return nil
 }
 
@@ -465,6 +454,50 @@ func (k *Deployer) Cleanup(ctx context.Context, out io.Writer) error {
 	return nil
 }
 
+// manifestsToDeploy returns the manifests that Deploy applies.
+func (k *Deployer) manifestsToDeploy(ctx context.Context, out io.Writer, builds []graph.Artifact) (manifest.ManifestList, error) {
+	switch {
+	// if any hydrated manifests are passed to `skaffold apply`, only deploy these
+	// also, manually set the labels to ensure the runID is added
+	case len(k.hydratedManifests) > 0:
+		_, endTrace := instrumentation.StartTrace(ctx, "Deploy_createManifestList")
+		defer endTrace()
+		manifests, err := createManifestList(k.hydratedManifests)
+		if err != nil {
+			endTrace(instrumentation.TraceEndError(err))
+			return nil, err
+		}
+		return manifests.SetLabels(k.labeller.Labels())
+	case k.skipRender:
+		childCtx, endTrace := instrumentation.StartTrace(ctx, "Deploy_readManifests")
+		defer endTrace()
+		return k.readManifests(childCtx, false)
+	default:
+		childCtx, endTrace := instrumentation.StartTrace(ctx, "Deploy_renderManifests")
+		defer endTrace()
+		return k.renderManifests(childCtx, out, builds, false)
+	}
+}
+
+// Pruned returns the resources that deploying the build results deletes.
+func (k *Deployer) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	manifests, err := k.manifestsToDeploy(ctx, ioutil.Discard, builds)
+	if err != nil {
+		return nil, err
+	}
+	return diff.ResourcesOf(k.kubectl.Pruned(manifests))
+}
+
+// Rollback reverts the resources changed by the last deployment to their previous state.
+func (k *Deployer) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	return k.kubectl.Rollback(ctx, out)
+}
+
+// RollbackOnFailure tells whether the deployer's config enables rollback on failure.
+func (k *Deployer) RollbackOnFailure() bool {
+	return k.kubectl.RollbackOnFailure()
+}
+
 // Dependencies lists all the files that describe what needs to be deployed.
 func (k *Deployer) Dependencies() ([]string, error) {
 	return k.manifestFiles(k.KubectlDeploy.Manifests)
@@ -32,6 +32,7 @@ import (
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
@@ -46,6 +47,7 @@ func TestKubectlDeploy(t *testing.T) {
 		builds                      []graph.Artifact
 		commands                    util.Command
 		shouldErr                   bool
+		expectedErr                 string
 		forceDeploy                 bool
 		waitForDeletions            bool
 		skipSkaffoldNamespaceOption bool
@@ -109,6 +111,87 @@ func TestKubectlDeploy(t *testing.T) {
 			}},
 			waitForDeletions: true,
 		},
+		{
+			description: "deploy success (server-side apply)",
+			kubectl: latestV1.KubectlDeploy{
+				Manifests: []string{"deployment.yaml"},
+				Flags: latestV1.KubectlFlags{
+					ServerSideApply: true,
+				},
+			},
+			commands: testutil.
+				CmdRunOut("kubectl version --client -ojson", KubectlVersion118).
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace create --dry-run=client -oyaml -f deployment.yaml", DeploymentWebYAML).
+				AndRunInputOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -ojson", DeploymentWebYAMLv1, "").
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace apply -f - --server-side --field-manager=skaffold", "deployment.apps/leeroy-web serverside-applied"),
+			builds: []graph.Artifact{{
+				ImageName: "leeroy-web",
+				Tag:       "leeroy-web:v1",
+			}},
+			waitForDeletions: true,
+		},
+		{
+			description: "deploy success (server-side apply, forced)",
+			kubectl: latestV1.KubectlDeploy{
+				Manifests: []string{"deployment.yaml"},
+				Flags: latestV1.KubectlFlags{
+					ServerSideApply: true,
+				},
+			},
+			commands: testutil.
+				CmdRunOut("kubectl version --client -ojson", KubectlVersion118).
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace create --dry-run=client -oyaml -f deployment.yaml", DeploymentWebYAML).
+				AndRunInputOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -ojson", DeploymentWebYAMLv1, "").
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace apply -f - --server-side --field-manager=skaffold --force-conflicts", ""),
+			builds: []graph.Artifact{{
+				ImageName: "leeroy-web",
+				Tag:       "leeroy-web:v1",
+			}},
+			forceDeploy:      true,
+			waitForDeletions: true,
+		},
+		{
+			description: "deploy success (server-side apply, force conflicts)",
+			kubectl: latestV1.KubectlDeploy{
+				Manifests: []string{"deployment.yaml"},
+				Flags: latestV1.KubectlFlags{
+					ServerSideApply:   true,
+					ForceConflicts:    true,
+					DisableValidation: true,
+				},
+			},
+			commands: testutil.
+				CmdRunOut("kubectl version --client -ojson", KubectlVersion118).
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace create --dry-run=client -oyaml -f deployment.yaml --validate=false", DeploymentWebYAML).
+				AndRunInputOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -ojson", DeploymentWebYAMLv1, "").
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace apply -f - --server-side --field-manager=skaffold --force-conflicts --validate=false", ""),
+			builds: []graph.Artifact{{
+				ImageName: "leeroy-web",
+				Tag:       "leeroy-web:v1",
+			}},
+			waitForDeletions: true,
+		},
+		{
+			description: "server-side apply conflict",
+			kubectl: latestV1.KubectlDeploy{
+				Manifests: []string{"deployment.yaml"},
+				Flags: latestV1.KubectlFlags{
+					ServerSideApply: true,
+				},
+			},
+			commands: testutil.
+				CmdRunOut("kubectl version --client -ojson", KubectlVersion118).
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace create --dry-run=client -oyaml -f deployment.yaml", DeploymentWebYAML).
+				AndRunInputOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -ojson", DeploymentWebYAMLv1, "").
+				AndRunOutErr("kubectl --context kubecontext --namespace testNamespace apply -f - --server-side --field-manager=skaffold", "", fmt.Errorf(`error: Apply failed with 1 conflict: conflict with "kubectl-client-side-apply" using apps/v1: .spec.replicas`)),
+			builds: []graph.Artifact{{
+				ImageName: "leeroy-web",
+				Tag:       "leeroy-web:v1",
+			}},
+			shouldErr:        true,
+			expectedErr:      "setting `forceConflicts: true`",
+			waitForDeletions: true,
+		},
 		{
 			description: "deploy success (kubectl v1.18)",
 			kubectl: latestV1.KubectlDeploy{
@@ -251,6 +334,9 @@ func TestKubectlDeploy(t *testing.T) {
 			err = k.Deploy(context.Background(), ioutil.Discard, test.builds)
 
 			t.CheckError(test.shouldErr, err)
+			if test.expectedErr != "" {
+				t.CheckErrorContains(test.expectedErr, err)
+			}
 		})
 	}
 }
@@ -427,6 +513,110 @@ func TestKubectlRedeploy(t *testing.T) {
 	})
 }
 
+func TestKubectlServerSideApplyPrune(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&client.Client, deployutil.MockK8sClient)
+		tmpDir := t.NewTempDir().
+			Write("deployment-app.yaml", DeploymentAppYAML).
+			Write("deployment-web.yaml", DeploymentWebYAML)
+
+		t.Override(&util.DefaultExecCommand, testutil.
+			CmdRunOut("kubectl version --client -ojson", KubectlVersion112).
+			AndRunOut("kubectl --context kubecontext create --dry-run -oyaml -f "+tmpDir.Path("deployment-app.yaml")+" -f "+tmpDir.Path("deployment-web.yaml"), DeploymentAppYAML+"\n"+DeploymentWebYAML).
+			AndRunInputOut("kubectl --context kubecontext get -f - --ignore-not-found -ojson", DeploymentAppYAMLv1+"\n---\n"+DeploymentWebYAMLv1, "").
+			AndRunInputOut("kubectl --context kubecontext apply -f - --server-side --field-manager=skaffold", DeploymentAppYAMLv1+"\n---\n"+DeploymentWebYAMLv1, "").
+			AndRunOut("kubectl --context kubecontext create --dry-run -oyaml -f "+tmpDir.Path("deployment-app.yaml")+" -f "+tmpDir.Path("deployment-web.yaml"), DeploymentWebYAML).
+			AndRunOut("kubectl --context kubecontext create --dry-run -oyaml -f "+tmpDir.Path("deployment-app.yaml")+" -f "+tmpDir.Path("deployment-web.yaml"), DeploymentWebYAML).
+			AndRunInputOut("kubectl --context kubecontext get -f - --ignore-not-found -ojson", DeploymentWebYAMLv1, "").
+			AndRunInput("kubectl --context kubecontext delete --ignore-not-found=true --wait=false -f -", DeploymentAppYAMLv1),
+		)
+
+		deployer, err := NewDeployer(&kubectlConfig{
+			workingDir: ".",
+			waitForDeletions: config.WaitForDeletions{
+				Enabled: true,
+				Delay:   0 * time.Millisecond,
+				Max:     10 * time.Second},
+		}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+			Manifests: []string{tmpDir.Path("deployment-app.yaml"), tmpDir.Path("deployment-web.yaml")},
+			Flags:     latestV1.KubectlFlags{ServerSideApply: true},
+		})
+		t.RequireNoError(err)
+
+		// Deploy both manifests
+		err = deployer.Deploy(context.Background(), ioutil.Discard, []graph.Artifact{
+			{ImageName: "leeroy-web", Tag: "leeroy-web:v1"},
+			{ImageName: "leeroy-app", Tag: "leeroy-app:v1"},
+		})
+		t.CheckNoError(err)
+
+		// The app deployment was removed from the manifests, so it's pruned
+		pruned, err := deployer.Pruned(context.Background(), []graph.Artifact{
+			{ImageName: "leeroy-web", Tag: "leeroy-web:v1"},
+			{ImageName: "leeroy-app", Tag: "leeroy-app:v1"},
+		})
+		t.CheckErrorAndDeepEqual(false, err, []diff.Resource{{Kind: "Pod", Name: "leeroy-app"}}, pruned)
+
+		err = deployer.Deploy(context.Background(), ioutil.Discard, []graph.Artifact{
+			{ImageName: "leeroy-web", Tag: "leeroy-web:v1"},
+			{ImageName: "leeroy-app", Tag: "leeroy-app:v1"},
+		})
+		t.CheckNoError(err)
+	})
+}
+
+func TestKubectlRollback(t *testing.T) {
+	liveWeb := `{"apiVersion":"v1","kind":"List","items":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"leeroy-web","namespace":"default",` +
+		`"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{\"apiVersion\":\"v1\",\"kind\":\"Pod\",\"metadata\":{\"name\":\"leeroy-web\"},` +
+		`\"spec\":{\"containers\":[{\"image\":\"leeroy-web:v0\",\"name\":\"leeroy-web\"}]}}"}},"status":{"phase":"Running"}}]}`
+	previousWeb := `apiVersion: v1
+kind: Pod
+metadata:
+  name: leeroy-web
+spec:
+  containers:
+  - image: leeroy-web:v0
+    name: leeroy-web`
+
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&client.Client, deployutil.MockK8sClient)
+		tmpDir := t.NewTempDir().
+			Write("deployment-app.yaml", DeploymentAppYAML).
+			Write("deployment-web.yaml", DeploymentWebYAML)
+
+		t.Override(&util.DefaultExecCommand, testutil.
+			CmdRunOut("kubectl version --client -ojson", KubectlVersion112).
+			AndRunOut("kubectl --context kubecontext create --dry-run -oyaml -f "+tmpDir.Path("deployment-app.yaml")+" -f "+tmpDir.Path("deployment-web.yaml"), DeploymentAppYAML+"\n"+DeploymentWebYAML).
+			AndRunInputOut("kubectl --context kubecontext get -f - --ignore-not-found -ojson", DeploymentAppYAMLv1+"\n---\n"+DeploymentWebYAMLv1, liveWeb).
+			AndRunInput("kubectl --context kubecontext apply -f -", DeploymentAppYAMLv1+"\n---\n"+DeploymentWebYAMLv1).
+			AndRunInput("kubectl --context kubecontext delete --ignore-not-found=true --wait=false -f -", DeploymentAppYAMLv1).
+			AndRunInput("kubectl --context kubecontext apply -f -", previousWeb),
+		)
+
+		deployer, err := NewDeployer(&kubectlConfig{
+			workingDir:        ".",
+			rollbackOnFailure: true,
+		}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+			Manifests: []string{tmpDir.Path("deployment-app.yaml"), tmpDir.Path("deployment-web.yaml")},
+		})
+		t.RequireNoError(err)
+
+		err = deployer.Deploy(context.Background(), ioutil.Discard, []graph.Artifact{
+			{ImageName: "leeroy-web", Tag: "leeroy-web:v1"},
+			{ImageName: "leeroy-app", Tag: "leeroy-app:v1"},
+		})
+		t.CheckNoError(err)
+
+		// The app pod didn't exist so it's deleted, and the web pod is re-applied with its previous configuration
+		reverted, err := deployer.Rollback(context.Background(), ioutil.Discard)
+		t.CheckErrorAndDeepEqual(false, err, []string{"Pod/leeroy-app deleted", "Pod/leeroy-web restored to its previous configuration"}, reverted)
+
+		// Nothing is left to revert
+		reverted, err = deployer.Rollback(context.Background(), ioutil.Discard)
+		t.CheckErrorAndDeepEqual(false, err, []string(nil), reverted)
+	})
+}
+
 func TestKubectlWaitForDeletions(t *testing.T) {
 	testutil.Run(t, "", func(t *testutil.T) {
 		t.Override(&client.Client, deployutil.MockK8sClient)
@@ -743,6 +933,7 @@ type kubectlConfig struct {
 	defaultRepo           string
 	skipRender            bool
 	force                 bool
+	rollbackOnFailure     bool
 	waitForDeletions      config.WaitForDeletions
 }
 
@@ -751,6 +942,7 @@ func (c *kubectlConfig) GetKubeNamespace() string                              {
 func (c *kubectlConfig) WorkingDir() string                                    { return c.workingDir }
 func (c *kubectlConfig) SkipRender() bool                                      { return c.skipRender }
 func (c *kubectlConfig) ForceDeploy() bool                                     { return c.force }
+func (c *kubectlConfig) RollbackOnFailure() bool                               { return c.rollbackOnFailure }
 func (c *kubectlConfig) DefaultRepo() *string                                  { return &c.defaultRepo }
 func (c *kubectlConfig) WaitForDeletions() config.WaitForDeletions             { return c.waitForDeletions }
 func (c *kubectlConfig) PortForwardResources() []*latestV1.PortForwardResource { return nil }
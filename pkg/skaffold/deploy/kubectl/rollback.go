@@ -0,0 +1,245 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
+)
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// resourceState is the state of a resource before it was applied.
+type resourceState struct {
+	id       string
+	manifest []byte // the previous configuration of the resource, or the applied one if it didn't exist
+	existed  bool
+}
+
+// resource identifies a Kubernetes resource.
+type resource struct {
+	apiVersion string
+	kind       string
+	namespace  string
+	name       string
+}
+
+func resourceOf(obj map[string]interface{}) resource {
+	r := resource{}
+	r.apiVersion, _ = obj["apiVersion"].(string)
+	r.kind, _ = obj["kind"].(string)
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		r.namespace, _ = metadata["namespace"].(string)
+		r.name, _ = metadata["name"].(string)
+	}
+	return r
+}
+
+func (r resource) String() string {
+	return r.kind + "/" + r.name
+}
+
+// matches tells if a live resource is the one described by a manifest.
+// The namespace is only compared when set in the manifest, since it's otherwise the default one.
+func (r resource) matches(live resource) bool {
+	return group(r.apiVersion) == group(live.apiVersion) && r.kind == live.kind && r.name == live.name &&
+		(r.namespace == "" || r.namespace == live.namespace)
+}
+
+func group(apiVersion string) string {
+	if i := strings.Index(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i]
+	}
+	return ""
+}
+
+// recordPreviousState records the state of the resources that are about to be applied or deleted,
+// so that they can be rolled back if the deployment fails.
+func (c *CLI) recordPreviousState(ctx context.Context, applied, deleted manifest.ManifestList) {
+	c.previousState = nil
+	all := append(manifest.ManifestList{}, applied...)
+	all = append(all, deleted...)
+	if len(all) == 0 {
+		return
+	}
+
+	buf, err := c.RunOutInput(ctx, all.Reader(), "get", c.args(nil, "-f", "-", "--ignore-not-found", "-ojson")...)
+	if err != nil {
+		logrus.Warnf("unable to record the state of the resources before deploying them, they won't be rolled back: %v", err)
+		return
+	}
+	state, err := previousState(buf, applied, deleted)
+	if err != nil {
+		logrus.Warnf("unable to record the state of the resources before deploying them, they won't be rolled back: %v", err)
+		return
+	}
+	c.previousState = state
+}
+
+// previousState computes the state of the resources to be applied and deleted, from their live version.
+func previousState(liveJSON []byte, applied, deleted manifest.ManifestList) ([]resourceState, error) {
+	live, err := parseLiveObjects(liveJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var state []resourceState
+	for i, m := range append(append(manifest.ManifestList{}, applied...), deleted...) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal(m, &obj); err != nil {
+			return nil, fmt.Errorf("reading manifest: %w", err)
+		}
+		r := resourceOf(obj)
+
+		var found map[string]interface{}
+		for _, l := range live {
+			if r.matches(resourceOf(l)) {
+				found = l
+				break
+			}
+		}
+
+		switch {
+		case found != nil:
+			previous, err := previousConfig(found)
+			if err != nil {
+				return nil, err
+			}
+			state = append(state, resourceState{id: r.String(), manifest: previous, existed: true})
+		case i < len(applied):
+			state = append(state, resourceState{id: r.String(), manifest: m})
+		}
+	}
+	return state, nil
+}
+
+func parseLiveObjects(buf []byte) ([]map[string]interface{}, error) {
+	if len(bytes.TrimSpace(buf)) == 0 {
+		return nil, nil
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return nil, fmt.Errorf("reading live resources: %w", err)
+	}
+	if obj["kind"] != "List" {
+		return []map[string]interface{}{obj}, nil
+	}
+
+	items, _ := obj["items"].([]interface{})
+	var objects []map[string]interface{}
+	for _, item := range items {
+		if o, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, o)
+		}
+	}
+	return objects, nil
+}
+
+// previousConfig returns the configuration that was last applied to a live resource.
+// Without a `last-applied-configuration` annotation, it's the live resource minus
+// its status and the metadata set by the cluster.
+func previousConfig(live map[string]interface{}) ([]byte, error) {
+	metadata, _ := live["metadata"].(map[string]interface{})
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		if lastApplied, ok := annotations[lastAppliedAnnotation].(string); ok && lastApplied != "" {
+			var config map[string]interface{}
+			if err := json.Unmarshal([]byte(lastApplied), &config); err == nil {
+				return yaml.Marshal(config)
+			}
+		}
+	}
+
+	delete(live, "status")
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		delete(metadata, field)
+	}
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		delete(annotations, "deployment.kubernetes.io/revision")
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+	return yaml.Marshal(live)
+}
+
+// RollbackOnFailure tells whether `Apply` records the state needed to roll back.
+func (c *CLI) RollbackOnFailure() bool {
+	return c.rollbackOnFailure
+}
+
+// Rollback reverts the resources changed by the last `Apply`: the resources that didn't exist are deleted,
+// and the others are re-applied with their previous configuration.
+func (c *CLI) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	state := c.previousState
+	c.previousState = nil
+	if len(state) == 0 {
+		return nil, nil
+	}
+	// Resources will have to be re-applied on the next deployment.
+	c.previousApply = nil
+
+	var created, previous manifest.ManifestList
+	var reverted []string
+	for _, s := range state {
+		if s.existed {
+			previous = append(previous, s.manifest)
+		} else {
+			created = append(created, s.manifest)
+		}
+	}
+
+	if len(created) > 0 {
+		if err := c.Delete(ctx, out, created); err != nil {
+			return reverted, err
+		}
+		for _, s := range state {
+			if !s.existed {
+				reverted = append(reverted, fmt.Sprintf("%s deleted", s.id))
+			}
+		}
+	}
+
+	if len(previous) > 0 {
+		var err error
+		if c.Flags.ServerSideApply {
+			// The previous configuration overrides the fields owned by other field managers.
+			err = c.serverSideApply(ctx, out, previous, true)
+		} else {
+			err = c.clientSideApply(ctx, out, previous)
+		}
+		if err != nil {
+			return reverted, err
+		}
+		for _, s := range state {
+			if s.existed {
+				reverted = append(reverted, fmt.Sprintf("%s restored to its previous configuration", s.id))
+			}
+		}
+	}
+
+	return reverted, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestPreviousState(t *testing.T) {
+	tests := []struct {
+		description string
+		live        string
+		applied     []string
+		deleted     []string
+		expected    []resourceState
+	}{
+		{
+			description: "resource that doesn't exist is created",
+			applied:     []string{"apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\n"},
+			expected:    []resourceState{{id: "Pod/app", manifest: []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\n")}},
+		},
+		{
+			description: "live resource without last applied configuration is stripped of its status and server-side metadata",
+			live: `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"app","namespace":"ns","uid":"1234","resourceVersion":"42",` +
+				`"annotations":{"deployment.kubernetes.io/revision":"3"}},"spec":{"restartPolicy":"Always"},"status":{"phase":"Running"}}`,
+			applied: []string{"apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\n"},
+			expected: []resourceState{{id: "Pod/app", existed: true, manifest: []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+  namespace: ns
+spec:
+  restartPolicy: Always
+`)}},
+		},
+		{
+			description: "resources are matched by group, kind, name and namespace",
+			live:        `{"kind":"List","items":[{"apiVersion":"v1","kind":"Pod","metadata":{"name":"app","namespace":"other"}},{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"app","namespace":"ns"}}]}`,
+			applied: []string{
+				"apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\n  namespace: ns\n",
+				"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n",
+			},
+			expected: []resourceState{
+				{id: "Pod/app", manifest: []byte("apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\n  namespace: ns\n")},
+				{id: "Deployment/app", existed: true, manifest: []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n  namespace: ns\n")},
+			},
+		},
+		{
+			description: "deleted resources are only restored if they exist",
+			live:        `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"kept"}}`,
+			deleted: []string{
+				"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: kept\n",
+				"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: gone\n",
+			},
+			expected: []resourceState{{id: "ConfigMap/kept", existed: true, manifest: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: kept\n")}},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			var applied, deleted manifest.ManifestList
+			for _, m := range test.applied {
+				applied = append(applied, []byte(m))
+			}
+			for _, m := range test.deleted {
+				deleted = append(deleted, []byte(m))
+			}
+
+			state, err := previousState([]byte(test.live), applied, deleted)
+
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, state, cmp.AllowUnexported(resourceState{}))
+		})
+	}
+}
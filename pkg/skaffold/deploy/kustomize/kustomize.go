@@ -20,11 +20,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 
 	"github.com/segmentio/textio"
+	"github.com/sirupsen/logrus"
 	yamlv3 "gopkg.in/yaml.v3"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/access"
@@ -37,9 +39,12 @@ import (
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/hooks"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	kstatus "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/status"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/loader"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/log"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
@@ -110,6 +115,7 @@ type Deployer struct {
 	debugger      debug.Debugger
 	statusMonitor status.Monitor
 	syncer        sync.Syncer
+	hookRunner    hooks.Runner
 
 	podSelector    *kubernetes.ImageList
 	originalImages []graph.Artifact // the set of images parsed from the Deployer's manifest set
@@ -150,7 +156,8 @@ func NewDeployer(cfg kubectl.Config, labeller *label.DefaultLabeller, d *latestV
 		imageLoader:         component.NewImageLoader(cfg, kubectl.CLI),
 		logger:              component.NewLogger(cfg, kubectl.CLI, podSelector, &namespaces),
 		statusMonitor:       component.NewMonitor(cfg, cfg.GetKubeContext(), labeller, &namespaces),
-		syncer:              component.NewSyncer(kubectl.CLI, &namespaces),
+		syncer:              component.NewSyncer(cfg, kubectl.CLI, &namespaces),
+		hookRunner:          hooks.DeployRunner(kubectl.CLI, d.LifecycleHooks, &namespaces, hooks.NewDeployEnvOpts(labeller.GetRunID(), kubectl.KubeContext, namespaces)),
 		kubectl:             kubectl,
 		insecureRegistries:  cfg.GetInsecureRegistries(),
 		globalConfig:        cfg.GlobalConfig(),
@@ -222,6 +229,10 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 		return fmt.Errorf("unable to connect to Kubernetes: %w", err)
 	}
 
+	if err := k.hookRunner.RunPreHooks(ctx, out); err != nil {
+		return fmt.Errorf("running pre-deploy hooks: %w", err)
+	}
+
 	childCtx, endTrace := instrumentation.StartTrace(ctx, "Deploy_renderManifests")
 	manifests, err := k.renderManifests(childCtx, out, builds)
 	if err != nil {
@@ -249,6 +260,11 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 			"This might cause port-forward and deploy health-check to fail: %w", err))
 	}
 	endTrace()
+	if kinds, err := manifests.CollectGroupKinds(); err != nil {
+		logrus.Debugf("could not collect deployed resource kinds: %v", err)
+	} else {
+		kstatus.TrackDeployedKinds(k.statusMonitor, kinds)
+	}
 
 	childCtx, endTrace = instrumentation.StartTrace(ctx, "Deploy_WaitForDeletions")
 	if err := k.kubectl.WaitForDeletions(childCtx, textio.NewPrefixWriter(out, " - "), manifests); err != nil {
@@ -267,6 +283,10 @@ func (k *Deployer) Deploy(ctx context.Context, out io.Writer, builds []graph.Art
 	endTrace()
 
 	k.trackNamespaces(namespaces)
+
+	if err := k.hookRunner.RunPostHooks(ctx, out); err != nil {
+		return fmt.Errorf("running post-deploy hooks: %w", err)
+	}
 	return nil
 }
 
@@ -326,6 +346,25 @@ func (k *Deployer) Cleanup(ctx context.Context, out io.Writer) error {
 	return nil
 }
 
+// Pruned returns the resources that deploying the build results deletes.
+func (k *Deployer) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	manifests, err := k.renderManifests(ctx, ioutil.Discard, builds)
+	if err != nil {
+		return nil, err
+	}
+	return diff.ResourcesOf(k.kubectl.Pruned(manifests))
+}
+
+// Rollback reverts the resources changed by the last deployment to their previous state.
+func (k *Deployer) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	return k.kubectl.Rollback(ctx, out)
+}
+
+// RollbackOnFailure tells whether the deployer's config enables rollback on failure.
+func (k *Deployer) RollbackOnFailure() bool {
+	return k.kubectl.RollbackOnFailure()
+}
+
 // Dependencies lists all the files that describe what needs to be deployed.
 func (k *Deployer) Dependencies() ([]string, error) {
 	deps := util.NewStringSet()
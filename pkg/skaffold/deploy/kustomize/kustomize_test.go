@@ -730,3 +730,4 @@ func (c *kustomizeConfig) WorkingDir() string
 func (c *kustomizeConfig) GetKubeContext() string                                { return kubectl.TestKubeContext }
 func (c *kustomizeConfig) GetKubeNamespace() string                              { return c.Opts.Namespace }
 func (c *kustomizeConfig) PortForwardResources() []*latestV1.PortForwardResource { return nil }
+func (c *kustomizeConfig) RollbackOnFailure() bool                               { return false }
@@ -0,0 +1,48 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
+)
+
+// Pruner is implemented by deployers that delete the resources they deployed before,
+// when they are no longer part of what they deploy.
+type Pruner interface {
+	// Pruned returns the resources that deploying the given build results would delete.
+	Pruned(context.Context, []graph.Artifact) ([]diff.Resource, error)
+}
+
+// Pruned returns the resources that deploying would delete, across all the deployers.
+func (m DeployerMux) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	var pruned []diff.Resource
+	for _, deployer := range m.deployers {
+		p, ok := deployer.(Pruner)
+		if !ok {
+			continue
+		}
+		resources, err := p.Pruned(ctx, builds)
+		if err != nil {
+			return nil, err
+		}
+		pruned = append(pruned, resources...)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+type mockPruner struct {
+	*MockDeployer
+	pruned   []diff.Resource
+	pruneErr error
+}
+
+func (m *mockPruner) Pruned(context.Context, []graph.Artifact) ([]diff.Resource, error) {
+	return m.pruned, m.pruneErr
+}
+
+func TestDeployerMux_Pruned(t *testing.T) {
+	tests := []struct {
+		description    string
+		deployers      []Deployer
+		expectedPruned []diff.Resource
+		shouldErr      bool
+	}{
+		{
+			description: "pruned resources of all deployers",
+			deployers: []Deployer{
+				&mockPruner{MockDeployer: NewMockDeployer(), pruned: []diff.Resource{{Kind: "Service", Name: "first"}}},
+				NewMockDeployer(),
+				&mockPruner{MockDeployer: NewMockDeployer(), pruned: []diff.Resource{{Group: "apps", Kind: "Deployment", Name: "second"}}},
+			},
+			expectedPruned: []diff.Resource{{Kind: "Service", Name: "first"}, {Group: "apps", Kind: "Deployment", Name: "second"}},
+		},
+		{
+			description: "deployer fails",
+			deployers: []Deployer{
+				&mockPruner{MockDeployer: NewMockDeployer(), pruneErr: errors.New("failed")},
+			},
+			shouldErr: true,
+		},
+		{
+			description: "nothing to prune",
+			deployers:   []Deployer{NewMockDeployer()},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			pruned, err := NewDeployerMux(test.deployers, false).(DeployerMux).Pruned(context.Background(), nil)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expectedPruned, pruned)
+		})
+	}
+}
@@ -18,11 +18,13 @@ package resource
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/diag"
 	"github.com/GoogleContainerTools/skaffold/pkg/diag/validator"
@@ -34,8 +36,6 @@ import (
 )
 
 const (
-	deploymentType          = "deployment"
-	rollOutSuccess          = "successfully rolled out"
 	connectionErrMsg        = "Unable to connect to the server"
 	killedErrMsg            = "signal: killed"
 	defaultPodCheckDeadline = 30 * time.Second
@@ -44,7 +44,32 @@ const (
 	maxLogLines             = 3
 )
 
+// Type is the type of a Kubernetes resource whose status is checked.
+type Type string
+
+// ResourceTypes lists the supported resource types.
+var ResourceTypes = struct {
+	Deployment     Type
+	StatefulSet    Type
+	DaemonSet      Type
+	Job            Type
+	CustomResource Type
+}{
+	Deployment:     "deployment",
+	StatefulSet:    "statefulset",
+	DaemonSet:      "daemonset",
+	Job:            "job",
+	CustomResource: "customresource",
+}
+
 var (
+	// rollOutSuccess lists the `kubectl rollout status` messages of complete rollouts.
+	rollOutSuccess = []string{
+		"successfully rolled out",       // deployments and daemonsets
+		"rolling update complete",       // statefulsets
+		"partitioned roll out complete", // statefulsets with a partition
+	}
+
 	msgKubectlKilled     = "kubectl rollout status command interrupted\n"
 	MsgKubectlConnection = "kubectl connection error\n"
 
@@ -56,13 +81,13 @@ var (
 	}
 )
 
-type Group map[string]*Deployment
+type Group map[string]*Resource
 
-func (r Group) Add(d *Deployment) {
+func (r Group) Add(d *Resource) {
 	r[d.ID()] = d
 }
 
-func (r Group) Contains(d *Deployment) bool {
+func (r Group) Contains(d *Resource) bool {
 	_, found := r[d.ID()]
 	return found
 }
@@ -73,10 +98,13 @@ func (r Group) Reset() {
 	}
 }
 
-type Deployment struct {
+// Resource is a Kubernetes workload, or custom resource, whose status is checked
+// until it is ready, it fails or the status check deadline is exceeded.
+type Resource struct {
 	name         string
 	namespace    string
-	rType        string
+	rType        Type
+	kind         string // the resource in `kubectl` commands, e.g. `deployment` or `widgets.example.com`
 	status       Status
 	statusCode   proto.StatusCode
 	done         bool
@@ -85,15 +113,15 @@ type Deployment struct {
 	podValidator diag.Diagnose
 }
 
-func (d *Deployment) ID() string {
-	return fmt.Sprintf("%s:%s:%s", d.name, d.namespace, d.rType)
+func (d *Resource) ID() string {
+	return fmt.Sprintf("%s:%s:%s", d.name, d.namespace, d.kind)
 }
 
-func (d *Deployment) Deadline() time.Duration {
+func (d *Resource) Deadline() time.Duration {
 	return d.deadline
 }
 
-func (d *Deployment) UpdateStatus(ae proto.ActionableErr) {
+func (d *Resource) UpdateStatus(ae proto.ActionableErr) {
 	updated := newStatus(ae)
 	if d.status.Equal(updated) {
 		d.status.changed = false
@@ -107,64 +135,199 @@ func (d *Deployment) UpdateStatus(ae proto.ActionableErr) {
 	}
 }
 
-func NewDeployment(name string, ns string, deadline time.Duration) *Deployment {
-	return &Deployment{
+// NewResource creates a resource of one of the built-in workload types.
+func NewResource(name string, rType Type, ns string, deadline time.Duration) *Resource {
+	return &Resource{
 		name:         name,
 		namespace:    ns,
-		rType:        deploymentType,
+		rType:        rType,
+		kind:         string(rType),
 		status:       newStatus(proto.ActionableErr{}),
 		deadline:     deadline,
 		podValidator: diag.New(nil),
 	}
 }
 
-func (d *Deployment) WithValidator(pd diag.Diagnose) *Deployment {
+func NewDeployment(name string, ns string, deadline time.Duration) *Resource {
+	return NewResource(name, ResourceTypes.Deployment, ns, deadline)
+}
+
+// NewCustomResource creates a custom resource, which is ready once its `Ready` status condition is true.
+func NewCustomResource(name string, ns string, gvr schema.GroupVersionResource, deadline time.Duration) *Resource {
+	r := NewResource(name, ResourceTypes.CustomResource, ns, deadline)
+	r.kind = gvr.GroupResource().String()
+	return r
+}
+
+func (d *Resource) WithValidator(pd diag.Diagnose) *Resource {
 	d.podValidator = pd
 	return d
 }
 
-func (d *Deployment) CheckStatus(ctx context.Context, cfg kubectl.Config) {
+func (d *Resource) CheckStatus(ctx context.Context, cfg kubectl.Config) {
 	kubeCtl := kubectl.NewCLI(cfg, "")
 
-	b, err := kubeCtl.RunOut(ctx, "rollout", "status", "deployment", d.name, "--namespace", d.namespace, "--watch=false")
+	var ae proto.ActionableErr
+	switch d.rType {
+	case ResourceTypes.Job:
+		ae = d.checkJobStatus(ctx, kubeCtl)
+	case ResourceTypes.CustomResource:
+		ae = d.checkReadyCondition(ctx, kubeCtl)
+	default:
+		ae = d.checkRolloutStatus(ctx, kubeCtl)
+	}
 	if ctx.Err() != nil {
 		return
 	}
 
+	d.UpdateStatus(ae)
+	if err := d.fetchPods(ctx); err != nil {
+		logrus.Debugf("pod statuses could be fetched this time due to %s", err)
+	}
+}
+
+func (d *Resource) checkRolloutStatus(ctx context.Context, kubeCtl *kubectl.CLI) proto.ActionableErr {
+	b, err := kubeCtl.RunOut(ctx, "rollout", "status", d.kind, d.name, "--namespace", d.namespace, "--watch=false")
 	details := d.cleanupStatus(string(b))
 
 	ae := parseKubectlRolloutError(details, err)
 	if ae.ErrCode == proto.StatusCode_STATUSCHECK_KUBECTL_PID_KILLED {
-		ae.Message = fmt.Sprintf("received Ctrl-C or deployments could not stabilize within %v: %v", d.deadline, err)
+		ae.Message = fmt.Sprintf("received Ctrl-C or %ss could not stabilize within %v: %v", d.kind, d.deadline, err)
 	}
+	return ae
+}
 
-	d.UpdateStatus(ae)
-	if err := d.fetchPods(ctx); err != nil {
-		logrus.Debugf("pod statuses could be fetched this time due to %s", err)
+// resourceStatus holds the status fields read from Jobs and custom resources.
+type resourceStatus struct {
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+	Status struct {
+		ObservedGeneration int64 `json:"observedGeneration"`
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+		Active    int `json:"active"`
+		Succeeded int `json:"succeeded"`
+	} `json:"status"`
+}
+
+func (d *Resource) getStatus(ctx context.Context, kubeCtl *kubectl.CLI) (resourceStatus, *proto.ActionableErr) {
+	var rs resourceStatus
+	b, err := kubeCtl.RunOut(ctx, "get", d.kind, d.name, "--namespace", d.namespace, "-o", "json")
+	if err != nil {
+		ae := parseKubectlRolloutError("", err)
+		if ae.ErrCode == proto.StatusCode_STATUSCHECK_KUBECTL_PID_KILLED {
+			ae.Message = fmt.Sprintf("received Ctrl-C or %ss could not stabilize within %v: %v", d.kind, d.deadline, err)
+		}
+		return rs, &ae
 	}
+	if err := json.Unmarshal(b, &rs); err != nil {
+		return rs, &proto.ActionableErr{
+			ErrCode: proto.StatusCode_STATUSCHECK_UNKNOWN,
+			Message: fmt.Sprintf("parsing status of %s: %v", d, err),
+		}
+	}
+	return rs, nil
 }
 
-func (d *Deployment) String() string {
+// checkJobStatus waits for a Job to complete.
+func (d *Resource) checkJobStatus(ctx context.Context, kubeCtl *kubectl.CLI) proto.ActionableErr {
+	rs, ae := d.getStatus(ctx, kubeCtl)
+	if ae != nil {
+		return *ae
+	}
+	for _, c := range rs.Status.Conditions {
+		if c.Status != "True" {
+			continue
+		}
+		switch c.Type {
+		case "Complete":
+			return proto.ActionableErr{
+				ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS,
+				Message: "successfully completed",
+			}
+		case "Failed":
+			return proto.ActionableErr{
+				ErrCode: proto.StatusCode_STATUSCHECK_UNHEALTHY,
+				Message: conditionMessage("job failed", c.Reason, c.Message),
+			}
+		}
+	}
+	return proto.ActionableErr{
+		ErrCode: proto.StatusCode_STATUSCHECK_DEPLOYMENT_ROLLOUT_PENDING,
+		Message: fmt.Sprintf("waiting for job to complete: %d active, %d succeeded", rs.Status.Active, rs.Status.Succeeded),
+	}
+}
+
+// checkReadyCondition waits for the `Ready` status condition of a custom resource to be true.
+// Custom resources which don't report a `Ready` condition are ready once their controller
+// has observed their latest generation.
+func (d *Resource) checkReadyCondition(ctx context.Context, kubeCtl *kubectl.CLI) proto.ActionableErr {
+	rs, ae := d.getStatus(ctx, kubeCtl)
+	if ae != nil {
+		return *ae
+	}
+	for _, c := range rs.Status.Conditions {
+		if c.Type != "Ready" {
+			continue
+		}
+		if c.Status == "True" {
+			return proto.ActionableErr{
+				ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS,
+				Message: "ready",
+			}
+		}
+		return proto.ActionableErr{
+			ErrCode: proto.StatusCode_STATUSCHECK_DEPLOYMENT_ROLLOUT_PENDING,
+			Message: conditionMessage("not ready", c.Reason, c.Message),
+		}
+	}
+	if rs.Metadata.Generation > 0 && rs.Status.ObservedGeneration == rs.Metadata.Generation {
+		return proto.ActionableErr{
+			ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS,
+			Message: "ready",
+		}
+	}
+	return proto.ActionableErr{
+		ErrCode: proto.StatusCode_STATUSCHECK_DEPLOYMENT_ROLLOUT_PENDING,
+		Message: "waiting for the resource to be reconciled",
+	}
+}
+
+func conditionMessage(prefix, reason, message string) string {
+	for _, s := range []string{reason, message} {
+		if s != "" {
+			prefix = fmt.Sprintf("%s: %s", prefix, s)
+		}
+	}
+	return prefix
+}
+
+func (d *Resource) String() string {
 	if d.namespace == "default" {
-		return fmt.Sprintf("%s/%s", d.rType, d.name)
+		return fmt.Sprintf("%s/%s", d.kind, d.name)
 	}
 
-	return fmt.Sprintf("%s:%s/%s", d.namespace, d.rType, d.name)
+	return fmt.Sprintf("%s:%s/%s", d.namespace, d.kind, d.name)
 }
 
-func (d *Deployment) Name() string {
+func (d *Resource) Name() string {
 	return d.name
 }
 
-func (d *Deployment) Status() Status {
+func (d *Resource) Status() Status {
 	return d.status
 }
 
-func (d *Deployment) IsStatusCheckCompleteOrCancelled() bool {
+func (d *Resource) IsStatusCheckCompleteOrCancelled() bool {
 	return d.done || d.statusCode == proto.StatusCode_STATUSCHECK_USER_CANCELLED
 }
 
-func (d *Deployment) StatusMessage() string {
+func (d *Resource) StatusMessage() string {
 	for _, p := range d.pods {
 		if s := p.ActionableError(); s.ErrCode != proto.StatusCode_STATUSCHECK_SUCCESS {
 			return fmt.Sprintf("%s\n", s.Message)
@@ -173,15 +336,15 @@ func (d *Deployment) StatusMessage() string {
 	return d.status.String()
 }
 
-func (d *Deployment) MarkComplete() {
+func (d *Resource) MarkComplete() {
 	d.done = true
 }
 
 // ReportSinceLastUpdated returns a string representing deployment status along with tab header
 // e.g.
-//  - testNs:deployment/leeroy-app: waiting for rollout to complete. (1/2) pending
-//      - testNs:pod/leeroy-app-xvbg : error pulling container image
-func (d *Deployment) ReportSinceLastUpdated(isMuted bool) string {
+//   - testNs:deployment/leeroy-app: waiting for rollout to complete. (1/2) pending
+//   - testNs:pod/leeroy-app-xvbg : error pulling container image
+func (d *Resource) ReportSinceLastUpdated(isMuted bool) string {
 	if d.status.reported && !d.status.changed {
 		return ""
 	}
@@ -218,8 +381,11 @@ func (d *Deployment) ReportSinceLastUpdated(isMuted bool) string {
 	return fmt.Sprintf("%s %s: %s%s", tabHeader, d, d.StatusMessage(), result.String())
 }
 
-func (d *Deployment) cleanupStatus(msg string) string {
-	clean := strings.ReplaceAll(msg, `deployment "`+d.Name()+`" `, "")
+func (d *Resource) cleanupStatus(msg string) string {
+	clean := msg
+	for _, kind := range []string{d.kind, "daemon set"} {
+		clean = strings.ReplaceAll(clean, kind+` "`+d.Name()+`" `, "")
+	}
 	if len(clean) > 0 {
 		clean = strings.ToLower(clean[0:1]) + clean[1:]
 	}
@@ -236,7 +402,7 @@ func (d *Deployment) cleanupStatus(msg string) string {
 // Killed: 9
 func parseKubectlRolloutError(details string, err error) proto.ActionableErr {
 	switch {
-	case err == nil && strings.Contains(details, rollOutSuccess):
+	case err == nil && isRolloutComplete(details):
 		return proto.ActionableErr{
 			ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS,
 			Message: details,
@@ -264,14 +430,27 @@ func parseKubectlRolloutError(details string, err error) proto.ActionableErr {
 	}
 }
 
+func isRolloutComplete(details string) bool {
+	for _, msg := range rollOutSuccess {
+		if strings.Contains(details, msg) {
+			return true
+		}
+	}
+	return false
+}
+
 func isErrAndNotRetryAble(statusCode proto.StatusCode) bool {
 	return statusCode != proto.StatusCode_STATUSCHECK_KUBECTL_CONNECTION_ERR &&
 		statusCode != proto.StatusCode_STATUSCHECK_DEPLOYMENT_ROLLOUT_PENDING
 }
 
 // HasEncounteredUnrecoverableError goes through all pod statuses and return true
-// if any cannot be recovered
-func (d *Deployment) HasEncounteredUnrecoverableError() bool {
+// if any cannot be recovered.
+// Failed Job pods are retried until the backoff limit, so only the Job status is considered for Jobs.
+func (d *Resource) HasEncounteredUnrecoverableError() bool {
+	if d.rType == ResourceTypes.Job {
+		return false
+	}
 	for _, p := range d.pods {
 		if _, ok := nonRetryContainerErrors[p.ActionableError().ErrCode]; ok {
 			return true
@@ -280,7 +459,7 @@ func (d *Deployment) HasEncounteredUnrecoverableError() bool {
 	return false
 }
 
-func (d *Deployment) fetchPods(ctx context.Context) error {
+func (d *Resource) fetchPods(ctx context.Context) error {
 	timeoutContext, cancel := context.WithTimeout(ctx, defaultPodCheckDeadline)
 	defer cancel()
 	pods, err := d.podValidator.Run(timeoutContext)
@@ -313,7 +492,7 @@ func (d *Deployment) fetchPods(ctx context.Context) error {
 // StatusCode() returns the deployment status code if the status check is cancelled
 // or if no pod data exists for this deployment.
 // If pods are fetched, this function returns the error code a pod container encountered.
-func (d *Deployment) StatusCode() proto.StatusCode {
+func (d *Resource) StatusCode() proto.StatusCode {
 	// do not process pod status codes if another deployment failed
 	// or the user aborted the run.
 	if d.statusCode == proto.StatusCode_STATUSCHECK_USER_CANCELLED {
@@ -327,7 +506,7 @@ func (d *Deployment) StatusCode() proto.StatusCode {
 	return d.statusCode
 }
 
-func (d *Deployment) WithPodStatuses(scs []proto.StatusCode) *Deployment {
+func (d *Resource) WithPodStatuses(scs []proto.StatusCode) *Resource {
 	d.pods = map[string]validator.Resource{}
 	for i, s := range scs {
 		name := fmt.Sprintf("%s-%d", d.name, i)
@@ -24,6 +24,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	"github.com/GoogleContainerTools/skaffold/pkg/diag/validator"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
@@ -120,6 +122,153 @@ func TestDeploymentCheckStatus(t *testing.T) {
 	}
 }
 
+func TestResourceCheckStatus(t *testing.T) {
+	tests := []struct {
+		description     string
+		resource        *Resource
+		commands        util.Command
+		expectedErr     string
+		expectedDetails string
+		complete        bool
+	}{
+		{
+			description: "statefulset rollout complete",
+			resource:    NewResource("db", ResourceTypes.StatefulSet, "test", 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext rollout status statefulset db --namespace test --watch=false",
+				"statefulset rolling update complete 3 pods at revision db-7f8b8c9d...",
+			),
+			expectedDetails: "statefulset rolling update complete 3 pods at revision db-7f8b8c9d...",
+			complete:        true,
+		},
+		{
+			description: "daemonset rollout pending",
+			resource:    NewResource("agent", ResourceTypes.DaemonSet, "test", 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext rollout status daemonset agent --namespace test --watch=false",
+				`Waiting for daemon set "agent" rollout to finish: 1 of 2 updated pods are available...`,
+			),
+			expectedDetails: "waiting for rollout to finish: 1 of 2 updated pods are available...",
+		},
+		{
+			description: "daemonset rollout complete",
+			resource:    NewResource("agent", ResourceTypes.DaemonSet, "test", 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext rollout status daemonset agent --namespace test --watch=false",
+				`daemon set "agent" successfully rolled out`,
+			),
+			expectedDetails: "successfully rolled out",
+			complete:        true,
+		},
+		{
+			description: "job running",
+			resource:    NewResource("migrate", ResourceTypes.Job, "test", 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get job migrate --namespace test -o json",
+				`{"status": {"active": 1}}`,
+			),
+			expectedDetails: "waiting for job to complete: 1 active, 0 succeeded",
+		},
+		{
+			description: "job complete",
+			resource:    NewResource("migrate", ResourceTypes.Job, "test", 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get job migrate --namespace test -o json",
+				`{"status": {"succeeded": 1, "conditions": [{"type": "Complete", "status": "True"}]}}`,
+			),
+			expectedDetails: "successfully completed",
+			complete:        true,
+		},
+		{
+			description: "job failed",
+			resource:    NewResource("migrate", ResourceTypes.Job, "test", 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get job migrate --namespace test -o json",
+				`{"status": {"failed": 4, "conditions": [{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded", "message": "Job has reached the specified backoff limit"}]}}`,
+			),
+			expectedErr: "job failed: BackoffLimitExceeded: Job has reached the specified backoff limit",
+			complete:    true,
+		},
+		{
+			description: "custom resource ready",
+			resource:    NewCustomResource("cache", "test", schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "redis"}, 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get redis.example.com cache --namespace test -o json",
+				`{"status": {"conditions": [{"type": "Ready", "status": "True"}]}}`,
+			),
+			expectedDetails: "ready",
+			complete:        true,
+		},
+		{
+			description: "custom resource not ready",
+			resource:    NewCustomResource("cache", "test", schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "redis"}, 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get redis.example.com cache --namespace test -o json",
+				`{"status": {"conditions": [{"type": "Ready", "status": "False", "reason": "Provisioning"}]}}`,
+			),
+			expectedDetails: "not ready: Provisioning",
+		},
+		{
+			description: "custom resource without status",
+			resource:    NewCustomResource("cache", "test", schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "redis"}, 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get redis.example.com cache --namespace test -o json",
+				`{"metadata": {"name": "cache", "generation": 1}}`,
+			),
+			expectedDetails: "waiting for the resource to be reconciled",
+		},
+		{
+			description: "custom resource without Ready condition",
+			resource:    NewCustomResource("cache", "test", schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "redis"}, 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get redis.example.com cache --namespace test -o json",
+				`{"metadata": {"generation": 2}, "status": {"observedGeneration": 1, "conditions": [{"type": "Synced", "status": "True"}]}}`,
+			),
+			expectedDetails: "waiting for the resource to be reconciled",
+		},
+		{
+			description: "custom resource without Ready condition, reconciled",
+			resource:    NewCustomResource("cache", "test", schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "redis"}, 0),
+			commands: testutil.CmdRunOut(
+				"kubectl --context kubecontext get redis.example.com cache --namespace test -o json",
+				`{"metadata": {"generation": 2}, "status": {"observedGeneration": 2}}`,
+			),
+			expectedDetails: "ready",
+			complete:        true,
+		},
+		{
+			description: "kubectl connection error",
+			resource:    NewResource("migrate", ResourceTypes.Job, "test", 0),
+			commands: testutil.CmdRunOutErr(
+				"kubectl --context kubecontext get job migrate --namespace test -o json",
+				"",
+				errors.New("Unable to connect to the server"),
+			),
+			expectedErr: MsgKubectlConnection,
+		},
+	}
+
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&util.DefaultExecCommand, test.commands)
+
+			test.resource.CheckStatus(context.Background(), &statusConfig{})
+
+			t.CheckDeepEqual(test.complete, test.resource.IsStatusCheckCompleteOrCancelled())
+			if test.expectedErr != "" {
+				t.CheckErrorContains(test.expectedErr, test.resource.Status().Error())
+			} else {
+				t.CheckDeepEqual(test.expectedDetails, test.resource.status.ae.Message)
+			}
+		})
+	}
+}
+
+func TestResourceString(t *testing.T) {
+	testutil.CheckDeepEqual(t, "statefulset/db", NewResource("db", ResourceTypes.StatefulSet, "default", 0).String())
+	testutil.CheckDeepEqual(t, "test:redis.example.com/cache", NewCustomResource("cache", "test", schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "redis"}, 0).String())
+}
+
 func TestParseKubectlError(t *testing.T) {
 	tests := []struct {
 		description string
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
+	eventV2 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/event/v2"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+)
+
+// Rollbacker is implemented by deployers that can revert their last deployment.
+type Rollbacker interface {
+	// Rollback reverts the resources changed by the last Deploy to their previous state,
+	// and returns a description of each reverted change.
+	// It does nothing when rollback is not enabled for the deployer.
+	Rollback(context.Context, io.Writer) ([]string, error)
+
+	// RollbackOnFailure tells whether the deployer's config enables rollback on failure.
+	RollbackOnFailure() bool
+}
+
+// RollbackFailedDeploy reverts the last deployment of a deployer, after it failed or its resources
+// failed to stabilize, and prints a summary of what was reverted.
+func RollbackFailedDeploy(ctx context.Context, out io.Writer, d Deployer) error {
+	r, ok := d.(Rollbacker)
+	if !ok || !r.RollbackOnFailure() {
+		return nil
+	}
+
+	out = output.WithEventContext(out, constants.Rollback, eventV2.SubtaskIDNone, "skaffold")
+	eventV2.TaskInProgress(constants.Rollback, "Revert the failed deployment")
+	output.Yellow.Fprintln(out, "Rolling back the failed deployment...")
+
+	reverted, err := r.Rollback(ctx, out)
+	switch {
+	case len(reverted) > 0:
+		output.Default.Fprintln(out, "Reverted:")
+		for _, change := range reverted {
+			output.Default.Fprintf(out, " - %s\n", change)
+		}
+	case err == nil:
+		output.Default.Fprintln(out, "Nothing to revert")
+	}
+
+	if err != nil {
+		eventV2.TaskFailed(constants.Rollback, err)
+		return fmt.Errorf("rolling back the failed deployment: %w", err)
+	}
+	eventV2.TaskSucceeded(constants.Rollback)
+	return nil
+}
+
+// RollbackOnFailure tells whether at least one of the deployers is rolled back on failure.
+func (m DeployerMux) RollbackOnFailure() bool {
+	for _, deployer := range m.deployers {
+		if r, ok := deployer.(Rollbacker); ok && r.RollbackOnFailure() {
+			return true
+		}
+	}
+	return false
+}
+
+// Rollback reverts the last deployment of each deployer that is rolled back on failure,
+// in the reverse order of deployment. All the deployers are rolled back, even if some of them fail to.
+func (m DeployerMux) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	var reverted []string
+	var firstErr error
+	for i := len(m.deployers) - 1; i >= 0; i-- {
+		r, ok := m.deployers[i].(Rollbacker)
+		if !ok || !r.RollbackOnFailure() {
+			continue
+		}
+		changes, err := r.Rollback(ctx, out)
+		reverted = append(reverted, changes...)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return reverted, firstErr
+}
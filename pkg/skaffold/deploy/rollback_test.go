@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+	testEvent "github.com/GoogleContainerTools/skaffold/testutil/event"
+)
+
+type mockRollbacker struct {
+	*MockDeployer
+	enabled     bool
+	reverted    []string
+	rollbackErr error
+}
+
+func (m *mockRollbacker) Rollback(context.Context, io.Writer) ([]string, error) {
+	return m.reverted, m.rollbackErr
+}
+
+func (m *mockRollbacker) RollbackOnFailure() bool {
+	return m.enabled
+}
+
+func TestDeployerMux_Rollback(t *testing.T) {
+	tests := []struct {
+		description      string
+		deployers        []Deployer
+		expectedReverted []string
+		shouldErr        bool
+	}{
+		{
+			description: "deployers are rolled back in reverse order",
+			deployers: []Deployer{
+				&mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, reverted: []string{"first"}},
+				NewMockDeployer(),
+				&mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, reverted: []string{"second", "third"}},
+			},
+			expectedReverted: []string{"second", "third", "first"},
+		},
+		{
+			description: "all deployers are rolled back even if one fails",
+			deployers: []Deployer{
+				&mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, reverted: []string{"first"}},
+				&mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, rollbackErr: errors.New("failed")},
+			},
+			expectedReverted: []string{"first"},
+			shouldErr:        true,
+		},
+		{
+			description: "deployers with rollback disabled are not rolled back",
+			deployers: []Deployer{
+				&mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, reverted: []string{"first"}},
+				&mockRollbacker{MockDeployer: NewMockDeployer(), reverted: []string{"second"}},
+			},
+			expectedReverted: []string{"first"},
+		},
+		{
+			description: "nothing to revert",
+			deployers:   []Deployer{NewMockDeployer()},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			reverted, err := NewDeployerMux(test.deployers, false).(DeployerMux).Rollback(context.Background(), &bytes.Buffer{})
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expectedReverted, reverted)
+		})
+	}
+}
+
+func TestRollbackFailedDeploy(t *testing.T) {
+	tests := []struct {
+		description    string
+		deployer       Deployer
+		shouldErr      bool
+		expectedOutput string
+	}{
+		{
+			description: "summary of the reverted changes",
+			deployer:    &mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, reverted: []string{"Pod/app deleted", "Pod/web restored to its previous configuration"}},
+			expectedOutput: `Rolling back the failed deployment...
+Reverted:
+ - Pod/app deleted
+ - Pod/web restored to its previous configuration
+`,
+		},
+		{
+			description:    "nothing to revert",
+			deployer:       &mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true},
+			expectedOutput: "Rolling back the failed deployment...\nNothing to revert\n",
+		},
+		{
+			description:    "partial rollback",
+			deployer:       &mockRollbacker{MockDeployer: NewMockDeployer(), enabled: true, reverted: []string{"Pod/app deleted"}, rollbackErr: errors.New("failed")},
+			shouldErr:      true,
+			expectedOutput: "Rolling back the failed deployment...\nReverted:\n - Pod/app deleted\n",
+		},
+		{
+			description: "rollback disabled",
+			deployer:    &mockRollbacker{MockDeployer: NewMockDeployer(), reverted: []string{"Pod/app deleted"}},
+		},
+		{
+			description: "deployer that can't be rolled back",
+			deployer:    NewMockDeployer(),
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			testEvent.InitializeState([]latestV1.Pipeline{{
+				Build: latestV1.BuildConfig{
+					BuildType: latestV1.BuildType{
+						LocalBuild: &latestV1.LocalBuild{},
+					},
+				}}})
+			var out bytes.Buffer
+
+			err := RollbackFailedDeploy(context.Background(), &out, test.deployer)
+
+			t.CheckError(test.shouldErr, err)
+			t.CheckDeepEqual(test.expectedOutput, out.String())
+		})
+	}
+}
@@ -110,6 +110,10 @@ func typeOfArtifact(a *latestV1.Artifact) string {
 		return "Custom artifact"
 	case a.BuildpackArtifact != nil:
 		return "Buildpack artifact"
+	case a.KoArtifact != nil:
+		return "Ko artifact"
+	case a.BuildKitArtifact != nil:
+		return "BuildKit artifact"
 	default:
 		panic("Unknown artifact")
 	}
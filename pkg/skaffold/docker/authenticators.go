@@ -29,6 +29,11 @@ var primaryKeychain = &Keychain{
 	configDir: configDir,
 }
 
+// PrimaryKeychain returns the keychain used to authenticate with remote registries.
+func PrimaryKeychain() authn.Keychain {
+	return primaryKeychain
+}
+
 // Keychain stores an authenticator per registry.
 type Keychain struct {
 	configDir  string
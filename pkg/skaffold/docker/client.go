@@ -40,6 +40,9 @@ import (
 const minikubeDriverConfictExitCode = 51
 const oldMinikubeBadUsageExitCode = 64
 
+// minikube exits with this code when `docker-env` is used with a non-docker container runtime
+const minikubeUsageExitCode = 14
+
 // For testing
 var (
 	NewAPIClient = NewAPIClientImpl
@@ -110,8 +113,10 @@ func newMinikubeAPIClient(minikubeProfile string) ([]string, client.CommonAPICli
 		// When minikube uses the infamous `none` driver, `minikube docker-env` will exit with
 		// code 51 (>= 1.13.0) or 64 (< 1.13.0).  Note that exit code 51 was unused prior to 1.13.0
 		// so it is safe to check here without knowing the minikube version.
+		// With the containerd or cri-o runtimes, it exits with code 14 and images are
+		// side-loaded into the cluster after being built by the local docker daemon.
 		var exitError ExitCoder
-		if errors.As(err, &exitError) && (exitError.ExitCode() == minikubeDriverConfictExitCode || exitError.ExitCode() == oldMinikubeBadUsageExitCode) {
+		if errors.As(err, &exitError) && (exitError.ExitCode() == minikubeDriverConfictExitCode || exitError.ExitCode() == oldMinikubeBadUsageExitCode || exitError.ExitCode() == minikubeUsageExitCode) {
 			// Let's ignore the error and fall back to local docker daemon.
 			logrus.Warnf("Could not get minikube docker env, falling back to local docker daemon: %s", err)
 			return newEnvAPIClient()
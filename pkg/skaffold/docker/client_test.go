@@ -143,6 +143,10 @@ DOCKER_HOST`),
 			description: "minikube exit code 51 (minikube >= 1.13.0) - fallback to host docker",
 			command:     testutil.CmdRunOutErr("minikube docker-env --shell none -p minikube", "", fmt.Errorf("fail: %w", &driverConflictErr{})),
 		},
+		{
+			description: "minikube exit code 14 (containerd runtime) - fallback to host docker",
+			command:     testutil.CmdRunOutErr("minikube docker-env --shell none -p minikube", "", fmt.Errorf("fail: %w", &usageErr{})),
+		},
 	}
 	for _, test := range tests {
 		testutil.Run(t, test.description, func(t *testutil.T) {
@@ -168,6 +172,12 @@ type driverConflictErr struct{}
 func (e *driverConflictErr) Error() string { return "driver conflict" }
 func (e *driverConflictErr) ExitCode() int { return 51 }
 
+// minikube returns exit code 14 (ExProgramUsage) on `minikube docker-env` with a non-docker container runtime
+type usageErr struct{}
+
+func (e *usageErr) Error() string { return "usage" }
+func (e *usageErr) ExitCode() int { return 14 }
+
 type fakeMinikubeClient struct{}
 
 func (fakeMinikubeClient) IsMinikube(string) bool { return false }
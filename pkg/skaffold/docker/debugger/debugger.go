@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug/annotations"
+	dockerutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+)
+
+var (
+	// For testing
+	transformImage                     = debug.TransformImage
+	notifyDebuggingContainerStarted    = event.DebuggingContainerStarted
+	notifyDebuggingContainerTerminated = event.DebuggingContainerTerminated
+)
+
+// DebugManager configures the containers deployed to the local Docker daemon for debugging,
+// and reports the debugging configuration of each of them once started.
+type DebugManager struct {
+	insecureRegistries   map[string]bool
+	debugHelpersRegistry string
+
+	lock           sync.Mutex
+	configurations map[string]annotations.ContainerDebugConfiguration // container name -> debugging configuration
+	started        bool
+}
+
+func NewDebugManager(insecureRegistries map[string]bool, debugHelpersRegistry string) *DebugManager {
+	return &DebugManager{
+		insecureRegistries:   insecureRegistries,
+		debugHelpersRegistry: debugHelpersRegistry,
+		configurations:       make(map[string]annotations.ContainerDebugConfiguration),
+	}
+}
+
+// TransformImage rewrites the entrypoint, command and environment used to create the container
+// for the given artifact to enable debugging.
+// Returns the debugging configuration of the container, and the ID of the debugging support
+// image that must populate `/dbg` in the container, if any.
+func (d *DebugManager) TransformImage(ctx context.Context, artifact graph.Artifact, opts *dockerutil.ContainerCreateOpts) (annotations.ContainerDebugConfiguration, string, error) {
+	container, configuration, supportImageID, err := transformImage(ctx, artifact, d.insecureRegistries)
+	if err != nil {
+		return annotations.ContainerDebugConfiguration{}, "", err
+	}
+
+	if len(container.Command) > 0 {
+		opts.Entrypoint = container.Command
+	}
+	if len(container.Args) > 0 {
+		opts.Cmd = container.Args
+	}
+	for _, env := range container.Env {
+		opts.Env = append(opts.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+	return configuration, supportImageID, nil
+}
+
+// SupportImage returns the image reference of the debugging support image with the given ID.
+func (d *DebugManager) SupportImage(id string) string {
+	return fmt.Sprintf("%s/%s", d.debugHelpersRegistry, id)
+}
+
+// AddContainer records the debugging configuration of a newly deployed container.
+func (d *DebugManager) AddContainer(name string, configuration annotations.ContainerDebugConfiguration) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if previous, found := d.configurations[name]; found && d.started {
+		notify(notifyDebuggingContainerTerminated, name, previous)
+	}
+	d.configurations[name] = configuration
+	if d.started {
+		notify(notifyDebuggingContainerStarted, name, configuration)
+	}
+}
+
+// RemoveContainer forgets the debugging configuration of a container, once it has been removed.
+func (d *DebugManager) RemoveContainer(name string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	configuration, found := d.configurations[name]
+	if !found {
+		return
+	}
+	delete(d.configurations, name)
+	if d.started {
+		notify(notifyDebuggingContainerTerminated, name, configuration)
+	}
+}
+
+// Start reports the debugging configuration of the containers deployed so far,
+// and of every container deployed from now on.
+func (d *DebugManager) Start(context.Context) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.started {
+		return nil
+	}
+	d.started = true
+
+	var names []string
+	for name := range d.configurations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		notify(notifyDebuggingContainerStarted, name, d.configurations[name])
+	}
+	return nil
+}
+
+func (d *DebugManager) Stop() {
+	d.lock.Lock()
+	d.started = false
+	d.lock.Unlock()
+}
+
+func (d *DebugManager) Name() string {
+	return "Docker Debug Manager"
+}
+
+func notify(notifyFunc func(podName, containerName, namespace, artifact, runtime, workingDir string, debugPorts map[string]uint32), name string, configuration annotations.ContainerDebugConfiguration) {
+	notifyFunc("", name, "", configuration.Artifact, configuration.Runtime, configuration.WorkingDir, configuration.Ports)
+}
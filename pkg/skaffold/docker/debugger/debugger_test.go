@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/debug/annotations"
+	dockerutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestTransformImage(t *testing.T) {
+	tests := []struct {
+		description string
+		container   *v1.Container
+		shouldErr   bool
+		opts        dockerutil.ContainerCreateOpts
+	}{
+		{
+			description: "entrypoint and env",
+			container: &v1.Container{
+				Command: []string{"/dbg/go/bin/dlv", "exec", "app"},
+				Env:     []v1.EnvVar{{Name: "GOTRACEBACK", Value: "single"}},
+			},
+			opts: dockerutil.ContainerCreateOpts{
+				Name:       "image",
+				Entrypoint: []string{"/dbg/go/bin/dlv", "exec", "app"},
+				Env:        []string{"GOTRACEBACK=single"},
+			},
+		},
+		{
+			description: "args only",
+			container:   &v1.Container{Args: []string{"node", "--inspect=9229", "index.js"}},
+			opts: dockerutil.ContainerCreateOpts{
+				Name: "image",
+				Cmd:  []string{"node", "--inspect=9229", "index.js"},
+			},
+		},
+		{
+			description: "not debuggable",
+			shouldErr:   true,
+			opts:        dockerutil.ContainerCreateOpts{Name: "image"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			config := annotations.ContainerDebugConfiguration{Runtime: "go"}
+			t.Override(&transformImage, func(context.Context, graph.Artifact, map[string]bool) (*v1.Container, annotations.ContainerDebugConfiguration, string, error) {
+				if test.container == nil {
+					return nil, annotations.ContainerDebugConfiguration{}, "", fmt.Errorf("unable to determine runtime")
+				}
+				return test.container, config, "go", nil
+			})
+
+			opts := dockerutil.ContainerCreateOpts{Name: "image"}
+			configuration, supportImage, err := NewDebugManager(nil, "gcr.io/debug").TransformImage(context.Background(), graph.Artifact{ImageName: "image"}, &opts)
+
+			t.CheckError(test.shouldErr, err)
+			t.CheckDeepEqual(test.opts, opts)
+			if !test.shouldErr {
+				t.CheckDeepEqual(config, configuration)
+				t.CheckDeepEqual("go", supportImage)
+			}
+		})
+	}
+}
+
+func TestDebugEvents(t *testing.T) {
+	testutil.Run(t, "events are sent once started", func(t *testutil.T) {
+		var events []string
+		record := func(status string) func(string, string, string, string, string, string, map[string]uint32) {
+			return func(podName, containerName, namespace, artifact, runtime, workingDir string, debugPorts map[string]uint32) {
+				events = append(events, fmt.Sprintf("%s %s %s %s %v", status, containerName, artifact, runtime, debugPorts))
+			}
+		}
+		t.Override(&notifyDebuggingContainerStarted, record("started"))
+		t.Override(&notifyDebuggingContainerTerminated, record("terminated"))
+
+		dm := NewDebugManager(nil, "gcr.io/debug")
+		dm.AddContainer("first", annotations.ContainerDebugConfiguration{Artifact: "first", Runtime: "jvm", Ports: map[string]uint32{"jdwp": 5005}})
+		t.CheckDeepEqual(0, len(events))
+
+		dm.Start(context.Background())
+		dm.AddContainer("second", annotations.ContainerDebugConfiguration{Artifact: "second", Runtime: "go", Ports: map[string]uint32{"dlv": 56268}})
+		dm.AddContainer("first", annotations.ContainerDebugConfiguration{Artifact: "first", Runtime: "jvm", Ports: map[string]uint32{"jdwp": 5005}})
+		dm.RemoveContainer("second")
+		dm.RemoveContainer("unknown")
+		dm.Stop()
+		dm.RemoveContainer("first")
+
+		t.CheckDeepEqual([]string{
+			"started first first jvm map[jdwp:5005]",
+			"started second second go map[dlv:56268]",
+			"terminated first first jvm map[jdwp:5005]",
+			"started first first jvm map[jdwp:5005]",
+			"terminated second second go map[dlv:56268]",
+		}, events)
+	})
+}
+
+func TestSupportImage(t *testing.T) {
+	testutil.CheckDeepEqual(t, "gcr.io/debug/go", NewDebugManager(nil, "gcr.io/debug").SupportImage("go"))
+}
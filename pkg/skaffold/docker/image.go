@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -35,7 +36,9 @@ import (
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/docker/pkg/streamformatter"
+	"github.com/docker/go-connections/nat"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/sirupsen/logrus"
 
@@ -64,7 +67,13 @@ type ContainerCreateOpts struct {
 	Image       string
 	Network     string
 	VolumesFrom []string
+	Mounts      []mount.Mount
 	Wait        bool
+	Ports       nat.PortSet
+	Bindings    nat.PortMap
+	Entrypoint  []string
+	Cmd         []string
+	Env         []string
 }
 
 // LocalDaemon talks to a local Docker API.
@@ -73,13 +82,16 @@ type LocalDaemon interface {
 	ExtraEnv() []string
 	ServerVersion(ctx context.Context) (types.Version, error)
 	ConfigFile(ctx context.Context, image string) (*v1.ConfigFile, error)
-	ContainerLogs(ctx context.Context, out io.Writer, id string, muter chan bool) (io.ReadCloser, error)
+	ContainerLogs(ctx context.Context, w io.Writer, id string, since time.Time) error
 	Build(ctx context.Context, out io.Writer, workspace string, artifact string, a *latestV1.DockerArtifact, opts BuildOptions) (string, error)
 	Push(ctx context.Context, out io.Writer, ref string) (string, error)
 	Pull(ctx context.Context, out io.Writer, ref string) error
 	Load(ctx context.Context, out io.Writer, input io.Reader, ref string) (string, error)
 	Run(ctx context.Context, out io.Writer, opts ContainerCreateOpts) (string, error)
 	Delete(ctx context.Context, out io.Writer, id string) error
+	ContainerExec(ctx context.Context, out io.Writer, id string, cmd []string) error
+	ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error)
+	CopyToContainer(ctx context.Context, id string, dir string, content io.Reader) error
 	Tag(ctx context.Context, image, ref string) error
 	TagWithImageID(ctx context.Context, ref string, imageID string) (string, error)
 	ImageID(ctx context.Context, ref string) (string, error)
@@ -89,6 +101,7 @@ type LocalDaemon interface {
 	ImageList(ctx context.Context, ref string) ([]types.ImageSummary, error)
 	NetworkCreate(ctx context.Context, name string) error
 	NetworkRemove(ctx context.Context, name string) error
+	VolumeRemove(ctx context.Context, name string) error
 	Prune(ctx context.Context, images []string, pruneChildren bool) ([]string, error)
 	DiskUsage(ctx context.Context) (uint64, error)
 	RawClient() client.CommonAPIClient
@@ -99,6 +112,8 @@ type BuildOptions struct {
 	Tag            string
 	Mode           config.RunMode
 	ExtraBuildArgs map[string]*string
+	// Platform is the target platform of the image, of the form `os/arch[/variant]`. Empty means the platform of the daemon.
+	Platform string
 }
 
 type localDaemon struct {
@@ -146,8 +161,24 @@ func (l *localDaemon) Close() error {
 	return l.apiClient.Close()
 }
 
-func (l *localDaemon) ContainerLogs(ctx context.Context, out io.Writer, id string, muter chan bool) (io.ReadCloser, error) {
-	return l.apiClient.ContainerLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+// ContainerLogs follows the stdout and stderr of a container, starting at a given time,
+// and writes them to the provided writer until the container stops.
+func (l *localDaemon) ContainerLogs(ctx context.Context, w io.Writer, id string, since time.Time) error {
+	opts := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true}
+	if !since.IsZero() {
+		opts.Since = strconv.FormatInt(since.Unix(), 10)
+	}
+	r, err := l.apiClient.ContainerLogs(ctx, id, opts)
+	if err != nil {
+		return fmt.Errorf("retrieving logs for container %s: %w", id, err)
+	}
+	defer r.Close()
+
+	// containers are not started with a TTY, so stdout and stderr are multiplexed in the stream.
+	if _, err := stdcopy.StdCopy(w, w, r); err != nil {
+		return fmt.Errorf("reading logs for container %s: %w", id, err)
+	}
+	return nil
 }
 
 // Delete stops, removes, and prunes a running container
@@ -168,12 +199,18 @@ func (l *localDaemon) Delete(ctx context.Context, out io.Writer, id string) erro
 // Run creates a container from a given image reference, and returns then container ID.
 func (l *localDaemon) Run(ctx context.Context, out io.Writer, opts ContainerCreateOpts) (string, error) {
 	cfg := &container.Config{
-		Image: opts.Image,
+		Image:        opts.Image,
+		ExposedPorts: opts.Ports,
+		Entrypoint:   opts.Entrypoint,
+		Cmd:          opts.Cmd,
+		Env:          opts.Env,
 	}
 
 	hCfg := &container.HostConfig{
-		NetworkMode: container.NetworkMode(opts.Network),
-		VolumesFrom: opts.VolumesFrom,
+		NetworkMode:  container.NetworkMode(opts.Network),
+		VolumesFrom:  opts.VolumesFrom,
+		Mounts:       opts.Mounts,
+		PortBindings: opts.Bindings,
 	}
 	c, err := l.apiClient.ContainerCreate(ctx, cfg, hCfg, nil, nil, opts.Name)
 	if err != nil {
@@ -183,11 +220,60 @@ func (l *localDaemon) Run(ctx context.Context, out io.Writer, opts ContainerCrea
 		return "", err
 	}
 	if opts.Wait {
-		l.apiClient.ContainerWait(ctx, c.ID, container.WaitConditionNotRunning)
+		statusCh, errCh := l.apiClient.ContainerWait(ctx, c.ID, container.WaitConditionNotRunning)
+		select {
+		case err := <-errCh:
+			if err != nil {
+				return "", fmt.Errorf("waiting for container %s: %w", c.ID, err)
+			}
+		case <-statusCh:
+		}
 	}
 	return c.ID, nil
 }
 
+// ContainerExec runs a command inside a running container, and writes its output to out.
+// It returns an error if the command exits with a non-zero status.
+func (l *localDaemon) ContainerExec(ctx context.Context, out io.Writer, id string, cmd []string) error {
+	exec, err := l.apiClient.ContainerExecCreate(ctx, id, types.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("creating exec in container %s: %w", id, err)
+	}
+
+	resp, err := l.apiClient.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return fmt.Errorf("attaching to exec in container %s: %w", id, err)
+	}
+	defer resp.Close()
+
+	if _, err := stdcopy.StdCopy(out, out, resp.Reader); err != nil {
+		return fmt.Errorf("reading output of exec in container %s: %w", id, err)
+	}
+
+	inspect, err := l.apiClient.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return fmt.Errorf("inspecting exec in container %s: %w", id, err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("command %q in container %s exited with code %d", strings.Join(cmd, " "), id, inspect.ExitCode)
+	}
+	return nil
+}
+
+// ContainerInspect retrieves the current state and configuration of a container.
+func (l *localDaemon) ContainerInspect(ctx context.Context, id string) (types.ContainerJSON, error) {
+	return l.apiClient.ContainerInspect(ctx, id)
+}
+
+// CopyToContainer extracts a tar archive into a directory of a container.
+func (l *localDaemon) CopyToContainer(ctx context.Context, id string, dir string, content io.Reader) error {
+	return l.apiClient.CopyToContainer(ctx, id, dir, content, types.CopyToContainerOptions{})
+}
+
 func (l *localDaemon) NetworkCreate(ctx context.Context, name string) error {
 	nr, err := l.apiClient.NetworkList(ctx, types.NetworkListOptions{})
 	if err != nil {
@@ -213,6 +299,11 @@ func (l *localDaemon) NetworkRemove(ctx context.Context, name string) error {
 	return l.apiClient.NetworkRemove(ctx, name)
 }
 
+// VolumeRemove removes a volume, and any data stored in it.
+func (l *localDaemon) VolumeRemove(ctx context.Context, name string) error {
+	return l.apiClient.VolumeRemove(ctx, name, true)
+}
+
 // ServerVersion retrieves the version information from the server.
 func (l *localDaemon) ServerVersion(ctx context.Context) (types.Version, error) {
 	return l.apiClient.ServerVersion(ctx)
@@ -295,6 +386,7 @@ func (l *localDaemon) Build(ctx context.Context, out io.Writer, workspace string
 		NetworkMode: strings.ToLower(a.NetworkMode),
 		ExtraHosts:  a.AddHost,
 		NoCache:     a.NoCache,
+		Platform:    opts.Platform,
 	})
 	if err != nil {
 		return "", fmt.Errorf("docker build: %w", err)
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sirupsen/logrus"
+
+	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
+)
+
+// for testing
+var (
+	remoteWriteIndex = remote.WriteIndex
+)
+
+// SinglePlatformImage is an image built for a single platform, that is part of a multi-platform image.
+type SinglePlatformImage struct {
+	Platform v1.Platform
+	// Image is a reference to the pushed image, preferably by digest.
+	Image string
+}
+
+// CreateManifestList assembles the given images into an OCI image index, pushes it with the given tag,
+// and returns the digest of the index.
+func CreateManifestList(images []SinglePlatformImage, targetTag string, cfg Config) (string, error) {
+	index := mutate.IndexMediaType(empty.Index, types.OCIImageIndex)
+	for _, i := range images {
+		img, err := getRemoteImage(i.Image, cfg)
+		if err != nil {
+			return "", fmt.Errorf("getting image %q: %w", i.Image, err)
+		}
+		platform := i.Platform
+		index = mutate.AppendManifests(index, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: &platform,
+			},
+		})
+	}
+
+	ref, err := parseReference(targetTag, cfg, name.WeakValidation)
+	if err != nil {
+		return "", err
+	}
+	logrus.Debugf("pushing image index %s for %d platforms", targetTag, len(images))
+	if err := remoteWriteIndex(ref, index, remote.WithAuthFromKeychain(primaryKeychain)); err != nil {
+		return "", fmt.Errorf("%s %q: %w", sErrors.PushImageErr, targetTag, err)
+	}
+	return digest(index)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestCreateManifestList(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		images := map[string]v1.Image{}
+		for _, tag := range []string{"gcr.io/foo/img:v1_linux_amd64", "gcr.io/foo/img:v1_linux_arm64"} {
+			img, err := random.Image(1024, 1)
+			t.CheckNoError(err)
+			images[tag] = img
+		}
+		t.Override(&remoteImage, func(ref name.Reference, options ...remote.Option) (v1.Image, error) {
+			img, found := images[ref.Name()]
+			if !found {
+				return nil, fmt.Errorf("not found: %s", ref.Name())
+			}
+			return img, nil
+		})
+		var pushed v1.ImageIndex
+		t.Override(&remoteWriteIndex, func(ref name.Reference, ii v1.ImageIndex, options ...remote.Option) error {
+			t.CheckDeepEqual("gcr.io/foo/img:v1", ref.Name())
+			pushed = ii
+			return nil
+		})
+
+		digest, err := CreateManifestList([]SinglePlatformImage{
+			{Platform: v1.Platform{OS: "linux", Architecture: "amd64"}, Image: "gcr.io/foo/img:v1_linux_amd64"},
+			{Platform: v1.Platform{OS: "linux", Architecture: "arm64"}, Image: "gcr.io/foo/img:v1_linux_arm64"},
+		}, "gcr.io/foo/img:v1", &mockConfig{})
+		t.CheckNoError(err)
+
+		expectedDigest, err := pushed.Digest()
+		t.CheckNoError(err)
+		t.CheckDeepEqual(expectedDigest.String(), digest)
+
+		mediaType, err := pushed.MediaType()
+		t.CheckNoError(err)
+		t.CheckDeepEqual(types.OCIImageIndex, mediaType)
+		manifest, err := pushed.IndexManifest()
+		t.CheckNoError(err)
+		t.CheckDeepEqual(2, len(manifest.Manifests))
+		t.CheckDeepEqual("amd64", manifest.Manifests[0].Platform.Architecture)
+		t.CheckDeepEqual("arm64", manifest.Manifests[1].Platform.Architecture)
+		amd64Digest, _ := images["gcr.io/foo/img:v1_linux_amd64"].Digest()
+		t.CheckDeepEqual(amd64Digest, manifest.Manifests[0].Digest)
+	})
+}
+
+func TestCreateManifestListImageNotFound(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&remoteImage, func(ref name.Reference, options ...remote.Option) (v1.Image, error) {
+			return nil, fmt.Errorf("not found: %s", ref.Name())
+		})
+
+		_, err := CreateManifestList([]SinglePlatformImage{
+			{Platform: v1.Platform{OS: "linux", Architecture: "amd64"}, Image: "gcr.io/foo/img:v1_linux_amd64"},
+		}, "gcr.io/foo/img:v1", &mockConfig{})
+		t.CheckErrorContains("getting image", err)
+	})
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/log/stream"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+type Config interface {
+	PipelineForImage(imageName string) (latestV1.Pipeline, bool)
+	DefaultPipeline() latestV1.Pipeline
+}
+
+// Logger streams the logs of all the containers deployed to the local Docker daemon.
+type Logger struct {
+	out         io.Writer
+	config      Config
+	client      docker.LocalDaemon
+	tracker     *tracker.ContainerTracker
+	colorPicker output.ColorPicker
+
+	muted      int32
+	sinceLock  sync.Mutex
+	sinceTime  time.Time
+	cancel     context.CancelFunc
+	outputLock sync.Mutex
+}
+
+// NewLogger creates a new Logger which follows the containers added to the given tracker.
+func NewLogger(client docker.LocalDaemon, tracker *tracker.ContainerTracker, config Config) *Logger {
+	return &Logger{
+		config:      config,
+		client:      client,
+		tracker:     tracker,
+		colorPicker: output.NewColorPicker(),
+		cancel:      func() {},
+	}
+}
+
+// RegisterArtifacts tracks the provided build artifacts in the colorpicker.
+func (l *Logger) RegisterArtifacts(artifacts []graph.Artifact) {
+	for _, artifact := range artifacts {
+		l.colorPicker.AddImage(artifact.Tag)
+	}
+}
+
+func (l *Logger) SetSince(t time.Time) {
+	if l == nil {
+		// Logs are not activated.
+		return
+	}
+
+	l.sinceLock.Lock()
+	l.sinceTime = t
+	l.sinceLock.Unlock()
+}
+
+func (l *Logger) since() time.Time {
+	l.sinceLock.Lock()
+	defer l.sinceLock.Unlock()
+	return l.sinceTime
+}
+
+// Start starts streaming the logs of every container deployed by Skaffold,
+// including the ones that replace previously deployed containers on redeploy.
+func (l *Logger) Start(ctx context.Context, out io.Writer) error {
+	if l == nil {
+		// Logs are not activated.
+		return nil
+	}
+
+	l.out = out
+	ctx, l.cancel = context.WithCancel(ctx)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-l.tracker.Notifier():
+				for _, c := range l.tracker.TakeNew() {
+					go l.streamContainerLogs(ctx, c)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the logger.
+func (l *Logger) Stop() {
+	if l == nil {
+		// Logs are not activated.
+		return
+	}
+	l.cancel()
+}
+
+func (l *Logger) streamContainerLogs(ctx context.Context, c tracker.Container) {
+	logrus.Infof("Streaming logs from container: %s", c.Name)
+
+	since := l.since()
+	tr, tw := io.Pipe()
+	go func() {
+		if err := l.client.ContainerLogs(ctx, tw, c.ID, since); err != nil {
+			// Don't print errors if the user interrupted the logs
+			// or if the logs were interrupted because of a configuration change
+			if ctx.Err() != context.Canceled {
+				logrus.Warn(err)
+			}
+		}
+		_ = tw.Close()
+	}()
+
+	artifact, _ := l.tracker.ArtifactForContainer(c.ID)
+	headerColor := l.colorPicker.Pick(artifact.Tag)
+	prefix := l.prefix(artifact, c)
+	if err := stream.StreamRequest(ctx, l.out, headerColor, prefix, c.Name, c.Name, make(chan bool), &l.outputLock, l.IsMuted, tr); err != nil {
+		logrus.Errorf("streaming request %s", err)
+	}
+}
+
+func (l *Logger) prefix(artifact graph.Artifact, c tracker.Container) string {
+	p, present := l.config.PipelineForImage(artifact.ImageName)
+	if !present {
+		p = l.config.DefaultPipeline()
+	}
+	switch p.Deploy.Logs.Prefix {
+	case "none":
+		return ""
+	default:
+		// containers have no enclosing pod, so all other prefixes reduce to the container name.
+		return fmt.Sprintf("[%s]", c.Name)
+	}
+}
+
+// Mute mutes the logs.
+func (l *Logger) Mute() {
+	if l == nil {
+		// Logs are not activated.
+		return
+	}
+
+	atomic.StoreInt32(&l.muted, 1)
+}
+
+// Unmute unmutes the logs.
+func (l *Logger) Unmute() {
+	if l == nil {
+		// Logs are not activated.
+		return
+	}
+
+	atomic.StoreInt32(&l.muted, 0)
+}
+
+// IsMuted says if the logs are to be muted.
+func (l *Logger) IsMuted() bool {
+	return atomic.LoadInt32(&l.muted) == 1
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+type fakeDaemon struct {
+	docker.LocalDaemon
+	logs map[string]string
+}
+
+func (f *fakeDaemon) ContainerLogs(_ context.Context, w io.Writer, id string, _ time.Time) error {
+	_, err := fmt.Fprint(w, f.logs[id])
+	return err
+}
+
+type mockConfig struct {
+	prefix string
+}
+
+func (c *mockConfig) PipelineForImage(string) (latestV1.Pipeline, bool) {
+	var pipeline latestV1.Pipeline
+	pipeline.Deploy.Logs.Prefix = c.prefix
+	return pipeline, true
+}
+
+func (c *mockConfig) DefaultPipeline() latestV1.Pipeline {
+	var pipeline latestV1.Pipeline
+	pipeline.Deploy.Logs.Prefix = c.prefix
+	return pipeline
+}
+
+type safeBuffer struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.Buffer.String()
+}
+
+func TestLoggerFollowsReplacedContainers(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		client := &fakeDaemon{logs: map[string]string{
+			"1": "first container\n",
+			"2": "second container\n",
+		}}
+		containerTracker := tracker.NewContainerTracker()
+		l := NewLogger(client, containerTracker, &mockConfig{prefix: "auto"})
+		artifact := graph.Artifact{ImageName: "image", Tag: "image:tag"}
+		l.RegisterArtifacts([]graph.Artifact{artifact})
+
+		var out safeBuffer
+		err := l.Start(context.Background(), &out)
+		t.CheckNoError(err)
+		defer l.Stop()
+
+		containerTracker.Add(artifact, tracker.Container{Name: "image", ID: "1"})
+		waitForOutput(t, &out, "[image] first container")
+		containerTracker.Add(artifact, tracker.Container{Name: "image", ID: "2"})
+		waitForOutput(t, &out, "[image] second container")
+	})
+}
+
+func TestPrefix(t *testing.T) {
+	tests := []struct {
+		description string
+		prefix      string
+		expected    string
+	}{
+		{
+			description: "auto",
+			prefix:      "auto",
+			expected:    "[container]",
+		},
+		{
+			description: "container",
+			prefix:      "container",
+			expected:    "[container]",
+		},
+		{
+			description: "none",
+			prefix:      "none",
+			expected:    "",
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			l := NewLogger(&fakeDaemon{}, tracker.NewContainerTracker(), &mockConfig{prefix: test.prefix})
+
+			p := l.prefix(graph.Artifact{ImageName: "image"}, tracker.Container{Name: "container", ID: "id"})
+
+			t.CheckDeepEqual(test.expected, p)
+		})
+	}
+}
+
+func waitForOutput(t *testutil.T, out *safeBuffer, expected string) {
+	for i := 0; i < 100; i++ {
+		if strings.Contains(out.String(), expected) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected output to contain %q, got %q", expected, out.String())
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
+	eventV2 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/event/v2"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	schemautil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// For testing
+var (
+	getAvailablePort   = util.GetAvailablePort
+	portForwardEvent   = event.PortForwarded
+	portForwardEventV2 = eventV2.PortForwarded
+)
+
+type Config interface {
+	Mode() config.RunMode
+	PortForwardOptions() config.PortForwardOptions
+}
+
+// portForwardEntry is a container port published on a local port.
+type portForwardEntry struct {
+	containerName string
+	resource      latestV1.PortForwardResource
+	localPort     int
+}
+
+// PortManager publishes the ports of the containers deployed to the local Docker daemon.
+// Docker can only bind ports when a container is created, so the deployer asks the
+// PortManager for the port bindings of each container before running it.
+type PortManager struct {
+	cfg       Config
+	resources []*latestV1.PortForwardResource
+
+	// usedPorts serves as a synchronized set of the local ports we've allocated.
+	usedPorts util.PortSet
+
+	lock    sync.Mutex
+	entries map[string][]*portForwardEntry // containerName -> published ports
+	out     io.Writer
+	started bool
+}
+
+// NewPortManager creates a new PortManager for the given user-defined port forward resources.
+func NewPortManager(cfg Config, resources []*latestV1.PortForwardResource) *PortManager {
+	return &PortManager{
+		cfg:       cfg,
+		resources: resources,
+		entries:   make(map[string][]*portForwardEntry),
+	}
+}
+
+// AllocatePorts selects the ports to publish for a container from the user-defined port forward
+// resources, the ports exposed by the image and the debugging ports, and reserves a free local
+// port for each of them. The returned port set and port map are meant to be used when creating the container.
+func (pm *PortManager) AllocatePorts(containerName string, exposedPorts map[string]struct{}, debugPorts map[string]uint32) (nat.PortSet, nat.PortMap) {
+	opts := pm.cfg.PortForwardOptions()
+	if !opts.Enabled() {
+		return nil, nil
+	}
+
+	var requested []latestV1.PortForwardResource
+	if opts.ForwardUser(pm.cfg.Mode()) {
+		for _, r := range pm.resources {
+			if !strings.EqualFold(string(r.Type), string(constants.Container)) || r.Name != containerName {
+				continue
+			}
+			if r.Port.Type != schemautil.Int {
+				logrus.Warnf("named port %q is not supported for container %s: a container port number is required", r.Port.StrVal, containerName)
+				continue
+			}
+			requested = append(requested, *r)
+		}
+	}
+	if opts.ForwardServices(pm.cfg.Mode()) || opts.ForwardPods(pm.cfg.Mode()) {
+		for _, p := range sortedPorts(exposedPorts) {
+			if containsPort(requested, p.Int()) {
+				continue
+			}
+			requested = append(requested, latestV1.PortForwardResource{
+				Type:      constants.Container,
+				Name:      containerName,
+				Port:      schemautil.FromInt(p.Int()),
+				LocalPort: p.Int(),
+			})
+		}
+	}
+	if opts.ForwardDebug(pm.cfg.Mode()) {
+		for _, p := range sortedDebugPorts(debugPorts) {
+			if containsPort(requested, p) {
+				continue
+			}
+			requested = append(requested, latestV1.PortForwardResource{
+				Type:      constants.Container,
+				Name:      containerName,
+				Port:      schemautil.FromInt(p),
+				LocalPort: p,
+			})
+		}
+	}
+
+	ports := make(nat.PortSet)
+	bindings := make(nat.PortMap)
+	var entries []*portForwardEntry
+	for _, r := range requested {
+		if r.Address == "" {
+			r.Address = constants.DefaultPortForwardAddress
+		}
+		localPort := getAvailablePort(r.Address, r.LocalPort, &pm.usedPorts)
+		if localPort == -1 {
+			logrus.Warnf("unable to find an available local port for port %d of container %s", r.Port.IntVal, containerName)
+			continue
+		}
+		if r.LocalPort != 0 && localPort != r.LocalPort {
+			logrus.Infof("local port %d is taken, publishing port %d of container %s on %d instead", r.LocalPort, r.Port.IntVal, containerName, localPort)
+		}
+
+		containerPort, err := nat.NewPort("tcp", strconv.Itoa(r.Port.IntVal))
+		if err != nil {
+			logrus.Warnf("invalid port %d for container %s: %v", r.Port.IntVal, containerName, err)
+			pm.usedPorts.Delete(localPort)
+			continue
+		}
+		ports[containerPort] = struct{}{}
+		bindings[containerPort] = append(bindings[containerPort], nat.PortBinding{HostIP: r.Address, HostPort: strconv.Itoa(localPort)})
+		entries = append(entries, &portForwardEntry{
+			containerName: containerName,
+			resource:      r,
+			localPort:     localPort,
+		})
+	}
+
+	pm.lock.Lock()
+	pm.entries[containerName] = entries
+	if pm.started {
+		for _, e := range entries {
+			pm.announce(e)
+		}
+	}
+	pm.lock.Unlock()
+
+	return ports, bindings
+}
+
+// RelinquishPorts releases the local ports allocated to a container, once it has been removed.
+func (pm *PortManager) RelinquishPorts(containerName string) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	for _, e := range pm.entries[containerName] {
+		pm.usedPorts.Delete(e.localPort)
+	}
+	delete(pm.entries, containerName)
+}
+
+// Start reports the ports published for the containers deployed so far,
+// and for every container deployed from now on.
+func (pm *PortManager) Start(_ context.Context, out io.Writer) error {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	pm.out = out
+	pm.started = true
+
+	var names []string
+	for name := range pm.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, e := range pm.entries[name] {
+			pm.announce(e)
+		}
+	}
+	return nil
+}
+
+// Stop stops reporting published ports. The ports themselves
+// are released by Docker when the containers are removed.
+func (pm *PortManager) Stop() {
+	pm.lock.Lock()
+	pm.started = false
+	pm.lock.Unlock()
+}
+
+func (pm *PortManager) announce(e *portForwardEntry) {
+	output.Green.Fprintln(pm.out, fmt.Sprintf("Port forwarding container %s, remote port %s -> %s:%d",
+		e.containerName,
+		e.resource.Port.String(),
+		e.resource.Address,
+		e.localPort))
+	portForwardEvent(int32(e.localPort), e.resource.Port, "", e.containerName, "", "", string(e.resource.Type), e.resource.Name, e.resource.Address)
+	portForwardEventV2(int32(e.localPort), e.resource.Port, "", e.containerName, "", "", string(e.resource.Type), e.resource.Name, e.resource.Address)
+}
+
+func sortedPorts(exposedPorts map[string]struct{}) []nat.Port {
+	var ports []nat.Port
+	for p := range exposedPorts {
+		port := nat.Port(p)
+		if port.Proto() != "tcp" {
+			continue
+		}
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Int() < ports[j].Int() })
+	return ports
+}
+
+func sortedDebugPorts(debugPorts map[string]uint32) []int {
+	var ports []int
+	for _, p := range debugPorts {
+		ports = append(ports, int(p))
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+func containsPort(resources []latestV1.PortForwardResource, port int) bool {
+	for _, r := range resources {
+		if r.Port.IntVal == port {
+			return true
+		}
+	}
+	return false
+}
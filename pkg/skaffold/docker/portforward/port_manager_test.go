@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	schemautil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+type mockConfig struct {
+	opts config.PortForwardOptions
+}
+
+func (m *mockConfig) Mode() config.RunMode                          { return config.RunModes.Dev }
+func (m *mockConfig) PortForwardOptions() config.PortForwardOptions { return m.opts }
+
+func TestAllocatePorts(t *testing.T) {
+	tests := []struct {
+		description      string
+		modes            []string
+		resources        []*latestV1.PortForwardResource
+		exposedPorts     map[string]struct{}
+		debugPorts       map[string]uint32
+		takenPorts       map[int]bool
+		expectedBindings nat.PortMap
+	}{
+		{
+			description:  "port forwarding disabled",
+			modes:        []string{"off"},
+			exposedPorts: map[string]struct{}{"8080/tcp": {}},
+		},
+		{
+			description:  "exposed ports",
+			modes:        []string{"true"},
+			exposedPorts: map[string]struct{}{"8080/tcp": {}, "53/udp": {}},
+			expectedBindings: nat.PortMap{
+				"8080/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}},
+			},
+		},
+		{
+			description:      "exposed ports aren't forwarded in user mode",
+			modes:            []string{"user"},
+			exposedPorts:     map[string]struct{}{"8080/tcp": {}},
+			expectedBindings: nat.PortMap{},
+		},
+		{
+			description: "user defined ports",
+			modes:       []string{"user"},
+			resources: []*latestV1.PortForwardResource{
+				{Type: constants.Container, Name: "image", Port: schemautil.FromInt(8080), LocalPort: 9000, Address: "0.0.0.0"},
+				{Type: constants.Container, Name: "other", Port: schemautil.FromInt(8081), LocalPort: 9001},
+				{Type: constants.Pod, Name: "image", Port: schemautil.FromInt(8082), LocalPort: 9002},
+			},
+			expectedBindings: nat.PortMap{
+				"8080/tcp": {{HostIP: "0.0.0.0", HostPort: "9000"}},
+			},
+		},
+		{
+			description: "user defined ports take precedence over exposed ports",
+			modes:       []string{"true"},
+			resources: []*latestV1.PortForwardResource{
+				{Type: constants.Container, Name: "image", Port: schemautil.FromInt(8080), LocalPort: 9000},
+			},
+			exposedPorts: map[string]struct{}{"8080/tcp": {}, "8081/tcp": {}},
+			expectedBindings: nat.PortMap{
+				"8080/tcp": {{HostIP: "127.0.0.1", HostPort: "9000"}},
+				"8081/tcp": {{HostIP: "127.0.0.1", HostPort: "8081"}},
+			},
+		},
+		{
+			description:  "taken ports are replaced",
+			modes:        []string{"true"},
+			exposedPorts: map[string]struct{}{"8080/tcp": {}},
+			takenPorts:   map[int]bool{8080: true},
+			expectedBindings: nat.PortMap{
+				"8080/tcp": {{HostIP: "127.0.0.1", HostPort: "8081"}},
+			},
+		},
+		{
+			description:  "debug ports",
+			modes:        []string{"debug"},
+			exposedPorts: map[string]struct{}{"8080/tcp": {}},
+			debugPorts:   map[string]uint32{"dlv": 56268},
+			expectedBindings: nat.PortMap{
+				"56268/tcp": {{HostIP: "127.0.0.1", HostPort: "56268"}},
+			},
+		},
+		{
+			description:      "debug ports aren't forwarded in user mode",
+			modes:            []string{"user"},
+			debugPorts:       map[string]uint32{"jdwp": 5005},
+			expectedBindings: nat.PortMap{},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&getAvailablePort, func(_ string, port int, usedPorts *util.PortSet) int {
+				for test.takenPorts[port] || usedPorts.LoadOrSet(port) {
+					port++
+				}
+				return port
+			})
+			var opts config.PortForwardOptions
+			t.CheckNoError(opts.Replace(test.modes))
+
+			pm := NewPortManager(&mockConfig{opts: opts}, test.resources)
+			_, bindings := pm.AllocatePorts("image", test.exposedPorts, test.debugPorts)
+
+			t.CheckDeepEqual(test.expectedBindings, bindings)
+		})
+	}
+}
+
+func TestPortManagerEvents(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&getAvailablePort, func(_ string, port int, usedPorts *util.PortSet) int {
+			usedPorts.Set(port)
+			return port
+		})
+		var forwarded []int32
+		t.Override(&portForwardEvent, func(localPort int32, _ schemautil.IntOrString, _, _, _, _, _, _, _ string) {
+			forwarded = append(forwarded, localPort)
+		})
+		t.Override(&portForwardEventV2, func(int32, schemautil.IntOrString, string, string, string, string, string, string, string) {})
+		var opts config.PortForwardOptions
+		t.CheckNoError(opts.Replace([]string{"true"}))
+
+		pm := NewPortManager(&mockConfig{opts: opts}, nil)
+		pm.AllocatePorts("first", map[string]struct{}{"8080/tcp": {}}, nil)
+		t.CheckEmpty(forwarded)
+
+		var out bytes.Buffer
+		t.CheckNoError(pm.Start(context.Background(), &out))
+		t.CheckDeepEqual([]int32{8080}, forwarded)
+		t.CheckContains("Port forwarding container first, remote port 8080 -> 127.0.0.1:8080", out.String())
+
+		pm.RelinquishPorts("first")
+		pm.AllocatePorts("first", map[string]struct{}{"8080/tcp": {}}, nil)
+		t.CheckDeepEqual([]int32{8080, 8080}, forwarded)
+
+		pm.Stop()
+		pm.AllocatePorts("second", map[string]struct{}{"9090/tcp": {}}, nil)
+		t.CheckDeepEqual([]int32{8080, 8080}, forwarded)
+	})
+}
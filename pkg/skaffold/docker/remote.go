@@ -37,14 +37,19 @@ var (
 
 func AddRemoteTag(src, target string, cfg Config) error {
 	logrus.Debugf("attempting to add tag %s to src %s", target, src)
-	img, err := getRemoteImage(src, cfg)
+	targetRef, err := parseReference(target, cfg, name.WeakValidation)
 	if err != nil {
-		return fmt.Errorf("getting image: %w", err)
+		return err
 	}
 
-	targetRef, err := parseReference(target, cfg, name.WeakValidation)
+	// Multi-platform images are tagged with their image index.
+	if idx, err := getRemoteIndex(src, cfg); err == nil {
+		return remote.WriteIndex(targetRef, idx, remote.WithAuthFromKeychain(primaryKeychain))
+	}
+
+	img, err := getRemoteImage(src, cfg)
 	if err != nil {
-		return err
+		return fmt.Errorf("getting image: %w", err)
 	}
 
 	return remote.Write(targetRef, img, remote.WithAuthFromKeychain(primaryKeychain))
@@ -111,6 +116,16 @@ func getRemoteIndex(identifier string, cfg Config) (v1.ImageIndex, error) {
 	return remoteIndex(ref, remote.WithAuthFromKeychain(primaryKeychain))
 }
 
+// RemoteImage retrieves an image from a registry with the configured credentials.
+func RemoteImage(ref name.Reference) (v1.Image, error) {
+	return remoteImage(ref, remote.WithAuthFromKeychain(primaryKeychain))
+}
+
+// WriteRemoteImage pushes an image to a registry with the configured credentials.
+func WriteRemoteImage(ref name.Reference, img v1.Image) error {
+	return remote.Write(ref, img, remote.WithAuthFromKeychain(primaryKeychain))
+}
+
 // IsInsecure tests if an image is pulled from an insecure registry; default is false
 func IsInsecure(ref name.Reference, insecureRegistries map[string]bool) bool {
 	return insecureRegistries[ref.Context().Registry.Name()]
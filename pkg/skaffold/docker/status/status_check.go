@@ -0,0 +1,302 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
+	eventV2 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/event/v2"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/proto/v1"
+)
+
+var (
+	// DefaultStatusCheckDeadline is the default timeout for container status checks
+	DefaultStatusCheckDeadline = 10 * time.Minute
+
+	// Poll period for checking set to 1 second
+	pollPeriod = 1 * time.Second
+
+	// stabilizationPeriod is how long a container without a HEALTHCHECK must keep running to be considered stable.
+	stabilizationPeriod = 3 * time.Second
+)
+
+const tabHeader = " -"
+
+type Config interface {
+	StatusCheckDeadlineSeconds() int
+}
+
+// Monitor runs status checks for the containers deployed to the local Docker daemon.
+type Monitor struct {
+	client   docker.LocalDaemon
+	tracker  *tracker.ContainerTracker
+	deadline time.Duration
+
+	lock           sync.Mutex
+	seenContainers map[string]bool
+}
+
+// NewStatusMonitor returns a status monitor which waits for deployed containers to be running and healthy.
+func NewStatusMonitor(cfg Config, client docker.LocalDaemon, tracker *tracker.ContainerTracker) *Monitor {
+	return &Monitor{
+		client:         client,
+		tracker:        tracker,
+		deadline:       getDeadline(cfg.StatusCheckDeadlineSeconds()),
+		seenContainers: make(map[string]bool),
+	}
+}
+
+// Check waits for the containers deployed in the current skaffold dev iteration to be
+// running, and to be healthy when their image defines a HEALTHCHECK.
+func (m *Monitor) Check(ctx context.Context, out io.Writer) error {
+	event.StatusCheckEventStarted()
+	ctx, endTrace := instrumentation.StartTrace(ctx, "performStatusCheck_WaitForContainersToStabilize")
+	defer endTrace()
+
+	start := time.Now()
+	output.Default.Fprintln(out, "Waiting for containers to stabilize...")
+
+	errCode, err := m.statusCheck(ctx, out)
+	event.StatusCheckEventEnded(errCode, err)
+	if err != nil {
+		return err
+	}
+
+	output.Default.Fprintln(out, "Containers stabilized in", util.ShowHumanizeTime(time.Since(start)))
+	return nil
+}
+
+func (m *Monitor) Reset() {
+	m.lock.Lock()
+	m.seenContainers = make(map[string]bool)
+	m.lock.Unlock()
+}
+
+func (m *Monitor) statusCheck(ctx context.Context, out io.Writer) (proto.StatusCode, error) {
+	var containers []tracker.Container
+	m.lock.Lock()
+	for _, c := range m.tracker.DeployedContainers() {
+		if m.seenContainers[c.ID] {
+			continue
+		}
+		m.seenContainers[c.ID] = true
+		containers = append(containers, c)
+	}
+	m.lock.Unlock()
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]proto.ActionableErr, len(containers))
+	var outputLock sync.Mutex
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		wg.Add(1)
+		go func(i int, c tracker.Container) {
+			defer wg.Done()
+			// keep polling the container until it fails/succeeds/times out
+			ae := m.pollContainerStatus(ctx, c)
+			results[i] = ae
+			outputLock.Lock()
+			printStatusCheckSummary(out, c, ae)
+			outputLock.Unlock()
+			// if one container fails, cancel status checks for all containers.
+			if ae.ErrCode != proto.StatusCode_STATUSCHECK_SUCCESS && ae.ErrCode != proto.StatusCode_STATUSCHECK_USER_CANCELLED {
+				cancel()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	return getSkaffoldDeployStatus(results)
+}
+
+func (m *Monitor) pollContainerStatus(ctx context.Context, c tracker.Container) proto.ActionableErr {
+	ticker := time.NewTicker(pollPeriod)
+	defer ticker.Stop()
+	// Add poll duration to account for one last attempt after the deadline.
+	timeoutContext, cancel := context.WithTimeout(ctx, m.deadline+pollPeriod)
+	defer cancel()
+	logrus.Debugf("checking status of container %s", c.Name)
+	for {
+		select {
+		case <-timeoutContext.Done():
+			if timeoutContext.Err() == context.DeadlineExceeded {
+				return proto.ActionableErr{
+					ErrCode: proto.StatusCode_STATUSCHECK_DEADLINE_EXCEEDED,
+					Message: fmt.Sprintf("could not stabilize within %v", m.deadline),
+				}
+			}
+			return proto.ActionableErr{
+				ErrCode: proto.StatusCode_STATUSCHECK_USER_CANCELLED,
+				Message: "check cancelled",
+			}
+		case <-ticker.C:
+			info, err := m.client.ContainerInspect(timeoutContext, c.ID)
+			if errdefs.IsNotFound(err) {
+				return proto.ActionableErr{
+					ErrCode: proto.StatusCode_STATUSCHECK_RUN_CONTAINER_ERR,
+					Message: fmt.Sprintf("container %s was removed", c.Name),
+				}
+			}
+			if err != nil {
+				logrus.Debugf("inspecting container %s: %v", c.Name, err)
+				continue
+			}
+			if ae, done := containerStatus(c.Name, info, time.Now()); done {
+				return ae
+			}
+		}
+	}
+}
+
+// containerStatus evaluates the state of a container, and reports whether it reached a final state.
+func containerStatus(name string, info types.ContainerJSON, now time.Time) (proto.ActionableErr, bool) {
+	if info.ContainerJSONBase == nil || info.State == nil {
+		return proto.ActionableErr{}, false
+	}
+	state := info.State
+
+	switch {
+	case state.OOMKilled:
+		return proto.ActionableErr{
+			ErrCode:     proto.StatusCode_STATUSCHECK_CONTAINER_TERMINATED,
+			Message:     fmt.Sprintf("container %s was OOM killed", name),
+			Suggestions: []*proto.Suggestion{checkContainerLogs()},
+		}, true
+	case state.Restarting || info.RestartCount > 0:
+		return proto.ActionableErr{
+			ErrCode:     proto.StatusCode_STATUSCHECK_CONTAINER_RESTARTING,
+			Message:     fmt.Sprintf("container %s is crash looping: restarted %d time(s), last exit code %d", name, info.RestartCount, state.ExitCode),
+			Suggestions: []*proto.Suggestion{checkContainerLogs()},
+		}, true
+	case state.Error != "":
+		return proto.ActionableErr{
+			ErrCode:     proto.StatusCode_STATUSCHECK_RUN_CONTAINER_ERR,
+			Message:     fmt.Sprintf("container %s failed to run: %s", name, state.Error),
+			Suggestions: []*proto.Suggestion{checkContainerLogs()},
+		}, true
+	case state.Status == "exited" || state.Status == "dead":
+		if state.ExitCode == 0 {
+			return proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS}, true
+		}
+		return proto.ActionableErr{
+			ErrCode:     proto.StatusCode_STATUSCHECK_CONTAINER_TERMINATED,
+			Message:     fmt.Sprintf("container %s terminated with exit code %d", name, state.ExitCode),
+			Suggestions: []*proto.Suggestion{checkContainerLogs()},
+		}, true
+	case !state.Running:
+		return proto.ActionableErr{}, false
+	}
+
+	if state.Health != nil {
+		switch state.Health.Status {
+		case types.Healthy:
+			return proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS}, true
+		case types.Unhealthy:
+			msg := fmt.Sprintf("container %s is unhealthy", name)
+			if n := len(state.Health.Log); n > 0 {
+				msg = fmt.Sprintf("%s: %s", msg, strings.TrimSpace(state.Health.Log[n-1].Output))
+			}
+			return proto.ActionableErr{
+				ErrCode: proto.StatusCode_STATUSCHECK_UNHEALTHY,
+				Message: msg,
+				Suggestions: []*proto.Suggestion{{
+					SuggestionCode: proto.SuggestionCode_CHECK_READINESS_PROBE,
+					Action:         "Try checking the image `HEALTHCHECK`",
+				}},
+			}, true
+		default:
+			return proto.ActionableErr{}, false
+		}
+	}
+
+	startedAt, err := time.Parse(time.RFC3339Nano, state.StartedAt)
+	if err != nil || now.Sub(startedAt) < stabilizationPeriod {
+		return proto.ActionableErr{}, false
+	}
+	return proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS}, true
+}
+
+func checkContainerLogs() *proto.Suggestion {
+	return &proto.Suggestion{
+		SuggestionCode: proto.SuggestionCode_CHECK_CONTAINER_LOGS,
+		Action:         "Try checking container logs",
+	}
+}
+
+func getSkaffoldDeployStatus(results []proto.ActionableErr) (proto.StatusCode, error) {
+	failed := 0
+	code := proto.StatusCode_STATUSCHECK_USER_CANCELLED
+	for _, ae := range results {
+		if ae.ErrCode == proto.StatusCode_STATUSCHECK_SUCCESS {
+			continue
+		}
+		failed++
+		if ae.ErrCode != proto.StatusCode_STATUSCHECK_USER_CANCELLED && code == proto.StatusCode_STATUSCHECK_USER_CANCELLED {
+			code = ae.ErrCode
+		}
+	}
+	if failed == 0 {
+		return proto.StatusCode_STATUSCHECK_SUCCESS, nil
+	}
+	return code, fmt.Errorf("%d/%d container(s) failed", failed, len(results))
+}
+
+func getDeadline(d int) time.Duration {
+	if d > 0 {
+		return time.Duration(d) * time.Second
+	}
+	return DefaultStatusCheckDeadline
+}
+
+func printStatusCheckSummary(out io.Writer, c tracker.Container, ae proto.ActionableErr) {
+	if ae.ErrCode == proto.StatusCode_STATUSCHECK_USER_CANCELLED {
+		// Don't print the status summary if the user ctrl-C or
+		// another container failed
+		return
+	}
+	resource := fmt.Sprintf("container/%s", c.Name)
+	event.ResourceStatusCheckEventCompleted(resource, ae)
+	eventV2.ResourceStatusCheckEventCompleted(resource, sErrors.V2fromV1(ae))
+	if ae.ErrCode != proto.StatusCode_STATUSCHECK_SUCCESS {
+		fmt.Fprintf(out, "%s %s failed. Error: %s.\n", tabHeader, resource, ae.Message)
+		for _, s := range ae.Suggestions {
+			fmt.Fprintf(out, "    %s\n", s.Action)
+		}
+		return
+	}
+	fmt.Fprintf(out, "%s %s is ready.\n", tabHeader, resource)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/proto/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+	testEvent "github.com/GoogleContainerTools/skaffold/testutil/event"
+)
+
+func TestContainerStatus(t *testing.T) {
+	now := time.Now()
+	started := now.Add(-time.Minute).Format(time.RFC3339Nano)
+	tests := []struct {
+		description  string
+		state        *types.ContainerState
+		restartCount int
+		expectedCode proto.StatusCode
+		expectedMsg  string
+		expectedDone bool
+	}{
+		{
+			description:  "running long enough",
+			state:        &types.ContainerState{Status: "running", Running: true, StartedAt: started},
+			expectedCode: proto.StatusCode_STATUSCHECK_SUCCESS,
+			expectedDone: true,
+		},
+		{
+			description: "just started",
+			state:       &types.ContainerState{Status: "running", Running: true, StartedAt: now.Format(time.RFC3339Nano)},
+		},
+		{
+			description: "health starting",
+			state:       &types.ContainerState{Status: "running", Running: true, StartedAt: started, Health: &types.Health{Status: types.Starting}},
+		},
+		{
+			description:  "healthy",
+			state:        &types.ContainerState{Status: "running", Running: true, StartedAt: started, Health: &types.Health{Status: types.Healthy}},
+			expectedCode: proto.StatusCode_STATUSCHECK_SUCCESS,
+			expectedDone: true,
+		},
+		{
+			description: "unhealthy",
+			state: &types.ContainerState{Status: "running", Running: true, StartedAt: started, Health: &types.Health{
+				Status: types.Unhealthy,
+				Log:    []*types.HealthcheckResult{{Output: "connection refused\n"}},
+			}},
+			expectedCode: proto.StatusCode_STATUSCHECK_UNHEALTHY,
+			expectedMsg:  "container app is unhealthy: connection refused",
+			expectedDone: true,
+		},
+		{
+			description:  "exited with error",
+			state:        &types.ContainerState{Status: "exited", ExitCode: 1},
+			expectedCode: proto.StatusCode_STATUSCHECK_CONTAINER_TERMINATED,
+			expectedMsg:  "container app terminated with exit code 1",
+			expectedDone: true,
+		},
+		{
+			description:  "completed",
+			state:        &types.ContainerState{Status: "exited", ExitCode: 0},
+			expectedCode: proto.StatusCode_STATUSCHECK_SUCCESS,
+			expectedDone: true,
+		},
+		{
+			description:  "crash loop",
+			state:        &types.ContainerState{Status: "restarting", Restarting: true, ExitCode: 2},
+			restartCount: 3,
+			expectedCode: proto.StatusCode_STATUSCHECK_CONTAINER_RESTARTING,
+			expectedMsg:  "container app is crash looping: restarted 3 time(s), last exit code 2",
+			expectedDone: true,
+		},
+		{
+			description:  "oom killed",
+			state:        &types.ContainerState{Status: "exited", OOMKilled: true, ExitCode: 137},
+			expectedCode: proto.StatusCode_STATUSCHECK_CONTAINER_TERMINATED,
+			expectedMsg:  "container app was OOM killed",
+			expectedDone: true,
+		},
+		{
+			description: "created",
+			state:       &types.ContainerState{Status: "created"},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			info := types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: test.state, RestartCount: test.restartCount}}
+
+			ae, done := containerStatus("app", info, now)
+
+			t.CheckDeepEqual(test.expectedDone, done)
+			t.CheckDeepEqual(test.expectedCode, ae.ErrCode)
+			t.CheckDeepEqual(test.expectedMsg, ae.Message)
+		})
+	}
+}
+
+type fakeDaemon struct {
+	docker.LocalDaemon
+	states map[string]*types.ContainerState
+}
+
+func (f *fakeDaemon) ContainerInspect(_ context.Context, id string) (types.ContainerJSON, error) {
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: f.states[id]}}, nil
+}
+
+type mockConfig struct{}
+
+func (mockConfig) StatusCheckDeadlineSeconds() int { return 2 }
+
+func TestMonitorCheck(t *testing.T) {
+	tests := []struct {
+		description string
+		states      map[string]*types.ContainerState
+		deadline    time.Duration
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "all containers ready",
+			states: map[string]*types.ContainerState{
+				"1": {Status: "running", Running: true, StartedAt: time.Now().Add(-time.Minute).Format(time.RFC3339Nano)},
+			},
+			deadline: time.Second,
+			expected: " - container/first is ready.",
+		},
+		{
+			description: "failed container",
+			states: map[string]*types.ContainerState{
+				"1": {Status: "exited", ExitCode: 1},
+			},
+			deadline:  time.Second,
+			expected:  " - container/first failed. Error: container first terminated with exit code 1.",
+			shouldErr: true,
+		},
+		{
+			description: "deadline exceeded",
+			states: map[string]*types.ContainerState{
+				"1": {Status: "created"},
+			},
+			deadline:  50 * time.Millisecond,
+			expected:  " - container/first failed. Error: could not stabilize within 50ms.",
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			testEvent.InitializeState([]latestV1.Pipeline{{}})
+			t.Override(&pollPeriod, 10*time.Millisecond)
+			containerTracker := tracker.NewContainerTracker()
+			containerTracker.Add(graph.Artifact{ImageName: "first"}, tracker.Container{Name: "first", ID: "1"})
+			m := NewStatusMonitor(mockConfig{}, &fakeDaemon{states: test.states}, containerTracker)
+			m.deadline = test.deadline
+
+			var out bytes.Buffer
+			err := m.Check(context.Background(), &out)
+
+			t.CheckError(test.shouldErr, err)
+			t.CheckContains(test.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"sync"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+)
+
+// Container is a container deployed to the local Docker daemon by Skaffold.
+type Container struct {
+	Name string
+	ID   string
+}
+
+// ContainerTracker keeps track of the containers deployed by the Docker deployer,
+// and notifies its consumer each time a new container is deployed.
+type ContainerTracker struct {
+	sync.RWMutex
+	deployedContainers  map[string]Container // imageName -> container
+	containerToArtifact map[string]graph.Artifact
+	pending             []Container // added containers not yet taken by the consumer
+	notifier            chan struct{}
+}
+
+// NewContainerTracker creates a new ContainerTracker.
+func NewContainerTracker() *ContainerTracker {
+	return &ContainerTracker{
+		deployedContainers:  make(map[string]Container),
+		containerToArtifact: make(map[string]graph.Artifact),
+		notifier:            make(chan struct{}, 1),
+	}
+}
+
+// Notifier returns a channel that receives a value whenever newly tracked
+// containers are waiting to be retrieved with TakeNew.
+func (t *ContainerTracker) Notifier() <-chan struct{} {
+	return t.notifier
+}
+
+// TakeNew returns the containers tracked since its previous call.
+func (t *ContainerTracker) TakeNew() []Container {
+	t.Lock()
+	defer t.Unlock()
+	pending := t.pending
+	t.pending = nil
+	return pending
+}
+
+// Add tracks a container deployed for a given artifact, replacing
+// any container previously tracked for the same image.
+func (t *ContainerTracker) Add(artifact graph.Artifact, c Container) {
+	t.Lock()
+	if old, found := t.deployedContainers[artifact.ImageName]; found {
+		delete(t.containerToArtifact, old.ID)
+		t.dropPending(old.ID)
+	}
+	t.deployedContainers[artifact.ImageName] = c
+	t.containerToArtifact[c.ID] = artifact
+	t.pending = append(t.pending, c)
+	t.Unlock()
+
+	// Never block: a notification is already waiting if the channel is full.
+	select {
+	case t.notifier <- struct{}{}:
+	default:
+	}
+}
+
+// Remove stops tracking the container deployed for a given image.
+func (t *ContainerTracker) Remove(imageName string) {
+	t.Lock()
+	defer t.Unlock()
+	if c, found := t.deployedContainers[imageName]; found {
+		delete(t.containerToArtifact, c.ID)
+		delete(t.deployedContainers, imageName)
+		t.dropPending(c.ID)
+	}
+}
+
+// dropPending forgets a container that's no longer tracked before it was taken.
+// Must be called with the lock held.
+func (t *ContainerTracker) dropPending(id string) {
+	var kept []Container
+	for _, c := range t.pending {
+		if c.ID != id {
+			kept = append(kept, c)
+		}
+	}
+	t.pending = kept
+}
+
+// DeployedContainers returns a copy of the currently tracked containers, keyed by image name.
+func (t *ContainerTracker) DeployedContainers() map[string]Container {
+	t.RLock()
+	defer t.RUnlock()
+	containers := make(map[string]Container, len(t.deployedContainers))
+	for image, c := range t.deployedContainers {
+		containers[image] = c
+	}
+	return containers
+}
+
+// DeployedContainerForImage returns the container currently deployed for a given image.
+func (t *ContainerTracker) DeployedContainerForImage(imageName string) (Container, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	c, found := t.deployedContainers[imageName]
+	return c, found
+}
+
+// ArtifactForContainer returns the artifact a given container was deployed from.
+func (t *ContainerTracker) ArtifactForContainer(id string) (graph.Artifact, bool) {
+	t.RLock()
+	defer t.RUnlock()
+	a, found := t.containerToArtifact[id]
+	return a, found
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracker
+
+import (
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestContainerTracker(t *testing.T) {
+	testutil.Run(t, "replaced containers are tracked and notified", func(t *testutil.T) {
+		tracker := NewContainerTracker()
+		artifact := graph.Artifact{ImageName: "image", Tag: "image:tag"}
+
+		tracker.Add(artifact, Container{Name: "first", ID: "1"})
+		<-tracker.Notifier()
+		t.CheckDeepEqual([]Container{{Name: "first", ID: "1"}}, tracker.TakeNew())
+
+		tracker.Add(artifact, Container{Name: "second", ID: "2"})
+		<-tracker.Notifier()
+		t.CheckDeepEqual([]Container{{Name: "second", ID: "2"}}, tracker.TakeNew())
+
+		c, found := tracker.DeployedContainerForImage("image")
+		t.CheckTrue(found)
+		t.CheckDeepEqual("2", c.ID)
+
+		_, found = tracker.ArtifactForContainer("1")
+		t.CheckFalse(found)
+		a, found := tracker.ArtifactForContainer("2")
+		t.CheckTrue(found)
+		t.CheckDeepEqual(artifact, a)
+
+		tracker.Remove("image")
+		t.CheckDeepEqual(map[string]Container{}, tracker.DeployedContainers())
+	})
+}
+
+func TestContainerTrackerAddDoesNotBlock(t *testing.T) {
+	testutil.Run(t, "containers added without a consumer are queued", func(t *testutil.T) {
+		tracker := NewContainerTracker()
+
+		tracker.Add(graph.Artifact{ImageName: "image1"}, Container{Name: "first", ID: "1"})
+		tracker.Add(graph.Artifact{ImageName: "image1"}, Container{Name: "second", ID: "2"})
+		tracker.Add(graph.Artifact{ImageName: "image2"}, Container{Name: "third", ID: "3"})
+		tracker.Add(graph.Artifact{ImageName: "image3"}, Container{Name: "fourth", ID: "4"})
+		tracker.Remove("image3")
+
+		<-tracker.Notifier()
+		t.CheckDeepEqual([]Container{{Name: "second", ID: "2"}, {Name: "third", ID: "3"}}, tracker.TakeNew())
+		t.CheckEmpty(tracker.TakeNew())
+	})
+}
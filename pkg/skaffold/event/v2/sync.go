@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
+	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
+	proto "github.com/GoogleContainerTools/skaffold/proto/v2"
+)
+
+// FileSyncInProgress notifies that a file is being synced to a container image.
+// The synced file is used as the id of the subtask.
+func FileSyncInProgress(file, image string) {
+	fileSyncSubtaskEvent(file, image, InProgress, nil)
+}
+
+// FileSyncFailed notifies that a file has failed to sync to a container image.
+func FileSyncFailed(file, image string, err error) {
+	fileSyncSubtaskEvent(file, image, Failed, err)
+}
+
+// FileSyncSucceeded notifies that a file has been synced to a container image.
+func FileSyncSucceeded(file, image string) {
+	fileSyncSubtaskEvent(file, image, Succeeded, nil)
+}
+
+func fileSyncSubtaskEvent(file, image, status string, err error) {
+	var aErr *proto.ActionableErr
+	if err != nil {
+		aErr = sErrors.ActionableErrV2(handler.cfg, constants.Sync, err)
+	}
+	handler.handleFileSyncEvent(&proto.FileSyncEvent{
+		Id:            file,
+		TaskId:        fmt.Sprintf("%s-%d", constants.Sync, handler.iteration),
+		FileCount:     1,
+		Image:         image,
+		Status:        status,
+		ActionableErr: aErr,
+	})
+}
+
+func (ev *eventHandler) handleFileSyncEvent(e *proto.FileSyncEvent) {
+	ev.handle(&proto.Event{
+		EventType: &proto.Event_FileSyncEvent{
+			FileSyncEvent: e,
+		},
+	})
+}
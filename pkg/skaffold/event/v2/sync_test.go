@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestFileSyncSubtaskEvents(t *testing.T) {
+	defer func() { handler = newHandler() }()
+
+	handler = newHandler()
+	handler.state = emptyState(mockCfg([]latestV1.Pipeline{{}}, "test"))
+
+	wait(t, func() bool { return handler.getState().FileSyncState.Status == NotStarted })
+	FileSyncInProgress("/app/main.go", "img")
+	wait(t, func() bool { return handler.getState().FileSyncState.Status == InProgress })
+	FileSyncFailed("/app/main.go", "img", errors.New("sync failed"))
+	wait(t, func() bool { return handler.getState().FileSyncState.Status == Failed })
+	FileSyncSucceeded("/app/main.go", "img")
+	wait(t, func() bool { return handler.getState().FileSyncState.Status == Succeeded })
+
+	var synced []string
+	handler.logLock.Lock()
+	for _, e := range handler.eventLog {
+		if fse := e.GetFileSyncEvent(); fse != nil {
+			synced = append(synced, fse.Id+":"+fse.Image+":"+fse.Status)
+		}
+	}
+	handler.logLock.Unlock()
+	testutil.CheckDeepEqual(t, []string{"/app/main.go:img:InProgress", "/app/main.go:img:Failed", "/app/main.go:img:Succeeded"}, synced)
+}
@@ -100,18 +100,25 @@ func Yaml(out io.Writer, namespace string, configFiles []*ConfigFile) (*bytes.Bu
 }
 
 func generateGitResource() (*tekton.PipelineResource, error) {
-	// Get git repo url
+	gitURL, err := gitRepoURL()
+	if err != nil {
+		return nil, err
+	}
+
+	return pipeline.NewGitResource("source-git", gitURL), nil
+}
+
+func gitRepoURL() (string, error) {
 	gitURL := os.Getenv("PIPELINE_GIT_URL")
 	if gitURL == "" {
 		getGitRepo := exec.Command("git", "config", "--get", "remote.origin.url")
 		bGitRepo, err := getGitRepo.Output()
 		if err != nil {
-			return nil, fmt.Errorf("getting git repo from git config: %w", err)
+			return "", fmt.Errorf("getting git repo from git config: %w", err)
 		}
-		gitURL = string(bGitRepo)
+		gitURL = strings.TrimSpace(string(bGitRepo))
 	}
-
-	return pipeline.NewGitResource("source-git", gitURL), nil
+	return gitURL, nil
 }
 
 func generatePipeline(tasks []*tekton.Task) (*tekton.Pipeline, error) {
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generatepipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+type githubWorkflow struct {
+	Name string          `yaml:"name"`
+	On   githubTriggers  `yaml:"on"`
+	Jobs yamlv2.MapSlice `yaml:"jobs"`
+}
+
+type githubTriggers struct {
+	Push githubPush `yaml:"push"`
+}
+
+type githubPush struct {
+	Branches []string `yaml:"branches"`
+}
+
+type githubJob struct {
+	Name   string       `yaml:"name"`
+	RunsOn string       `yaml:"runs-on"`
+	Needs  []string     `yaml:"needs,omitempty"`
+	Steps  []githubStep `yaml:"steps"`
+}
+
+type githubStep struct {
+	Name string            `yaml:"name,omitempty"`
+	Uses string            `yaml:"uses,omitempty"`
+	With yamlv2.MapSlice   `yaml:"with,omitempty"`
+	Env  map[string]string `yaml:"env,omitempty"`
+	Run  string            `yaml:"run,omitempty"`
+}
+
+func githubActionsYaml(opts Options, configFiles []*ConfigFile) (*bytes.Buffer, error) {
+	if len(configFiles) == 0 {
+		return nil, errors.New("no configs to add to pipeline")
+	}
+
+	workflow := githubWorkflow{
+		Name: "skaffold",
+		On:   githubTriggers{Push: githubPush{Branches: []string{"main"}}},
+	}
+	for i, configFile := range configFiles {
+		if len(configFile.Config.Build.Artifacts) == 0 {
+			return nil, errors.New("no artifacts to build")
+		}
+		buildJob := fmt.Sprintf("build-%d", i)
+		buildOutput := fmt.Sprintf("build-%d.json", i)
+
+		buildSteps := []githubStep{{Uses: "actions/checkout@v2"}, githubInstallSkaffold()}
+		for _, registry := range registries(opts, []*ConfigFile{configFile}) {
+			buildSteps = append(buildSteps, githubStep{
+				Name: "Log in to " + registry,
+				Uses: "docker/login-action@v1",
+				With: yamlv2.MapSlice{
+					{Key: "registry", Value: registry},
+					{Key: "username", Value: githubSecret(registryUsernamePlaceholder)},
+					{Key: "password", Value: githubSecret(registryPasswordPlaceholder)},
+				},
+			})
+		}
+		buildSteps = append(buildSteps,
+			githubStep{Name: "Build and push", Run: skaffoldCommand(buildArgs(configFile, opts, opts.Profiles, buildOutput))},
+			githubStep{Name: "Test", Run: skaffoldCommand(testArgs(configFile, opts.Profiles, buildOutput))},
+			githubStep{Uses: "actions/upload-artifact@v2", With: yamlv2.MapSlice{{Key: "name", Value: buildJob}, {Key: "path", Value: buildOutput}}},
+		)
+
+		deploySteps := []githubStep{
+			{Uses: "actions/checkout@v2"},
+			githubInstallSkaffold(),
+			{Uses: "actions/download-artifact@v2", With: yamlv2.MapSlice{{Key: "name", Value: buildJob}}},
+			{
+				Name: "Configure cluster access",
+				Env:  map[string]string{"KUBECONFIG_DATA": githubSecret(kubeconfigPlaceholder)},
+				Run:  "mkdir -p $HOME/.kube\necho \"$KUBECONFIG_DATA\" > $HOME/.kube/config\n",
+			},
+			{Name: "Deploy", Run: skaffoldCommand(deployArgs(configFile, opts, opts.Profiles, buildOutput))},
+		}
+
+		workflow.Jobs = append(workflow.Jobs,
+			yamlv2.MapItem{Key: buildJob, Value: githubJob{Name: "Build " + displayName(configFile), RunsOn: "ubuntu-latest", Steps: buildSteps}},
+			yamlv2.MapItem{Key: fmt.Sprintf("deploy-%d", i), Value: githubJob{Name: "Deploy " + displayName(configFile), RunsOn: "ubuntu-latest", Needs: []string{buildJob}, Steps: deploySteps}},
+		)
+	}
+
+	bWorkflow, err := yamlv2.Marshal(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling workflow: %w", err)
+	}
+
+	output := bytes.NewBufferString(header("repository secrets"))
+	output.Write(bWorkflow)
+	return output, nil
+}
+
+func githubInstallSkaffold() githubStep {
+	return githubStep{
+		Name: "Install skaffold",
+		Run:  fmt.Sprintf("curl -fsSLo skaffold https://storage.googleapis.com/skaffold/releases/%s/skaffold-linux-amd64\nsudo install skaffold /usr/local/bin/\n", skaffoldVersion()),
+	}
+}
+
+func githubSecret(name string) string {
+	return fmt.Sprintf("${{ secrets.%s }}", name)
+}
+
+// skaffoldCommand returns a skaffold command line.
+// The trailing newline renders it as a literal block so that long command lines aren't folded.
+func skaffoldCommand(args []string) string {
+	return "skaffold " + strings.Join(args, " ") + "\n"
+}
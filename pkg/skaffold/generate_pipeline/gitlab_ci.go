@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generatepipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+type gitlabJob struct {
+	Stage        string            `yaml:"stage"`
+	Image        string            `yaml:"image"`
+	Services     []string          `yaml:"services,omitempty"`
+	Variables    map[string]string `yaml:"variables,omitempty"`
+	Needs        []string          `yaml:"needs,omitempty"`
+	BeforeScript []string          `yaml:"before_script,omitempty"`
+	Script       []string          `yaml:"script"`
+	Artifacts    *gitlabArtifacts  `yaml:"artifacts,omitempty"`
+}
+
+type gitlabArtifacts struct {
+	Paths []string `yaml:"paths"`
+}
+
+func gitlabCIYaml(opts Options, configFiles []*ConfigFile) (*bytes.Buffer, error) {
+	if len(configFiles) == 0 {
+		return nil, errors.New("no configs to add to pipeline")
+	}
+
+	image := fmt.Sprintf("gcr.io/k8s-skaffold/skaffold:%s", skaffoldVersion())
+	pipeline := yamlv2.MapSlice{{Key: "stages", Value: []string{"build", "test", "deploy"}}}
+	for i, configFile := range configFiles {
+		if len(configFile.Config.Build.Artifacts) == 0 {
+			return nil, errors.New("no artifacts to build")
+		}
+		buildJob := fmt.Sprintf("build-%d", i)
+		testJob := fmt.Sprintf("test-%d", i)
+		buildOutput := fmt.Sprintf("build-%d.json", i)
+
+		var login []string
+		for _, registry := range registries(opts, []*ConfigFile{configFile}) {
+			login = append(login, fmt.Sprintf(`echo "$%s" | docker login --username "$%s" --password-stdin %s
+`, registryPasswordPlaceholder, registryUsernamePlaceholder, registry))
+		}
+
+		dockerVariables := map[string]string{
+			"DOCKER_HOST":        "tcp://docker:2375",
+			"DOCKER_TLS_CERTDIR": "",
+		}
+
+		pipeline = append(pipeline,
+			yamlv2.MapItem{Key: buildJob, Value: gitlabJob{
+				Stage:        "build",
+				Image:        image,
+				Services:     []string{"docker:dind"},
+				Variables:    dockerVariables,
+				BeforeScript: login,
+				Script:       []string{skaffoldCommand(buildArgs(configFile, opts, opts.Profiles, buildOutput))},
+				Artifacts:    &gitlabArtifacts{Paths: []string{buildOutput}},
+			}},
+			// the tests pull the images that were pushed by the build job.
+			yamlv2.MapItem{Key: testJob, Value: gitlabJob{
+				Stage:        "test",
+				Image:        image,
+				Services:     []string{"docker:dind"},
+				Variables:    dockerVariables,
+				Needs:        []string{buildJob},
+				BeforeScript: login,
+				Script:       []string{skaffoldCommand(testArgs(configFile, opts.Profiles, buildOutput))},
+			}},
+			// a file variable named KUBECONFIG is picked up by kubectl without further configuration.
+			yamlv2.MapItem{Key: fmt.Sprintf("deploy-%d", i), Value: gitlabJob{
+				Stage:  "deploy",
+				Image:  image,
+				Needs:  []string{buildJob, testJob},
+				Script: []string{skaffoldCommand(deployArgs(configFile, opts, opts.Profiles, buildOutput))},
+			}},
+		)
+	}
+
+	bPipeline, err := yamlv2.Marshal(pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling pipeline: %w", err)
+	}
+
+	output := bytes.NewBufferString(header("CI/CD variables (KUBECONFIG as a file variable)"))
+	output.Write(bPipeline)
+	return output, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generatepipeline
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/version"
+)
+
+// Supported pipeline targets.
+const (
+	TargetTekton        = "tekton"
+	TargetTektonV1      = "tekton-v1"
+	TargetGitHubActions = "github-actions"
+	TargetGitLabCI      = "gitlab-ci"
+)
+
+// Targets lists the CI systems that pipelines can be generated for.
+var Targets = []string{TargetTekton, TargetTektonV1, TargetGitHubActions, TargetGitLabCI}
+
+// Placeholders for the credentials that must be configured in the CI system.
+const (
+	registryUsernamePlaceholder = "REGISTRY_USERNAME"
+	registryPasswordPlaceholder = "REGISTRY_PASSWORD"
+	kubeconfigPlaceholder       = "KUBECONFIG"
+)
+
+// Options holds the skaffold flags that the generated pipeline passes on to skaffold.
+type Options struct {
+	Namespace   string
+	Profiles    []string
+	DefaultRepo string
+}
+
+// Generate renders the pipeline for the given target.
+func Generate(out io.Writer, target string, opts Options, configFiles []*ConfigFile) (*bytes.Buffer, error) {
+	switch target {
+	case TargetTekton:
+		return Yaml(out, opts.Namespace, configFiles)
+	case TargetTektonV1:
+		return tektonV1Yaml(opts, configFiles)
+	case TargetGitHubActions:
+		return githubActionsYaml(opts, configFiles)
+	case TargetGitLabCI:
+		return gitlabCIYaml(opts, configFiles)
+	default:
+		return nil, fmt.Errorf("unknown pipeline target %q, expected one of %s", target, strings.Join(Targets, ", "))
+	}
+}
+
+// OutputFile returns the file the pipeline for the given target is written to by default.
+func OutputFile(target string) string {
+	switch target {
+	case TargetGitHubActions:
+		return ".github/workflows/skaffold.yaml"
+	case TargetGitLabCI:
+		return ".gitlab-ci.yml"
+	default:
+		return "pipeline.yaml"
+	}
+}
+
+// BuildsOnCluster returns true if the pipeline for the given target builds images on the cluster
+// and therefore needs the "oncluster" profile.
+func BuildsOnCluster(target string) bool {
+	return target == TargetTekton || target == TargetTektonV1
+}
+
+func skaffoldVersion() string {
+	if v := os.Getenv("PIPELINE_SKAFFOLD_VERSION"); v != "" {
+		return v
+	}
+	return version.Get().Version
+}
+
+// buildArgs returns the arguments of the `skaffold build` command for a config file.
+func buildArgs(configFile *ConfigFile, opts Options, profiles []string, fileOutput string) []string {
+	args := []string{"build"}
+	args = append(args, selectionArgs(configFile, profiles)...)
+	if opts.DefaultRepo != "" {
+		args = append(args, "--default-repo", opts.DefaultRepo)
+	}
+	// tests run in a separate step, on the pushed images.
+	return append(args, "--push", "--skip-tests", "--file-output", fileOutput)
+}
+
+// testArgs returns the arguments of the `skaffold test` command for a config file.
+func testArgs(configFile *ConfigFile, profiles []string, buildArtifacts string) []string {
+	args := []string{"test"}
+	args = append(args, selectionArgs(configFile, profiles)...)
+	return append(args, "--build-artifacts", buildArtifacts)
+}
+
+// deployArgs returns the arguments of the `skaffold deploy` command for a config file.
+func deployArgs(configFile *ConfigFile, opts Options, profiles []string, buildArtifacts string) []string {
+	args := []string{"deploy"}
+	args = append(args, selectionArgs(configFile, profiles)...)
+	args = append(args, "--build-artifacts", buildArtifacts)
+	if opts.Namespace != "" {
+		args = append(args, "--namespace", opts.Namespace)
+	}
+	return args
+}
+
+// selectionArgs selects the config file, its module and the profiles to activate.
+func selectionArgs(configFile *ConfigFile, profiles []string) []string {
+	args := []string{"--filename", configFile.Path}
+	if module := configFile.Config.Metadata.Name; module != "" {
+		args = append(args, "--module", module)
+	}
+	if len(profiles) > 0 {
+		args = append(args, "--profile", strings.Join(profiles, ","))
+	}
+	return args
+}
+
+// displayName returns a human readable name for the jobs of a config file.
+func displayName(configFile *ConfigFile) string {
+	if module := configFile.Config.Metadata.Name; module != "" {
+		return module
+	}
+	return configFile.Path
+}
+
+// registries returns the registries that images of the config files are pushed to.
+func registries(opts Options, configFiles []*ConfigFile) []string {
+	images := []string{opts.DefaultRepo}
+	if opts.DefaultRepo == "" {
+		images = nil
+		for _, configFile := range configFiles {
+			for _, artifact := range configFile.Config.Build.Artifacts {
+				images = append(images, artifact.ImageName)
+			}
+		}
+	}
+
+	found := map[string]bool{}
+	var registries []string
+	for _, image := range images {
+		repo, err := name.NewRepository(image)
+		if err != nil {
+			continue
+		}
+		registry := repo.RegistryStr()
+		if registry == name.DefaultRegistry {
+			registry = "docker.io"
+		}
+		if !found[registry] {
+			found[registry] = true
+			registries = append(registries, registry)
+		}
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// header documents the placeholders that must be configured in the CI system.
+func header(secretsLocation string) string {
+	return fmt.Sprintf(`# Generated by skaffold generate-pipeline.
+# Configure the following %s before running this pipeline:
+#   %s, %s: credentials of the image registries
+#   %s: kubeconfig of the target cluster
+`, secretsLocation, registryUsernamePlaceholder, registryPasswordPlaceholder, kubeconfigPlaceholder)
+}
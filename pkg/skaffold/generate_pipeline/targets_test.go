@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generatepipeline
+
+import (
+	"bytes"
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+const expectedGitHubActions = `# Generated by skaffold generate-pipeline.
+# Configure the following repository secrets before running this pipeline:
+#   REGISTRY_USERNAME, REGISTRY_PASSWORD: credentials of the image registries
+#   KUBECONFIG: kubeconfig of the target cluster
+name: skaffold
+"on":
+  push:
+    branches:
+    - main
+jobs:
+  build-0:
+    name: Build web
+    runs-on: ubuntu-latest
+    steps:
+    - uses: actions/checkout@v2
+    - name: Install skaffold
+      run: |
+        curl -fsSLo skaffold https://storage.googleapis.com/skaffold/releases/v1.27.0/skaffold-linux-amd64
+        sudo install skaffold /usr/local/bin/
+    - name: Log in to gcr.io
+      uses: docker/login-action@v1
+      with:
+        registry: gcr.io
+        username: ${{ secrets.REGISTRY_USERNAME }}
+        password: ${{ secrets.REGISTRY_PASSWORD }}
+    - name: Build and push
+      run: |
+        skaffold build --filename skaffold.yaml --module web --profile ci --push --skip-tests --file-output build-0.json
+    - name: Test
+      run: |
+        skaffold test --filename skaffold.yaml --module web --profile ci --build-artifacts build-0.json
+    - uses: actions/upload-artifact@v2
+      with:
+        name: build-0
+        path: build-0.json
+  deploy-0:
+    name: Deploy web
+    runs-on: ubuntu-latest
+    needs:
+    - build-0
+    steps:
+    - uses: actions/checkout@v2
+    - name: Install skaffold
+      run: |
+        curl -fsSLo skaffold https://storage.googleapis.com/skaffold/releases/v1.27.0/skaffold-linux-amd64
+        sudo install skaffold /usr/local/bin/
+    - uses: actions/download-artifact@v2
+      with:
+        name: build-0
+    - name: Configure cluster access
+      env:
+        KUBECONFIG_DATA: ${{ secrets.KUBECONFIG }}
+      run: |
+        mkdir -p $HOME/.kube
+        echo "$KUBECONFIG_DATA" > $HOME/.kube/config
+    - name: Deploy
+      run: |
+        skaffold deploy --filename skaffold.yaml --module web --profile ci --build-artifacts build-0.json --namespace prod
+`
+
+const expectedGitLabCI = `# Generated by skaffold generate-pipeline.
+# Configure the following CI/CD variables (KUBECONFIG as a file variable) before running this pipeline:
+#   REGISTRY_USERNAME, REGISTRY_PASSWORD: credentials of the image registries
+#   KUBECONFIG: kubeconfig of the target cluster
+stages:
+- build
+- test
+- deploy
+build-0:
+  stage: build
+  image: gcr.io/k8s-skaffold/skaffold:v1.27.0
+  services:
+  - docker:dind
+  variables:
+    DOCKER_HOST: tcp://docker:2375
+    DOCKER_TLS_CERTDIR: ""
+  before_script:
+  - |
+    echo "$REGISTRY_PASSWORD" | docker login --username "$REGISTRY_USERNAME" --password-stdin gcr.io
+  script:
+  - |
+    skaffold build --filename skaffold.yaml --module web --profile ci --push --skip-tests --file-output build-0.json
+  artifacts:
+    paths:
+    - build-0.json
+test-0:
+  stage: test
+  image: gcr.io/k8s-skaffold/skaffold:v1.27.0
+  services:
+  - docker:dind
+  variables:
+    DOCKER_HOST: tcp://docker:2375
+    DOCKER_TLS_CERTDIR: ""
+  needs:
+  - build-0
+  before_script:
+  - |
+    echo "$REGISTRY_PASSWORD" | docker login --username "$REGISTRY_USERNAME" --password-stdin gcr.io
+  script:
+  - |
+    skaffold test --filename skaffold.yaml --module web --profile ci --build-artifacts build-0.json
+deploy-0:
+  stage: deploy
+  image: gcr.io/k8s-skaffold/skaffold:v1.27.0
+  needs:
+  - build-0
+  - test-0
+  script:
+  - |
+    skaffold deploy --filename skaffold.yaml --module web --profile ci --build-artifacts build-0.json --namespace prod
+`
+
+func TestGenerate(t *testing.T) {
+	configFiles := []*ConfigFile{
+		{
+			Path: "skaffold.yaml",
+			Config: &latestV1.SkaffoldConfig{
+				Metadata: latestV1.Metadata{Name: "web"},
+				Pipeline: latestV1.Pipeline{
+					Build: latestV1.BuildConfig{
+						Artifacts: []*latestV1.Artifact{{ImageName: "gcr.io/project/web"}},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		description string
+		target      string
+		expected    string
+		shouldErr   bool
+	}{
+		{
+			description: "github actions",
+			target:      TargetGitHubActions,
+			expected:    expectedGitHubActions,
+		},
+		{
+			description: "gitlab ci",
+			target:      TargetGitLabCI,
+			expected:    expectedGitLabCI,
+		},
+		{
+			description: "unknown target",
+			target:      "jenkins",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.SetEnvs(map[string]string{"PIPELINE_SKAFFOLD_VERSION": "v1.27.0"})
+
+			output, err := Generate(&bytes.Buffer{}, test.target, Options{Namespace: "prod", Profiles: []string{"ci"}}, configFiles)
+
+			if test.shouldErr {
+				t.CheckError(true, err)
+				return
+			}
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, output.String())
+		})
+	}
+}
+
+func TestGenerateTektonV1(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.SetEnvs(map[string]string{
+			"PIPELINE_SKAFFOLD_VERSION": "v1.27.0",
+			"PIPELINE_GIT_URL":          "https://github.com/org/repo.git",
+		})
+		configFiles := []*ConfigFile{
+			{
+				Path: "skaffold.yaml",
+				Config: &latestV1.SkaffoldConfig{
+					Pipeline: latestV1.Pipeline{
+						Build: latestV1.BuildConfig{
+							Artifacts: []*latestV1.Artifact{{ImageName: "gcr.io/project/web"}},
+						},
+					},
+				},
+				Profile: &latestV1.Profile{
+					Name: "oncluster",
+					Pipeline: latestV1.Pipeline{
+						Build: latestV1.BuildConfig{
+							Artifacts: []*latestV1.Artifact{{
+								ImageName:    "gcr.io/project/web-pipeline",
+								ArtifactType: latestV1.ArtifactType{KanikoArtifact: &latestV1.KanikoArtifact{}},
+							}},
+						},
+					},
+				},
+			},
+		}
+
+		output, err := Generate(&bytes.Buffer{}, TargetTektonV1, Options{Profiles: []string{"ci"}}, configFiles)
+
+		t.CheckNoError(err)
+		t.CheckContains("apiVersion: tekton.dev/v1\nkind: Pipeline", output.String())
+		t.CheckContains("- default: https://github.com/org/repo.git\n    name: git-url", output.String())
+		t.CheckContains("    - --profile\n    - ci,oncluster\n", output.String())
+		t.CheckContains("secretName: kaniko-secret", output.String())
+		t.CheckContains("runAfter:\n    - skaffold-build-0-task", output.String())
+	})
+}
+
+func TestRegistries(t *testing.T) {
+	configFiles := []*ConfigFile{
+		{
+			Config: &latestV1.SkaffoldConfig{
+				Pipeline: latestV1.Pipeline{
+					Build: latestV1.BuildConfig{
+						Artifacts: []*latestV1.Artifact{
+							{ImageName: "gcr.io/project/web"},
+							{ImageName: "busybox"},
+							{ImageName: "gcr.io/project/api"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testutil.Run(t, "artifact registries", func(t *testutil.T) {
+		t.CheckDeepEqual([]string{"docker.io", "gcr.io"}, registries(Options{}, configFiles))
+	})
+	testutil.Run(t, "default repo", func(t *testutil.T) {
+		t.CheckDeepEqual([]string{"us-docker.pkg.dev"}, registries(Options{DefaultRepo: "us-docker.pkg.dev/project/repo"}, configFiles))
+	})
+}
@@ -25,6 +25,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/pipeline"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/version"
 )
 
@@ -82,10 +83,18 @@ func generateBuildTask(configFile *ConfigFile) (*tekton.Task, error) {
 	}
 
 	// Add secret volume mounting if any artifacts in config need to be built with kaniko
-	var volumes []v1.Volume
+	volumes, mounts, env := kanikoSecret(buildConfig)
+	steps[0].VolumeMounts = mounts
+	steps[0].Env = env
+
+	return pipeline.NewTask("skaffold-build", inputs, outputs, steps, volumes), nil
+}
+
+// kanikoSecret returns the volume, mount and environment that give kaniko builds access to the registry credentials.
+func kanikoSecret(buildConfig latestV1.BuildConfig) ([]v1.Volume, []v1.VolumeMount, []v1.EnvVar) {
 	for _, artifact := range buildConfig.Artifacts {
 		if artifact.KanikoArtifact != nil {
-			volumes = []v1.Volume{
+			volumes := []v1.Volume{
 				{
 					Name: kanikoSecretName,
 					VolumeSource: v1.VolumeSource{
@@ -95,22 +104,22 @@ func generateBuildTask(configFile *ConfigFile) (*tekton.Task, error) {
 					},
 				},
 			}
-			steps[0].VolumeMounts = []v1.VolumeMount{
+			mounts := []v1.VolumeMount{
 				{
 					Name:      kanikoSecretName,
 					MountPath: "/secret",
 				},
 			}
-			steps[0].Env = []v1.EnvVar{
+			env := []v1.EnvVar{
 				{
 					Name:  "GOOGLE_APPLICATION_CREDENTIALS",
 					Value: "/secret/" + kanikoSecretName,
 				},
 			}
+			return volumes, mounts, env
 		}
 	}
-
-	return pipeline.NewTask("skaffold-build", inputs, outputs, steps, volumes), nil
+	return nil, nil, nil
 }
 
 func generateDeployTasks(namespace string, configFiles []*ConfigFile) ([]*tekton.Task, error) {
@@ -0,0 +1,222 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generatepipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	v1 "k8s.io/api/core/v1"
+)
+
+const tektonV1APIVersion = "tekton.dev/v1"
+
+// The vendored Tekton API only covers v1alpha1, so the subset of tekton.dev/v1 used by skaffold is declared here.
+type tektonV1Object struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   tektonV1ObjectMeta `json:"metadata"`
+	Spec       interface{}        `json:"spec"`
+}
+
+type tektonV1ObjectMeta struct {
+	Name string `json:"name"`
+}
+
+type tektonV1TaskSpec struct {
+	Params     []tektonV1ParamSpec     `json:"params,omitempty"`
+	Workspaces []tektonV1WorkspaceSpec `json:"workspaces"`
+	Steps      []tektonV1Step          `json:"steps"`
+	Volumes    []v1.Volume             `json:"volumes,omitempty"`
+}
+
+type tektonV1ParamSpec struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default,omitempty"`
+}
+
+type tektonV1WorkspaceSpec struct {
+	Name string `json:"name"`
+}
+
+type tektonV1Step struct {
+	Name         string           `json:"name"`
+	Image        string           `json:"image"`
+	WorkingDir   string           `json:"workingDir"`
+	Command      []string         `json:"command"`
+	Args         []string         `json:"args"`
+	Env          []v1.EnvVar      `json:"env,omitempty"`
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+}
+
+type tektonV1PipelineSpec struct {
+	Params     []tektonV1ParamSpec     `json:"params"`
+	Workspaces []tektonV1WorkspaceSpec `json:"workspaces"`
+	Tasks      []tektonV1PipelineTask  `json:"tasks"`
+}
+
+type tektonV1PipelineTask struct {
+	Name       string                     `json:"name"`
+	TaskRef    tektonV1TaskRef            `json:"taskRef"`
+	RunAfter   []string                   `json:"runAfter,omitempty"`
+	Params     []tektonV1Param            `json:"params,omitempty"`
+	Workspaces []tektonV1WorkspaceBinding `json:"workspaces"`
+}
+
+type tektonV1TaskRef struct {
+	Name string `json:"name"`
+}
+
+type tektonV1Param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type tektonV1WorkspaceBinding struct {
+	Name      string `json:"name"`
+	Workspace string `json:"workspace"`
+}
+
+func tektonV1Yaml(opts Options, configFiles []*ConfigFile) (*bytes.Buffer, error) {
+	if len(configFiles) == 0 {
+		return nil, errors.New("no configs to add to pipeline")
+	}
+	gitURL, err := gitRepoURL()
+	if err != nil {
+		return nil, fmt.Errorf("generating git source for pipeline: %w", err)
+	}
+
+	image := fmt.Sprintf("gcr.io/k8s-skaffold/skaffold:%s", skaffoldVersion())
+	source := []tektonV1WorkspaceSpec{{Name: "source"}}
+	bindings := []tektonV1WorkspaceBinding{{Name: "source", Workspace: "source"}}
+
+	objects := []tektonV1Object{
+		newTektonV1Task("skaffold-git-clone", tektonV1TaskSpec{
+			Params:     []tektonV1ParamSpec{{Name: "url", Type: "string"}},
+			Workspaces: source,
+			Steps: []tektonV1Step{{
+				Name:       "clone",
+				Image:      "alpine/git",
+				WorkingDir: "$(workspaces.source.path)",
+				Command:    []string{"git"},
+				Args:       []string{"clone", "$(params.url)", "."},
+			}},
+		}),
+	}
+	pipelineTasks := []tektonV1PipelineTask{{
+		Name:       "fetch-source",
+		TaskRef:    tektonV1TaskRef{Name: "skaffold-git-clone"},
+		Params:     []tektonV1Param{{Name: "url", Value: "$(params.git-url)"}},
+		Workspaces: bindings,
+	}}
+
+	for i, configFile := range configFiles {
+		// build with the "oncluster" profile, unless the user chose not to create it.
+		buildConfig := configFile.Config.Build
+		profiles := opts.Profiles
+		if configFile.Profile != nil {
+			buildConfig = configFile.Profile.Build
+			profiles = append(append([]string{}, opts.Profiles...), configFile.Profile.Name)
+		}
+		if len(buildConfig.Artifacts) == 0 {
+			return nil, errors.New("no artifacts to build")
+		}
+
+		buildTask := fmt.Sprintf("skaffold-build-%d", i)
+		deployTask := fmt.Sprintf("skaffold-deploy-%d", i)
+		buildOutput := fmt.Sprintf("build-%d.json", i)
+		volumes, mounts, env := kanikoSecret(buildConfig)
+
+		objects = append(objects,
+			newTektonV1Task(buildTask, tektonV1TaskSpec{
+				Workspaces: source,
+				Steps: []tektonV1Step{{
+					Name:         "run-build",
+					Image:        image,
+					WorkingDir:   "$(workspaces.source.path)",
+					Command:      []string{"skaffold"},
+					Args:         buildArgs(configFile, opts, profiles, buildOutput),
+					Env:          env,
+					VolumeMounts: mounts,
+				}},
+				Volumes: volumes,
+			}),
+			newTektonV1Task(deployTask, tektonV1TaskSpec{
+				Workspaces: source,
+				Steps: []tektonV1Step{{
+					Name:       "run-deploy",
+					Image:      image,
+					WorkingDir: "$(workspaces.source.path)",
+					Command:    []string{"skaffold"},
+					Args:       deployArgs(configFile, opts, opts.Profiles, buildOutput),
+				}},
+			}),
+		)
+		pipelineTasks = append(pipelineTasks,
+			tektonV1PipelineTask{
+				Name:       buildTask + "-task",
+				TaskRef:    tektonV1TaskRef{Name: buildTask},
+				RunAfter:   []string{"fetch-source"},
+				Workspaces: bindings,
+			},
+			tektonV1PipelineTask{
+				Name:       deployTask + "-task",
+				TaskRef:    tektonV1TaskRef{Name: deployTask},
+				RunAfter:   []string{buildTask + "-task"},
+				Workspaces: bindings,
+			},
+		)
+	}
+
+	objects = append(objects, tektonV1Object{
+		APIVersion: tektonV1APIVersion,
+		Kind:       "Pipeline",
+		Metadata:   tektonV1ObjectMeta{Name: "skaffold-pipeline"},
+		Spec: tektonV1PipelineSpec{
+			Params:     []tektonV1ParamSpec{{Name: "git-url", Type: "string", Default: gitURL}},
+			Workspaces: source,
+			Tasks:      pipelineTasks,
+		},
+	})
+
+	output := bytes.NewBuffer([]byte{})
+	for _, object := range objects {
+		bObject, err := json.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling %s: %w", object.Metadata.Name, err)
+		}
+		objectYaml, err := yaml.JSONToYAML(bObject)
+		if err != nil {
+			return nil, fmt.Errorf("converting jsons to yamls: %w", err)
+		}
+		output.Write(append(objectYaml, []byte("---\n")...))
+	}
+	return output, nil
+}
+
+func newTektonV1Task(name string, spec tektonV1TaskSpec) tektonV1Object {
+	return tektonV1Object{
+		APIVersion: tektonV1APIVersion,
+		Kind:       "Task",
+		Metadata:   tektonV1ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
@@ -22,6 +22,8 @@ import latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/la
 type Artifact struct {
 	ImageName string `json:"imageName"`
 	Tag       string `json:"tag"`
+	// IndexDigest is the digest of the image index of an image built for multiple platforms.
+	IndexDigest string `json:"indexDigest,omitempty"`
 }
 
 // ArtifactGraph is a map of [artifact image : artifact definition]
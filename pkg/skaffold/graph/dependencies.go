@@ -24,6 +24,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/buildpacks"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/custom"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/jib"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/ko"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/misc"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
@@ -131,6 +132,14 @@ func sourceDependenciesForArtifact(ctx context.Context, a *latestV1.Artifact, cf
 		}
 		paths, err = docker.GetDependencies(ctx, docker.NewBuildConfig(a.Workspace, a.ImageName, a.KanikoArtifact.DockerfilePath, args), cfg)
 
+	case a.BuildKitArtifact != nil:
+		deps := docker.ResolveDependencyImages(a.Dependencies, r, false)
+		args, evalErr := docker.EvalBuildArgs(cfg.Mode(), a.Workspace, a.BuildKitArtifact.DockerfilePath, a.BuildKitArtifact.BuildArgs, deps)
+		if evalErr != nil {
+			return nil, fmt.Errorf("unable to evaluate build args: %w", evalErr)
+		}
+		paths, err = docker.GetDependencies(ctx, docker.NewBuildConfig(a.Workspace, a.ImageName, a.BuildKitArtifact.DockerfilePath, args), cfg)
+
 	case a.BazelArtifact != nil:
 		paths, err = bazel.GetDependencies(ctx, a.Workspace, a.BazelArtifact)
 
@@ -143,6 +152,9 @@ func sourceDependenciesForArtifact(ctx context.Context, a *latestV1.Artifact, cf
 	case a.BuildpackArtifact != nil:
 		paths, err = buildpacks.GetDependencies(ctx, a.Workspace, a.BuildpackArtifact)
 
+	case a.KoArtifact != nil:
+		paths, err = ko.GetDependencies(ctx, a.Workspace, a.KoArtifact)
+
 	default:
 		return nil, fmt.Errorf("unexpected artifact type %q:\n%s", misc.ArtifactType(a), misc.FormatArtifact(a))
 	}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/label"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	v1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// containerSelector selects the containers in which a container hook is executed
+type containerSelector func(corev1.Pod, corev1.Container) (bool, error)
+
+// containerHook represents a lifecycle hook to be executed inside running containers
+type containerHook struct {
+	cfg        v1.ContainerHook
+	cli        *kubectl.CLI
+	selector   containerSelector
+	namespaces []string
+	runID      string
+}
+
+// run executes the lifecycle hook, one container at a time, in all the running containers of the current run matched by the selector
+func (h containerHook) run(ctx context.Context, out io.Writer) error {
+	client, err := kubernetesclient.Client()
+	if err != nil {
+		return fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+
+	for _, ns := range h.namespaces {
+		pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", label.RunIDLabel, h.runID),
+		})
+		if err != nil {
+			return fmt.Errorf("getting pods for namespace %q: %w", ns, err)
+		}
+
+		for _, p := range pods.Items {
+			if p.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			for _, c := range p.Spec.Containers {
+				matched, err := h.selector(p, c)
+				if err != nil {
+					return err
+				}
+				if !matched {
+					continue
+				}
+
+				args := []string{p.Name, "--namespace", p.Namespace, "-c", c.Name, "--"}
+				args = append(args, h.cfg.Command...)
+				cmd := h.cli.Command(ctx, "exec", args...)
+				cmd.Stdout = out
+				cmd.Stderr = out
+
+				logrus.Debugf("Running command in container %s of pod %s: %s", c.Name, p.Name, h.cfg.Command)
+				if err := util.RunCmd(cmd); err != nil {
+					return fmt.Errorf("running hook in container %s of pod %s: %w", c.Name, p.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
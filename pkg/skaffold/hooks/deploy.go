@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	v1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// DeployRunner creates a new runner for pre-deploy and post-deploy lifecycle hooks.
+// Container hooks are executed in the namespaces the deployer has deployed to, which are read when the hooks run.
+func DeployRunner(cli *kubectl.CLI, d v1.DeployHooks, namespaces *[]string, opts DeployEnvOpts) Runner {
+	return deployRunner{DeployHooks: d, cli: cli, namespaces: namespaces, opts: opts}
+}
+
+// NewDeployEnvOpts returns `DeployEnvOpts` required to create a `Runner` for deploy lifecycle hooks
+func NewDeployEnvOpts(runID string, kubeContext string, namespaces []string) DeployEnvOpts {
+	return DeployEnvOpts{
+		RunID:       runID,
+		KubeContext: kubeContext,
+		Namespaces:  strings.Join(namespaces, ","),
+	}
+}
+
+type deployRunner struct {
+	v1.DeployHooks
+	cli        *kubectl.CLI
+	namespaces *[]string
+	opts       DeployEnvOpts
+}
+
+func (r deployRunner) RunPreHooks(ctx context.Context, out io.Writer) error {
+	return r.run(ctx, out, r.PreHooks, phases.PreDeploy)
+}
+
+func (r deployRunner) RunPostHooks(ctx context.Context, out io.Writer) error {
+	return r.run(ctx, out, r.PostHooks, phases.PostDeploy)
+}
+
+func (r deployRunner) getEnv() []string {
+	opts := r.opts
+	if r.namespaces != nil {
+		opts.Namespaces = strings.Join(*r.namespaces, ",")
+	}
+	common := getEnv(staticEnvOpts)
+	deploy := getEnv(opts)
+	return append(common, deploy...)
+}
+
+func (r deployRunner) run(ctx context.Context, out io.Writer, hooks []v1.DeployHookItem, phase phase) error {
+	if len(hooks) > 0 {
+		output.Default.Fprintln(out, fmt.Sprintf("Starting %s hooks...", phase))
+	}
+	env := r.getEnv()
+	for _, h := range hooks {
+		if h.HostHook != nil {
+			hook := hostHook{*h.HostHook, env}
+			if err := hook.run(ctx, out); err != nil {
+				return err
+			}
+		} else if h.ContainerHook != nil {
+			var namespaces []string
+			if r.namespaces != nil {
+				namespaces = *r.namespaces
+			}
+			hook := containerHook{
+				cfg:        h.ContainerHook.ContainerHook,
+				cli:        r.cli,
+				selector:   namedContainerSelector(*h.ContainerHook),
+				namespaces: namespaces,
+				runID:      r.opts.RunID,
+			}
+			if err := hook.run(ctx, out); err != nil {
+				return err
+			}
+		}
+	}
+	if len(hooks) > 0 {
+		output.Default.Fprintln(out, fmt.Sprintf("Completed %s hooks", phase))
+	}
+	return nil
+}
+
+// namedContainerSelector selects the containers matching the pod and container names of a hook.
+// Names can be glob patterns, like `web-*`. All the containers of the matched pods are selected if no container name is set.
+func namedContainerSelector(h v1.NamedContainerHook) containerSelector {
+	return func(p corev1.Pod, c corev1.Container) (bool, error) {
+		matched, err := path.Match(h.PodName, p.Name)
+		if err != nil || !matched {
+			return false, err
+		}
+		if h.ContainerName == "" {
+			return true, nil
+		}
+		return path.Match(h.ContainerName, c.Name)
+	}
+}
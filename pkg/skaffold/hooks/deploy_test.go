@@ -0,0 +1,99 @@
+// +build !windows
+
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	v1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestDeployHooks(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		hooks := v1.DeployHooks{
+			PreHooks: []v1.DeployHookItem{
+				{
+					HostHook: &v1.HostHook{
+						Command: []string{"sh", "-c", "echo pre-hook running with SKAFFOLD_RUN_ID=$SKAFFOLD_RUN_ID,SKAFFOLD_KUBE_CONTEXT=$SKAFFOLD_KUBE_CONTEXT,SKAFFOLD_NAMESPACES=$SKAFFOLD_NAMESPACES"},
+					},
+				},
+			},
+			PostHooks: []v1.DeployHookItem{
+				{
+					ContainerHook: &v1.NamedContainerHook{
+						ContainerHook: v1.ContainerHook{Command: []string{"foo", "bar"}},
+						PodName:       "web-*",
+						ContainerName: "app",
+					},
+				},
+			},
+		}
+		t.Override(&client.Client, func() (kubernetes.Interface, error) {
+			return fake.NewSimpleClientset(
+				runningPod("web-1", "np1", corev1.Container{Name: "app"}, corev1.Container{Name: "sidecar"}),
+				runningPod("db-1", "np1", corev1.Container{Name: "app"}),
+				otherRun(runningPod("web-2", "np1", corev1.Container{Name: "app"})),
+			), nil
+		})
+		t.Override(&util.DefaultExecCommand, testutil.CmdRun("kubectl --context kubecontext exec web-1 --namespace np1 -c app -- foo bar"))
+
+		namespaces := []string{"np1"}
+		runner := DeployRunner(&kubectl.CLI{KubeContext: "kubecontext"}, hooks, &namespaces, NewDeployEnvOpts("run_id", "kubecontext", nil))
+
+		var preOut, postOut bytes.Buffer
+		t.CheckNoError(runner.RunPreHooks(context.Background(), &preOut))
+		t.CheckContains("pre-hook running with SKAFFOLD_RUN_ID=run_id,SKAFFOLD_KUBE_CONTEXT=kubecontext,SKAFFOLD_NAMESPACES=np1\n", preOut.String())
+		t.CheckNoError(runner.RunPostHooks(context.Background(), &postOut))
+		t.CheckContains("Completed post-deploy hooks", postOut.String())
+	})
+}
+
+func TestNamedContainerSelector(t *testing.T) {
+	tests := []struct {
+		description string
+		hook        v1.NamedContainerHook
+		pod         string
+		container   string
+		expected    bool
+		shouldErr   bool
+	}{
+		{description: "exact match", hook: v1.NamedContainerHook{PodName: "web", ContainerName: "app"}, pod: "web", container: "app", expected: true},
+		{description: "any container", hook: v1.NamedContainerHook{PodName: "web"}, pod: "web", container: "sidecar", expected: true},
+		{description: "glob pod name", hook: v1.NamedContainerHook{PodName: "web-*"}, pod: "web-5d8f9", container: "app", expected: true},
+		{description: "other pod", hook: v1.NamedContainerHook{PodName: "web-*"}, pod: "db-0", container: "app"},
+		{description: "other container", hook: v1.NamedContainerHook{PodName: "web", ContainerName: "app"}, pod: "web", container: "sidecar"},
+		{description: "bad pattern", hook: v1.NamedContainerHook{PodName: "web-["}, pod: "web", container: "app", shouldErr: true},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			matched, err := namedContainerSelector(test.hook)(*runningPod(test.pod, ""), corev1.Container{Name: test.container})
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, matched)
+		})
+	}
+}
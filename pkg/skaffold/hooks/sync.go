@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	v1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// SyncRunner creates a new runner for pre-sync and post-sync lifecycle hooks.
+// Container hooks are executed in the running containers of the synced image, deployed by the run with the given ID.
+func SyncRunner(cli *kubectl.CLI, image string, runID string, namespaces []string, d v1.SyncHooks, opts SyncEnvOpts) Runner {
+	return syncRunner{SyncHooks: d, cli: cli, image: image, runID: runID, namespaces: namespaces, opts: opts}
+}
+
+// NewSyncEnvOpts returns `SyncEnvOpts` required to create a `Runner` for sync lifecycle hooks
+func NewSyncEnvOpts(a *v1.Artifact, image string, addOrModifyFiles []string, deleteFiles []string, namespaces []string, kubeContext string) (SyncEnvOpts, error) {
+	w, err := filepath.Abs(a.Workspace)
+	if err != nil {
+		return SyncEnvOpts{}, fmt.Errorf("determining build workspace directory for image %v: %w", a.ImageName, err)
+	}
+	var modified, deleted *string
+	if len(addOrModifyFiles) > 0 {
+		s := strings.Join(addOrModifyFiles, ",")
+		modified = &s
+	}
+	if len(deleteFiles) > 0 {
+		s := strings.Join(deleteFiles, ",")
+		deleted = &s
+	}
+	return SyncEnvOpts{
+		Image:                image,
+		BuildContext:         w,
+		FilesAddedOrModified: modified,
+		FilesDeleted:         deleted,
+		KubeContext:          kubeContext,
+		Namespaces:           strings.Join(namespaces, ","),
+	}, nil
+}
+
+type syncRunner struct {
+	v1.SyncHooks
+	cli        *kubectl.CLI
+	image      string
+	runID      string
+	namespaces []string
+	opts       SyncEnvOpts
+}
+
+func (r syncRunner) RunPreHooks(ctx context.Context, out io.Writer) error {
+	return r.run(ctx, out, r.PreHooks, phases.PreSync)
+}
+
+func (r syncRunner) RunPostHooks(ctx context.Context, out io.Writer) error {
+	return r.run(ctx, out, r.PostHooks, phases.PostSync)
+}
+
+func (r syncRunner) getEnv() []string {
+	common := getEnv(staticEnvOpts)
+	sync := getEnv(r.opts)
+	return append(common, sync...)
+}
+
+func (r syncRunner) run(ctx context.Context, out io.Writer, hooks []v1.SyncHookItem, phase phase) error {
+	if len(hooks) > 0 {
+		output.Default.Fprintln(out, fmt.Sprintf("Starting %s hooks...", phase))
+	}
+	env := r.getEnv()
+	for _, h := range hooks {
+		if h.HostHook != nil {
+			hook := hostHook{*h.HostHook, env}
+			if err := hook.run(ctx, out); err != nil {
+				return err
+			}
+		} else if h.ContainerHook != nil {
+			hook := containerHook{
+				cfg:        *h.ContainerHook,
+				cli:        r.cli,
+				selector:   runningImageSelector(r.image),
+				namespaces: r.namespaces,
+				runID:      r.runID,
+			}
+			if err := hook.run(ctx, out); err != nil {
+				return err
+			}
+		}
+	}
+	if len(hooks) > 0 {
+		output.Default.Fprintln(out, fmt.Sprintf("Completed %s hooks", phase))
+	}
+	return nil
+}
+
+// runningImageSelector selects the containers running the given image
+func runningImageSelector(image string) containerSelector {
+	return func(_ corev1.Pod, c corev1.Container) (bool, error) {
+		return c.Image == image, nil
+	}
+}
@@ -0,0 +1,95 @@
+// +build !windows
+
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+*/
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/label"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	v1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestSyncHooks(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		workDir, _ := filepath.Abs("./foo")
+		artifact := &v1.Artifact{ImageName: "img1", Workspace: "./foo"}
+		hooks := v1.SyncHooks{
+			PreHooks: []v1.SyncHookItem{
+				{
+					HostHook: &v1.HostHook{
+						Command: []string{"sh", "-c", "echo pre-hook running with SKAFFOLD_IMAGE=$SKAFFOLD_IMAGE,SKAFFOLD_BUILD_CONTEXT=$SKAFFOLD_BUILD_CONTEXT,SKAFFOLD_FILES_ADDED_OR_MODIFIED=$SKAFFOLD_FILES_ADDED_OR_MODIFIED,SKAFFOLD_FILES_DELETED=$SKAFFOLD_FILES_DELETED,SKAFFOLD_KUBE_CONTEXT=$SKAFFOLD_KUBE_CONTEXT,SKAFFOLD_NAMESPACES=$SKAFFOLD_NAMESPACES"},
+					},
+				},
+			},
+			PostHooks: []v1.SyncHookItem{
+				{
+					ContainerHook: &v1.ContainerHook{
+						Command: []string{"foo", "bar"},
+					},
+				},
+			},
+		}
+		t.Override(&client.Client, func() (kubernetes.Interface, error) {
+			return fake.NewSimpleClientset(
+				runningPod("pod1", "np1", corev1.Container{Name: "container1", Image: "gcr.io/foo/img1:latest"}),
+				runningPod("pod2", "np1", corev1.Container{Name: "container2", Image: "gcr.io/foo/img2:latest"}),
+				otherRun(runningPod("pod3", "np1", corev1.Container{Name: "container3", Image: "gcr.io/foo/img1:latest"})),
+			), nil
+		})
+		t.Override(&util.DefaultExecCommand, testutil.CmdRun("kubectl --context kubecontext exec pod1 --namespace np1 -c container1 -- foo bar"))
+
+		opts, err := NewSyncEnvOpts(artifact, "gcr.io/foo/img1:latest", []string{"foo1", "foo2"}, nil, []string{"np1", "np2"}, "kubecontext")
+		t.CheckNoError(err)
+		runner := SyncRunner(&kubectl.CLI{KubeContext: "kubecontext"}, "gcr.io/foo/img1:latest", "run_id", []string{"np1", "np2"}, hooks, opts)
+
+		var preOut, postOut bytes.Buffer
+		t.CheckNoError(runner.RunPreHooks(context.Background(), &preOut))
+		t.CheckContains(fmt.Sprintf("pre-hook running with SKAFFOLD_IMAGE=gcr.io/foo/img1:latest,SKAFFOLD_BUILD_CONTEXT=%s,SKAFFOLD_FILES_ADDED_OR_MODIFIED=foo1,foo2,SKAFFOLD_FILES_DELETED=,SKAFFOLD_KUBE_CONTEXT=kubecontext,SKAFFOLD_NAMESPACES=np1,np2\n", workDir), preOut.String())
+		t.CheckNoError(runner.RunPostHooks(context.Background(), &postOut))
+		t.CheckContains("Completed post-sync hooks", postOut.String())
+	})
+}
+
+// runningPod returns a running pod deployed by the run with ID `run_id`.
+func runningPod(name, namespace string, containers ...corev1.Container) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{label.RunIDLabel: "run_id"}},
+		Spec:       corev1.PodSpec{Containers: containers},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// otherRun makes a pod look like it was deployed by another run.
+func otherRun(pod *corev1.Pod) *corev1.Pod {
+	pod.Labels[label.RunIDLabel] = "other_run_id"
+	return pod
+}
@@ -52,15 +52,25 @@ import (
 )
 
 func ExportMetrics(exitCode int) error {
-	if !ShouldExportMetrics || meter.Command == "" {
+	if meter.Command == "" {
+		return nil
+	}
+	meter.ExitCode = exitCode
+	meter.Duration = time.Since(meter.StartTime)
+
+	if otlpMetricsEnabled() {
+		if err := exportOTLPMetrics(context.Background(), meter); err != nil {
+			logrus.Debugf("error exporting metrics to OTLP collector: %v", err)
+		}
+	}
+
+	if !ShouldExportMetrics {
 		return nil
 	}
 	home, err := homedir.Dir()
 	if err != nil {
 		return fmt.Errorf("retrieving home directory: %w", err)
 	}
-	meter.ExitCode = exitCode
-	meter.Duration = time.Since(meter.StartTime)
 	return exportMetrics(context.Background(),
 		filepath.Join(home, constants.DefaultSkaffoldDir, constants.DefaultMetricFile),
 		meter)
@@ -331,6 +341,11 @@ func initTraceExporter(opts ...TraceExporterOption) (trace.TracerProvider, func(
 		return tp, func(context.Context) error { shutdown(); return nil }, err
 	}
 
+	if otlpTracesEnabled() {
+		logrus.Debugf("using otlp trace exporter")
+		return initOTLPTraceExporter()
+	}
+
 	if otelTraceExporterVal, ok := os.LookupEnv("OTEL_TRACES_EXPORTER"); ok {
 		logrus.Debugf("using otel default exporter - OTEL_TRACES_EXPORTER=%s", otelTraceExporterVal)
 		return nil, func(context.Context) error { return nil }, nil
@@ -25,9 +25,10 @@ import (
 )
 
 // Init initializes the skaffold metrics and trace tooling built on top of open-telemetry (otel)
-func Init(configs []*latestV1.SkaffoldConfig, user string, opts ...TraceExporterOption) {
+func Init(configs []*latestV1.SkaffoldConfig, user string, globalConfig string, opts ...TraceExporterOption) {
+	loadOTLPConfig(globalConfig)
 	InitMeterFromConfig(configs, user)
-	InitTraceFromEnvVar()
+	InitTraceFromEnvVar(opts...)
 }
 
 func ShutdownAndFlush(ctx context.Context, exitCode int) {
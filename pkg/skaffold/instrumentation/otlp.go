@@ -0,0 +1,249 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instrumentation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlphttp"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/version"
+)
+
+const (
+	otlpExporter     = "otlp"
+	otlpProtocolGRPC = "grpc"
+	otlpProtocolHTTP = "http/protobuf"
+	otlpServiceName  = "skaffold"
+)
+
+// otlpSettings holds the OTLP collector configuration, resolved from the
+// standard OTEL_* environment variables and the global Skaffold config.
+type otlpSettings struct {
+	endpoint string
+	protocol string
+	insecure bool
+	headers  map[string]string
+}
+
+var (
+	// otlpGlobalConfig is the OTLP configuration read from the global Skaffold config.
+	otlpGlobalConfig = &config.ContextConfig{}
+
+	// for testing
+	initOTLPMetrics = initOTLPMetricsExporter
+)
+
+// loadOTLPConfig reads the OTLP settings from the global Skaffold config.
+func loadOTLPConfig(globalConfig string) {
+	cfg, err := config.GetConfigForCurrentKubectx(globalConfig)
+	if err != nil || cfg == nil {
+		logrus.Debugf("unable to read OTLP settings from global config: %v", err)
+		return
+	}
+	otlpGlobalConfig = cfg
+}
+
+// otlpTracesEnabled returns true when traces should be exported to an OTLP collector.
+func otlpTracesEnabled() bool {
+	return otlpSignalEnabled(os.Getenv("SKAFFOLD_TRACE"), "OTEL_TRACES_EXPORTER")
+}
+
+// otlpMetricsEnabled returns true when the skaffold metrics should be exported to an OTLP collector.
+func otlpMetricsEnabled() bool {
+	return otlpSignalEnabled("", "OTEL_METRICS_EXPORTER")
+}
+
+func otlpSignalEnabled(skaffoldValue string, otelEnvVar string) bool {
+	if skaffoldValue != "" {
+		return skaffoldValue == otlpExporter
+	}
+	if value, ok := os.LookupEnv(otelEnvVar); ok {
+		return value == otlpExporter
+	}
+	return otlpGlobalConfig.OTLPEndpoint != ""
+}
+
+// resolveOTLPSettings merges the OTEL_EXPORTER_OTLP_* environment variables with the global config.
+// Environment variables take precedence.
+func resolveOTLPSettings(signal string) (otlpSettings, error) {
+	s := otlpSettings{
+		endpoint: otlpGlobalConfig.OTLPEndpoint,
+		protocol: otlpGlobalConfig.OTLPProtocol,
+		insecure: otlpGlobalConfig.OTLPInsecure != nil && *otlpGlobalConfig.OTLPInsecure,
+	}
+	if v := otlpEnv(signal, "ENDPOINT"); v != "" {
+		s.endpoint = v
+	}
+	if v := otlpEnv(signal, "PROTOCOL"); v != "" {
+		s.protocol = v
+	}
+	if v := otlpEnv(signal, "INSECURE"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return otlpSettings{}, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_INSECURE value %q: %w", v, err)
+		}
+		s.insecure = insecure
+	}
+
+	// Endpoints can be given as urls but the exporters expect `host:port`.
+	switch {
+	case strings.HasPrefix(s.endpoint, "http://"):
+		s.endpoint = strings.TrimPrefix(s.endpoint, "http://")
+		s.insecure = true
+	case strings.HasPrefix(s.endpoint, "https://"):
+		s.endpoint = strings.TrimPrefix(s.endpoint, "https://")
+	}
+	s.endpoint = strings.TrimSuffix(s.endpoint, "/")
+
+	switch s.protocol {
+	case "":
+		s.protocol = otlpProtocolGRPC
+	case otlpProtocolGRPC, otlpProtocolHTTP:
+	default:
+		return otlpSettings{}, fmt.Errorf("unsupported OTLP protocol %q, must be one of %q or %q", s.protocol, otlpProtocolGRPC, otlpProtocolHTTP)
+	}
+
+	// Headers from the environment are applied by the exporters themselves.
+	if otlpEnv(signal, "HEADERS") == "" && len(otlpGlobalConfig.OTLPHeaders) > 0 {
+		s.headers = map[string]string{}
+		for _, header := range otlpGlobalConfig.OTLPHeaders {
+			kv := strings.SplitN(header, "=", 2)
+			if len(kv) != 2 {
+				return otlpSettings{}, fmt.Errorf("invalid OTLP header %q, must be of the form key=value", header)
+			}
+			s.headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return s, nil
+}
+
+// otlpEnv returns the signal specific OTEL_EXPORTER_OTLP_<SIGNAL>_<KEY> variable, or the generic OTEL_EXPORTER_OTLP_<KEY>.
+func otlpEnv(signal string, key string) string {
+	if v := os.Getenv(fmt.Sprintf("OTEL_EXPORTER_OTLP_%s_%s", signal, key)); v != "" {
+		return v
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + key)
+}
+
+func newOTLPDriver(s otlpSettings) otlp.ProtocolDriver {
+	if s.protocol == otlpProtocolHTTP {
+		var opts []otlphttp.Option
+		if s.endpoint != "" {
+			opts = append(opts, otlphttp.WithEndpoint(s.endpoint))
+		}
+		if s.insecure {
+			opts = append(opts, otlphttp.WithInsecure())
+		}
+		if s.headers != nil {
+			opts = append(opts, otlphttp.WithHeaders(s.headers))
+		}
+		return otlphttp.NewDriver(opts...)
+	}
+
+	var opts []otlpgrpc.Option
+	if s.endpoint != "" {
+		opts = append(opts, otlpgrpc.WithEndpoint(s.endpoint))
+	}
+	if s.insecure {
+		opts = append(opts, otlpgrpc.WithInsecure())
+	}
+	if s.headers != nil {
+		opts = append(opts, otlpgrpc.WithHeaders(s.headers))
+	}
+	return otlpgrpc.NewDriver(opts...)
+}
+
+func otlpResource() *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.ServiceNameKey.String(otlpServiceName),
+		semconv.ServiceVersionKey.String(version.Get().Version),
+	)
+}
+
+// initOTLPTraceExporter returns a TracerProvider that sends spans to an OTLP collector.
+func initOTLPTraceExporter() (*sdktrace.TracerProvider, func(context.Context) error, error) {
+	s, err := resolveOTLPSettings("TRACES")
+	if err != nil {
+		return nil, func(context.Context) error { return nil }, err
+	}
+
+	exp, err := otlp.NewExporter(context.Background(), newOTLPDriver(s))
+	if err != nil {
+		return nil, func(context.Context) error { return nil }, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithResource(otlpResource()),
+	)
+	return tp, tp.Shutdown, nil
+}
+
+// initOTLPMetricsExporter returns a controller that pushes the skaffold metrics to an OTLP collector when stopped,
+// and a function to close the connection to the collector.
+func initOTLPMetricsExporter() (*basic.Controller, func(context.Context) error, error) {
+	s, err := resolveOTLPSettings("METRICS")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exp, err := otlp.NewExporter(context.Background(), newOTLPDriver(s))
+	if err != nil {
+		return nil, nil, err
+	}
+	controller := basic.New(
+		processor.New(simple.NewWithInexpensiveDistribution(), exp),
+		basic.WithExporter(exp),
+		basic.WithResource(otlpResource()),
+	)
+	global.SetMeterProvider(controller.MeterProvider())
+	return controller, exp.Shutdown, nil
+}
+
+// exportOTLPMetrics sends the skaffold metrics to an OTLP collector.
+// Unlike the metrics collected by the Skaffold team, they are never cached on disk.
+func exportOTLPMetrics(ctx context.Context, meter skaffoldMeter) error {
+	logrus.Debug("exporting metrics to OTLP collector")
+	p, shutdown, err := initOTLPMetrics()
+	if err != nil {
+		return err
+	}
+	defer shutdown(ctx)
+
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
+	createMetrics(ctx, meter)
+	return p.Stop(ctx)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instrumentation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestResolveOTLPSettings(t *testing.T) {
+	tests := []struct {
+		description string
+		cfg         *config.ContextConfig
+		envs        map[string]string
+		expected    otlpSettings
+		shouldErr   bool
+	}{
+		{
+			description: "defaults",
+			cfg:         &config.ContextConfig{},
+			expected:    otlpSettings{protocol: "grpc"},
+		},
+		{
+			description: "from global config",
+			cfg: &config.ContextConfig{
+				OTLPEndpoint: "collector:4318",
+				OTLPProtocol: "http/protobuf",
+				OTLPHeaders:  []string{"api-key=secret", "team = platform"},
+				OTLPInsecure: util.BoolPtr(true),
+			},
+			expected: otlpSettings{
+				endpoint: "collector:4318",
+				protocol: "http/protobuf",
+				insecure: true,
+				headers:  map[string]string{"api-key": "secret", "team": "platform"},
+			},
+		},
+		{
+			description: "env vars take precedence",
+			cfg: &config.ContextConfig{
+				OTLPEndpoint: "collector:4318",
+				OTLPProtocol: "http/protobuf",
+				OTLPHeaders:  []string{"api-key=secret"},
+			},
+			envs: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "otel:4317",
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+				"OTEL_EXPORTER_OTLP_INSECURE": "true",
+				"OTEL_EXPORTER_OTLP_HEADERS":  "api-key=other",
+			},
+			expected: otlpSettings{endpoint: "otel:4317", protocol: "grpc", insecure: true},
+		},
+		{
+			description: "signal specific env vars",
+			cfg:         &config.ContextConfig{},
+			envs: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "otel:4317",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "traces:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL": "http/protobuf",
+			},
+			expected: otlpSettings{endpoint: "traces:4318", protocol: "http/protobuf"},
+		},
+		{
+			description: "http url",
+			cfg:         &config.ContextConfig{OTLPEndpoint: "http://localhost:4317/"},
+			expected:    otlpSettings{endpoint: "localhost:4317", protocol: "grpc", insecure: true},
+		},
+		{
+			description: "https url",
+			cfg:         &config.ContextConfig{OTLPEndpoint: "https://collector.example.com"},
+			expected:    otlpSettings{endpoint: "collector.example.com", protocol: "grpc"},
+		},
+		{
+			description: "invalid protocol",
+			cfg:         &config.ContextConfig{OTLPProtocol: "http/json"},
+			shouldErr:   true,
+		},
+		{
+			description: "invalid header",
+			cfg:         &config.ContextConfig{OTLPHeaders: []string{"api-key"}},
+			shouldErr:   true,
+		},
+		{
+			description: "invalid insecure",
+			cfg:         &config.ContextConfig{},
+			envs:        map[string]string{"OTEL_EXPORTER_OTLP_INSECURE": "maybe"},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&otlpGlobalConfig, test.cfg)
+			t.SetEnvs(test.envs)
+
+			settings, err := resolveOTLPSettings("TRACES")
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, settings, cmpOTLPSettings)
+		})
+	}
+}
+
+func TestOTLPSignalEnabled(t *testing.T) {
+	tests := []struct {
+		description     string
+		cfg             *config.ContextConfig
+		envs            map[string]string
+		expectedTraces  bool
+		expectedMetrics bool
+	}{
+		{
+			description: "disabled by default",
+			cfg:         &config.ContextConfig{},
+		},
+		{
+			description:     "enabled by global config",
+			cfg:             &config.ContextConfig{OTLPEndpoint: "collector:4317"},
+			expectedTraces:  true,
+			expectedMetrics: true,
+		},
+		{
+			description:    "enabled by env vars",
+			cfg:            &config.ContextConfig{},
+			envs:           map[string]string{"OTEL_TRACES_EXPORTER": "otlp"},
+			expectedTraces: true,
+		},
+		{
+			description:    "enabled by SKAFFOLD_TRACE",
+			cfg:            &config.ContextConfig{},
+			envs:           map[string]string{"SKAFFOLD_TRACE": "otlp"},
+			expectedTraces: true,
+		},
+		{
+			description:     "other exporter selected",
+			cfg:             &config.ContextConfig{OTLPEndpoint: "collector:4317"},
+			envs:            map[string]string{"SKAFFOLD_TRACE": "stdout", "OTEL_METRICS_EXPORTER": "none"},
+			expectedTraces:  false,
+			expectedMetrics: false,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&otlpGlobalConfig, test.cfg)
+			t.SetEnvs(test.envs)
+
+			t.CheckDeepEqual(test.expectedTraces, otlpTracesEnabled())
+			t.CheckDeepEqual(test.expectedMetrics, otlpMetricsEnabled())
+		})
+	}
+}
+
+func TestOTLPHTTPExport(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		var mu sync.Mutex
+		received := map[string]string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received[r.URL.Path] = r.Header.Get("api-key")
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		t.Override(&otlpGlobalConfig, &config.ContextConfig{
+			OTLPEndpoint: server.URL,
+			OTLPProtocol: "http/protobuf",
+			OTLPHeaders:  []string{"api-key=secret"},
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		tp, shutdown, err := initOTLPTraceExporter()
+		t.CheckNoError(err)
+		_, span := tp.Tracer("test").Start(ctx, "build")
+		span.End()
+		t.CheckNoError(shutdown(ctx))
+
+		err = exportOTLPMetrics(ctx, skaffoldMeter{Command: "build", Builders: map[string]int{"docker": 1}})
+		t.CheckNoError(err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		t.CheckDeepEqual(map[string]string{"/v1/traces": "secret", "/v1/metrics": "secret"}, received)
+	})
+}
+
+var cmpOTLPSettings = cmp.AllowUnexported(otlpSettings{})
@@ -36,7 +36,8 @@ var tracerProvider trace.TracerProvider
 var tracerShutdown func(context.Context) error = func(context.Context) error { return nil }
 var tracerInitErr error
 
-// InitTraceFromEnvVar initializes the singleton skaffold tracer from the SKAFFOLD_TRACE env variable.
+// InitTraceFromEnvVar initializes the singleton skaffold tracer from the SKAFFOLD_TRACE and OTEL_TRACES_EXPORTER env variables,
+// or from the OTLP collector set in the global config.
 // The code here is a wrapper around the opentelemetry(otel) trace libs for usability
 // When SKAFFOLD_TRACE is set, this will setup the proper tracer provider (& exporter),
 // configures otel to use this tracer provider and saves the  tracer provider shutdown function
@@ -45,13 +46,13 @@ func InitTraceFromEnvVar(opts ...TraceExporterOption) (trace.TracerProvider, fun
 	traceInitOnce.Do(func() {
 		_, skaffTraceEnv := os.LookupEnv("SKAFFOLD_TRACE")
 		_, otelTraceExporterEnv := os.LookupEnv("OTEL_TRACES_EXPORTER")
-		if skaffTraceEnv || otelTraceExporterEnv {
+		if skaffTraceEnv || otelTraceExporterEnv || otlpTracesEnabled() {
 			traceEnabled = true
 		}
 		if traceEnabled {
 			tp, shutdown, err := initTraceExporter(opts...)
 			tracerInitErr = err
-			if err == nil && skaffTraceEnv { // if only OTEL_TRACES_EXPORTER, tp set automatically
+			if err == nil && tp != nil { // if OTEL_TRACES_EXPORTER is not handled by skaffold, tp set automatically
 				otel.SetTracerProvider(tp)
 				tracerProvider = tp
 				tracerShutdown = shutdown
@@ -0,0 +1,404 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	apimachinery "k8s.io/apimachinery/pkg/runtime/schema"
+
+	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
+)
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Action describes what deploying the rendered manifests does to a resource.
+type Action string
+
+const (
+	Create    Action = "create"
+	Change    Action = "change"
+	Delete    Action = "delete"
+	Unchanged Action = "unchanged"
+)
+
+// ResourceDiff is the difference between the live and the rendered version of a resource.
+type ResourceDiff struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Action    Action `json:"action"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+// Summary counts the resources by action.
+type Summary struct {
+	Create    int `json:"create"`
+	Change    int `json:"change"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Report is the result of diffing rendered manifests against the live cluster.
+type Report struct {
+	Resources []ResourceDiff `json:"resources"`
+	Summary   Summary        `json:"summary"`
+}
+
+// Resource identifies a live resource. An empty namespace stands for the default namespace,
+// or for no namespace at all for cluster-scoped resources.
+type Resource struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ResourcesOf returns the resources described by a list of manifests.
+func ResourcesOf(manifests manifest.ManifestList) ([]Resource, error) {
+	objects, err := parseManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	var resources []Resource
+	for _, obj := range objects {
+		k := keyOf(obj)
+		resources = append(resources, Resource{Group: k.group, Kind: k.kind, Namespace: k.namespace, Name: k.name})
+	}
+	return resources, nil
+}
+
+// Differ compares rendered manifests with the objects that are live on the cluster.
+type Differ struct {
+	kubectl *pkgkubectl.CLI
+	labels  map[string]string
+}
+
+// NewDiffer creates a Differ. `labels` are the labels that Skaffold adds to the resources it deploys:
+// they are ignored when comparing resources.
+func NewDiffer(cli *pkgkubectl.CLI, labels map[string]string) *Differ {
+	return &Differ{
+		kubectl: cli,
+		labels:  labels,
+	}
+}
+
+// Diff compares the rendered manifests with their live version.
+// `pruned` are the resources that deploying would delete, as reported by the deployers.
+// Those that still exist, and are not rendered anymore, are reported as deleted.
+func (d *Differ) Diff(ctx context.Context, manifests manifest.ManifestList, pruned []Resource) (*Report, error) {
+	desired, err := parseManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := d.liveObjects(ctx, manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := d.prunedObjects(ctx, desired, pruned)
+	if err != nil {
+		return nil, err
+	}
+
+	return compare(desired, live, deleted, d.labels)
+}
+
+type object = map[string]interface{}
+
+// key identifies a resource, regardless of its api version.
+type key struct {
+	group, kind, namespace, name string
+}
+
+func keyOf(obj object) key {
+	var k key
+	if gk, found := groupKind(obj); found {
+		k.group, k.kind = gk.Group, gk.Kind
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	k.namespace, _ = metadata["namespace"].(string)
+	k.name, _ = metadata["name"].(string)
+	return k
+}
+
+// matches tells whether a live object is the live version of a rendered resource.
+// Rendered resources without a namespace are deployed to the default namespace, whatever it is.
+func (k key) matches(live key) bool {
+	return k.group == live.group && k.kind == live.kind && k.name == live.name && (k.namespace == "" || k.namespace == live.namespace)
+}
+
+func (k key) path() string {
+	kind := k.kind
+	if k.group != "" {
+		kind += "." + k.group
+	}
+	if k.namespace == "" {
+		return kind + "/" + k.name
+	}
+	return kind + "/" + k.namespace + "/" + k.name
+}
+
+func groupKind(obj object) (apimachinery.GroupKind, bool) {
+	apiVersion, ok := obj["apiVersion"].(string)
+	if !ok {
+		return apimachinery.GroupKind{}, false
+	}
+	kind, ok := obj["kind"].(string)
+	if !ok {
+		return apimachinery.GroupKind{}, false
+	}
+	return apimachinery.FromAPIVersionAndKind(apiVersion, kind).GroupKind(), true
+}
+
+func parseManifests(manifests manifest.ManifestList) ([]object, error) {
+	var objects []object
+	for _, m := range manifests {
+		var obj object
+		if err := yaml.Unmarshal(m, &obj); err != nil {
+			return nil, fmt.Errorf("reading rendered manifests: %w", err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		// Go through json so that numbers have the same type as in live objects.
+		buf, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("reading rendered manifests: %w", err)
+		}
+		obj = nil
+		if err := json.Unmarshal(buf, &obj); err != nil {
+			return nil, fmt.Errorf("reading rendered manifests: %w", err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+func compare(desired, live, deleted []object, labels map[string]string) (*Report, error) {
+	report := &Report{Resources: []ResourceDiff{}}
+
+	for _, obj := range desired {
+		k := keyOf(obj)
+
+		var current object
+		for _, l := range live {
+			if k.matches(keyOf(l)) {
+				current = l
+				break
+			}
+		}
+
+		after, err := toYaml(normalize(obj, labels))
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			report.add(k, Create, unifiedDiff(k, "", after))
+			continue
+		}
+
+		projected, ok := project(current, obj, lastApplied(current)).(object)
+		if !ok {
+			return nil, fmt.Errorf("unexpected live object %s", k.path())
+		}
+		if k.namespace == "" {
+			delete(metadataOf(projected), "namespace")
+		}
+		before, err := toYaml(normalize(projected, labels))
+		if err != nil {
+			return nil, err
+		}
+		if before == after {
+			report.add(k, Unchanged, "")
+		} else {
+			report.add(k, Change, unifiedDiff(k, before, after))
+		}
+	}
+
+	for _, obj := range deleted {
+		k := keyOf(obj)
+		if applied := lastApplied(obj); applied != nil {
+			obj = project(obj, applied).(object)
+		}
+		before, err := toYaml(normalize(obj, labels))
+		if err != nil {
+			return nil, err
+		}
+		report.add(k, Delete, unifiedDiff(k, before, ""))
+	}
+
+	return report, nil
+}
+
+// isRendered tells whether a live resource is the live version of one of the rendered resources.
+func isRendered(live key, desired []object) bool {
+	for _, obj := range desired {
+		if keyOf(obj).matches(live) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) add(k key, action Action, diff string) {
+	r.Resources = append(r.Resources, ResourceDiff{
+		Group:     k.group,
+		Kind:      k.kind,
+		Namespace: k.namespace,
+		Name:      k.name,
+		Action:    action,
+		Diff:      diff,
+	})
+
+	switch action {
+	case Create:
+		r.Summary.Create++
+	case Change:
+		r.Summary.Change++
+	case Delete:
+		r.Summary.Delete++
+	default:
+		r.Summary.Unchanged++
+	}
+}
+
+// lastApplied returns the configuration last applied with `kubectl apply`, if any.
+func lastApplied(obj object) interface{} {
+	annotations, _ := metadataOf(obj)["annotations"].(map[string]interface{})
+	config, ok := annotations[lastAppliedAnnotation].(string)
+	if !ok {
+		return nil
+	}
+	var applied interface{}
+	if err := json.Unmarshal([]byte(config), &applied); err != nil {
+		return nil
+	}
+	return applied
+}
+
+// project keeps only the fields of a live value that are set in one of the given configurations.
+// This hides the defaults and the fields that are managed by the cluster, while showing
+// the fields that were previously applied and are no longer rendered.
+func project(live interface{}, configs ...interface{}) interface{} {
+	switch value := live.(type) {
+	case map[string]interface{}:
+		projected := map[string]interface{}{}
+		for k, v := range value {
+			var sub []interface{}
+			for _, c := range configs {
+				if m, ok := c.(map[string]interface{}); ok {
+					if cv, found := m[k]; found {
+						sub = append(sub, cv)
+					}
+				}
+			}
+			if len(sub) > 0 {
+				projected[k] = project(v, sub...)
+			}
+		}
+		return projected
+
+	case []interface{}:
+		projected := make([]interface{}, len(value))
+		for i, v := range value {
+			var sub []interface{}
+			for _, c := range configs {
+				if l, ok := c.([]interface{}); ok && i < len(l) {
+					sub = append(sub, l[i])
+				}
+			}
+			if len(sub) > 0 {
+				projected[i] = project(v, sub...)
+			} else {
+				projected[i] = v
+			}
+		}
+		return projected
+
+	default:
+		return live
+	}
+}
+
+// normalize removes the fields that are set by the cluster, or by Skaffold at deploy time.
+func normalize(obj object, labels map[string]string) object {
+	delete(obj, "status")
+
+	metadata := metadataOf(obj)
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		delete(metadata, field)
+	}
+
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+		delete(annotations, lastAppliedAnnotation)
+		delete(annotations, "deployment.kubernetes.io/revision")
+		if len(annotations) == 0 {
+			delete(metadata, "annotations")
+		}
+	}
+	if l, ok := metadata["labels"].(map[string]interface{}); ok {
+		for k := range labels {
+			delete(l, k)
+		}
+		if len(l) == 0 {
+			delete(metadata, "labels")
+		}
+	}
+
+	return obj
+}
+
+func metadataOf(obj object) map[string]interface{} {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	return metadata
+}
+
+func toYaml(obj object) (string, error) {
+	buf, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("marshalling resource: %w", err)
+	}
+	return string(buf), nil
+}
+
+func unifiedDiff(k key, before, after string) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines(before),
+		B:        lines(after),
+		FromFile: "live/" + k.path(),
+		ToFile:   "rendered/" + k.path(),
+		Context:  3,
+	})
+	return diff
+}
+
+func lines(s string) []string {
+	l := strings.SplitAfter(s, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+const (
+	deploymentV2 = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: web:v2
+        name: web`
+
+	service = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+spec:
+  ports:
+  - port: 80`
+
+	configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value`
+
+	liveDeployment = `{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {
+    "name": "web",
+    "namespace": "testNamespace",
+    "uid": "1234",
+    "resourceVersion": "42",
+    "labels": {"app.kubernetes.io/managed-by": "skaffold", "skaffold.dev/run-id": "old-run"},
+    "annotations": {"deployment.kubernetes.io/revision": "1"}
+  },
+  "spec": {
+    "replicas": 1,
+    "template": {"spec": {"containers": [{"image": "web:v1", "imagePullPolicy": "IfNotPresent", "name": "web"}]}}
+  },
+  "status": {"replicas": 1}
+}`
+
+	liveService = `{
+  "apiVersion": "v1",
+  "kind": "Service",
+  "metadata": {"name": "web", "namespace": "prod", "uid": "5678"},
+  "spec": {"clusterIP": "10.0.0.1", "ports": [{"port": 80, "protocol": "TCP"}]}
+}`
+
+	liveOrphan = `{
+  "apiVersion": "v1",
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "old",
+    "namespace": "testNamespace",
+    "labels": {"app.kubernetes.io/managed-by": "skaffold", "skaffold.dev/run-id": "old-run"},
+    "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"apiVersion\":\"v1\",\"kind\":\"ConfigMap\",\"metadata\":{\"name\":\"old\"},\"data\":{\"key\":\"old\"}}"}
+  },
+  "data": {"key": "old"}
+}`
+
+	deploymentDiff = `--- live/Deployment.apps/web
++++ rendered/Deployment.apps/web
+@@ -6,5 +6,5 @@
+   template:
+     spec:
+       containers:
+-      - image: web:v1
++      - image: web:v2
+         name: web
+`
+
+	configMapDiff = `--- live/ConfigMap/config
++++ rendered/ConfigMap/config
+@@ -0,0 +1,6 @@
++apiVersion: v1
++data:
++  key: value
++kind: ConfigMap
++metadata:
++  name: config
+`
+
+	orphanDiff = `--- live/ConfigMap/testNamespace/old
++++ rendered/ConfigMap/testNamespace/old
+@@ -1,6 +0,0 @@
+-apiVersion: v1
+-data:
+-  key: old
+-kind: ConfigMap
+-metadata:
+-  name: old
+`
+)
+
+var skaffoldLabels = map[string]string{
+	"app.kubernetes.io/managed-by": "skaffold",
+	"skaffold.dev/run-id":          "new-run",
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		description string
+		manifests   []string
+		pruned      []Resource
+		labels      map[string]string
+		commands    util.Command
+		expected    *Report
+	}{
+		{
+			description: "create, change and unchanged resources",
+			manifests:   []string{deploymentV2, service, configMap},
+			labels:      skaffoldLabels,
+			commands:    testutil.CmdRunOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -o json", `{"kind": "List", "items": [`+liveDeployment+`,`+liveService+`]}`),
+			expected: &Report{
+				Resources: []ResourceDiff{
+					{Group: "apps", Kind: "Deployment", Name: "web", Action: Change, Diff: deploymentDiff},
+					{Kind: "Service", Namespace: "prod", Name: "web", Action: Unchanged},
+					{Kind: "ConfigMap", Name: "config", Action: Create, Diff: configMapDiff},
+				},
+				Summary: Summary{Create: 1, Change: 1, Unchanged: 1},
+			},
+		},
+		{
+			description: "pruned resources",
+			manifests:   []string{service},
+			pruned: []Resource{
+				{Kind: "ConfigMap", Name: "old"},
+				{Kind: "ConfigMap", Name: "old"},
+				{Group: "apps", Kind: "Deployment", Namespace: "prod", Name: "gone"},
+				{Kind: "Service", Namespace: "prod", Name: "web"},
+			},
+			labels: skaffoldLabels,
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -o json", liveService).
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace get configmap old --ignore-not-found -o json", liveOrphan).
+				AndRunOut("kubectl --context kubecontext --namespace prod get deployment.apps gone --ignore-not-found -o json", "").
+				AndRunOut("kubectl --context kubecontext --namespace prod get service web --ignore-not-found -o json", liveService),
+			expected: &Report{
+				Resources: []ResourceDiff{
+					{Kind: "Service", Namespace: "prod", Name: "web", Action: Unchanged},
+					{Kind: "ConfigMap", Namespace: "testNamespace", Name: "old", Action: Delete, Diff: orphanDiff},
+				},
+				Summary: Summary{Delete: 1, Unchanged: 1},
+			},
+		},
+		{
+			description: "single live object",
+			manifests:   []string{service},
+			commands:    testutil.CmdRunOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -o json", liveService),
+			expected: &Report{
+				Resources: []ResourceDiff{{Kind: "Service", Namespace: "prod", Name: "web", Action: Unchanged}},
+				Summary:   Summary{Unchanged: 1},
+			},
+		},
+		{
+			description: "get resources one by one",
+			manifests:   []string{deploymentV2, configMap},
+			commands: testutil.
+				CmdRunOutErr("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -o json", "", errors.New("no matches for kind")).
+				AndRunOut("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -o json", liveDeployment).
+				AndRunOutErr("kubectl --context kubecontext --namespace testNamespace get -f - --ignore-not-found -o json", "", errors.New("no matches for kind")),
+			expected: &Report{
+				Resources: []ResourceDiff{
+					{Group: "apps", Kind: "Deployment", Name: "web", Action: Change, Diff: deploymentDiff},
+					{Kind: "ConfigMap", Name: "config", Action: Create, Diff: configMapDiff},
+				},
+				Summary: Summary{Create: 1, Change: 1},
+			},
+		},
+		{
+			description: "no resources",
+			expected:    &Report{Resources: []ResourceDiff{}},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&util.DefaultExecCommand, test.commands)
+
+			var manifests manifest.ManifestList
+			for _, m := range test.manifests {
+				manifests.Append([]byte(m))
+			}
+			cli := &pkgkubectl.CLI{KubeContext: "kubecontext", Namespace: "testNamespace"}
+			report, err := NewDiffer(cli, test.labels).Diff(context.Background(), manifests, test.pruned)
+
+			t.CheckNoError(err)
+			t.CheckDeepEqual(test.expected, report)
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	live := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 1.0,
+			"ports":    []interface{}{map[string]interface{}{"port": 80.0, "protocol": "TCP"}, map[string]interface{}{"port": 443.0}},
+		},
+		"status": map[string]interface{}{"ready": true},
+	}
+	desired := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ports": []interface{}{map[string]interface{}{"port": 8080.0}},
+		},
+	}
+	applied := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 2.0,
+		},
+	}
+
+	testutil.CheckDeepEqual(t, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 1.0,
+			"ports":    []interface{}{map[string]interface{}{"port": 80.0}, map[string]interface{}{"port": 443.0}},
+		},
+	}, project(live, desired, applied, nil))
+}
+
+func TestPrint(t *testing.T) {
+	report := &Report{
+		Resources: []ResourceDiff{
+			{Group: "apps", Kind: "Deployment", Name: "web", Action: Change, Diff: deploymentDiff},
+			{Kind: "Service", Namespace: "prod", Name: "web", Action: Unchanged},
+		},
+		Summary: Summary{Change: 1, Unchanged: 1},
+	}
+
+	tests := []struct {
+		description string
+		format      string
+		shouldErr   bool
+		expected    string
+	}{
+		{
+			description: "text",
+			format:      TextFormat,
+			expected:    "Deployment.apps/web change\n" + deploymentDiff + "Summary: 0 to create, 1 to change, 0 to delete, 1 unchanged\n",
+		},
+		{
+			description: "default to text",
+			expected:    "Deployment.apps/web change\n" + deploymentDiff + "Summary: 0 to create, 1 to change, 0 to delete, 1 unchanged\n",
+		},
+		{
+			description: "json",
+			format:      JSONFormat,
+			expected:    `{"resources":[{"group":"apps","kind":"Deployment","name":"web","action":"change","diff":` + `"--- live/Deployment.apps/web\n+++ rendered/Deployment.apps/web\n@@ -6,5 +6,5 @@\n   template:\n     spec:\n       containers:\n-      - image: web:v1\n+      - image: web:v2\n         name: web\n"},{"kind":"Service","namespace":"prod","name":"web","action":"unchanged"}],"summary":{"create":0,"change":1,"delete":0,"unchanged":1}}` + "\n",
+		},
+		{
+			description: "unknown format",
+			format:      "xml",
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			var out bytes.Buffer
+			err := report.Print(&out, test.format)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, out.String())
+		})
+	}
+}
+
+func TestResourcesOf(t *testing.T) {
+	manifests := manifest.ManifestList{[]byte(deploymentV2), []byte(service)}
+
+	resources, err := ResourcesOf(manifests)
+
+	testutil.CheckErrorAndDeepEqual(t, false, err, []Resource{
+		{Group: "apps", Kind: "Deployment", Name: "web"},
+		{Kind: "Service", Namespace: "prod", Name: "web"},
+	}, resources)
+}
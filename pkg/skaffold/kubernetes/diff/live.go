@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// liveObjects fetches the live version of the rendered resources.
+// Resources that don't exist yet are simply not returned.
+func (d *Differ) liveObjects(ctx context.Context, manifests manifest.ManifestList) ([]object, error) {
+	if len(manifests) == 0 {
+		return nil, nil
+	}
+
+	out, err := d.kubectl.RunOutInput(ctx, manifests.Reader(), "get", "-f", "-", "--ignore-not-found", "-o", "json")
+	if err == nil {
+		return parseObjects(out)
+	}
+
+	// A single unknown kind, for example a custom resource whose definition is not
+	// deployed yet, fails the whole query. Fall back to querying resources one by one.
+	logrus.Debugf("unable to get the live resources at once, getting them one by one: %v", err)
+	var objects []object
+	for _, m := range manifests {
+		out, err := d.kubectl.RunOutInput(ctx, bytes.NewReader(m), "get", "-f", "-", "--ignore-not-found", "-o", "json")
+		if err != nil {
+			logrus.Warnf("unable to get the live version of a resource, assuming it doesn't exist: %v", err)
+			continue
+		}
+		parsed, err := parseObjects(out)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, parsed...)
+	}
+	return objects, nil
+}
+
+// prunedObjects fetches the live version of the resources that deploying would delete.
+// Resources that don't exist anymore, or that are rendered again, are not returned.
+func (d *Differ) prunedObjects(ctx context.Context, desired []object, pruned []Resource) ([]object, error) {
+	var objects []object
+	seen := map[key]bool{}
+	for _, r := range pruned {
+		k := key{group: r.Group, kind: r.Kind, namespace: r.Namespace, name: r.Name}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		resource := strings.ToLower(k.kind)
+		if k.group != "" {
+			resource += "." + k.group
+		}
+		cmd := d.kubectl.CommandWithNamespaceArg(ctx, "get", k.namespace, resource, k.name, "--ignore-not-found", "-o", "json")
+		out, err := util.RunCmdOut(cmd)
+		if err != nil {
+			logrus.Warnf("unable to get the live version of %s, assuming it doesn't exist: %v", k.path(), err)
+			continue
+		}
+		parsed, err := parseObjects(out)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range parsed {
+			if !isRendered(keyOf(obj), desired) {
+				objects = append(objects, obj)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// parseObjects reads the output of `kubectl get -o json`, which is either a single object or a list.
+func parseObjects(out []byte) ([]object, error) {
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+
+	var obj object
+	if err := json.Unmarshal(out, &obj); err != nil {
+		return nil, fmt.Errorf("reading live resources: %w", err)
+	}
+	if obj["kind"] != "List" {
+		return []object{obj}, nil
+	}
+
+	items, _ := obj["items"].([]interface{})
+	var objects []object
+	for _, item := range items {
+		if o, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, o)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+)
+
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// Print writes the report in the given format.
+func (r *Report) Print(out io.Writer, format string) error {
+	switch format {
+	case JSONFormat:
+		return json.NewEncoder(out).Encode(r)
+	case TextFormat, "":
+		r.printText(out)
+		return nil
+	default:
+		return fmt.Errorf("unsupported diff format %q, must be one of [%s %s]", format, TextFormat, JSONFormat)
+	}
+}
+
+func (r *Report) printText(out io.Writer) {
+	for _, res := range r.Resources {
+		if res.Action == Unchanged {
+			continue
+		}
+		k := key{group: res.Group, kind: res.Kind, namespace: res.Namespace, name: res.Name}
+		output.Default.Fprintf(out, "%s %s\n", k.path(), res.Action)
+
+		if res.Diff == "" {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(res.Diff, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				fmt.Fprintln(out, line)
+			case strings.HasPrefix(line, "+"):
+				output.Green.Fprintln(out, line)
+			case strings.HasPrefix(line, "-"):
+				output.Red.Fprintln(out, line)
+			default:
+				fmt.Fprintln(out, line)
+			}
+		}
+	}
+
+	output.Default.Fprintf(out, "Summary: %d to create, %d to change, %d to delete, %d unchanged\n", r.Summary.Create, r.Summary.Change, r.Summary.Delete, r.Summary.Unchanged)
+}
@@ -20,13 +20,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/docker/distribution/reference"
+	"github.com/sirupsen/logrus"
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/cluster"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
@@ -36,8 +41,13 @@ import (
 )
 
 type ImageLoader struct {
-	kubeContext string
-	cli         *kubectl.CLI
+	kubeContext     string
+	loader          string
+	minikubeProfile string
+	cli             *kubectl.CLI
+
+	// dockerRuntime caches whether the cluster nodes use the docker container runtime.
+	dockerRuntime *bool
 }
 
 type Config interface {
@@ -45,12 +55,19 @@ type Config interface {
 
 	GetKubeContext() string
 	LoadImages() bool
+	ImageLoader() string
+	MinikubeProfile() string
 }
 
-func NewImageLoader(kubeContext string, cli *kubectl.CLI) *ImageLoader {
+// For testing
+var makeTempDir = ioutil.TempDir
+
+func NewImageLoader(cfg Config, cli *kubectl.CLI) *ImageLoader {
 	return &ImageLoader{
-		kubeContext: kubeContext,
-		cli:         cli,
+		kubeContext:     cfg.GetKubeContext(),
+		loader:          cfg.ImageLoader(),
+		minikubeProfile: cfg.MinikubeProfile(),
+		cli:             cli,
 	}
 }
 
@@ -83,29 +100,47 @@ func imagesToLoad(localImages, deployerImages, images []graph.Artifact) []graph.
 // imagesToLoad is used to determine the set of images we should load, based on images that are
 // marked as local by the Runner, and part of the calling Deployer's set of manifests
 func (i *ImageLoader) LoadImages(ctx context.Context, out io.Writer, localImages, deployerImages, images []graph.Artifact) error {
-	currentContext, err := i.getCurrentContext()
-	if err != nil {
-		return err
-	}
-
 	artifacts := imagesToLoad(localImages, deployerImages, images)
 
-	if config.IsKindCluster(i.kubeContext) {
+	switch i.loader {
+	case config.ImageLoaderKind:
+		currentContext, err := i.getCurrentContext()
+		if err != nil {
+			return err
+		}
 		kindCluster := config.KindClusterName(currentContext.Cluster)
 
 		// With `kind`, docker images have to be loaded with the `kind` CLI.
 		if err := i.loadImagesInKindNodes(ctx, out, kindCluster, artifacts); err != nil {
 			return fmt.Errorf("loading images into kind nodes: %w", err)
 		}
-	}
 
-	if config.IsK3dCluster(i.kubeContext) {
+	case config.ImageLoaderK3d:
+		currentContext, err := i.getCurrentContext()
+		if err != nil {
+			return err
+		}
 		k3dCluster := config.K3dClusterName(currentContext.Cluster)
 
 		// With `k3d`, docker images have to be loaded with the `k3d` CLI.
 		if err := i.loadImagesInK3dNodes(ctx, out, k3dCluster, artifacts); err != nil {
 			return fmt.Errorf("loading images into k3d nodes: %w", err)
 		}
+
+	case config.ImageLoaderMinikube:
+		if err := i.loadImagesInMinikube(ctx, out, artifacts); err != nil {
+			return fmt.Errorf("loading images into minikube: %w", err)
+		}
+
+	case config.ImageLoaderNerdctl:
+		if err := i.loadImagesWithNerdctl(ctx, out, artifacts); err != nil {
+			return fmt.Errorf("loading images with nerdctl: %w", err)
+		}
+
+	case config.ImageLoaderCtr:
+		if err := i.loadImagesWithCtr(ctx, out, artifacts); err != nil {
+			return fmt.Errorf("loading images with ctr: %w", err)
+		}
 	}
 
 	return nil
@@ -114,20 +149,115 @@ func (i *ImageLoader) LoadImages(ctx context.Context, out io.Writer, localImages
 // loadImagesInKindNodes loads artifact images into every node of a kind cluster.
 func (i *ImageLoader) loadImagesInKindNodes(ctx context.Context, out io.Writer, kindCluster string, artifacts []graph.Artifact) error {
 	output.Default.Fprintln(out, "Loading images into kind cluster nodes...")
-	return i.loadImages(ctx, out, artifacts, func(tag string) *exec.Cmd {
-		return exec.CommandContext(ctx, "kind", "load", "docker-image", "--name", kindCluster, tag)
+	return i.loadImages(ctx, out, artifacts, func(tag string) ([]*exec.Cmd, error) {
+		return []*exec.Cmd{exec.CommandContext(ctx, "kind", "load", "docker-image", "--name", kindCluster, tag)}, nil
 	})
 }
 
 // loadImagesInK3dNodes loads artifact images into every node of a k3s cluster.
 func (i *ImageLoader) loadImagesInK3dNodes(ctx context.Context, out io.Writer, k3dCluster string, artifacts []graph.Artifact) error {
 	output.Default.Fprintln(out, "Loading images into k3d cluster nodes...")
-	return i.loadImages(ctx, out, artifacts, func(tag string) *exec.Cmd {
-		return exec.CommandContext(ctx, "k3d", "image", "import", "--cluster", k3dCluster, tag)
+	return i.loadImages(ctx, out, artifacts, func(tag string) ([]*exec.Cmd, error) {
+		return []*exec.Cmd{exec.CommandContext(ctx, "k3d", "image", "import", "--cluster", k3dCluster, tag)}, nil
 	})
 }
 
-func (i *ImageLoader) loadImages(ctx context.Context, out io.Writer, artifacts []graph.Artifact, createCmd func(tag string) *exec.Cmd) error {
+// loadImagesInMinikube loads artifact images into a minikube cluster that doesn't use the docker runtime.
+// With the docker runtime, images are built directly by minikube's docker daemon.
+func (i *ImageLoader) loadImagesInMinikube(ctx context.Context, out io.Writer, artifacts []graph.Artifact) error {
+	if skip, err := i.usesDockerRuntime(ctx, artifacts); skip || err != nil {
+		return err
+	}
+
+	profile := i.minikubeProfile
+	if profile == "" {
+		profile = i.kubeContext
+	}
+
+	output.Default.Fprintln(out, "Loading images into minikube...")
+	return i.loadImages(ctx, out, artifacts, func(tag string) ([]*exec.Cmd, error) {
+		cmd, err := cluster.GetClient().MinikubeExec("image", "load", "-p", profile, tag)
+		if err != nil {
+			return nil, err
+		}
+		return []*exec.Cmd{cmd}, nil
+	})
+}
+
+// loadImagesWithNerdctl loads artifact images into the `k8s.io` containerd namespace with nerdctl,
+// as used by Rancher Desktop. With the docker runtime, images are shared with the local docker daemon.
+func (i *ImageLoader) loadImagesWithNerdctl(ctx context.Context, out io.Writer, artifacts []graph.Artifact) error {
+	if skip, err := i.usesDockerRuntime(ctx, artifacts); skip || err != nil {
+		return err
+	}
+
+	output.Default.Fprintln(out, "Loading images into containerd with nerdctl...")
+	return i.loadImageArchives(ctx, out, artifacts, func(archive string) *exec.Cmd {
+		return exec.CommandContext(ctx, "nerdctl", "--namespace", "k8s.io", "load", "-i", archive)
+	})
+}
+
+// loadImagesWithCtr imports artifact images into the `k8s.io` namespace of a containerd
+// daemon running on the same machine as Skaffold.
+func (i *ImageLoader) loadImagesWithCtr(ctx context.Context, out io.Writer, artifacts []graph.Artifact) error {
+	output.Default.Fprintln(out, "Loading images into containerd with ctr...")
+	return i.loadImageArchives(ctx, out, artifacts, func(archive string) *exec.Cmd {
+		return exec.CommandContext(ctx, "ctr", "--namespace", "k8s.io", "images", "import", archive)
+	})
+}
+
+// loadImageArchives exports each image from the local docker daemon to a tarball and imports it with the given command.
+func (i *ImageLoader) loadImageArchives(ctx context.Context, out io.Writer, artifacts []graph.Artifact, importCmd func(archive string) *exec.Cmd) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+
+	dir, err := makeTempDir("", "skaffold-images")
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "image.tar")
+	return i.loadImages(ctx, out, artifacts, func(tag string) ([]*exec.Cmd, error) {
+		return []*exec.Cmd{
+			exec.CommandContext(ctx, "docker", "save", "-o", archive, tag),
+			importCmd(archive),
+		}, nil
+	})
+}
+
+// usesDockerRuntime checks whether all the cluster nodes run the docker container runtime,
+// in which case there's no need to load images.
+func (i *ImageLoader) usesDockerRuntime(ctx context.Context, artifacts []graph.Artifact) (bool, error) {
+	if len(artifacts) == 0 {
+		return true, nil
+	}
+	if i.dockerRuntime != nil {
+		return *i.dockerRuntime, nil
+	}
+
+	runtimesOut, err := i.cli.RunOut(ctx, "get", "nodes", "-ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}")
+	if err != nil {
+		return false, fmt.Errorf("unable to inspect the nodes: %w", err)
+	}
+
+	runtimes := strings.Fields(string(runtimesOut))
+	docker := len(runtimes) > 0
+	for _, runtime := range runtimes {
+		if !strings.HasPrefix(runtime, "docker://") {
+			docker = false
+		}
+	}
+	if docker {
+		logrus.Debugf("Not loading images since the cluster nodes use the docker runtime")
+	}
+
+	i.dockerRuntime = &docker
+	return docker, nil
+}
+
+func (i *ImageLoader) loadImages(ctx context.Context, out io.Writer, artifacts []graph.Artifact, createCmds func(tag string) ([]*exec.Cmd, error)) error {
 	start := time.Now()
 
 	var knownImages []string
@@ -151,10 +281,16 @@ func (i *ImageLoader) loadImages(ctx context.Context, out io.Writer, artifacts [
 			continue
 		}
 
-		cmd := createCmd(artifact.Tag)
-		if cmdOut, err := util.RunCmdOut(cmd); err != nil {
+		cmds, err := createCmds(artifact.Tag)
+		if err != nil {
 			output.Red.Fprintln(out, "Failed")
-			return fmt.Errorf("unable to load image %q into cluster: %w, %s", artifact.Tag, err, cmdOut)
+			return err
+		}
+		for _, cmd := range cmds {
+			if cmdOut, err := util.RunCmdOut(cmd); err != nil {
+				output.Red.Fprintln(out, "Failed")
+				return fmt.Errorf("unable to load image %q into cluster: %w, %s", artifact.Tag, err, cmdOut)
+			}
 		}
 
 		output.Green.Fprintln(out, "Loaded")
@@ -20,8 +20,10 @@ import (
 	"context"
 	"errors"
 	"io/ioutil"
+	"os/exec"
 	"testing"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/cluster"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
@@ -147,10 +149,137 @@ func TestLoadImagesInK3dNodes(t *testing.T) {
 	})
 }
 
+func TestLoadImagesInMinikube(t *testing.T) {
+	tests := []ImageLoadingTest{
+		{
+			description: "load image",
+			deployed:    []graph.Artifact{{Tag: "tag1"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "containerd://1.4.4").
+				AndRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "").
+				AndRunOut("minikube image load -p kubecontext tag1", "output: image loaded"),
+		},
+		{
+			description: "load image with profile",
+			cluster:     "profile",
+			deployed:    []graph.Artifact{{Tag: "tag1"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "cri-o://1.20.0").
+				AndRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "").
+				AndRunOut("minikube image load -p profile tag1", "output: image loaded"),
+		},
+		{
+			description: "docker runtime",
+			deployed:    []graph.Artifact{{Tag: "tag1"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "docker://20.10.6"),
+		},
+		{
+			description: "runtime inspect error",
+			deployed:    []graph.Artifact{{Tag: "tag1"}},
+			commands: testutil.
+				CmdRunOutErr("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "", errors.New("BUG")),
+			shouldErr:     true,
+			expectedError: "unable to inspect",
+		},
+		{
+			description: "load error",
+			deployed:    []graph.Artifact{{Tag: "tag"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "containerd://1.4.4").
+				AndRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "").
+				AndRunOutErr("minikube image load -p kubecontext tag", "output: error!", errors.New("BUG")),
+			shouldErr:     true,
+			expectedError: "output: error!",
+		},
+		{
+			description: "no artifact",
+			deployed:    []graph.Artifact{},
+		},
+	}
+
+	runImageLoadingTests(t, tests, func(i *ImageLoader, test ImageLoadingTest) error {
+		i.minikubeProfile = test.cluster
+		return i.loadImagesInMinikube(context.Background(), ioutil.Discard, test.deployed)
+	})
+}
+
+func TestLoadImagesWithNerdctl(t *testing.T) {
+	tests := []ImageLoadingTest{
+		{
+			description: "load image",
+			deployed:    []graph.Artifact{{Tag: "tag1"}, {Tag: "tag2"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "containerd://1.4.4").
+				AndRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "docker.io/library/tag1").
+				AndRunOut("docker save -o images/image.tar tag2", "").
+				AndRunOut("nerdctl --namespace k8s.io load -i images/image.tar", "output: image loaded"),
+		},
+		{
+			description: "docker runtime",
+			deployed:    []graph.Artifact{{Tag: "tag1"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "docker://20.10.6"),
+		},
+		{
+			description: "save error",
+			deployed:    []graph.Artifact{{Tag: "tag"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath={.items[*].status.nodeInfo.containerRuntimeVersion}", "containerd://1.4.4").
+				AndRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "").
+				AndRunOutErr("docker save -o images/image.tar tag", "output: no such image", errors.New("BUG")),
+			shouldErr:     true,
+			expectedError: "output: no such image",
+		},
+		{
+			description: "no artifact",
+			deployed:    []graph.Artifact{},
+		},
+	}
+
+	runImageLoadingTests(t, tests, func(i *ImageLoader, test ImageLoadingTest) error {
+		return i.loadImagesWithNerdctl(context.Background(), ioutil.Discard, test.deployed)
+	})
+}
+
+func TestLoadImagesWithCtr(t *testing.T) {
+	tests := []ImageLoadingTest{
+		{
+			description: "load image",
+			deployed:    []graph.Artifact{{Tag: "tag1"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "").
+				AndRunOut("docker save -o images/image.tar tag1", "").
+				AndRunOut("ctr --namespace k8s.io images import images/image.tar", "output: image loaded"),
+		},
+		{
+			description: "import error",
+			deployed:    []graph.Artifact{{Tag: "tag"}},
+			commands: testutil.
+				CmdRunOut("kubectl --context kubecontext --namespace namespace get nodes -ojsonpath='{@.items[*].status.images[*].names[*]}'", "").
+				AndRunOut("docker save -o images/image.tar tag", "").
+				AndRunOutErr("ctr --namespace k8s.io images import images/image.tar", "output: error!", errors.New("BUG")),
+			shouldErr:     true,
+			expectedError: "output: error!",
+		},
+		{
+			description: "no artifact",
+			deployed:    []graph.Artifact{},
+		},
+	}
+
+	runImageLoadingTests(t, tests, func(i *ImageLoader, test ImageLoadingTest) error {
+		return i.loadImagesWithCtr(context.Background(), ioutil.Discard, test.deployed)
+	})
+}
+
 func runImageLoadingTests(t *testing.T, tests []ImageLoadingTest, loadingFunc func(i *ImageLoader, test ImageLoadingTest) error) {
 	for _, test := range tests {
 		testutil.Run(t, test.description, func(t *testutil.T) {
 			t.Override(&util.DefaultExecCommand, test.commands)
+			t.Override(&cluster.GetClient, func() cluster.Client { return fakeMinikubeClient{} })
+			t.NewTempDir().Chdir()
+			t.Override(&makeTempDir, func(string, string) (string, error) { return "images", nil })
 
 			runCtx := &runcontext.RunContext{
 				Opts: config.SkaffoldOptions{
@@ -159,7 +288,7 @@ func runImageLoadingTests(t *testing.T, tests []ImageLoadingTest, loadingFunc fu
 				KubeContext: "kubecontext",
 			}
 
-			i := NewImageLoader(runCtx.KubeContext, kubectl.NewCLI(runCtx, ""))
+			i := NewImageLoader(runCtx, kubectl.NewCLI(runCtx, ""))
 			err := loadingFunc(i, test)
 
 			if test.shouldErr {
@@ -229,3 +358,10 @@ func TestImagesToLoad(t *testing.T) {
 		})
 	}
 }
+
+type fakeMinikubeClient struct{}
+
+func (fakeMinikubeClient) IsMinikube(string) bool { return true }
+func (fakeMinikubeClient) MinikubeExec(arg ...string) (*exec.Cmd, error) {
+	return exec.Command("minikube", arg...), nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
+	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	kubectx "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/context"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
+)
+
+const (
+	// RegistryContainerName is the name of the registry container managed by Skaffold.
+	RegistryContainerName = "skaffold-registry"
+	registryImage         = "registry:2"
+	registryPort          = "5000"
+
+	// registryEndpoint is the address of the registry container on the docker network of the cluster.
+	registryEndpoint  = "http://" + RegistryContainerName + ":" + registryPort
+	k3sRegistriesFile = "/etc/rancher/k3s/registries.yaml"
+
+	localRegistryHostingName      = "local-registry-hosting"
+	localRegistryHostingNamespace = "kube-public"
+	localRegistryHelp             = "https://skaffold.dev/docs/environment/local-cluster/"
+)
+
+// EnsureLocalRegistry makes sure that the Skaffold-managed registry container is running
+// and attached to the docker network of the local cluster, if any.
+func EnsureLocalRegistry(ctx context.Context, out io.Writer, kubeContext string, minikubeProfile string) error {
+	running, err := util.RunCmdOut(exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", RegistryContainerName))
+	switch {
+	case err != nil:
+		output.Default.Fprintf(out, "Starting local registry %s on %s\n", RegistryContainerName, config.LocalRegistry)
+		hostPort := strings.Replace(config.LocalRegistry, "localhost", "127.0.0.1", 1)
+		cmd := exec.CommandContext(ctx, "docker", "run", "-d", "--restart=always", "-p", hostPort+":"+registryPort, "--name", RegistryContainerName, registryImage)
+		if cmdOut, err := util.RunCmdOut(cmd); err != nil {
+			return fmt.Errorf("starting local registry: %w, %s", err, cmdOut)
+		}
+
+	case strings.TrimSpace(string(running)) != "true":
+		output.Default.Fprintf(out, "Restarting local registry %s\n", RegistryContainerName)
+		if cmdOut, err := util.RunCmdOut(exec.CommandContext(ctx, "docker", "start", RegistryContainerName)); err != nil {
+			return fmt.Errorf("restarting local registry: %w, %s", err, cmdOut)
+		}
+	}
+
+	network := clusterNetwork(kubeContext, minikubeProfile)
+	if network != "" {
+		cmdOut, err := util.RunCmdOut(exec.CommandContext(ctx, "docker", "network", "connect", network, RegistryContainerName))
+		if err != nil && !strings.Contains(string(cmdOut), "already exists") {
+			// The cluster might not run in a docker container, for example with a VM based minikube driver.
+			logrus.Warnf("Unable to connect local registry to the %q network: %v, %s", network, err, cmdOut)
+		}
+	}
+
+	if err := configureRegistryMirror(ctx, out, kubeContext); err != nil {
+		return err
+	}
+
+	// Advertise the registry to tools running against the cluster, as described in
+	// https://github.com/kubernetes/enhancements/tree/master/keps/sig-cluster-lifecycle/generic/1755-communicating-a-local-registry
+	if err := advertiseLocalRegistry(ctx); err != nil {
+		logrus.Warnf("Unable to advertise the local registry in the cluster: %v", err)
+	}
+	return nil
+}
+
+// configureRegistryMirror makes the containerd of kind and k3d nodes pull the `localhost:5001` images
+// from the registry container, that they reach on the cluster's docker network.
+func configureRegistryMirror(ctx context.Context, out io.Writer, kubeContext string) error {
+	switch {
+	case config.IsKindCluster(kubeContext):
+		nodes, err := util.RunCmdOut(exec.CommandContext(ctx, "kind", "get", "nodes", "--name", config.KindClusterName(clusterName(kubeContext))))
+		if err != nil {
+			return fmt.Errorf("listing kind nodes: %w", err)
+		}
+		for _, node := range strings.Fields(string(nodes)) {
+			if err := configureKindNode(ctx, node); err != nil {
+				return err
+			}
+		}
+
+	case config.IsK3dCluster(kubeContext):
+		nodes, err := util.RunCmdOut(exec.CommandContext(ctx, "docker", "ps", "--filter", "label=k3d.cluster="+config.K3dClusterName(clusterName(kubeContext)), "--format", `{{.Names}} {{.Label "k3d.role"}}`))
+		if err != nil {
+			return fmt.Errorf("listing k3d nodes: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(nodes)), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 || (fields[1] != "server" && fields[1] != "agent") {
+				continue
+			}
+			if err := configureK3dNode(ctx, out, fields[0]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// configureKindNode adds a containerd host configuration for `localhost:5001` to a kind node.
+// containerd reads it on each pull, provided that its registry `config_path` is `/etc/containerd/certs.d`.
+func configureKindNode(ctx context.Context, node string) error {
+	dir := "/etc/containerd/certs.d/" + config.LocalRegistry
+	cmd := exec.CommandContext(ctx, "docker", "exec", "-i", node, "sh", "-c", fmt.Sprintf("mkdir -p %s && cat > %s/hosts.toml", dir, dir))
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("[host.%q]\n", registryEndpoint))
+	if cmdOut, err := util.RunCmdOut(cmd); err != nil {
+		return fmt.Errorf("configuring registry mirror on kind node %s: %w, %s", node, err, cmdOut)
+	}
+	return nil
+}
+
+// configureK3dNode adds a `localhost:5001` mirror to the `registries.yaml` of a k3d node.
+// k3s only reads this file on startup, so the node is restarted when the mirror is added.
+func configureK3dNode(ctx context.Context, out io.Writer, node string) error {
+	current, err := util.RunCmdOut(exec.CommandContext(ctx, "docker", "exec", node, "cat", k3sRegistriesFile))
+	if err != nil {
+		// The file doesn't exist unless the cluster was created with a registry configuration.
+		current = nil
+	}
+
+	registries := map[string]interface{}{}
+	if err := yaml.Unmarshal(current, &registries); err != nil {
+		return fmt.Errorf("parsing %s of k3d node %s: %w", k3sRegistriesFile, node, err)
+	}
+	mirrors, _ := registries["mirrors"].(map[string]interface{})
+	if mirrors == nil {
+		mirrors = map[string]interface{}{}
+	}
+	if _, found := mirrors[config.LocalRegistry]; found {
+		return nil
+	}
+	mirrors[config.LocalRegistry] = map[string]interface{}{"endpoint": []string{registryEndpoint}}
+	registries["mirrors"] = mirrors
+
+	buf, err := yaml.Marshal(registries)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "docker", "exec", "-i", node, "sh", "-c", "cat > "+k3sRegistriesFile)
+	cmd.Stdin = bytes.NewReader(buf)
+	if cmdOut, err := util.RunCmdOut(cmd); err != nil {
+		return fmt.Errorf("configuring registry mirror on k3d node %s: %w, %s", node, err, cmdOut)
+	}
+
+	output.Default.Fprintf(out, "Restarting k3d node %s to use the local registry\n", node)
+	if cmdOut, err := util.RunCmdOut(exec.CommandContext(ctx, "docker", "restart", node)); err != nil {
+		return fmt.Errorf("restarting k3d node %s: %w, %s", node, err, cmdOut)
+	}
+	return nil
+}
+
+// advertiseLocalRegistry creates or updates the `local-registry-hosting` ConfigMap of the `kube-public` namespace.
+func advertiseLocalRegistry(ctx context.Context) error {
+	client, err := kubernetesclient.Client()
+	if err != nil {
+		return fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(localRegistryHostingNamespace)
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      localRegistryHostingName,
+			Namespace: localRegistryHostingNamespace,
+		},
+		Data: map[string]string{
+			"localRegistryHosting.v1": fmt.Sprintf("host: %q\nhelp: %q\n", config.LocalRegistry, localRegistryHelp),
+		},
+	}
+
+	existing, err := configMaps.Get(ctx, localRegistryHostingName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	case err == nil:
+		existing.Data = configMap.Data
+		_, err = configMaps.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// clusterNetwork returns the name of the docker network used by a kind, k3d or minikube cluster.
+func clusterNetwork(kubeContext string, minikubeProfile string) string {
+	switch {
+	case minikubeProfile != "":
+		return minikubeProfile
+	case kubeContext == constants.DefaultMinikubeContext:
+		return kubeContext
+	case config.IsKindCluster(kubeContext):
+		return "kind"
+	case config.IsK3dCluster(kubeContext):
+		return "k3d-" + config.K3dClusterName(clusterName(kubeContext))
+	default:
+		return ""
+	}
+}
+
+// clusterName returns the name of the cluster that a kubeconfig context points to.
+func clusterName(kubeContext string) string {
+	if cfg, err := kubectx.CurrentConfig(); err == nil && cfg.Contexts[kubeContext] != nil {
+		return cfg.Contexts[kubeContext].Cluster
+	}
+	return kubeContext
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	kubectx "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/context"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestEnsureLocalRegistry(t *testing.T) {
+	tests := []struct {
+		description     string
+		kubeContext     string
+		minikubeProfile string
+		commands        util.Command
+		shouldErr       bool
+	}{
+		{
+			description: "start registry",
+			kubeContext: "docker-desktop",
+			commands: testutil.
+				CmdRunOutErr("docker inspect -f {{.State.Running}} skaffold-registry", "", errors.New("no such container")).
+				AndRunOut("docker run -d --restart=always -p 127.0.0.1:5001:5000 --name skaffold-registry registry:2", "id"),
+		},
+		{
+			description: "restart stopped registry",
+			kubeContext: "docker-desktop",
+			commands: testutil.
+				CmdRunOut("docker inspect -f {{.State.Running}} skaffold-registry", "false\n").
+				AndRunOut("docker start skaffold-registry", "skaffold-registry"),
+		},
+		{
+			description: "connect to kind network and configure the nodes",
+			kubeContext: "kind-kind",
+			commands: testutil.
+				CmdRunOut("docker inspect -f {{.State.Running}} skaffold-registry", "true\n").
+				AndRunOut("docker network connect kind skaffold-registry", "").
+				AndRunOut("kind get nodes --name kind", "kind-control-plane\nkind-worker\n").
+				AndRunInputOut("docker exec -i kind-control-plane sh -c mkdir -p /etc/containerd/certs.d/localhost:5001 && cat > /etc/containerd/certs.d/localhost:5001/hosts.toml", `[host."http://skaffold-registry:5000"]`+"\n", "").
+				AndRunInputOut("docker exec -i kind-worker sh -c mkdir -p /etc/containerd/certs.d/localhost:5001 && cat > /etc/containerd/certs.d/localhost:5001/hosts.toml", `[host."http://skaffold-registry:5000"]`+"\n", ""),
+		},
+		{
+			description: "add a mirror to the k3d nodes",
+			kubeContext: "k3d-dev",
+			commands: testutil.
+				CmdRunOut("docker inspect -f {{.State.Running}} skaffold-registry", "true\n").
+				AndRunOut("docker network connect k3d-dev skaffold-registry", "").
+				AndRunOut(`docker ps --filter label=k3d.cluster=dev --format {{.Names}} {{.Label "k3d.role"}}`, "k3d-dev-server-0 server\nk3d-dev-serverlb loadbalancer\nk3d-dev-agent-0 agent\n").
+				AndRunOut("docker exec k3d-dev-server-0 cat /etc/rancher/k3s/registries.yaml", "mirrors:\n  docker.io:\n    endpoint:\n      - https://mirror.gcr.io\n").
+				AndRunInputOut("docker exec -i k3d-dev-server-0 sh -c cat > /etc/rancher/k3s/registries.yaml", "mirrors:\n  docker.io:\n    endpoint:\n    - https://mirror.gcr.io\n  localhost:5001:\n    endpoint:\n    - http://skaffold-registry:5000\n", "").
+				AndRunOut("docker restart k3d-dev-server-0", "k3d-dev-server-0").
+				AndRunOut("docker exec k3d-dev-agent-0 cat /etc/rancher/k3s/registries.yaml", "mirrors:\n  localhost:5001:\n    endpoint:\n      - http://skaffold-registry:5000\n"),
+		},
+		{
+			description:     "already connected to minikube network",
+			kubeContext:     "some-context",
+			minikubeProfile: "profile",
+			commands: testutil.
+				CmdRunOut("docker inspect -f {{.State.Running}} skaffold-registry", "true\n").
+				AndRunOutErr("docker network connect profile skaffold-registry", "endpoint with name skaffold-registry already exists in network profile", errors.New("exit status 1")),
+		},
+		{
+			description: "network not found is not an error",
+			kubeContext: "minikube",
+			commands: testutil.
+				CmdRunOut("docker inspect -f {{.State.Running}} skaffold-registry", "true\n").
+				AndRunOutErr("docker network connect minikube skaffold-registry", "network minikube not found", errors.New("exit status 1")),
+		},
+		{
+			description: "start error",
+			kubeContext: "docker-desktop",
+			commands: testutil.
+				CmdRunOutErr("docker inspect -f {{.State.Running}} skaffold-registry", "", errors.New("no such container")).
+				AndRunOutErr("docker run -d --restart=always -p 127.0.0.1:5001:5000 --name skaffold-registry registry:2", "port is already allocated", errors.New("exit status 125")),
+			shouldErr: true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&util.DefaultExecCommand, test.commands)
+			t.Override(&kubectx.CurrentConfig, func() (api.Config, error) { return api.Config{}, nil })
+			client := fake.NewSimpleClientset()
+			t.Override(&kubernetesclient.Client, func() (kubernetes.Interface, error) { return client, nil })
+
+			err := EnsureLocalRegistry(context.Background(), ioutil.Discard, test.kubeContext, test.minikubeProfile)
+			t.CheckError(test.shouldErr, err)
+			if test.shouldErr {
+				return
+			}
+
+			configMap, err := client.CoreV1().ConfigMaps("kube-public").Get(context.Background(), "local-registry-hosting", metav1.GetOptions{})
+			t.CheckNoError(err)
+			t.CheckDeepEqual("host: \"localhost:5001\"\nhelp: \"https://skaffold.dev/docs/environment/local-cluster/\"\n", configMap.Data["localRegistryHosting.v1"])
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	apimachinery "k8s.io/apimachinery/pkg/runtime/schema"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+// customResources is the set of user-declared custom resource kinds that can be transformed by Skaffold.
+var customResources = map[apimachinery.GroupKind]CustomResource{}
+
+// CustomResource declares the fields of a custom resource kind that are transformed by Skaffold.
+type CustomResource struct {
+	Image       []FieldPath
+	PodTemplate []FieldPath
+	Labels      []FieldPath
+}
+
+// FieldPath is the list of keys leading to a field of a resource.
+type FieldPath []string
+
+// SetCustomResources replaces the custom resource kinds that are transformed by Skaffold.
+func SetCustomResources(filters []latestV1.ResourceFilter) error {
+	resources := map[apimachinery.GroupKind]CustomResource{}
+	for _, f := range filters {
+		groupKind := apimachinery.ParseGroupKind(f.GroupKind)
+		if groupKind.Kind == "" {
+			return fmt.Errorf("invalid groupKind %q: expected the form `Kind.group`", f.GroupKind)
+		}
+
+		cr := resources[groupKind]
+		for _, paths := range []struct {
+			from []string
+			to   *[]FieldPath
+		}{
+			{f.Image, &cr.Image},
+			{f.PodTemplate, &cr.PodTemplate},
+			{f.Labels, &cr.Labels},
+		} {
+			for _, p := range paths.from {
+				path, err := ParseFieldPath(p)
+				if err != nil {
+					return fmt.Errorf("invalid field path for %q: %w", f.GroupKind, err)
+				}
+				*paths.to = append(*paths.to, path)
+			}
+		}
+		resources[groupKind] = cr
+	}
+
+	customResources = resources
+	return nil
+}
+
+// ParseFieldPath parses a dot-separated field path, e.g. `.spec.template`.
+func ParseFieldPath(path string) (FieldPath, error) {
+	keys := strings.Split(strings.TrimPrefix(path, "."), ".")
+	for _, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("%q has an empty field name", path)
+		}
+	}
+	return keys, nil
+}
+
+// GetCustomResource returns the declaration of the custom resource kind of a manifest, if the kind is declared.
+func GetCustomResource(manifest map[string]interface{}) (CustomResource, bool) {
+	groupKind, ok := getGroupKind(manifest)
+	if !ok {
+		return CustomResource{}, false
+	}
+	cr, found := customResources[groupKind]
+	return cr, found
+}
+
+// PodTemplates returns the pod templates found at the declared paths of a resource.
+func (c CustomResource) PodTemplates(manifest map[string]interface{}) []map[string]interface{} {
+	var templates []map[string]interface{}
+	for _, p := range c.PodTemplate {
+		p.visit(manifest, func(parent map[string]interface{}, key string) {
+			if template, ok := parent[key].(map[string]interface{}); ok {
+				templates = append(templates, template)
+			}
+		})
+	}
+	return templates
+}
+
+// transformsAllFields returns true when no field is declared, which means that all fields are transformable.
+func (c CustomResource) transformsAllFields() bool {
+	return len(c.Image) == 0 && len(c.PodTemplate) == 0 && len(c.Labels) == 0
+}
+
+// visitDeclaredFields lets the visitor transform the declared fields of a resource.
+func (c CustomResource) visitDeclaredFields(manifest map[string]interface{}, visitor FieldVisitor) {
+	for _, p := range c.Image {
+		p.visit(manifest, func(parent map[string]interface{}, key string) {
+			// lists of images are supported too
+			if images, ok := parent[key].([]interface{}); ok {
+				for i := range images {
+					visitImage(images, i, visitor)
+				}
+				return
+			}
+			o := map[string]interface{}{}
+			if v, found := parent[key]; found {
+				o["image"] = v
+			}
+			visitor.Visit(o, "image", o["image"])
+			if v, found := o["image"]; found {
+				parent[key] = v
+			}
+		})
+	}
+
+	for _, p := range c.PodTemplate {
+		p.visit(manifest, func(parent map[string]interface{}, key string) {
+			visitFields(parent[key], &recursiveVisitorDecorator{visitor})
+		})
+	}
+
+	for _, p := range c.Labels {
+		p.visit(manifest, func(parent map[string]interface{}, key string) {
+			// the visitors set labels on `metadata` objects
+			metadata := map[string]interface{}{}
+			if labels, found := parent[key]; found && labels != nil {
+				metadata["labels"] = labels
+			}
+			visitor.Visit(map[string]interface{}{"metadata": metadata}, "metadata", metadata)
+			if labels, found := metadata["labels"]; found {
+				parent[key] = labels
+			}
+		})
+	}
+}
+
+func visitImage(images []interface{}, i int, visitor FieldVisitor) {
+	o := map[string]interface{}{"image": images[i]}
+	visitor.Visit(o, "image", images[i])
+	images[i] = o["image"]
+}
+
+// visit calls the given function with the parent object and the key of each field found at the path.
+// The parent objects must exist but the field itself can be missing.
+func (p FieldPath) visit(o interface{}, fn func(parent map[string]interface{}, key string)) {
+	if len(p) == 0 {
+		return
+	}
+	switch v := o.(type) {
+	case []interface{}:
+		for _, e := range v {
+			p.visit(e, fn)
+		}
+	case map[string]interface{}:
+		if len(p) == 1 {
+			fn(v, p[0])
+			return
+		}
+		if next, found := v[p[0]]; found {
+			p[1:].visit(next, fn)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"testing"
+
+	apimachinery "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestSetCustomResources(t *testing.T) {
+	tests := []struct {
+		description string
+		filters     []latestV1.ResourceFilter
+		expected    map[apimachinery.GroupKind]CustomResource
+		shouldErr   bool
+	}{
+		{
+			description: "none",
+			expected:    map[apimachinery.GroupKind]CustomResource{},
+		},
+		{
+			description: "paths",
+			filters: []latestV1.ResourceFilter{
+				{GroupKind: "Workflow.argoproj.io", Image: []string{".spec.image", "spec.sidecar.image"}, PodTemplate: []string{".spec.template"}},
+				{GroupKind: "Workflow.argoproj.io", Labels: []string{".spec.podLabels"}},
+				{GroupKind: "Cache.example.com"},
+			},
+			expected: map[apimachinery.GroupKind]CustomResource{
+				{Group: "argoproj.io", Kind: "Workflow"}: {
+					Image:       []FieldPath{{"spec", "image"}, {"spec", "sidecar", "image"}},
+					PodTemplate: []FieldPath{{"spec", "template"}},
+					Labels:      []FieldPath{{"spec", "podLabels"}},
+				},
+				{Group: "example.com", Kind: "Cache"}: {},
+			},
+		},
+		{
+			description: "invalid group kind",
+			filters:     []latestV1.ResourceFilter{{GroupKind: ".argoproj.io"}},
+			shouldErr:   true,
+		},
+		{
+			description: "invalid path",
+			filters:     []latestV1.ResourceFilter{{GroupKind: "Workflow.argoproj.io", Image: []string{".spec..image"}}},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&customResources, map[apimachinery.GroupKind]CustomResource{})
+
+			err := SetCustomResources(test.filters)
+
+			t.CheckError(test.shouldErr, err)
+			if !test.shouldErr {
+				t.CheckDeepEqual(test.expected, customResources)
+			}
+		})
+	}
+}
+
+func TestReplaceImagesInCustomResources(t *testing.T) {
+	manifests := ManifestList{[]byte(`
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  ignored:
+    image: gcr.io/k8s-skaffold/example
+  image: gcr.io/k8s-skaffold/example
+  steps:
+  - runner: gcr.io/k8s-skaffold/example
+  - runner: skaffold/other
+  template:
+    spec:
+      containers:
+      - image: gcr.io/k8s-skaffold/example
+        name: example
+  tools:
+  - gcr.io/k8s-skaffold/example
+`), []byte(`
+apiVersion: example.com/v1
+kind: Cache
+spec:
+  image: gcr.io/k8s-skaffold/example
+`), []byte(`
+apiVersion: example.com/v1
+kind: Undeclared
+spec:
+  image: gcr.io/k8s-skaffold/example
+`)}
+
+	expected := ManifestList{[]byte(`
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  ignored:
+    image: gcr.io/k8s-skaffold/example
+  image: gcr.io/k8s-skaffold/example:TAG
+  steps:
+  - runner: gcr.io/k8s-skaffold/example:TAG
+  - runner: skaffold/other:OTHER_TAG
+  template:
+    spec:
+      containers:
+      - image: gcr.io/k8s-skaffold/example:TAG
+        name: example
+  tools:
+  - gcr.io/k8s-skaffold/example:TAG
+`), []byte(`
+apiVersion: example.com/v1
+kind: Cache
+spec:
+  image: gcr.io/k8s-skaffold/example:TAG
+`), []byte(`
+apiVersion: example.com/v1
+kind: Undeclared
+spec:
+  image: gcr.io/k8s-skaffold/example
+`)}
+
+	builds := []graph.Artifact{
+		{ImageName: "gcr.io/k8s-skaffold/example", Tag: "gcr.io/k8s-skaffold/example:TAG"},
+		{ImageName: "skaffold/other", Tag: "skaffold/other:OTHER_TAG"},
+	}
+
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&customResources, map[apimachinery.GroupKind]CustomResource{})
+		t.CheckNoError(SetCustomResources([]latestV1.ResourceFilter{
+			{
+				GroupKind:   "Workflow.argoproj.io",
+				Image:       []string{".spec.image", ".spec.steps.runner", ".spec.tools", ".spec.missing.image"},
+				PodTemplate: []string{".spec.template"},
+			},
+			{GroupKind: "Cache.example.com"},
+		}))
+
+		resultManifest, err := manifests.ReplaceImages(context.TODO(), builds)
+
+		t.CheckNoError(err)
+		t.CheckDeepEqual(expected.String(), resultManifest.String())
+	})
+}
+
+func TestSetLabelsInCustomResources(t *testing.T) {
+	manifests := ManifestList{[]byte(`
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  name: build
+spec:
+  podLabels:
+    app: build
+  template:
+    metadata:
+      name: runner
+  workers:
+  - name: worker
+`)}
+
+	expected := ManifestList{[]byte(`
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  labels:
+    key1: value1
+  name: build
+spec:
+  podLabels:
+    app: build
+    key1: value1
+  template:
+    metadata:
+      labels:
+        key1: value1
+      name: runner
+  workers:
+  - labels:
+      key1: value1
+    name: worker
+`)}
+
+	testutil.Run(t, "", func(t *testutil.T) {
+		t.Override(&customResources, map[apimachinery.GroupKind]CustomResource{})
+		t.CheckNoError(SetCustomResources([]latestV1.ResourceFilter{{
+			GroupKind:   "Workflow.argoproj.io",
+			PodTemplate: []string{".spec.template"},
+			Labels:      []string{".spec.podLabels", ".spec.workers.labels"},
+		}}))
+
+		resultManifest, err := manifests.SetLabels(map[string]string{"key1": "value1"})
+
+		t.CheckNoError(err)
+		t.CheckDeepEqual(expected.String(), resultManifest.String())
+	})
+}
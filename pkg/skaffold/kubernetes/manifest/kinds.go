@@ -0,0 +1,47 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"fmt"
+	"sort"
+
+	apimachinery "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
+)
+
+// CollectGroupKinds returns the group and kind of all the resources in the manifests.
+func (l *ManifestList) CollectGroupKinds() ([]apimachinery.GroupKind, error) {
+	found := map[apimachinery.GroupKind]bool{}
+	for _, manifest := range *l {
+		m := make(map[string]interface{})
+		if err := yaml.Unmarshal(manifest, &m); err != nil {
+			return nil, fmt.Errorf("collecting kinds: reading Kubernetes YAML: %w", err)
+		}
+		if gk, ok := getGroupKind(m); ok {
+			found[gk] = true
+		}
+	}
+
+	kinds := make([]apimachinery.GroupKind, 0, len(found))
+	for gk := range found {
+		kinds = append(kinds, gk)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+	return kinds, nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"testing"
+
+	apimachinery "k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestCollectGroupKinds(t *testing.T) {
+	manifests := ManifestList{[]byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: foo`), []byte(`
+apiVersion: example.com/v1
+kind: Cache
+metadata:
+  name: cache`), []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: bar`), []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app`)}
+
+	kinds, err := manifests.CollectGroupKinds()
+
+	testutil.CheckErrorAndDeepEqual(t, false, err, []apimachinery.GroupKind{
+		{Group: "example.com", Kind: "Cache"},
+		{Group: "apps", Kind: "Deployment"},
+		{Kind: "Pod"},
+	}, kinds)
+}
@@ -24,6 +24,8 @@ import (
 	"strings"
 
 	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/yaml"
 )
 
 // ManifestList is a list of yaml manifests.
@@ -129,6 +131,50 @@ func (l *ManifestList) Diff(latest ManifestList) ManifestList {
 	return updated
 }
 
+// Removed computes the list of manifests describing resources that aren't part of the latest manifests anymore.
+// Resources are identified by their group, kind, namespace and name.
+func (l *ManifestList) Removed(latest ManifestList) ManifestList {
+	if l == nil {
+		return nil
+	}
+
+	latestResources := map[string]bool{}
+	for _, manifest := range latest {
+		latestResources[resourceKey(manifest)] = true
+	}
+
+	var removed ManifestList
+
+	for _, oldManifest := range *l {
+		if !latestResources[resourceKey(oldManifest)] {
+			removed = append(removed, oldManifest)
+		}
+	}
+
+	return removed
+}
+
+// resourceKey identifies the resource described by a manifest, regardless of its api version.
+func resourceKey(manifest []byte) string {
+	var resource struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Metadata   struct {
+			Name      string `yaml:"name"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal(manifest, &resource); err != nil {
+		return string(manifest)
+	}
+
+	group := ""
+	if i := strings.LastIndex(resource.APIVersion, "/"); i >= 0 {
+		group = resource.APIVersion[:i]
+	}
+	return strings.Join([]string{group, resource.Kind, resource.Metadata.Namespace, resource.Metadata.Name}, "/")
+}
+
 // Reader returns a reader on the raw yaml descriptors.
 func (l *ManifestList) Reader() io.Reader {
 	return strings.NewReader(l.String())
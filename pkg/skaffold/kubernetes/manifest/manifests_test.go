@@ -162,3 +162,48 @@ func TestAppendServiceAndRoleBinding(t *testing.T) {
 	testutil.CheckDeepEqual(t, service, string(manifests[1]))
 	testutil.CheckDeepEqual(t, manifests.String(), roleBinding+"\n---\n"+service)
 }
+
+func TestRemoved(t *testing.T) {
+	deploymentV1beta1 := "apiVersion: apps/v1beta1\nkind: Deployment\nmetadata:\n  name: leeroy-web"
+	deploymentV1 := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: leeroy-web"
+	deploymentOtherNamespace := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: leeroy-web\n  namespace: other"
+
+	tests := []struct {
+		description string
+		previous    *ManifestList
+		latest      ManifestList
+		expected    ManifestList
+	}{
+		{
+			description: "no previous manifests",
+			latest:      ManifestList{[]byte(pod1)},
+		},
+		{
+			description: "updated manifest",
+			previous:    &ManifestList{[]byte(pod1)},
+			latest:      ManifestList{[]byte(pod1 + "\n    imagePullPolicy: Always")},
+		},
+		{
+			description: "removed manifest",
+			previous:    &ManifestList{[]byte(pod1), []byte(service)},
+			latest:      ManifestList{[]byte(service)},
+			expected:    ManifestList{[]byte(pod1)},
+		},
+		{
+			description: "new api version",
+			previous:    &ManifestList{[]byte(deploymentV1beta1)},
+			latest:      ManifestList{[]byte(deploymentV1)},
+		},
+		{
+			description: "moved to another namespace",
+			previous:    &ManifestList{[]byte(deploymentV1)},
+			latest:      ManifestList{[]byte(deploymentOtherNamespace)},
+			expected:    ManifestList{[]byte(deploymentV1)},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.CheckDeepEqual(test.expected, test.previous.Removed(test.latest))
+		})
+	}
+}
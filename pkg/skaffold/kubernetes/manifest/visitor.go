@@ -80,17 +80,33 @@ func (l *ManifestList) Visit(visitor FieldVisitor) (ManifestList, error) {
 func traverseManifestFields(manifest map[string]interface{}, visitor FieldVisitor) {
 	if shouldTransformManifest(manifest) {
 		visitor = &recursiveVisitorDecorator{visitor}
+	} else if cr, found := GetCustomResource(manifest); found {
+		if cr.transformsAllFields() {
+			visitor = &recursiveVisitorDecorator{visitor}
+		} else {
+			cr.visitDeclaredFields(manifest, visitor)
+		}
 	}
 	visitFields(manifest, visitor)
 }
 
 func shouldTransformManifest(manifest map[string]interface{}) bool {
+	groupKind, ok := getGroupKind(manifest)
+	if !ok {
+		return false
+	}
+
+	return transformableAllowlist[groupKind]
+}
+
+// getGroupKind returns the group and kind of a manifest.
+func getGroupKind(manifest map[string]interface{}) (apimachinery.GroupKind, bool) {
 	var apiVersion string
 	switch value := manifest["apiVersion"].(type) {
 	case string:
 		apiVersion = value
 	default:
-		return false
+		return apimachinery.GroupKind{}, false
 	}
 
 	var kind string
@@ -98,16 +114,14 @@ func shouldTransformManifest(manifest map[string]interface{}) bool {
 	case string:
 		kind = value
 	default:
-		return false
+		return apimachinery.GroupKind{}, false
 	}
 
 	gvk := apimachinery.FromAPIVersionAndKind(apiVersion, kind)
-	groupKind := apimachinery.GroupKind{
+	return apimachinery.GroupKind{
 		Group: gvk.Group,
 		Kind:  gvk.Kind,
-	}
-
-	return transformableAllowlist[groupKind]
+	}, true
 }
 
 // recursiveVisitorDecorator adds recursion to a FieldVisitor.
@@ -41,6 +41,7 @@ type Config interface {
 	Mode() config.RunMode
 	PortForwardResources() []*latestV1.PortForwardResource
 	PortForwardOptions() config.PortForwardOptions
+	PortForwarder() string
 }
 
 // Forwarder is an interface that can modify and manage port-forward processes
@@ -61,12 +62,12 @@ type ForwarderManager struct {
 
 // NewForwarderManager returns a new port manager which handles starting and stopping port forwarding
 func NewForwarderManager(cli *kubectl.CLI, podSelector kubernetes.PodSelector, label string, runMode config.RunMode, namespaces *[]string,
-	options config.PortForwardOptions, userDefined []*latestV1.PortForwardResource) *ForwarderManager {
+	options config.PortForwardOptions, forwarderType string, userDefined []*latestV1.PortForwardResource) *ForwarderManager {
 	if !options.Enabled() {
 		return nil
 	}
 
-	entryManager := NewEntryManager(NewKubectlForwarder(cli))
+	entryManager := NewEntryManager(NewEntryForwarder(forwarderType, cli))
 
 	var forwarders []Forwarder
 	if options.ForwardUser(runMode) {
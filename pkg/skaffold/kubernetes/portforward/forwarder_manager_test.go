@@ -63,6 +63,7 @@ func TestNewForwarderManager(t *testing.T) {
 				"",
 				nil,
 				options,
+				"",
 				nil)
 
 			if fm != nil {
@@ -0,0 +1,407 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	kubectx "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/context"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	schemautil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+const (
+	// KubectlForwarderType runs a `kubectl port-forward` process for each forwarded port.
+	KubectlForwarderType = "kubectl"
+	// NativeForwarderType forwards ports from within Skaffold, using the Kubernetes API.
+	NativeForwarderType = "native"
+)
+
+// For testing
+var (
+	findNewestPod   = findNewestPodForResource
+	forwardPodPorts = forwardPodPortsWithSPDY
+	waitRetry       = 500 * time.Millisecond
+)
+
+// NewEntryForwarder returns the EntryForwarder of the given type.
+func NewEntryForwarder(forwarderType string, cli *kubectl.CLI) EntryForwarder {
+	switch forwarderType {
+	case NativeForwarderType:
+		return NewNativeForwarder()
+	case KubectlForwarderType, "":
+	default:
+		logrus.Warnf("unknown port forwarder %q, using %q instead", forwarderType, KubectlForwarderType)
+	}
+	return NewKubectlForwarder(cli)
+}
+
+// NativeForwarder port-forwards entries through the Kubernetes API, without spawning `kubectl` processes.
+// Entries are forwarded to the newest running pod of the forwarded resource, and are failed over to the
+// newest matching pod when that pod is deleted.
+type NativeForwarder struct {
+	started int32
+	out     io.Writer
+}
+
+// NewNativeForwarder returns a new NativeForwarder
+func NewNativeForwarder() *NativeForwarder {
+	return &NativeForwarder{}
+}
+
+func (n *NativeForwarder) Start(out io.Writer) {
+	atomic.StoreInt32(&n.started, 1)
+	n.out = out
+}
+
+// Forward port-forwards the entry in the background.
+// It returns once the port is forwarded, or the first attempt has failed.
+func (n *NativeForwarder) Forward(parentCtx context.Context, pfe *portForwardEntry) error {
+	errChan := make(chan error, 1)
+	go n.forward(parentCtx, pfe, errChan)
+	return <-errChan
+}
+
+func (n *NativeForwarder) forward(parentCtx context.Context, pfe *portForwardEntry, errChan chan error) {
+	if atomic.LoadInt32(&n.started) == 0 {
+		errChan <- fmt.Errorf("Forward() called before native forwarder was started")
+		return
+	}
+	var notifiedUser bool
+
+	for {
+		pfe.terminationLock.Lock()
+		if pfe.terminated {
+			logrus.Debugf("port forwarding %v was cancelled...", pfe)
+			pfe.terminationLock.Unlock()
+			notify(errChan, nil)
+			return
+		}
+		ctx, cancel := context.WithCancel(parentCtx)
+		pfe.cancel = cancel
+		pfe.terminationLock.Unlock()
+
+		if !isPortFree(util.Loopback, pfe.localPort) {
+			cancel()
+			// Assuming that Skaffold brokered ports don't overlap, this has to be an external process that started
+			// since the dev loop kicked off. We are notifying the user in the hope that they can fix it
+			output.Red.Fprintf(n.out, "failed to port forward %v, port %d is taken, retrying...\n", pfe, pfe.localPort)
+			notifiedUser = true
+			time.Sleep(waitPortNotFree)
+			continue
+		}
+
+		if notifiedUser {
+			output.Green.Fprintf(n.out, "port forwarding %v recovered on port %d\n", pfe, pfe.localPort)
+			notifiedUser = false
+		}
+
+		podName, remotePort, err := findNewestPod(ctx, pfe.resource)
+		if err == nil {
+			logrus.Debugf("port forwarding %v to pod %s, port %d", pfe, podName, remotePort)
+			err = n.forwardToPod(ctx, pfe, podName, remotePort, errChan)
+		}
+		if ctx.Err() == context.Canceled {
+			logrus.Debugf("terminated %v due to context cancellation", pfe)
+			return
+		}
+		cancel()
+		if err != nil {
+			logrus.Debugf("port forwarding %v failed: %v", pfe, err)
+			notify(errChan, fmt.Errorf("port forwarding %v failed: %w", pfe, err))
+		} else {
+			logrus.Debugf("pod %s was deleted, port forwarding %v to the newest pod", podName, pfe)
+		}
+		time.Sleep(waitRetry)
+	}
+}
+
+// forwardToPod forwards the entry to a pod until the pod is deleted, or the context is cancelled.
+func (n *NativeForwarder) forwardToPod(ctx context.Context, pfe *portForwardEntry, podName string, remotePort int, errChan chan error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	var once sync.Once
+	stop := func() { once.Do(func() { close(stopChan) }) }
+	defer stop()
+
+	// a nil channel never fires: without a watch, the pod is only failed over when the connection is lost
+	gone, err := watchPodDeletion(ctx, pfe.resource.Namespace, podName)
+	if err != nil {
+		logrus.Debugf("unable to detect deletion of pod %s: %v", podName, err)
+	}
+	go func() {
+		select {
+		case <-gone:
+		case <-ctx.Done():
+		}
+		stop()
+	}()
+	go func() {
+		select {
+		case <-readyChan:
+			notify(errChan, nil)
+		case <-stopChan:
+		}
+	}()
+
+	address := pfe.resource.Address
+	if address == "" {
+		address = "localhost"
+	}
+	return forwardPodPorts(pfe.resource.Namespace, podName, address, pfe.localPort, remotePort, stopChan, readyChan)
+}
+
+// Terminate stops forwarding an entry.
+func (*NativeForwarder) Terminate(p *portForwardEntry) {
+	logrus.Debugf("Terminating port-forward %v", p)
+
+	p.terminationLock.Lock()
+	defer p.terminationLock.Unlock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.terminated = true
+}
+
+// notify reports the outcome of the first attempt to forward an entry.
+func notify(errChan chan error, err error) {
+	select {
+	case errChan <- err:
+	default:
+	}
+}
+
+// watchPodDeletion returns a channel that is closed when the pod is deleted or stops running.
+// The pod is watched until the context is cancelled.
+func watchPodDeletion(ctx context.Context, ns, podName string) (<-chan struct{}, error) {
+	client, err := kubernetesclient.Client()
+	if err != nil {
+		return nil, fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+	w, err := client.CoreV1().Pods(ns).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", podName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("watching pod %s: %w", podName, err)
+	}
+
+	gone := make(chan struct{})
+	// the pod might have been deleted before the watch was started
+	if pod, err := client.CoreV1().Pods(ns).Get(ctx, podName, metav1.GetOptions{}); err != nil || isTerminating(pod) {
+		w.Stop()
+		close(gone)
+		return gone, nil
+	}
+
+	go func() {
+		defer w.Stop()
+		defer close(gone)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-w.ResultChan():
+				if !ok {
+					// the watch timed out: forward to the newest pod again
+					return
+				}
+				pod, ok := evt.Object.(*corev1.Pod)
+				if !ok || pod.Name != podName {
+					continue
+				}
+				if evt.Type == watch.Deleted || isTerminating(pod) {
+					return
+				}
+			}
+		}
+	}()
+	return gone, nil
+}
+
+func isTerminating(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// forwardPodPortsWithSPDY forwards a local port to a pod port, using the port-forward subresource of the pod.
+// It blocks until stopChan is closed, or the connection to the pod is lost.
+func forwardPodPortsWithSPDY(ns, podName, address string, localPort, remotePort int, stopChan <-chan struct{}, readyChan chan struct{}) error {
+	config, err := kubectx.GetRestClientConfig()
+	if err != nil {
+		return fmt.Errorf("getting client config for Kubernetes client: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return fmt.Errorf("creating port-forward transport: %w", err)
+	}
+
+	url := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(ns).
+		Name(podName).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	errOut := logrus.StandardLogger().WriterLevel(logrus.DebugLevel)
+	defer errOut.Close()
+
+	fw, err := portforward.NewOnAddresses(dialer, []string{address}, []string{fmt.Sprintf("%d:%d", localPort, remotePort)}, stopChan, readyChan, ioutil.Discard, errOut)
+	if err != nil {
+		return err
+	}
+	return fw.ForwardPorts()
+}
+
+// findNewestPodForResource finds the newest running pod of a resource, and the pod port matching the resource port.
+func findNewestPodForResource(ctx context.Context, resource latestV1.PortForwardResource) (string, int, error) {
+	resourceType := strings.ToLower(string(resource.Type))
+	if resourceType == "service" {
+		return findNewestPodForSvc(ctx, resource.Namespace, resource.Name, resource.Port)
+	}
+
+	client, err := kubernetesclient.Client()
+	if err != nil {
+		return "", -1, fmt.Errorf("getting Kubernetes client: %w", err)
+	}
+
+	var pods []corev1.Pod
+	if resourceType == "pod" {
+		pod, err := client.CoreV1().Pods(resource.Namespace).Get(ctx, resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", -1, fmt.Errorf("getting pod %s/%s: %w", resource.Namespace, resource.Name, err)
+		}
+		pods = []corev1.Pod{*pod}
+	} else {
+		selector, err := podSelectorForResource(ctx, client, resourceType, resource.Namespace, resource.Name)
+		if err != nil {
+			return "", -1, err
+		}
+		podsList, err := client.CoreV1().Pods(resource.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return "", -1, fmt.Errorf("listing pods: %w", err)
+		}
+		pods = podsList.Items
+	}
+
+	var running []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+		}
+	}
+	sort.Slice(running, newestPodsFirst(running))
+
+	for _, p := range running {
+		if port := findContainerPort(p, resource.Port); port > 0 {
+			return p.Name, port, nil
+		}
+	}
+	return "", -1, fmt.Errorf("no running pods match %s/%s/%s", resourceType, resource.Name, resource.Port.String())
+}
+
+// podSelectorForResource returns the label selector of the pods managed by a workload resource.
+func podSelectorForResource(ctx context.Context, client kubernetes.Interface, resourceType, ns, name string) (string, error) {
+	var selector *metav1.LabelSelector
+	var err error
+	switch resourceType {
+	case "deployment", "deployments", "deploy":
+		obj, e := client.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		err = e
+		if err == nil {
+			selector = obj.Spec.Selector
+		}
+	case "replicaset", "replicasets", "rs":
+		obj, e := client.AppsV1().ReplicaSets(ns).Get(ctx, name, metav1.GetOptions{})
+		err = e
+		if err == nil {
+			selector = obj.Spec.Selector
+		}
+	case "statefulset", "statefulsets", "sts":
+		obj, e := client.AppsV1().StatefulSets(ns).Get(ctx, name, metav1.GetOptions{})
+		err = e
+		if err == nil {
+			selector = obj.Spec.Selector
+		}
+	case "daemonset", "daemonsets", "ds":
+		obj, e := client.AppsV1().DaemonSets(ns).Get(ctx, name, metav1.GetOptions{})
+		err = e
+		if err == nil {
+			selector = obj.Spec.Selector
+		}
+	case "job", "jobs":
+		obj, e := client.BatchV1().Jobs(ns).Get(ctx, name, metav1.GetOptions{})
+		err = e
+		if err == nil {
+			selector = obj.Spec.Selector
+		}
+	default:
+		return "", fmt.Errorf("resource type %q is not supported by the %s port forwarder", resourceType, NativeForwarderType)
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting %s %s/%s: %w", resourceType, ns, name, err)
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", fmt.Errorf("parsing selector of %s %s/%s: %w", resourceType, ns, name, err)
+	}
+	return s.String(), nil
+}
+
+// findContainerPort returns the pod port matching the given port number or port name.
+func findContainerPort(pod corev1.Pod, port schemautil.IntOrString) int {
+	if port.Type == schemautil.Int {
+		return port.IntVal
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == port.StrVal {
+				return int(p.ContainerPort)
+			}
+		}
+	}
+	return -1
+}
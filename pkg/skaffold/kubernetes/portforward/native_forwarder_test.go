@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	schemautil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestNewEntryForwarder(t *testing.T) {
+	tests := []struct {
+		description   string
+		forwarderType string
+		expected      EntryForwarder
+	}{
+		{
+			description:   "default",
+			forwarderType: "",
+			expected:      NewKubectlForwarder(&kubectl.CLI{}),
+		},
+		{
+			description:   "kubectl",
+			forwarderType: "kubectl",
+			expected:      NewKubectlForwarder(&kubectl.CLI{}),
+		},
+		{
+			description:   "native",
+			forwarderType: "native",
+			expected:      NewNativeForwarder(),
+		},
+		{
+			description:   "unknown falls back to kubectl",
+			forwarderType: "other",
+			expected:      NewKubectlForwarder(&kubectl.CLI{}),
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.CheckTypeEquality(test.expected, NewEntryForwarder(test.forwarderType, &kubectl.CLI{}))
+		})
+	}
+}
+
+func TestFindNewestPodForResource(t *testing.T) {
+	labelled := func(pod *corev1.Pod, phase corev1.PodPhase) *corev1.Pod {
+		pod.Labels = map[string]string{"app": "web"}
+		pod.Status.Phase = phase
+		return pod
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+
+	tests := []struct {
+		description     string
+		clientResources []pkgruntime.Object
+		resource        latestV1.PortForwardResource
+		shouldErr       bool
+		chosenPod       string
+		chosenPort      int
+	}{
+		{
+			description:     "pod with port number",
+			clientResources: []pkgruntime.Object{mockPod("pod", nil, time.Now())},
+			resource:        latestV1.PortForwardResource{Type: "pod", Name: "pod", Port: schemautil.FromInt(8080)},
+			chosenPod:       "pod",
+			chosenPort:      8080,
+		},
+		{
+			description:     "pod with port name",
+			clientResources: []pkgruntime.Object{mockPod("pod", []corev1.ContainerPort{{Name: "http", ContainerPort: 9000}}, time.Now())},
+			resource:        latestV1.PortForwardResource{Type: "Pod", Name: "pod", Port: schemautil.FromString("http")},
+			chosenPod:       "pod",
+			chosenPort:      9000,
+		},
+		{
+			description:     "unknown port name",
+			clientResources: []pkgruntime.Object{mockPod("pod", []corev1.ContainerPort{{Name: "http", ContainerPort: 9000}}, time.Now())},
+			resource:        latestV1.PortForwardResource{Type: "pod", Name: "pod", Port: schemautil.FromString("grpc")},
+			shouldErr:       true,
+		},
+		{
+			description: "newest running pod of a deployment",
+			clientResources: []pkgruntime.Object{
+				deployment,
+				labelled(mockPod("old", nil, time.Now().Add(-time.Hour)), corev1.PodRunning),
+				labelled(mockPod("new", nil, time.Now().Add(-time.Minute)), corev1.PodRunning),
+				labelled(mockPod("pending", nil, time.Now()), corev1.PodPending),
+				mockPod("other", nil, time.Now()),
+			},
+			resource:   latestV1.PortForwardResource{Type: "deployment", Name: "web", Port: schemautil.FromInt(8080)},
+			chosenPod:  "new",
+			chosenPort: 8080,
+		},
+		{
+			description:     "no running pods",
+			clientResources: []pkgruntime.Object{deployment, labelled(mockPod("pending", nil, time.Now()), corev1.PodPending)},
+			resource:        latestV1.PortForwardResource{Type: "deployment", Name: "web", Port: schemautil.FromInt(8080)},
+			shouldErr:       true,
+		},
+		{
+			description: "unsupported resource type",
+			resource:    latestV1.PortForwardResource{Type: "cronjob", Name: "web", Port: schemautil.FromInt(8080)},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.Override(&client.Client, func() (kubernetes.Interface, error) {
+				return fake.NewSimpleClientset(test.clientResources...), nil
+			})
+
+			pod, port, err := findNewestPodForResource(context.Background(), test.resource)
+
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.chosenPod, pod)
+			if !test.shouldErr {
+				t.CheckDeepEqual(test.chosenPort, port)
+			}
+		})
+	}
+}
+
+func TestNativeForwarderFailover(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		fakeClient := fake.NewSimpleClientset(mockPod("pod-1", nil, time.Now()))
+		t.Override(&client.Client, func() (kubernetes.Interface, error) { return fakeClient, nil })
+		t.Override(&isPortFree, func(string, int) bool { return true })
+		t.Override(&waitRetry, time.Millisecond)
+
+		candidates := []string{"pod-1", "pod-2"}
+		var lock sync.Mutex
+		t.Override(&findNewestPod, func(context.Context, latestV1.PortForwardResource) (string, int, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			return candidates[0], 8080, nil
+		})
+		forwarded := make(chan string, 10)
+		stopped := make(chan bool, 10)
+		t.Override(&forwardPodPorts, func(_, podName, _ string, _, _ int, stopChan <-chan struct{}, readyChan chan struct{}) error {
+			forwarded <- podName
+			close(readyChan)
+			<-stopChan
+			stopped <- true
+			return nil
+		})
+
+		f := NewNativeForwarder()
+		f.Start(ioutil.Discard)
+		pfe := newPortForwardEntry(0, latestV1.PortForwardResource{Type: "deployment", Name: "web"}, "", "", "", "", 9000, false)
+		t.CheckNoError(f.Forward(context.Background(), pfe))
+		t.CheckDeepEqual("pod-1", <-forwarded)
+
+		// deleting the pod fails over to the newest pod
+		lock.Lock()
+		candidates = candidates[1:]
+		lock.Unlock()
+		_, err := fakeClient.CoreV1().Pods("").Create(context.Background(), mockPod("pod-2", nil, time.Now()), metav1.CreateOptions{})
+		t.CheckNoError(err)
+		t.CheckNoError(fakeClient.CoreV1().Pods("").Delete(context.Background(), "pod-1", metav1.DeleteOptions{}))
+		select {
+		case pod := <-forwarded:
+			t.CheckDeepEqual("pod-2", pod)
+		case <-time.After(10 * time.Second):
+			t.Fatal("port forwarding did not fail over to the newest pod")
+		}
+
+		f.Terminate(pfe)
+		<-stopped
+		<-stopped
+	})
+}
@@ -28,6 +28,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/singleflight"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/diag"
@@ -41,7 +44,9 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/instrumentation"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
 	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/status"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
 	"github.com/GoogleContainerTools/skaffold/proto/v1"
 )
@@ -57,6 +62,8 @@ var (
 	reportStatusTime = 5 * time.Second
 )
 
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
 const (
 	tabHeader             = " -"
 	kubernetesMaxDeadline = 600
@@ -76,7 +83,7 @@ type Config interface {
 	StatusCheck() *bool
 }
 
-// Monitor runs status checks for pods and deployments
+// Monitor runs status checks for pods, workloads and custom resources
 type Monitor struct {
 	cfg             Config
 	labeller        *label.DefaultLabeller
@@ -85,9 +92,14 @@ type Monitor struct {
 	seenResources   resource.Group
 	singleRun       singleflight.Group
 	namespaces      *[]string
+
+	// kinds are the kinds of the deployed resources. Only custom resources of these kinds are checked.
+	kinds     map[schema.GroupKind]bool
+	kindsLock sync.Mutex
 }
 
-// NewStatusMonitor returns a status monitor which runs checks on deployments and pods.
+// NewStatusMonitor returns a status monitor which runs checks on deployments, statefulsets, daemonsets,
+// jobs, custom resources and their pods.
 func NewStatusMonitor(cfg Config, labeller *label.DefaultLabeller, namespaces *[]string) *Monitor {
 	return &Monitor{
 		muteLogs:        cfg.Muted().MuteStatusCheck(),
@@ -97,7 +109,34 @@ func NewStatusMonitor(cfg Config, labeller *label.DefaultLabeller, namespaces *[
 		seenResources:   make(resource.Group),
 		singleRun:       singleflight.Group{},
 		namespaces:      namespaces,
+		kinds:           map[schema.GroupKind]bool{},
+	}
+}
+
+// TrackKinds records the kinds of deployed resources, so that the status of the custom resources among them is checked.
+func (s *Monitor) TrackKinds(kinds []schema.GroupKind) {
+	s.kindsLock.Lock()
+	defer s.kindsLock.Unlock()
+	for _, gk := range kinds {
+		s.kinds[gk] = true
+	}
+}
+
+// TrackDeployedKinds records the kinds of deployed resources with the Kubernetes status monitor, if status checks are enabled.
+func TrackDeployedKinds(m status.Monitor, kinds []schema.GroupKind) {
+	if monitor, ok := m.(*Monitor); ok {
+		monitor.TrackKinds(kinds)
+	}
+}
+
+func (s *Monitor) trackedKinds() map[schema.GroupKind]bool {
+	s.kindsLock.Lock()
+	defer s.kindsLock.Unlock()
+	kinds := make(map[schema.GroupKind]bool, len(s.kinds))
+	for gk := range s.kinds {
+		kinds[gk] = true
 	}
+	return kinds
 }
 
 // Check runs the status checks on deployments and pods deployed in current skaffold dev iteration.
@@ -136,19 +175,46 @@ func (s *Monitor) statusCheck(ctx context.Context, out io.Writer) (proto.StatusC
 		return proto.StatusCode_STATUSCHECK_KUBECTL_CLIENT_FETCH_ERR, fmt.Errorf("getting Kubernetes client: %w", err)
 	}
 
-	deployments := make([]*resource.Deployment, 0)
+	// the status of custom resources is only checked for the deployed kinds, when their custom resource definitions can be listed
+	var customResourceTypes []schema.GroupVersionResource
+	var dynClient dynamic.Interface
+	if kinds := s.trackedKinds(); len(kinds) > 0 {
+		if dynClient, err = kubernetesclient.DynamicClient(); err != nil {
+			logrus.Debugf("could not get Kubernetes dynamic client: %v", err)
+		} else if customResourceTypes, err = getCustomResourceTypes(ctx, dynClient, kinds); err != nil {
+			logrus.Debugf("could not fetch custom resource definitions: %v", err)
+		}
+	}
+
+	deadline := getDeadline(s.deadlineSeconds)
+	deployments := make([]*resource.Resource, 0)
 	for _, n := range *s.namespaces {
-		newDeployments, err := getDeployments(ctx, client, n, s.labeller,
-			getDeadline(s.deadlineSeconds))
+		newDeployments, err := getDeployments(ctx, client, n, s.labeller, deadline)
 		if err != nil {
 			return proto.StatusCode_STATUSCHECK_DEPLOYMENT_FETCH_ERR, fmt.Errorf("could not fetch deployments: %w", err)
 		}
-		for _, d := range newDeployments {
-			if s.seenResources.Contains(d) {
-				continue
+		newStatefulSets, err := getStatefulSets(ctx, client, n, s.labeller, deadline)
+		if err != nil {
+			return proto.StatusCode_STATUSCHECK_DEPLOYMENT_FETCH_ERR, fmt.Errorf("could not fetch statefulsets: %w", err)
+		}
+		newDaemonSets, err := getDaemonSets(ctx, client, n, s.labeller, deadline)
+		if err != nil {
+			return proto.StatusCode_STATUSCHECK_DEPLOYMENT_FETCH_ERR, fmt.Errorf("could not fetch daemonsets: %w", err)
+		}
+		newJobs, err := getJobs(ctx, client, n, s.labeller, deadline)
+		if err != nil {
+			return proto.StatusCode_STATUSCHECK_DEPLOYMENT_FETCH_ERR, fmt.Errorf("could not fetch jobs: %w", err)
+		}
+		newCustomResources := getCustomResources(ctx, dynClient, client, customResourceTypes, n, s.labeller, deadline)
+
+		for _, resources := range [][]*resource.Resource{newDeployments, newStatefulSets, newDaemonSets, newJobs, newCustomResources} {
+			for _, d := range resources {
+				if s.seenResources.Contains(d) {
+					continue
+				}
+				deployments = append(deployments, d)
+				s.seenResources.Add(d)
 			}
-			deployments = append(deployments, d)
-			s.seenResources.Add(d)
 		}
 	}
 
@@ -161,7 +227,7 @@ func (s *Monitor) statusCheck(ctx context.Context, out io.Writer) (proto.StatusC
 
 	for _, d := range deployments {
 		wg.Add(1)
-		go func(r *resource.Deployment) {
+		go func(r *resource.Resource) {
 			defer wg.Done()
 			// keep updating the resource status until it fails/succeeds/times out
 			pollDeploymentStatus(ctx, s.cfg, r)
@@ -185,7 +251,7 @@ func (s *Monitor) statusCheck(ctx context.Context, out io.Writer) (proto.StatusC
 	return getSkaffoldDeployStatus(c, deployments)
 }
 
-func getDeployments(ctx context.Context, client kubernetes.Interface, ns string, l *label.DefaultLabeller, deadlineDuration time.Duration) ([]*resource.Deployment, error) {
+func getDeployments(ctx context.Context, client kubernetes.Interface, ns string, l *label.DefaultLabeller, deadlineDuration time.Duration) ([]*resource.Resource, error) {
 	deps, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{
 		LabelSelector: l.RunIDSelector(),
 	})
@@ -193,7 +259,7 @@ func getDeployments(ctx context.Context, client kubernetes.Interface, ns string,
 		return nil, fmt.Errorf("could not fetch deployments: %w", err)
 	}
 
-	deployments := make([]*resource.Deployment, len(deps.Items))
+	deployments := make([]*resource.Resource, len(deps.Items))
 	for i, d := range deps.Items {
 		var deadline time.Duration
 		if d.Spec.ProgressDeadlineSeconds == nil || *d.Spec.ProgressDeadlineSeconds == kubernetesMaxDeadline {
@@ -201,20 +267,149 @@ func getDeployments(ctx context.Context, client kubernetes.Interface, ns string,
 		} else {
 			deadline = time.Duration(*d.Spec.ProgressDeadlineSeconds) * time.Second
 		}
-		pd := diag.New([]string{d.Namespace}).
-			WithLabel(label.RunIDLabel, l.Labels()[label.RunIDLabel]).
-			WithValidators([]validator.Validator{validator.NewPodValidator(client)})
+		pd := podValidator(client, d.Namespace, l, d.Spec.Template.Labels)
+		deployments[i] = resource.NewDeployment(d.Name, d.Namespace, deadline).WithValidator(pd)
+	}
+	return deployments, nil
+}
+
+func getStatefulSets(ctx context.Context, client kubernetes.Interface, ns string, l *label.DefaultLabeller, deadline time.Duration) ([]*resource.Resource, error) {
+	sets, err := client.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: l.RunIDSelector(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch statefulsets: %w", err)
+	}
 
-		for k, v := range d.Spec.Template.Labels {
-			pd = pd.WithLabel(k, v)
+	statefulSets := make([]*resource.Resource, len(sets.Items))
+	for i, s := range sets.Items {
+		pd := podValidator(client, s.Namespace, l, s.Spec.Template.Labels)
+		statefulSets[i] = resource.NewResource(s.Name, resource.ResourceTypes.StatefulSet, s.Namespace, deadline).WithValidator(pd)
+	}
+	return statefulSets, nil
+}
+
+func getDaemonSets(ctx context.Context, client kubernetes.Interface, ns string, l *label.DefaultLabeller, deadline time.Duration) ([]*resource.Resource, error) {
+	sets, err := client.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: l.RunIDSelector(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch daemonsets: %w", err)
+	}
+
+	daemonSets := make([]*resource.Resource, len(sets.Items))
+	for i, s := range sets.Items {
+		pd := podValidator(client, s.Namespace, l, s.Spec.Template.Labels)
+		daemonSets[i] = resource.NewResource(s.Name, resource.ResourceTypes.DaemonSet, s.Namespace, deadline).WithValidator(pd)
+	}
+	return daemonSets, nil
+}
+
+func getJobs(ctx context.Context, client kubernetes.Interface, ns string, l *label.DefaultLabeller, deadline time.Duration) ([]*resource.Resource, error) {
+	list, err := client.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: l.RunIDSelector(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch jobs: %w", err)
+	}
+
+	jobs := make([]*resource.Resource, len(list.Items))
+	for i, j := range list.Items {
+		jobDeadline := deadline
+		if j.Spec.ActiveDeadlineSeconds != nil && time.Duration(*j.Spec.ActiveDeadlineSeconds)*time.Second < deadline {
+			jobDeadline = time.Duration(*j.Spec.ActiveDeadlineSeconds) * time.Second
 		}
+		pd := podValidator(client, j.Namespace, l, j.Spec.Template.Labels)
+		jobs[i] = resource.NewResource(j.Name, resource.ResourceTypes.Job, j.Namespace, jobDeadline).WithValidator(pd)
+	}
+	return jobs, nil
+}
 
-		deployments[i] = resource.NewDeployment(d.Name, d.Namespace, deadline).WithValidator(pd)
+// getCustomResourceTypes lists the namespaced custom resource types defined in the cluster for the given kinds.
+func getCustomResourceTypes(ctx context.Context, client dynamic.Interface, kinds map[schema.GroupKind]bool) ([]schema.GroupVersionResource, error) {
+	crds, err := client.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
 	}
-	return deployments, nil
+
+	var gvrs []schema.GroupVersionResource
+	for _, crd := range crds.Items {
+		if scope, _, _ := unstructured.NestedString(crd.Object, "spec", "scope"); scope != "Namespaced" {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		if !kinds[schema.GroupKind{Group: group, Kind: kind}] {
+			continue
+		}
+		plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		for _, v := range versions {
+			version, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if storage, _, _ := unstructured.NestedBool(version, "storage"); storage {
+				name, _, _ := unstructured.NestedString(version, "name")
+				gvrs = append(gvrs, schema.GroupVersionResource{Group: group, Version: name, Resource: plural})
+				break
+			}
+		}
+	}
+	return gvrs, nil
+}
+
+// getCustomResources lists the custom resources deployed by the current run. Their pods are only diagnosed
+// when the custom resource declares pod templates, since the pods created by operators don't necessarily
+// carry the labels of the custom resource.
+func getCustomResources(ctx context.Context, client dynamic.Interface, kubeClient kubernetes.Interface, gvrs []schema.GroupVersionResource, ns string, l *label.DefaultLabeller, deadline time.Duration) []*resource.Resource {
+	var resources []*resource.Resource
+	for _, gvr := range gvrs {
+		list, err := client.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{
+			LabelSelector: l.RunIDSelector(),
+		})
+		if err != nil {
+			logrus.Debugf("could not fetch %s: %v", gvr.GroupResource(), err)
+			continue
+		}
+		for _, r := range list.Items {
+			cr := resource.NewCustomResource(r.GetName(), r.GetNamespace(), gvr, deadline)
+			if templateLabels, found := podTemplateLabels(r.Object); found {
+				cr = cr.WithValidator(podValidator(kubeClient, r.GetNamespace(), l, templateLabels))
+			}
+			resources = append(resources, cr)
+		}
+	}
+	return resources
+}
+
+// podTemplateLabels returns the labels of the first pod template declared for a custom resource kind.
+func podTemplateLabels(obj map[string]interface{}) (map[string]string, bool) {
+	cr, found := manifest.GetCustomResource(obj)
+	if !found {
+		return nil, false
+	}
+	templates := cr.PodTemplates(obj)
+	if len(templates) == 0 {
+		return nil, false
+	}
+	labels, _, _ := unstructured.NestedStringMap(templates[0], "metadata", "labels")
+	return labels, true
+}
+
+// podValidator diagnoses the pods of the current run created from the given pod template labels.
+func podValidator(client kubernetes.Interface, ns string, l *label.DefaultLabeller, templateLabels map[string]string) diag.Diagnose {
+	pd := diag.New([]string{ns}).
+		WithLabel(label.RunIDLabel, l.Labels()[label.RunIDLabel]).
+		WithValidators([]validator.Validator{validator.NewPodValidator(client)})
+
+	for k, v := range templateLabels {
+		pd = pd.WithLabel(k, v)
+	}
+	return pd
 }
 
-func pollDeploymentStatus(ctx context.Context, cfg kubectl.Config, r *resource.Deployment) {
+func pollDeploymentStatus(ctx context.Context, cfg kubectl.Config, r *resource.Resource) {
 	pollDuration := time.Duration(defaultPollPeriodInMilliseconds) * time.Millisecond
 	ticker := time.NewTicker(pollDuration)
 	defer ticker.Stop()
@@ -256,7 +451,7 @@ func pollDeploymentStatus(ctx context.Context, cfg kubectl.Config, r *resource.D
 	}
 }
 
-func getSkaffoldDeployStatus(c *counter, rs []*resource.Deployment) (proto.StatusCode, error) {
+func getSkaffoldDeployStatus(c *counter, rs []*resource.Resource) (proto.StatusCode, error) {
 	if c.failed == 0 {
 		return proto.StatusCode_STATUSCHECK_SUCCESS, nil
 	}
@@ -277,7 +472,7 @@ func getDeadline(d int) time.Duration {
 	return DefaultStatusCheckDeadline
 }
 
-func (s *Monitor) printStatusCheckSummary(out io.Writer, r *resource.Deployment, c counter) {
+func (s *Monitor) printStatusCheckSummary(out io.Writer, r *resource.Resource, c counter) {
 	ae := r.Status().ActionableError()
 	if r.StatusCode() == proto.StatusCode_STATUSCHECK_USER_CANCELLED {
 		// Don't print the status summary if the user ctrl-C or
@@ -303,7 +498,7 @@ func (s *Monitor) printStatusCheckSummary(out io.Writer, r *resource.Deployment,
 }
 
 // printDeploymentStatus prints resource statuses until all status check are completed or context is cancelled.
-func (s *Monitor) printDeploymentStatus(ctx context.Context, out io.Writer, deployments []*resource.Deployment) {
+func (s *Monitor) printDeploymentStatus(ctx context.Context, out io.Writer, deployments []*resource.Resource) {
 	ticker := time.NewTicker(reportStatusTime)
 	defer ticker.Stop()
 	for {
@@ -320,7 +515,7 @@ func (s *Monitor) printDeploymentStatus(ctx context.Context, out io.Writer, depl
 	}
 }
 
-func (s *Monitor) printStatus(deployments []*resource.Deployment, out io.Writer) bool {
+func (s *Monitor) printStatus(deployments []*resource.Resource, out io.Writer) bool {
 	allDone := true
 	for _, r := range deployments {
 		if r.IsStatusCheckCompleteOrCancelled() {
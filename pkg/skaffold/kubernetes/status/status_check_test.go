@@ -26,8 +26,12 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynclient "k8s.io/client-go/dynamic/fake"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	utilpointer "k8s.io/utils/pointer"
 
@@ -35,6 +39,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/diag/validator"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/label"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/resource"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
@@ -50,7 +55,7 @@ func TestGetDeployments(t *testing.T) {
 	tests := []struct {
 		description string
 		deps        []*appsv1.Deployment
-		expected    []*resource.Deployment
+		expected    []*resource.Resource
 		shouldErr   bool
 	}{
 		{
@@ -78,7 +83,7 @@ func TestGetDeployments(t *testing.T) {
 					Spec: appsv1.DeploymentSpec{ProgressDeadlineSeconds: utilpointer.Int32Ptr(20)},
 				},
 			},
-			expected: []*resource.Deployment{
+			expected: []*resource.Resource{
 				resource.NewDeployment("dep1", "test", 10*time.Second),
 				resource.NewDeployment("dep2", "test", 20*time.Second),
 			},
@@ -98,7 +103,7 @@ func TestGetDeployments(t *testing.T) {
 					Spec: appsv1.DeploymentSpec{ProgressDeadlineSeconds: utilpointer.Int32Ptr(300)},
 				},
 			},
-			expected: []*resource.Deployment{
+			expected: []*resource.Resource{
 				resource.NewDeployment("dep1", "test", 300*time.Second),
 			},
 		},
@@ -125,7 +130,7 @@ func TestGetDeployments(t *testing.T) {
 					},
 				},
 			},
-			expected: []*resource.Deployment{
+			expected: []*resource.Resource{
 				resource.NewDeployment("dep1", "test", 100*time.Second),
 				resource.NewDeployment("dep2", "test", 200*time.Second),
 			},
@@ -144,13 +149,13 @@ func TestGetDeployments(t *testing.T) {
 					Spec: appsv1.DeploymentSpec{ProgressDeadlineSeconds: utilpointer.Int32Ptr(600)},
 				},
 			},
-			expected: []*resource.Deployment{
+			expected: []*resource.Resource{
 				resource.NewDeployment("dep1", "test", 200*time.Second),
 			},
 		},
 		{
 			description: "no deployments",
-			expected:    []*resource.Deployment{},
+			expected:    []*resource.Resource{},
 		},
 		{
 			description: "multiple deployments in different namespaces",
@@ -176,7 +181,7 @@ func TestGetDeployments(t *testing.T) {
 					Spec: appsv1.DeploymentSpec{ProgressDeadlineSeconds: utilpointer.Int32Ptr(100)},
 				},
 			},
-			expected: []*resource.Deployment{
+			expected: []*resource.Resource{
 				resource.NewDeployment("dep1", "test", 100*time.Second),
 			},
 		},
@@ -194,7 +199,7 @@ func TestGetDeployments(t *testing.T) {
 					Spec: appsv1.DeploymentSpec{ProgressDeadlineSeconds: utilpointer.Int32Ptr(100)},
 				},
 			},
-			expected: []*resource.Deployment{},
+			expected: []*resource.Resource{},
 		},
 		{
 			description: "deployment in correct namespace deployed by skaffold but different run",
@@ -210,7 +215,7 @@ func TestGetDeployments(t *testing.T) {
 					Spec: appsv1.DeploymentSpec{ProgressDeadlineSeconds: utilpointer.Int32Ptr(100)},
 				},
 			},
-			expected: []*resource.Deployment{},
+			expected: []*resource.Resource{},
 		},
 	}
 
@@ -223,17 +228,153 @@ func TestGetDeployments(t *testing.T) {
 			client := fakekubeclientset.NewSimpleClientset(objs...)
 			actual, err := getDeployments(context.Background(), client, "test", labeller, 200*time.Second)
 			t.CheckErrorAndDeepEqual(test.shouldErr, err, &test.expected, &actual,
-				cmp.AllowUnexported(resource.Deployment{}, resource.Status{}),
+				cmp.AllowUnexported(resource.Resource{}, resource.Status{}),
 				cmpopts.IgnoreInterfaces(struct{ diag.Diagnose }{}))
 		})
 	}
 }
 
+func TestGetWorkloads(t *testing.T) {
+	labeller := label.NewLabeller(true, nil, "run-id")
+	runLabels := map[string]string{label.RunIDLabel: labeller.GetRunID()}
+	objs := []runtime.Object{
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test", Labels: runLabels}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "other-run", Namespace: "test", Labels: map[string]string{label.RunIDLabel: "9876-6789"}}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "test", Labels: runLabels}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "test", Labels: runLabels}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "short", Namespace: "test", Labels: runLabels}, Spec: batchv1.JobSpec{ActiveDeadlineSeconds: utilpointer.Int64Ptr(30)}},
+	}
+
+	testutil.Run(t, "", func(t *testutil.T) {
+		client := fakekubeclientset.NewSimpleClientset(objs...)
+		opts := cmp.Options{cmp.AllowUnexported(resource.Resource{}, resource.Status{}), cmpopts.IgnoreInterfaces(struct{ diag.Diagnose }{})}
+
+		statefulSets, err := getStatefulSets(context.Background(), client, "test", labeller, 200*time.Second)
+		t.CheckErrorAndDeepEqual(false, err, []*resource.Resource{
+			resource.NewResource("db", resource.ResourceTypes.StatefulSet, "test", 200*time.Second),
+		}, statefulSets, opts...)
+
+		daemonSets, err := getDaemonSets(context.Background(), client, "test", labeller, 200*time.Second)
+		t.CheckErrorAndDeepEqual(false, err, []*resource.Resource{
+			resource.NewResource("agent", resource.ResourceTypes.DaemonSet, "test", 200*time.Second),
+		}, daemonSets, opts...)
+
+		jobs, err := getJobs(context.Background(), client, "test", labeller, 200*time.Second)
+		t.CheckErrorAndDeepEqual(false, err, []*resource.Resource{
+			resource.NewResource("migrate", resource.ResourceTypes.Job, "test", 200*time.Second),
+			resource.NewResource("short", resource.ResourceTypes.Job, "test", 30*time.Second),
+		}, jobs, opts...)
+	})
+}
+
+func TestGetCustomResources(t *testing.T) {
+	labeller := label.NewLabeller(true, nil, "run-id")
+	crd := func(name, kind, scope string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name + ".example.com"},
+			"spec": map[string]interface{}{
+				"group": "example.com",
+				"scope": scope,
+				"names": map[string]interface{}{"plural": name, "kind": kind},
+				"versions": []interface{}{
+					map[string]interface{}{"name": "v1beta1", "storage": false},
+					map[string]interface{}{"name": "v1", "storage": true},
+				},
+			},
+		}}
+	}
+	cr := func(name, runID string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Cache",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "test",
+				"labels":    map[string]interface{}{label.RunIDLabel: runID},
+			},
+		}}
+	}
+
+	testutil.Run(t, "", func(t *testutil.T) {
+		client := fakedynclient.NewSimpleDynamicClient(runtime.NewScheme(),
+			crd("caches", "Cache", "Namespaced"), crd("queues", "Queue", "Namespaced"), crd("clusters", "Cluster", "Cluster"),
+			cr("cache", labeller.GetRunID()), cr("other-run", "9876-6789"))
+
+		// only the custom resource types that were deployed are listed.
+		deployed := map[schema.GroupKind]bool{
+			{Group: "example.com", Kind: "Cache"}:   true,
+			{Group: "example.com", Kind: "Cluster"}: true,
+			{Group: "apps", Kind: "Deployment"}:     true,
+		}
+		gvrs, err := getCustomResourceTypes(context.Background(), client, deployed)
+		cachesGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "caches"}
+		t.CheckErrorAndDeepEqual(false, err, []schema.GroupVersionResource{cachesGVR}, gvrs)
+
+		resources := getCustomResources(context.Background(), client, fakekubeclientset.NewSimpleClientset(), gvrs, "test", labeller, 200*time.Second)
+		t.CheckDeepEqual([]*resource.Resource{
+			resource.NewCustomResource("cache", "test", cachesGVR, 200*time.Second),
+		}, resources, cmp.AllowUnexported(resource.Resource{}, resource.Status{}), cmpopts.IgnoreInterfaces(struct{ diag.Diagnose }{}))
+	})
+}
+
+func TestPodTemplateLabels(t *testing.T) {
+	workflow := map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Workflow",
+		"spec": map[string]interface{}{
+			"steps": []interface{}{
+				map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "first"}},
+					},
+				},
+				map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "second"}},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		description string
+		filters     []latestV1.ResourceFilter
+		expected    map[string]string
+		found       bool
+	}{
+		{
+			description: "undeclared kind",
+		},
+		{
+			description: "no pod template",
+			filters:     []latestV1.ResourceFilter{{GroupKind: "Workflow.example.com", Image: []string{".spec.image"}}},
+		},
+		{
+			description: "first pod template",
+			filters:     []latestV1.ResourceFilter{{GroupKind: "Workflow.example.com", PodTemplate: []string{".spec.steps.template"}}},
+			expected:    map[string]string{"app": "first"},
+			found:       true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.CheckNoError(manifest.SetCustomResources(test.filters))
+			defer manifest.SetCustomResources(nil)
+
+			labels, found := podTemplateLabels(workflow)
+			t.CheckDeepEqual(test.expected, labels)
+			t.CheckDeepEqual(test.found, found)
+		})
+	}
+}
+
 func TestGetDeployStatus(t *testing.T) {
 	tests := []struct {
 		description  string
 		counter      *counter
-		deployments  []*resource.Deployment
+		deployments  []*resource.Resource
 		expected     string
 		expectedCode proto.StatusCode
 		shouldErr    bool
@@ -241,7 +382,7 @@ func TestGetDeployStatus(t *testing.T) {
 		{
 			description: "one error",
 			counter:     &counter{total: 2, failed: 1},
-			deployments: []*resource.Deployment{
+			deployments: []*resource.Resource{
 				resource.NewDeployment("foo", "test", time.Second).
 					WithPodStatuses([]proto.StatusCode{proto.StatusCode_STATUSCHECK_NODE_DISK_PRESSURE}),
 			},
@@ -252,7 +393,7 @@ func TestGetDeployStatus(t *testing.T) {
 		{
 			description: "no error",
 			counter:     &counter{total: 2},
-			deployments: []*resource.Deployment{
+			deployments: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("r1", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS},
@@ -267,7 +408,7 @@ func TestGetDeployStatus(t *testing.T) {
 			description: "multiple errors",
 			counter:     &counter{total: 3, failed: 2},
 			expected:    "2/3 deployment(s) failed",
-			deployments: []*resource.Deployment{
+			deployments: []*resource.Resource{
 				resource.NewDeployment("foo", "test", time.Second).
 					WithPodStatuses([]proto.StatusCode{proto.StatusCode_STATUSCHECK_NODE_DISK_PRESSURE}),
 			},
@@ -281,7 +422,7 @@ func TestGetDeployStatus(t *testing.T) {
 		{
 			description: "unable to retrieve pods for deployment",
 			counter:     &counter{total: 1, failed: 1},
-			deployments: []*resource.Deployment{
+			deployments: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("deployment", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_DEPLOYMENT_FETCH_ERR},
@@ -293,7 +434,7 @@ func TestGetDeployStatus(t *testing.T) {
 		{
 			description: "one deployment failed and others cancelled and or succeeded",
 			counter:     &counter{total: 3, failed: 2},
-			deployments: []*resource.Deployment{
+			deployments: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("deployment-cancelled", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_USER_CANCELLED},
@@ -406,13 +547,13 @@ func TestPrintStatus(t *testing.T) {
 	labeller := label.NewLabeller(true, nil, "run-id")
 	tests := []struct {
 		description string
-		rs          []*resource.Deployment
+		rs          []*resource.Resource
 		expectedOut string
 		expected    bool
 	}{
 		{
 			description: "single resource successful marked complete - skip print",
-			rs: []*resource.Deployment{
+			rs: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("r1", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS},
@@ -422,7 +563,7 @@ func TestPrintStatus(t *testing.T) {
 		},
 		{
 			description: "single resource in error marked complete -skip print",
-			rs: []*resource.Deployment{
+			rs: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("r1", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_UNKNOWN, Message: "error"},
@@ -432,7 +573,7 @@ func TestPrintStatus(t *testing.T) {
 		},
 		{
 			description: "multiple resources 1 not complete",
-			rs: []*resource.Deployment{
+			rs: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("r1", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS},
@@ -450,7 +591,7 @@ func TestPrintStatus(t *testing.T) {
 		},
 		{
 			description: "multiple resources 1 not complete and retry-able error",
-			rs: []*resource.Deployment{
+			rs: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("r1", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_SUCCESS},
@@ -467,7 +608,7 @@ func TestPrintStatus(t *testing.T) {
 		},
 		{
 			description: "skip printing if status check is cancelled",
-			rs: []*resource.Deployment{
+			rs: []*resource.Resource{
 				withStatus(
 					resource.NewDeployment("r1", "test", 1),
 					proto.ActionableErr{ErrCode: proto.StatusCode_STATUSCHECK_USER_CANCELLED},
@@ -490,7 +631,7 @@ func TestPrintStatus(t *testing.T) {
 	}
 }
 
-func withStatus(d *resource.Deployment, ae proto.ActionableErr) *resource.Deployment {
+func withStatus(d *resource.Resource, ae proto.ActionableErr) *resource.Resource {
 	d.UpdateStatus(ae)
 	return d
 }
@@ -559,7 +700,7 @@ func TestPollDeployment(t *testing.T) {
 	rolloutCmd := "kubectl --context kubecontext rollout status deployment dep --namespace test --watch=false"
 	tests := []struct {
 		description string
-		dep         *resource.Deployment
+		dep         *resource.Resource
 		runs        [][]validator.Resource
 		command     util.Command
 		expected    proto.StatusCode
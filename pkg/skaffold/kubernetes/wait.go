@@ -118,6 +118,36 @@ func WaitForPodInitialized(ctx context.Context, pods corev1.PodInterface, podNam
 	})
 }
 
+// WaitForPodReady waits until the Pod is running and ready.
+func WaitForPodReady(ctx context.Context, pods corev1.PodInterface, podName string, timeout time.Duration) error {
+	logrus.Infof("Waiting for %s to be ready", podName)
+
+	w, err := pods.Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("initializing pod watcher: %s", err)
+	}
+	defer w.Stop()
+
+	return watchUntilTimeout(ctx, timeout, w, func(event *watch.Event) (bool, error) {
+		pod, ok := event.Object.(*v1.Pod)
+		if !ok || pod.Name != podName {
+			return false, nil
+		}
+
+		switch pod.Status.Phase {
+		case v1.PodFailed, v1.PodSucceeded:
+			return false, fmt.Errorf("pod has terminated: %s", pod.Status.Phase)
+		case v1.PodRunning:
+			for _, c := range pod.Status.Conditions {
+				if c.Type == v1.PodReady && c.Status == v1.ConditionTrue {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	})
+}
+
 // WaitForDeploymentToStabilize waits until the Deployment has a matching generation/replica count between spec and status.
 func WaitForDeploymentToStabilize(ctx context.Context, c kubernetes.Interface, ns, name string, timeout time.Duration) error {
 	logrus.Infof("Waiting for %s to stabilize", name)
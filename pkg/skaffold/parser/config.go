@@ -26,6 +26,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/bundle"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/git"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema"
@@ -172,6 +173,12 @@ func processEachConfig(config *latestV1.SkaffoldConfig, cfgOpts configOpts, opts
 			return nil, sErrors.ConfigSetDefaultValuesErr(config.Metadata.Name, cfgOpts.file, err)
 		}
 	}
+	// platforms set on the command line override the platforms of every artifact.
+	if len(opts.Platforms) > 0 {
+		for _, a := range config.Build.Artifacts {
+			a.Platforms = opts.Platforms
+		}
+	}
 	// if `opts.MakePathsAbsolute` is not set, convert relative file paths to absolute for all configs that are not invoked explicitly.
 	// This avoids maintaining multiple root directory information since the dependency skaffold configs would have their own root directory.
 	// if `opts.MakePathsAbsolute` is set, use that as condition to decide on making file paths absolute for all configs or none at all.
@@ -256,6 +263,22 @@ func processEachDependency(d latestV1.ConfigDependency, cfgOpts configOpts, opts
 		path = cachePath
 	}
 
+	if d.OCIBundle != nil {
+		cachePath, err := cacheOCIBundle(*d.OCIBundle, opts, r)
+		if err != nil {
+			return nil, sErrors.ConfigParsingError(fmt.Errorf("caching remote dependency %s: %w", d.OCIBundle.Ref, err))
+		}
+		path = cachePath
+	}
+
+	if d.Archive != nil {
+		cachePath, err := cacheArchive(*d.Archive, opts, r)
+		if err != nil {
+			return nil, sErrors.ConfigParsingError(fmt.Errorf("caching remote dependency %s: %w", d.Archive.URL, err))
+		}
+		path = cachePath
+	}
+
 	if path == "" {
 		// empty path means configs in the same file
 		path = cfgOpts.file
@@ -288,25 +311,39 @@ func processEachDependency(d latestV1.ConfigDependency, cfgOpts configOpts, opts
 // cacheRepo downloads the referenced git repository to skaffold's cache if required and returns the path to the target configuration file in that repository.
 func cacheRepo(g latestV1.GitInfo, opts config.SkaffoldOptions, r *record) (string, error) {
 	key := fmt.Sprintf("%s@%s", g.Repo, g.Ref)
+	return cacheDependency(key, g.Path, r, func() (string, error) { return git.SyncRepo(g, opts) })
+}
+
+// cacheOCIBundle downloads the referenced OCI config bundle to skaffold's cache if required and returns the path to the target configuration file in that bundle.
+func cacheOCIBundle(b latestV1.OCIBundleInfo, opts config.SkaffoldOptions, r *record) (string, error) {
+	return cacheDependency("oci:"+b.Ref, b.Path, r, func() (string, error) { return bundle.SyncOCI(b, opts) })
+}
+
+// cacheArchive downloads the referenced config archive to skaffold's cache if required and returns the path to the target configuration file in that archive.
+func cacheArchive(a latestV1.ArchiveInfo, opts config.SkaffoldOptions, r *record) (string, error) {
+	return cacheDependency(fmt.Sprintf("archive:%s@%s", a.URL, a.SHA256), a.Path, r, func() (string, error) { return bundle.SyncArchive(a, opts) })
+}
+
+// cacheDependency syncs a remote dependency at most once per run and returns the path to `subPath` in its cached copy.
+func cacheDependency(key, subPath string, r *record, sync func() (string, error)) (string, error) {
 	if p, found := r.cachedRepos[key]; found {
 		switch v := p.(type) {
 		case string:
-			return filepath.Join(v, g.Path), nil
+			return filepath.Join(v, subPath), nil
 		case error:
 			return "", v
 		default:
-			logrus.Fatalf("unable to check download status of repo %s at ref %s", g.Repo, g.Ref)
+			logrus.Fatalf("unable to check download status of remote dependency %s", key)
 			return "", nil
 		}
-	} else {
-		p, err := git.SyncRepo(g, opts)
-		if err != nil {
-			r.cachedRepos[key] = err
-			return "", err
-		}
-		r.cachedRepos[key] = p
-		return filepath.Join(p, g.Path), nil
 	}
+	p, err := sync()
+	if err != nil {
+		r.cachedRepos[key] = err
+		return "", err
+	}
+	r.cachedRepos[key] = p
+	return filepath.Join(p, subPath), nil
 }
 
 // checkRevisit ensures that each config is activated with the same set of active profiles
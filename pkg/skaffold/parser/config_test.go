@@ -23,6 +23,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/bundle"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/git"
@@ -1346,3 +1347,29 @@ requires:
 		})
 	}
 }
+
+func TestCacheRemoteDependencies(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		var synced []string
+		t.Override(&bundle.SyncOCI, func(b latestV1.OCIBundleInfo, _ config.SkaffoldOptions) (string, error) {
+			synced = append(synced, b.Ref)
+			return "/cache/oci", nil
+		})
+		t.Override(&bundle.SyncArchive, func(a latestV1.ArchiveInfo, _ config.SkaffoldOptions) (string, error) {
+			synced = append(synced, a.URL)
+			return "", errors.New("checksum mismatch")
+		})
+		r := newRecord()
+
+		for i := 0; i < 2; i++ {
+			p, err := cacheOCIBundle(latestV1.OCIBundleInfo{Ref: "gcr.io/platform/modules:v1", Path: "web"}, config.SkaffoldOptions{}, r)
+			t.CheckErrorAndDeepEqual(false, err, filepath.Join("/cache/oci", "web"), p)
+
+			_, err = cacheArchive(latestV1.ArchiveInfo{URL: "https://example.com/modules.tgz", SHA256: "abc"}, config.SkaffoldOptions{}, r)
+			t.CheckErrorContains("checksum mismatch", err)
+		}
+
+		// each dependency is synced at most once per run, including failures.
+		t.CheckDeepEqual([]string{"gcr.io/platform/modules:v1", "https://example.com/modules.tgz"}, synced)
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
+	"github.com/GoogleContainerTools/skaffold/proto/v1"
+)
+
+// Parse parses a platform of the form `os/arch[/variant]`, for instance `linux/arm64/v8`.
+func Parse(s string) (v1.Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v1.Platform{}, fmt.Errorf("invalid platform %q: expected format os/arch[/variant]", s)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return v1.Platform{}, fmt.Errorf("invalid platform %q: expected format os/arch[/variant]", s)
+		}
+	}
+	platform := v1.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+	return platform, nil
+}
+
+// ParseAll parses a list of platforms.
+func ParseAll(platforms []string) ([]v1.Platform, error) {
+	var parsed []v1.Platform
+	for _, s := range platforms {
+		p, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+// Format returns the `os/arch[/variant]` representation of a platform.
+func Format(p v1.Platform) string {
+	if p.Variant == "" {
+		return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+	}
+	return fmt.Sprintf("%s/%s/%s", p.OS, p.Architecture, p.Variant)
+}
+
+// IsMultiPlatform returns true when images need to be built for more than one platform.
+func IsMultiPlatform(platforms []string) bool {
+	return len(platforms) > 1
+}
+
+// TagSuffix returns a suffix used to tag the image built for a single platform of a multi-platform image.
+func TagSuffix(platform string) string {
+	return "_" + strings.ReplaceAll(platform, "/", "_")
+}
+
+// UnsupportedPlatformsErr is returned when a builder can't build an image for the requested platforms.
+func UnsupportedPlatformsErr(builder, imageName string, platforms []string) error {
+	msg := fmt.Sprintf("%s builder doesn't support building image %q for platforms %s", builder, imageName, strings.Join(platforms, ","))
+	return sErrors.NewError(errors.New(msg),
+		proto.ActionableErr{
+			Message: msg,
+			ErrCode: proto.StatusCode_BUILD_USER_ERROR,
+			Suggestions: []*proto.Suggestion{
+				{
+					SuggestionCode: proto.SuggestionCode_FIX_USER_BUILD_ERR,
+					Action:         fmt.Sprintf("Remove the `platforms` setting of artifact %q, or build it with a builder that supports multiple platforms, such as docker, jib, kaniko or custom", imageName),
+				},
+			},
+		})
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package platform
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		description string
+		platform    string
+		expected    v1.Platform
+		shouldErr   bool
+	}{
+		{description: "os and arch", platform: "linux/amd64", expected: v1.Platform{OS: "linux", Architecture: "amd64"}},
+		{description: "with variant", platform: "linux/arm64/v8", expected: v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		{description: "missing arch", platform: "linux", shouldErr: true},
+		{description: "empty arch", platform: "linux/", shouldErr: true},
+		{description: "too many parts", platform: "linux/arm/v7/foo", shouldErr: true},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			p, err := Parse(test.platform)
+			t.CheckErrorAndDeepEqual(test.shouldErr, err, test.expected, p)
+			if !test.shouldErr {
+				t.CheckDeepEqual(test.platform, Format(p))
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		platforms, err := ParseAll([]string{"linux/amd64", "linux/arm/v7"})
+		t.CheckNoError(err)
+		t.CheckDeepEqual([]v1.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm", Variant: "v7"}}, platforms)
+
+		_, err = ParseAll([]string{"linux/amd64", "arm64"})
+		t.CheckErrorContains(`invalid platform "arm64"`, err)
+	})
+}
+
+func TestTagSuffix(t *testing.T) {
+	testutil.CheckDeepEqual(t, "_linux_arm_v7", TagSuffix("linux/arm/v7"))
+}
@@ -27,10 +27,12 @@ import (
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/cache"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/config"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	eventV2 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/event/v2"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	k8sloader "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/loader"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
@@ -53,8 +55,9 @@ type Builder struct {
 	cache   cache.Cache
 	Builds  []graph.Artifact
 
-	hasBuilt bool
-	runCtx   *runcontext.RunContext
+	hasBuilt      bool
+	registryReady bool
+	runCtx        *runcontext.RunContext
 }
 
 // GetBuilds returns the builds value.
@@ -97,6 +100,11 @@ func (r *Builder) Build(ctx context.Context, out io.Writer, artifacts []*latestV
 	default:
 	}
 
+	if err := r.ensureLocalRegistry(ctx, out); err != nil {
+		eventV2.TaskFailed(constants.Build, err)
+		return nil, err
+	}
+
 	bRes, err := r.cache.Build(ctx, out, tags, artifacts, func(ctx context.Context, out io.Writer, tags tag.ImageTags, artifacts []*latestV1.Artifact) ([]graph.Artifact, error) {
 		if len(artifacts) == 0 {
 			return nil, nil
@@ -123,6 +131,19 @@ func (r *Builder) Build(ctx context.Context, out io.Writer, artifacts []*latestV
 	return bRes, nil
 }
 
+// ensureLocalRegistry starts the Skaffold-managed registry once, when it is used to make images available to the cluster.
+func (r *Builder) ensureLocalRegistry(ctx context.Context, out io.Writer) error {
+	if r.registryReady || r.runCtx.GetCluster().ImageLoader != config.ImageLoaderRegistry {
+		return nil
+	}
+
+	if err := k8sloader.EnsureLocalRegistry(ctx, out, r.runCtx.GetKubeContext(), r.runCtx.MinikubeProfile()); err != nil {
+		return fmt.Errorf("setting up local registry: %w", err)
+	}
+	r.registryReady = true
+	return nil
+}
+
 // HasBuilt returns true if this runner has built something.
 func (r *Builder) HasBuilt() bool {
 	return r.hasBuilt
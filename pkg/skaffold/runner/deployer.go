@@ -56,6 +56,17 @@ func (d *deployerCtx) StatusCheck() *bool {
 	return d.deploy.StatusCheck
 }
 
+func (d *deployerCtx) RollbackOnFailure() bool {
+	if !d.RunContext.RollbackAllowed() {
+		return false
+	}
+	// the cli flag `--rollback-on-failure` overrides the value set in the individual configs.
+	if cliValue := d.RunContext.Opts.RollbackOnFailure.Value(); cliValue != nil {
+		return *cliValue
+	}
+	return d.deploy.RollbackOnFailure != nil && *d.deploy.RollbackOnFailure
+}
+
 // GetDeployer creates a deployer from a given RunContext and deploy pipeline definitions.
 func GetDeployer(runCtx *runcontext.RunContext, labeller *label.DefaultLabeller) (deploy.Deployer, error) {
 	if runCtx.Opts.Apply {
@@ -68,16 +79,16 @@ func GetDeployer(runCtx *runcontext.RunContext, labeller *label.DefaultLabeller)
 
 	var deployers []deploy.Deployer
 	for _, d := range deployerCfg {
+		dCtx := &deployerCtx{runCtx, d}
 		if d.DockerDeploy != nil {
 			localDeploy = true
-			d, err := docker.NewDeployer(runCtx, labeller, d.DockerDeploy, runCtx.PortForwardResources())
+			d, err := docker.NewDeployer(dCtx, labeller, d.DockerDeploy, runCtx.PortForwardResources())
 			if err != nil {
 				return nil, err
 			}
 			deployers = append(deployers, d)
 		}
 
-		dCtx := &deployerCtx{runCtx, d}
 		if d.HelmDeploy != nil {
 			h, err := helm.NewDeployer(dCtx, labeller, d.HelmDeploy)
 			if err != nil {
@@ -121,6 +132,7 @@ on a cluster regardless of provided deployer configuration in the skaffold.yaml.
 The default deployer will honor a select set of deploy configuration from an existing skaffold.yaml:
 	- deploy.StatusCheckDeadlineSeconds
 	- deploy.Logs.Prefix
+	- deploy.RollbackOnFailure
 	- deploy.Kubectl.Flags
 	- deploy.Kubectl.DefaultNamespace
 	- deploy.Kustomize.Flags
@@ -135,6 +147,7 @@ func getDefaultDeployer(runCtx *runcontext.RunContext, labeller *label.DefaultLa
 	var logPrefix string
 	var defaultNamespace *string
 	var kubeContext string
+	var rollbackOnFailure *bool
 	statusCheckTimeout := -1
 
 	for _, d := range deployCfgs {
@@ -156,6 +169,12 @@ func getDefaultDeployer(runCtx *runcontext.RunContext, labeller *label.DefaultLa
 			}
 			logPrefix = d.Logs.Prefix
 		}
+		if d.RollbackOnFailure != nil {
+			if rollbackOnFailure != nil && *rollbackOnFailure != *d.RollbackOnFailure {
+				return nil, fmt.Errorf("found multiple rollbackOnFailure values in skaffold.yaml (not supported in `skaffold apply`): %t, %t", *rollbackOnFailure, *d.RollbackOnFailure)
+			}
+			rollbackOnFailure = d.RollbackOnFailure
+		}
 		var currentDefaultNamespace *string
 		var currentKubectlFlags v1.KubectlFlags
 		if d.KubectlDeploy != nil {
@@ -186,7 +205,8 @@ func getDefaultDeployer(runCtx *runcontext.RunContext, labeller *label.DefaultLa
 		Flags:            *kFlags,
 		DefaultNamespace: defaultNamespace,
 	}
-	defaultDeployer, err := kubectl.NewDeployer(runCtx, labeller, k)
+	dCtx := &deployerCtx{runCtx, v1.DeployConfig{RollbackOnFailure: rollbackOnFailure}}
+	defaultDeployer, err := kubectl.NewDeployer(dCtx, labeller, k)
 	if err != nil {
 		return nil, fmt.Errorf("instantiating default kubectl deployer: %w", err)
 	}
@@ -198,6 +218,12 @@ func validateKubectlFlags(flags *v1.KubectlFlags, additional v1.KubectlFlags) er
 	if additional.DisableValidation != flags.DisableValidation {
 		return fmt.Errorf(errStr, strconv.FormatBool(additional.DisableValidation))
 	}
+	if additional.ServerSideApply != flags.ServerSideApply {
+		return fmt.Errorf(errStr, "serverSideApply="+strconv.FormatBool(additional.ServerSideApply))
+	}
+	if additional.ForceConflicts != flags.ForceConflicts {
+		return fmt.Errorf(errStr, "forceConflicts="+strconv.FormatBool(additional.ForceConflicts))
+	}
 	for _, flag := range additional.Apply {
 		if !util.StrSliceContains(flags.Apply, flag) {
 			return fmt.Errorf(errStr, flag)
@@ -76,9 +76,9 @@ func TestGetDeployer(tOuter *testing.T) {
 				description: "kubectl deployer",
 				cfg:         latestV1.DeployType{KubectlDeploy: &latestV1.KubectlDeploy{}},
 				expected: deploy.NewDeployerMux([]deploy.Deployer{
-					t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+					t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 						Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-					}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+					}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 						Flags: latestV1.KubectlFlags{},
 					})).(deploy.Deployer),
 				}, false),
@@ -87,9 +87,9 @@ func TestGetDeployer(tOuter *testing.T) {
 				description: "kustomize deployer",
 				cfg:         latestV1.DeployType{KustomizeDeploy: &latestV1.KustomizeDeploy{}},
 				expected: deploy.NewDeployerMux([]deploy.Deployer{
-					t.RequireNonNilResult(kustomize.NewDeployer(&runcontext.RunContext{
+					t.RequireNonNilResult(kustomize.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 						Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-					}, &label.DefaultLabeller{}, &latestV1.KustomizeDeploy{
+					}}, &label.DefaultLabeller{}, &latestV1.KustomizeDeploy{
 						Flags: latestV1.KubectlFlags{},
 					})).(deploy.Deployer),
 				}, false),
@@ -105,9 +105,9 @@ func TestGetDeployer(tOuter *testing.T) {
 				description: "apply forces creation of kubectl deployer with kpt config",
 				cfg:         latestV1.DeployType{KptDeploy: &latestV1.KptDeploy{}},
 				apply:       true,
-				expected: t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 					Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+				}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 					Flags: latestV1.KubectlFlags{},
 				})).(deploy.Deployer),
 			},
@@ -116,9 +116,9 @@ func TestGetDeployer(tOuter *testing.T) {
 				cfg:         latestV1.DeployType{HelmDeploy: &latestV1.HelmDeploy{}},
 				helmVersion: `version.BuildInfo{Version:"v3.0.0"}`,
 				apply:       true,
-				expected: t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 					Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+				}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 					Flags: latestV1.KubectlFlags{},
 				})).(deploy.Deployer),
 			},
@@ -185,26 +185,27 @@ func TestGetDefaultDeployer(tOuter *testing.T) {
 		t.Override(&component.NewImageLoader, func(k8sloader.Config, *pkgkubectl.CLI) loader.ImageLoader {
 			return &loader.NoopImageLoader{}
 		})
-		t.Override(&component.NewSyncer, func(*pkgkubectl.CLI, *[]string) sync.Syncer {
+		t.Override(&component.NewSyncer, func(sync.Config, *pkgkubectl.CLI, *[]string) sync.Syncer {
 			return &sync.NoopSyncer{}
 		})
 		t.Override(&component.NewLogger, func(k8slogger.Config, *pkgkubectl.CLI, kubernetes.PodSelector, *[]string) log.Logger {
 			return &log.NoopLogger{}
 		})
 		tests := []struct {
-			name      string
-			cfgs      []latestV1.DeployType
-			expected  *kubectl.Deployer
-			shouldErr bool
+			name              string
+			cfgs              []latestV1.DeployType
+			rollbackOnFailure []*bool
+			expected          *kubectl.Deployer
+			shouldErr         bool
 		}{
 			{
 				name: "one config with kubectl deploy",
 				cfgs: []latestV1.DeployType{{
 					KubectlDeploy: &latestV1.KubectlDeploy{},
 				}},
-				expected: t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 					Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+				}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 					Flags: latestV1.KubectlFlags{},
 				})).(*kubectl.Deployer),
 			},
@@ -218,9 +219,9 @@ func TestGetDefaultDeployer(tOuter *testing.T) {
 						},
 					},
 				}},
-				expected: t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 					Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+				}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 					Flags: latestV1.KubectlFlags{
 						Apply:  []string{"--foo"},
 						Global: []string{"--bar"},
@@ -247,14 +248,40 @@ func TestGetDefaultDeployer(tOuter *testing.T) {
 				},
 				shouldErr: true,
 			},
+			{
+				name: "two configs with rollback on failure",
+				cfgs: []latestV1.DeployType{
+					{KubectlDeploy: &latestV1.KubectlDeploy{}},
+					{KubectlDeploy: &latestV1.KubectlDeploy{}},
+				},
+				rollbackOnFailure: []*bool{util.BoolPtr(true), nil},
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{
+					RunContext: &runcontext.RunContext{
+						Opts:      config.SkaffoldOptions{Command: "apply"},
+						Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
+					},
+					deploy: latestV1.DeployConfig{RollbackOnFailure: util.BoolPtr(true)},
+				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+					Flags: latestV1.KubectlFlags{},
+				})).(*kubectl.Deployer),
+			},
+			{
+				name: "two configs with mismatched rollback on failure should fail",
+				cfgs: []latestV1.DeployType{
+					{KubectlDeploy: &latestV1.KubectlDeploy{}},
+					{KubectlDeploy: &latestV1.KubectlDeploy{}},
+				},
+				rollbackOnFailure: []*bool{util.BoolPtr(true), util.BoolPtr(false)},
+				shouldErr:         true,
+			},
 			{
 				name: "one config with helm deploy",
 				cfgs: []latestV1.DeployType{{
 					HelmDeploy: &latestV1.HelmDeploy{},
 				}},
-				expected: t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 					Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+				}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 					Flags: latestV1.KubectlFlags{},
 				})).(*kubectl.Deployer),
 			},
@@ -263,9 +290,9 @@ func TestGetDefaultDeployer(tOuter *testing.T) {
 				cfgs: []latestV1.DeployType{{
 					KustomizeDeploy: &latestV1.KustomizeDeploy{},
 				}},
-				expected: t.RequireNonNilResult(kubectl.NewDeployer(&runcontext.RunContext{
+				expected: t.RequireNonNilResult(kubectl.NewDeployer(&deployerCtx{RunContext: &runcontext.RunContext{
 					Pipelines: runcontext.NewPipelines([]latestV1.Pipeline{{}}),
-				}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
+				}}, &label.DefaultLabeller{}, &latestV1.KubectlDeploy{
 					Flags: latestV1.KubectlFlags{},
 				})).(*kubectl.Deployer),
 			},
@@ -274,14 +301,19 @@ func TestGetDefaultDeployer(tOuter *testing.T) {
 		for _, test := range tests {
 			testutil.Run(tOuter, test.name, func(t *testutil.T) {
 				pipelines := []latestV1.Pipeline{}
-				for _, cfg := range test.cfgs {
+				for i, cfg := range test.cfgs {
+					deployCfg := latestV1.DeployConfig{
+						DeployType: cfg,
+					}
+					if i < len(test.rollbackOnFailure) {
+						deployCfg.RollbackOnFailure = test.rollbackOnFailure[i]
+					}
 					pipelines = append(pipelines, latestV1.Pipeline{
-						Deploy: latestV1.DeployConfig{
-							DeployType: cfg,
-						},
+						Deploy: deployCfg,
 					})
 				}
 				deployer, err := getDefaultDeployer(&runcontext.RunContext{
+					Opts:      config.SkaffoldOptions{Command: "apply"},
 					Pipelines: runcontext.NewPipelines(pipelines),
 				}, &label.DefaultLabeller{})
 
@@ -23,6 +23,7 @@ import (
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 )
 
 const (
@@ -48,3 +49,27 @@ func (w withNotification) Deploy(ctx context.Context, out io.Writer, builds []gr
 	}
 	return err
 }
+
+// Pruned forwards to the wrapped deployer, when it reports the resources it deletes.
+func (w withNotification) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	if p, ok := w.Deployer.(deploy.Pruner); ok {
+		return p.Pruned(ctx, builds)
+	}
+	return nil, nil
+}
+
+// Rollback forwards to the wrapped deployer, when it can roll back its last deployment.
+func (w withNotification) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	if r, ok := w.Deployer.(deploy.Rollbacker); ok {
+		return r.Rollback(ctx, out)
+	}
+	return nil, nil
+}
+
+// RollbackOnFailure forwards to the wrapped deployer, when it can roll back its last deployment.
+func (w withNotification) RollbackOnFailure() bool {
+	if r, ok := w.Deployer.(deploy.Rollbacker); ok {
+		return r.RollbackOnFailure()
+	}
+	return false
+}
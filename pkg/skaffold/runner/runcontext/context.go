@@ -74,6 +74,15 @@ func (ps Pipelines) PortForwardResources() []*latestV1.PortForwardResource {
 	return pf
 }
 
+// ResourceFilters returns the custom resources declared in all config pipelines.
+func (ps Pipelines) ResourceFilters() []latestV1.ResourceFilter {
+	var filters []latestV1.ResourceFilter
+	for _, p := range ps.pipelines {
+		filters = append(filters, p.ResourceSelector.Allow...)
+	}
+	return filters
+}
+
 func (ps Pipelines) Artifacts() []*latestV1.Artifact {
 	var artifacts []*latestV1.Artifact
 	for _, p := range ps.pipelines {
@@ -136,6 +145,10 @@ func (rc *RunContext) PortForwardResources() []*latestV1.PortForwardResource {
 
 func (rc *RunContext) Artifacts() []*latestV1.Artifact { return rc.Pipelines.Artifacts() }
 
+func (rc *RunContext) ResourceFilters() []latestV1.ResourceFilter {
+	return rc.Pipelines.ResourceFilters()
+}
+
 func (rc *RunContext) DeployConfigs() []latestV1.DeployConfig { return rc.Pipelines.DeployConfigs() }
 
 func (rc *RunContext) Deployers() []latestV1.DeployConfig { return rc.Pipelines.Deployers() }
@@ -146,6 +159,17 @@ func (rc *RunContext) StatusCheckDeadlineSeconds() int {
 	return rc.Pipelines.StatusCheckDeadlineSeconds()
 }
 
+// RollbackAllowed tells whether the current command can roll back failed deployments.
+// Iterations of `skaffold dev` and `skaffold debug` are never rolled back.
+func (rc *RunContext) RollbackAllowed() bool {
+	switch rc.Mode() {
+	case config.RunModes.Run, config.RunModes.Deploy, config.RunModes.Apply:
+		return true
+	default:
+		return false
+	}
+}
+
 func (rc *RunContext) DefaultPipeline() latestV1.Pipeline            { return rc.Pipelines.Head() }
 func (rc *RunContext) GetKubeContext() string                        { return rc.KubeContext }
 func (rc *RunContext) GetPipelines() []latestV1.Pipeline             { return rc.Pipelines.All() }
@@ -158,6 +182,8 @@ func (rc *RunContext) AutoDeploy() bool                              { return rc
 func (rc *RunContext) AutoSync() bool                                { return rc.Opts.AutoSync }
 func (rc *RunContext) CacheArtifacts() bool                          { return rc.Opts.CacheArtifacts }
 func (rc *RunContext) CacheFile() string                             { return rc.Opts.CacheFile }
+func (rc *RunContext) CacheRepo() string                             { return rc.Opts.CacheRepo }
+func (rc *RunContext) CacheRepoReadOnly() bool                       { return rc.Opts.CacheRepoReadOnly }
 func (rc *RunContext) ConfigurationFile() string                     { return rc.Opts.ConfigurationFile }
 func (rc *RunContext) CustomLabels() []string                        { return rc.Opts.CustomLabels }
 func (rc *RunContext) CustomTag() string                             { return rc.Opts.CustomTag }
@@ -170,13 +196,18 @@ func (rc *RunContext) GetKubeConfig() string                         { return rc
 func (rc *RunContext) GetKubeNamespace() string                      { return rc.Opts.Namespace }
 func (rc *RunContext) GlobalConfig() string                          { return rc.Opts.GlobalConfig }
 func (rc *RunContext) HydratedManifests() []string                   { return rc.Opts.HydratedManifests }
+func (rc *RunContext) ImageLoader() string                           { return rc.Cluster.ImageLoader }
 func (rc *RunContext) LoadImages() bool                              { return rc.Cluster.LoadImages }
 func (rc *RunContext) MinikubeProfile() string                       { return rc.Opts.MinikubeProfile }
+func (rc *RunContext) FileSyncer() string                            { return rc.Opts.FileSyncer }
 func (rc *RunContext) Muted() config.Muted                           { return rc.Opts.Muted }
 func (rc *RunContext) NoPruneChildren() bool                         { return rc.Opts.NoPruneChildren }
 func (rc *RunContext) Notification() bool                            { return rc.Opts.Notification }
+func (rc *RunContext) Preview() bool                                 { return rc.Opts.Preview }
+func (rc *RunContext) DiffFormat() string                            { return rc.Opts.DiffFormat }
 func (rc *RunContext) PortForward() bool                             { return rc.Opts.PortForward.Enabled() }
 func (rc *RunContext) PortForwardOptions() config.PortForwardOptions { return rc.Opts.PortForward }
+func (rc *RunContext) PortForwarder() string                         { return rc.Opts.PortForwarder }
 func (rc *RunContext) Prune() bool                                   { return rc.Opts.Prune() }
 func (rc *RunContext) RenderOnly() bool                              { return rc.Opts.RenderOnly }
 func (rc *RunContext) RenderOutput() string                          { return rc.Opts.RenderOutput }
@@ -44,7 +44,8 @@ type Runner interface {
 	Dev(context.Context, io.Writer, []*latestV1.Artifact) error
 	Deploy(context.Context, io.Writer, []graph.Artifact) error
 	DeployAndLog(context.Context, io.Writer, []graph.Artifact) error
-	GeneratePipeline(context.Context, io.Writer, []util.VersionedConfig, []string, string) error
+	Diff(context.Context, io.Writer, []graph.Artifact) error
+	GeneratePipeline(context.Context, io.Writer, []util.VersionedConfig, []string, string, string) error
 	HasBuilt() bool
 	HasDeployed() bool
 	Prune(context.Context, io.Writer) error
@@ -26,6 +26,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/tag"
@@ -114,3 +115,27 @@ func (w withTimings) Prune(ctx context.Context, out io.Writer) error {
 	logrus.Infoln("Image prune completed in", util.ShowHumanizeTime(time.Since(start)))
 	return nil
 }
+
+// Pruned forwards to the wrapped deployer, when it reports the resources it deletes.
+func (w withTimings) Pruned(ctx context.Context, builds []graph.Artifact) ([]diff.Resource, error) {
+	if p, ok := w.Deployer.(deploy.Pruner); ok {
+		return p.Pruned(ctx, builds)
+	}
+	return nil, nil
+}
+
+// Rollback forwards to the wrapped deployer, when it can roll back its last deployment.
+func (w withTimings) Rollback(ctx context.Context, out io.Writer) ([]string, error) {
+	if r, ok := w.Deployer.(deploy.Rollbacker); ok {
+		return r.Rollback(ctx, out)
+	}
+	return nil, nil
+}
+
+// RollbackOnFailure forwards to the wrapped deployer, when it can roll back its last deployment.
+func (w withTimings) RollbackOnFailure() bool {
+	if r, ok := w.Deployer.(deploy.Rollbacker); ok {
+		return r.RollbackOnFailure()
+	}
+	return false
+}
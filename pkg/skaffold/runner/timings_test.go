@@ -265,6 +265,57 @@ func TestTimingsCleanup(t *testing.T) {
 	}
 }
 
+type mockRollbackDeployer struct {
+	mockDeployer
+	rollbackOnFailure bool
+}
+
+func (m *mockRollbackDeployer) Rollback(context.Context, io.Writer) ([]string, error) {
+	return []string{"Pod/app deleted"}, nil
+}
+
+func (m *mockRollbackDeployer) RollbackOnFailure() bool {
+	return m.rollbackOnFailure
+}
+
+func TestTimingsRollback(t *testing.T) {
+	tests := []struct {
+		description               string
+		deployer                  deploy.Deployer
+		expectedRollbackOnFailure bool
+		expectedReverted          []string
+	}{
+		{
+			description:               "rollback enabled",
+			deployer:                  &mockRollbackDeployer{rollbackOnFailure: true},
+			expectedRollbackOnFailure: true,
+			expectedReverted:          []string{"Pod/app deleted"},
+		},
+		{
+			description:      "rollback disabled",
+			deployer:         &mockRollbackDeployer{},
+			expectedReverted: []string{"Pod/app deleted"},
+		},
+		{
+			description: "deployer that can't be rolled back",
+			deployer:    &mockDeployer{},
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			_, _, deployer := WithTimings(nil, nil, WithNotification(test.deployer), false)
+
+			r, ok := deployer.(deploy.Rollbacker)
+			t.CheckTrue(ok)
+
+			reverted, err := r.Rollback(context.Background(), &bytes.Buffer{})
+
+			t.CheckErrorAndDeepEqual(false, err, test.expectedReverted, reverted)
+			t.CheckDeepEqual(test.expectedRollbackOnFailure, r.RollbackOnFailure())
+		})
+	}
+}
+
 func lastInfoEntry(hook *logrustest.Hook) string {
 	for _, entry := range hook.AllEntries() {
 		if entry.Level == logrus.InfoLevel {
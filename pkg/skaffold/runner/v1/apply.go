@@ -38,8 +38,10 @@ func (r *SkaffoldRunner) Apply(ctx context.Context, out io.Writer) error {
 	if err != nil {
 		return err
 	}
-	sErr := r.deployer.GetStatusMonitor().Check(ctx, statusCheckOut)
-	return sErr
+	if sErr := r.deployer.GetStatusMonitor().Check(ctx, statusCheckOut); sErr != nil {
+		return r.rollback(ctx, out, sErr)
+	}
+	return nil
 }
 
 func (r *SkaffoldRunner) applyResources(ctx context.Context, out io.Writer, artifacts, localImages []graph.Artifact) error {
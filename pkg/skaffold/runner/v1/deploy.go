@@ -25,6 +25,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy"
 	deployutil "github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy/util"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/event"
 	eventV2 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/event/v2"
@@ -35,6 +36,9 @@ import (
 
 // DeployAndLog deploys a list of already built artifacts and optionally show the logs.
 func (r *SkaffoldRunner) DeployAndLog(ctx context.Context, out io.Writer, artifacts []graph.Artifact) error {
+	if r.runCtx.Preview() {
+		return r.Diff(ctx, out, artifacts)
+	}
 	defer r.deployer.GetLogger().Stop()
 
 	// Logs should be retrieved up to just before the deploy
@@ -67,6 +71,9 @@ func (r *SkaffoldRunner) Deploy(ctx context.Context, out io.Writer, artifacts []
 	if r.runCtx.RenderOnly() {
 		return r.Render(ctx, out, artifacts, false, r.runCtx.RenderOutput())
 	}
+	if r.runCtx.Preview() {
+		return r.Diff(ctx, out, artifacts)
+	}
 	defer r.deployer.GetStatusMonitor().Reset()
 
 	out = output.WithEventContext(out, constants.Deploy, eventV2.SubtaskIDNone, "skaffold")
@@ -118,7 +125,7 @@ See https://skaffold.dev/docs/pipeline-stages/taggers/#how-tagging-works`)
 		event.DeployFailed(err)
 		eventV2.TaskFailed(constants.Deploy, err)
 		endTrace(instrumentation.TraceEndError(err))
-		return err
+		return r.rollback(ctx, out, err)
 	}
 
 	r.hasDeployed = true
@@ -135,13 +142,22 @@ See https://skaffold.dev/docs/pipeline-stages/taggers/#how-tagging-works`)
 		// run final aggregated status check only if iterative status check is turned off.
 		if err = r.deployer.GetStatusMonitor().Check(ctx, statusCheckOut); err != nil {
 			eventV2.TaskFailed(constants.Deploy, err)
-			return err
+			return r.rollback(ctx, out, err)
 		}
 	}
 	eventV2.TaskSucceeded(constants.Deploy)
 	return nil
 }
 
+// rollback reverts the deployers of a failed deployment that have rollback on failure enabled,
+// and returns the deployment error.
+func (r *SkaffoldRunner) rollback(ctx context.Context, out io.Writer, deployErr error) error {
+	if err := deploy.RollbackFailedDeploy(ctx, out, r.deployer); err != nil {
+		logrus.Errorln(err)
+	}
+	return deployErr
+}
+
 func (r *SkaffoldRunner) wasBuilt(tag string) bool {
 	for _, built := range r.Builds {
 		if built.Tag == tag {
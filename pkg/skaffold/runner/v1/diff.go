@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/deploy"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/diff"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/manifest"
+)
+
+// Diff renders the manifests and shows how deploying them would change the resources on the cluster.
+// The manifests are rendered like they are deployed, with the image tags resulting from the build.
+func (r *SkaffoldRunner) Diff(ctx context.Context, out io.Writer, builds []graph.Artifact) error {
+	var rendered bytes.Buffer
+	if err := r.deployer.Render(ctx, &rendered, builds, false, ""); err != nil {
+		return fmt.Errorf("rendering manifests: %w", err)
+	}
+	manifests, err := manifest.Load(&rendered)
+	if err != nil {
+		return fmt.Errorf("reading rendered manifests: %w", err)
+	}
+
+	var pruned []diff.Resource
+	if p, ok := r.deployer.(deploy.Pruner); ok {
+		if pruned, err = p.Pruned(ctx, builds); err != nil {
+			return fmt.Errorf("listing the resources to delete: %w", err)
+		}
+	}
+
+	differ := diff.NewDiffer(pkgkubectl.NewCLI(r.runCtx, ""), r.labeller.Labels())
+	report, err := differ.Diff(ctx, manifests, pruned)
+	if err != nil {
+		return fmt.Errorf("diffing manifests against the cluster: %w", err)
+	}
+	return report.Print(out, r.runCtx.DiffFormat())
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	pipeline "github.com/GoogleContainerTools/skaffold/pkg/skaffold/generate_pipeline"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/output"
@@ -30,7 +32,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/util"
 )
 
-func (r *SkaffoldRunner) GeneratePipeline(ctx context.Context, out io.Writer, configs []util.VersionedConfig, configPaths []string, fileOut string) error {
+func (r *SkaffoldRunner) GeneratePipeline(ctx context.Context, out io.Writer, configs []util.VersionedConfig, configPaths []string, target, fileOut string) error {
 	// Keep track of files, configs, and profiles. This will be used to know which files to write
 	// profiles to and what flags to add to task commands
 	var baseConfig []*pipeline.ConfigFile
@@ -49,21 +51,35 @@ func (r *SkaffoldRunner) GeneratePipeline(ctx context.Context, out io.Writer, co
 	}
 	configFiles = append(baseConfig, configFiles...)
 
-	// Will run the profile setup multiple times and require user input for each specified config
-	output.Default.Fprintln(out, "Running profile setup...")
-	for _, configFile := range configFiles {
-		if err := pipeline.CreateSkaffoldProfile(out, r.runCtx.GetKubeNamespace(), configFile); err != nil {
-			return fmt.Errorf("setting up profile: %w", err)
+	// Images are only built on the cluster by Tekton pipelines, other CI systems build them on their own runners.
+	if pipeline.BuildsOnCluster(target) {
+		// Will run the profile setup multiple times and require user input for each specified config
+		output.Default.Fprintln(out, "Running profile setup...")
+		for _, configFile := range configFiles {
+			if err := pipeline.CreateSkaffoldProfile(out, r.runCtx.GetKubeNamespace(), configFile); err != nil {
+				return fmt.Errorf("setting up profile: %w", err)
+			}
 		}
 	}
 
+	opts := pipeline.Options{
+		Namespace: r.runCtx.GetKubeNamespace(),
+		Profiles:  r.runCtx.Opts.Profiles,
+	}
+	if defaultRepo := r.runCtx.DefaultRepo(); defaultRepo != nil {
+		opts.DefaultRepo = *defaultRepo
+	}
+
 	output.Default.Fprintln(out, "Generating Pipeline...")
-	pipelineYaml, err := pipeline.Yaml(out, r.runCtx.GetKubeNamespace(), configFiles)
+	pipelineYaml, err := pipeline.Generate(out, target, opts, configFiles)
 	if err != nil {
 		return fmt.Errorf("generating pipeline yaml contents: %w", err)
 	}
 
 	// write all yaml pieces to output
+	if err := os.MkdirAll(filepath.Dir(fileOut), 0755); err != nil {
+		return fmt.Errorf("creating pipeline directory: %w", err)
+	}
 	return ioutil.WriteFile(fileOut, pipelineYaml.Bytes(), 0755)
 }
 
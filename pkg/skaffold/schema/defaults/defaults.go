@@ -23,6 +23,7 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/sirupsen/logrus"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/buildkit"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/kaniko"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
 	kubectx "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/context"
@@ -49,8 +50,9 @@ func Set(c *latestV1.SkaffoldConfig) error {
 	for _, a := range c.Build.Artifacts {
 		setDefaultWorkspace(a)
 		setDefaultSync(a)
+		setDefaultPlatforms(a, c.Build.Platforms)
 
-		if c.Build.Cluster != nil && a.CustomArtifact == nil && a.BuildpackArtifact == nil {
+		if c.Build.Cluster != nil && a.CustomArtifact == nil && a.BuildpackArtifact == nil && a.KoArtifact == nil && a.BuildKitArtifact == nil {
 			defaultToKanikoArtifact(a)
 		} else {
 			defaultToDockerArtifact(a)
@@ -63,6 +65,12 @@ func Set(c *latestV1.SkaffoldConfig) error {
 		case a.KanikoArtifact != nil:
 			setKanikoArtifactDefaults(a.KanikoArtifact)
 
+		case a.BuildKitArtifact != nil:
+			setBuildKitArtifactDefaults(a.BuildKitArtifact)
+			if c.Build.Cluster != nil {
+				c.Build.Cluster.BuildKitImage = valueOrDefault(c.Build.Cluster.BuildKitImage, buildkit.DefaultImage)
+			}
+
 		case a.CustomArtifact != nil:
 			setCustomArtifactDefaults(a.CustomArtifact)
 
@@ -242,6 +250,12 @@ func setDefaultWorkspace(a *latestV1.Artifact) {
 	a.Workspace = valueOrDefault(a.Workspace, ".")
 }
 
+func setDefaultPlatforms(a *latestV1.Artifact, platforms []string) {
+	if len(a.Platforms) == 0 {
+		a.Platforms = platforms
+	}
+}
+
 func setDefaultSync(a *latestV1.Artifact) {
 	if a.Sync != nil {
 		if len(a.Sync.Manual) == 0 && len(a.Sync.Infer) == 0 && a.Sync.Auto == nil {
@@ -343,6 +357,10 @@ func setKanikoArtifactDefaults(a *latestV1.KanikoArtifact) {
 	a.InitImage = valueOrDefault(a.InitImage, constants.DefaultBusyboxImage)
 }
 
+func setBuildKitArtifactDefaults(a *latestV1.BuildKitArtifact) {
+	a.DockerfilePath = valueOrDefault(a.DockerfilePath, constants.DefaultDockerfilePath)
+}
+
 func valueOrDefault(v, def string) string {
 	if v != "" {
 		return v
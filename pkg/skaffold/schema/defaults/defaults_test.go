@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/buildkit"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/build/kaniko"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/constants"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
@@ -167,6 +168,12 @@ func TestSetDefaultsOnCluster(t *testing.T) {
 								BuildpackArtifact: &latestV1.BuildpackArtifact{},
 							},
 						},
+						{
+							ImageName: "buildkit",
+							ArtifactType: latestV1.ArtifactType{
+								BuildKitArtifact: &latestV1.BuildKitArtifact{},
+							},
+						},
 					},
 					BuildType: latestV1.BuildType{
 						Cluster: &latestV1.ClusterDetails{},
@@ -186,6 +193,9 @@ func TestSetDefaultsOnCluster(t *testing.T) {
 		t.CheckNotNil(cfg.Pipeline.Build.Artifacts[1].KanikoArtifact)
 		t.CheckNil(cfg.Pipeline.Build.Artifacts[2].KanikoArtifact)
 		t.CheckNil(cfg.Pipeline.Build.Artifacts[3].KanikoArtifact)
+		t.CheckNil(cfg.Pipeline.Build.Artifacts[4].KanikoArtifact)
+		t.CheckDeepEqual("Dockerfile", cfg.Pipeline.Build.Artifacts[4].BuildKitArtifact.DockerfilePath)
+		t.CheckDeepEqual(buildkit.DefaultImage, cfg.Build.Cluster.BuildKitImage)
 
 		// pull secret set
 		cfg = &latestV1.SkaffoldConfig{
@@ -322,6 +332,21 @@ func TestSetDefaultsOnLocalBuild(t *testing.T) {
 	testutil.CheckDeepEqual(t, 1, *cfg2.Build.LocalBuild.Concurrency)
 }
 
+func TestSetDefaultPlatforms(t *testing.T) {
+	cfg := &latestV1.SkaffoldConfig{Pipeline: latestV1.Pipeline{Build: latestV1.BuildConfig{
+		Platforms: []string{"linux/amd64", "linux/arm64"},
+		Artifacts: []*latestV1.Artifact{
+			{ImageName: "inherited"},
+			{ImageName: "overridden", Platforms: []string{"linux/arm/v7"}},
+		},
+	}}}
+
+	err := Set(cfg)
+	testutil.CheckError(t, false, err)
+	testutil.CheckDeepEqual(t, []string{"linux/amd64", "linux/arm64"}, cfg.Build.Artifacts[0].Platforms)
+	testutil.CheckDeepEqual(t, []string{"linux/arm/v7"}, cfg.Build.Artifacts[1].Platforms)
+}
+
 func TestSetPortForwardLocalPort(t *testing.T) {
 	cfg := &latestV1.SkaffoldConfig{
 		Pipeline: latestV1.Pipeline{
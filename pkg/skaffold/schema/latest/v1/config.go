@@ -73,6 +73,9 @@ type Pipeline struct {
 
 	// PortForward describes user defined resources to port-forward.
 	PortForward []*PortForwardResource `yaml:"portForward,omitempty"`
+
+	// ResourceSelector declares the custom resources that Skaffold transforms, in addition to the built-in kinds.
+	ResourceSelector ResourceSelectorConfig `yaml:"resourceSelector,omitempty"`
 }
 
 // GitInfo contains information on the origin of skaffold configurations cloned from a git repository.
@@ -90,6 +93,31 @@ type GitInfo struct {
 	Sync *bool `yaml:"sync,omitempty"`
 }
 
+// OCIBundleInfo contains information on the origin of skaffold configurations pulled from an OCI registry.
+type OCIBundleInfo struct {
+	// Ref is the reference of the config bundle in the registry. e.g. `gcr.io/k8s-skaffold/modules:v1.0.0`.
+	Ref string `yaml:"ref" yamltags:"required"`
+
+	// Path is the relative path from the bundle root to the skaffold configuration file. eg. `getting-started/skaffold.yaml`.
+	Path string `yaml:"path,omitempty"`
+
+	// Sync when set to `true` will check the registry for a newer bundle on every run. To use the cached bundle without contacting the registry, it needs to be set to `false`.
+	// Defaults to `true`.
+	Sync *bool `yaml:"sync,omitempty"`
+}
+
+// ArchiveInfo contains information on the origin of skaffold configurations downloaded as a tarball.
+type ArchiveInfo struct {
+	// URL is the HTTPS location of the `.tar.gz` archive. e.g. `https://example.com/modules/v1.0.0.tar.gz`.
+	URL string `yaml:"url" yamltags:"required"`
+
+	// SHA256 is the expected sha256 checksum of the archive.
+	SHA256 string `yaml:"sha256" yamltags:"required"`
+
+	// Path is the relative path from the archive root to the skaffold configuration file. eg. `getting-started/skaffold.yaml`.
+	Path string `yaml:"path,omitempty"`
+}
+
 // ConfigDependency describes a dependency on another skaffold configuration.
 type ConfigDependency struct {
 	// Names includes specific named configs within the file path. If empty, then all configs in the file are included.
@@ -101,6 +129,12 @@ type ConfigDependency struct {
 	// GitRepo describes a remote git repository containing the required configs.
 	GitRepo *GitInfo `yaml:"git,omitempty" yamltags:"oneOf=paths"`
 
+	// OCIBundle describes a config bundle stored in an OCI registry containing the required configs.
+	OCIBundle *OCIBundleInfo `yaml:"oci,omitempty" yamltags:"oneOf=paths"`
+
+	// Archive describes a remote tarball containing the required configs.
+	Archive *ArchiveInfo `yaml:"archive,omitempty" yamltags:"oneOf=paths"`
+
 	// ActiveProfiles describes the list of profiles to activate when resolving the required configs. These profiles must exist in the imported config.
 	ActiveProfiles []ProfileDependency `yaml:"activeProfiles,omitempty"`
 }
@@ -144,6 +178,31 @@ type PortForwardResource struct {
 	LocalPort int `yaml:"localPort,omitempty"`
 }
 
+// ResourceSelectorConfig declares the custom resources that Skaffold transforms.
+type ResourceSelectorConfig struct {
+	// Allow lists the custom resource kinds in which Skaffold replaces images, sets labels and applies debug transforms.
+	Allow []ResourceFilter `yaml:"allow,omitempty"`
+}
+
+// ResourceFilter declares the fields of a custom resource kind that are transformed by Skaffold.
+// Field paths are dot-separated, e.g. `.spec.template`. Lists found along a path are traversed.
+// When no field is declared, all the `image` and `metadata` fields of the resource are transformed.
+type ResourceFilter struct {
+	// GroupKind is the kind and group of the custom resource, e.g. `Workflow.argoproj.io`.
+	GroupKind string `yaml:"groupKind" yamltags:"required"`
+
+	// Image lists the paths of fields holding container images, e.g. `.spec.runner.image`.
+	Image []string `yaml:"image,omitempty"`
+
+	// PodTemplate lists the paths of pod templates, e.g. `.spec.template`.
+	// The images and labels of pod templates are transformed, pod templates are configured by `skaffold debug`,
+	// and the pods created from the first pod template are diagnosed by the status check.
+	PodTemplate []string `yaml:"podTemplate,omitempty"`
+
+	// Labels lists the paths of label maps to which Skaffold adds its labels, e.g. `.spec.podLabels`.
+	Labels []string `yaml:"labels,omitempty"`
+}
+
 // BuildConfig contains all the configuration for the build steps.
 type BuildConfig struct {
 	// Artifacts lists the images you're going to be building.
@@ -158,6 +217,11 @@ type BuildConfig struct {
 	// If not specified, it defaults to `gitCommit: {variant: Tags}`.
 	TagPolicy TagPolicy `yaml:"tagPolicy,omitempty"`
 
+	// Platforms is the list of platforms to build all artifacts for, unless an artifact defines its own `platforms`.
+	// Each platform is of the form `os/arch[/variant]`. For example: `["linux/amd64", "linux/arm64"]`.
+	// The `--platform` flag overrides this value.
+	Platforms []string `yaml:"platforms,omitempty"`
+
 	BuildType `yaml:",inline"`
 }
 
@@ -180,6 +244,9 @@ type TagPolicy struct {
 
 	// InputDigest *beta* tags images with their sha256 digest of their content.
 	InputDigest *InputDigest `yaml:"inputDigest,omitempty" yamltags:"oneOf=tag"`
+
+	// SemverTagger *beta* tags images with a semantic version computed from the git history of the artifact's workspace.
+	SemverTagger *SemverTagger `yaml:"semver,omitempty" yamltags:"oneOf=tag"`
 }
 
 // ShaTagger *beta* tags images with their sha256 digest.
@@ -205,6 +272,39 @@ type GitTagger struct {
 	IgnoreChanges bool `yaml:"ignoreChanges,omitempty"`
 }
 
+// SemverTagger *beta* tags images with a semantic version computed from the git history of the artifact's workspace.
+// The latest version tag reachable from HEAD is bumped according to the messages of the commits made since.
+type SemverTagger struct {
+	// Prefix adds a fixed prefix to the tag.
+	Prefix string `yaml:"prefix,omitempty"`
+
+	// TagPrefix selects the git tags that hold versions, for example `web/` for tags like `web/v1.2.3`.
+	// A `v` in front of the version is always accepted.
+	TagPrefix string `yaml:"tagPrefix,omitempty"`
+
+	// Rules map commit messages to version bumps. The highest bump of all matching rules is applied, or a patch bump if none match.
+	// Defaults to [Conventional Commits](https://www.conventionalcommits.org): breaking changes bump the major version,
+	// `feat` commits bump the minor version and any other commit bumps the patch version.
+	Rules []SemverBumpRule `yaml:"rules,omitempty"`
+
+	// PreRelease is the pre-release identifier added to versions that aren't tagged yet, followed by the number of commits since the latest version tag.
+	// For example, `rc` produces versions like `1.3.0-rc.4`. If empty, the bumped version is used as is.
+	PreRelease string `yaml:"preRelease,omitempty"`
+
+	// IgnoreChanges specifies whether to omit the `dirty` marker if there are uncommitted changes.
+	IgnoreChanges bool `yaml:"ignoreChanges,omitempty"`
+}
+
+// SemverBumpRule *beta* maps commit messages to a version bump.
+type SemverBumpRule struct {
+	// Pattern is a regular expression matched against each commit message.
+	// For example: `(?m)^BREAKING CHANGE:`.
+	Pattern string `yaml:"pattern" yamltags:"required"`
+
+	// Bump is the part of the version to increment when the pattern matches: `major`, `minor` or `patch`.
+	Bump string `yaml:"bump" yamltags:"required"`
+}
+
 // EnvTemplateTagger *beta* tags images with a configurable template string.
 type EnvTemplateTagger struct {
 	// Template used to produce the image name and tag.
@@ -437,6 +537,10 @@ type ClusterDetails struct {
 
 	// RandomDockerConfigSecret adds a random UUID postfix to the default name of the docker secret to facilitate parallel builds, e.g. docker-cfgfd154022-c761-416f-8eb3-cf8258450b85.
 	RandomDockerConfigSecret bool `yaml:"randomDockerConfigSecret,omitempty"`
+
+	// BuildKitImage is the image of the BuildKit daemon that builds the `buildkit` artifacts.
+	// Defaults to `moby/buildkit:v0.9.0`.
+	BuildKitImage string `yaml:"buildkitImage,omitempty"`
 }
 
 // DockerConfig contains information about the docker `config.json` to mount.
@@ -510,6 +614,10 @@ type DeployConfig struct {
 	// StatusCheckDeadlineSeconds *beta* is the deadline for deployments to stabilize in seconds.
 	StatusCheckDeadlineSeconds int `yaml:"statusCheckDeadlineSeconds,omitempty"`
 
+	// RollbackOnFailure reverts the deployed resources to their previous state when the deployment fails or they fail to stabilize.
+	// Only applies to `skaffold run`, `skaffold deploy` and `skaffold apply`.
+	RollbackOnFailure *bool `yaml:"rollbackOnFailure,omitempty"`
+
 	// KubeContext is the Kubernetes context that Skaffold should deploy to.
 	// For example: `minikube`.
 	KubeContext string `yaml:"kubeContext,omitempty"`
@@ -544,6 +652,11 @@ type DockerDeploy struct {
 	// UseCompose tells skaffold whether or not to deploy using `docker-compose`.
 	UseCompose bool `yaml:"useCompose,omitempty"`
 
+	// ComposeFile is the path to the `docker-compose` file to deploy when `useCompose` is set.
+	// The images of the services matching one of the `images` are replaced with the tags built by Skaffold.
+	// Defaults to `docker-compose.yml`.
+	ComposeFile string `yaml:"composeFile,omitempty" skaffold:"filepath"`
+
 	// Images are the container images to run in Docker.
 	Images []string `yaml:"images" yamltags:"required"`
 }
@@ -565,7 +678,7 @@ type KubectlDeploy struct {
 	DefaultNamespace *string `yaml:"defaultNamespace,omitempty"`
 
 	// LifecycleHooks describes a set of lifecycle hooks that are executed before and after every deploy.
-	LifecycleHooks DeployHooks `yaml:"-"`
+	LifecycleHooks DeployHooks `yaml:"hooks,omitempty"`
 }
 
 // KubectlFlags are additional flags passed on the command
@@ -584,6 +697,14 @@ type KubectlFlags struct {
 	// DisableValidation passes the `--validate=false` flag to supported
 	// `kubectl` commands when enabled.
 	DisableValidation bool `yaml:"disableValidation,omitempty"`
+
+	// ServerSideApply applies manifests with server-side apply (`kubectl apply --server-side`),
+	// using `skaffold` as the field manager, instead of client-side `kubectl apply`.
+	ServerSideApply bool `yaml:"serverSideApply,omitempty"`
+
+	// ForceConflicts makes server-side apply take ownership of fields that are managed by other field managers,
+	// instead of failing on conflicts. The `--force` flag has the same effect with server-side apply.
+	ForceConflicts bool `yaml:"forceConflicts,omitempty"`
 }
 
 // HelmDeploy *beta* uses the `helm` CLI to apply the charts to the cluster.
@@ -596,7 +717,7 @@ type HelmDeploy struct {
 	Flags HelmDeployFlags `yaml:"flags,omitempty"`
 
 	// LifecycleHooks describes a set of lifecycle hooks that are executed before and after every deploy.
-	LifecycleHooks DeployHooks `yaml:"-"`
+	LifecycleHooks DeployHooks `yaml:"hooks,omitempty"`
 }
 
 // HelmDeployFlags are additional option flags that are passed on the command
@@ -628,7 +749,7 @@ type KustomizeDeploy struct {
 	DefaultNamespace *string `yaml:"defaultNamespace,omitempty"`
 
 	// LifecycleHooks describes a set of lifecycle hooks that are executed before and after every deploy.
-	LifecycleHooks DeployHooks `yaml:"-"`
+	LifecycleHooks DeployHooks `yaml:"hooks,omitempty"`
 }
 
 // KptDeploy *alpha* uses the `kpt` CLI to manage and deploy manifests.
@@ -646,7 +767,7 @@ type KptDeploy struct {
 	Live KptLive `yaml:"live,omitempty"`
 
 	// LifecycleHooks describes a set of lifecycle hooks that are executed before and after every deploy.
-	LifecycleHooks DeployHooks `yaml:"-"`
+	LifecycleHooks DeployHooks `yaml:"hooks,omitempty"`
 }
 
 // KptFn adds additional configurations used when calling `kpt fn`.
@@ -798,6 +919,11 @@ type HelmRelease struct {
 	// ImageStrategy controls how an `ArtifactOverrides` entry is
 	// turned into `--set-string` Helm CLI flag or flags.
 	ImageStrategy HelmImageStrategy `yaml:"imageStrategy,omitempty"`
+
+	// DependsOn lists the names of the releases that are deployed, and ready, before this release.
+	// When any release has dependencies, independent releases are deployed concurrently,
+	// and releases are deleted in the reverse order.
+	DependsOn []string `yaml:"dependsOn,omitempty"`
 }
 
 // HelmPackaged parameters for packaging helm chart (`helm package`).
@@ -871,6 +997,12 @@ type Artifact struct {
 
 	// LifecycleHooks describes a set of lifecycle hooks that are executed before and after each build of the target artifact.
 	LifecycleHooks BuildHooks `yaml:"hooks,omitempty"`
+
+	// Platforms is the list of platforms to build the image for.
+	// Each platform is of the form `os/arch[/variant]`. For example: `["linux/amd64", "linux/arm64"]`.
+	// When more than one platform is listed, the images are pushed as a single OCI image index.
+	// Defaults to the `platforms` of the build config.
+	Platforms []string `yaml:"platforms,omitempty"`
 }
 
 // Sync *beta* specifies what files to sync into the container.
@@ -893,7 +1025,7 @@ type Sync struct {
 	Auto *bool `yaml:"auto,omitempty" yamltags:"oneOf=sync"`
 
 	// LifecycleHooks describes a set of lifecycle hooks that are executed before and after each file sync action on the target artifact's containers.
-	LifecycleHooks SyncHooks `yaml:"-"`
+	LifecycleHooks SyncHooks `yaml:"hooks,omitempty"`
 }
 
 // SyncRule specifies which local files to sync to remote folders.
@@ -991,6 +1123,12 @@ type ArtifactType struct {
 
 	// CustomArtifact *beta* builds images using a custom build script written by the user.
 	CustomArtifact *CustomArtifact `yaml:"custom,omitempty" yamltags:"oneOf=artifact"`
+
+	// KoArtifact *alpha* builds images for Go programs using [ko](https://github.com/google/ko).
+	KoArtifact *KoArtifact `yaml:"ko,omitempty" yamltags:"oneOf=artifact"`
+
+	// BuildKitArtifact *alpha* builds images using [BuildKit](https://github.com/moby/buildkit), in a Kubernetes cluster.
+	BuildKitArtifact *BuildKitArtifact `yaml:"buildkit,omitempty" yamltags:"oneOf=artifact"`
 }
 
 // ArtifactDependency describes a specific build dependency for an artifact.
@@ -1063,6 +1201,80 @@ type BuildpackVolume struct {
 	Options string `yaml:"options,omitempty"`
 }
 
+// KoArtifact *alpha* describes an artifact built from Go sources using [ko](https://github.com/google/ko).
+// The Go program is built without a Dockerfile and without a Docker daemon, and added on top of a base image.
+type KoArtifact struct {
+	// BaseImage overrides the default base image used by ko.
+	// Defaults to `gcr.io/distroless/static:nonroot`.
+	BaseImage string `yaml:"fromImage,omitempty"`
+
+	// Main is the import path of the main package to build, relative to the workspace or fully qualified.
+	// It can also be prefixed with `ko://`.
+	// Defaults to the artifact's image name if it uses the `ko://` prefix, and to `.` otherwise.
+	// For example: `./cmd/app`.
+	Main string `yaml:"main,omitempty"`
+
+	// Env are environment variables, in the `key=value` form, passed to `go build`.
+	// Values can use the go template syntax.
+	// For example: `["GOPRIVATE=source.developers.google.com", "GOARM={{.GOARM}}"]`.
+	Env []string `yaml:"env,omitempty"`
+
+	// Flags are additional build flags passed to `go build`.
+	// Flags with values must use the `-flag=value` form.
+	// For example: `["-tags=netgo", "-v"]`.
+	Flags []string `yaml:"flags,omitempty"`
+
+	// Ldflags are linker flags passed to `go build`.
+	// Values can use the go template syntax.
+	// For example: `["-s", "-w", "-X main.version={{.VERSION}}"]`.
+	Ldflags []string `yaml:"ldflags,omitempty"`
+
+	// Dependencies are the file dependencies that skaffold should watch for rebuilding this artifact.
+	// Defaults to the Go sources, `go.mod` and `go.sum` files, and `kodata` directories of the workspace.
+	Dependencies *KoDependencies `yaml:"dependencies,omitempty"`
+}
+
+// BuildKitArtifact *alpha* describes an artifact built from a Dockerfile by a BuildKit daemon running in a Kubernetes cluster.
+// The build context is streamed from the local machine, and the image is pushed to its registry by the daemon.
+type BuildKitArtifact struct {
+	// DockerfilePath locates the Dockerfile relative to workspace.
+	// Defaults to `Dockerfile`.
+	DockerfilePath string `yaml:"dockerfile,omitempty"`
+
+	// Target is the Dockerfile target name to build.
+	Target string `yaml:"target,omitempty"`
+
+	// BuildArgs are arguments passed to the docker build.
+	// It also accepts environment variables and generated values via the go template syntax.
+	// Exposed generated values: IMAGE_REPO, IMAGE_NAME, IMAGE_TAG.
+	// For example: `{"key1": "value1", "key2": "value2", "key3": "'{{.IMAGE_NAME}}'"}`.
+	BuildArgs map[string]*string `yaml:"buildArgs,omitempty"`
+
+	// Secrets are the local files made available to `RUN --mount=type=secret` instructions.
+	Secrets []*DockerSecret `yaml:"secrets,omitempty"`
+
+	// SSH are the SSH agent sockets or keys made available to `RUN --mount=type=ssh` instructions.
+	// Format is "default|<id>[=<socket>|<key>[,<key>]]".
+	SSH []string `yaml:"ssh,omitempty"`
+
+	// CacheFrom are the registry references of build caches to import.
+	// For example: `["gcr.io/my-project/app:buildcache"]`.
+	CacheFrom []string `yaml:"cacheFrom,omitempty"`
+
+	// CacheTo is the registry reference where the build cache is exported, with all the intermediate layers.
+	// For example: `gcr.io/my-project/app:buildcache`.
+	CacheTo string `yaml:"cacheTo,omitempty"`
+}
+
+// KoDependencies *alpha* is used to specify dependencies for an artifact built by ko.
+type KoDependencies struct {
+	// Paths should be set to the file dependencies for this artifact, so that the skaffold file watcher knows when to rebuild.
+	Paths []string `yaml:"paths,omitempty"`
+
+	// Ignore specifies the paths that should be ignored by skaffold's file watcher. If a file exists in both `paths` and in `ignore`, it will be ignored, and will be excluded from rebuilds.
+	Ignore []string `yaml:"ignore,omitempty"`
+}
+
 // CustomArtifact *beta* describes an artifact built from a custom build script
 // written by the user. It can be used to build images with builders that aren't directly integrated with skaffold.
 type CustomArtifact struct {
@@ -1394,9 +1606,9 @@ type ContainerHook struct {
 type NamedContainerHook struct {
 	// ContainerHook describes a lifecycle hook definition to execute on a container.
 	ContainerHook `yaml:",inline"`
-	// PodName is the name of the pod to execute the command in.
+	// PodName is the name of the pod to execute the command in. It can be a glob pattern, like `web-*`.
 	PodName string `yaml:"podName" yamltags:"required"`
-	// ContainerName is the name of the container to execute the command in.
+	// ContainerName is the name of the container to execute the command in. It can be a glob pattern. Defaults to all the containers of the pod.
 	ContainerName string `yaml:"containerName,omitempty"`
 }
 
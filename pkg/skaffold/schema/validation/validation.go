@@ -33,6 +33,7 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
 	sErrors "github.com/GoogleContainerTools/skaffold/pkg/skaffold/errors"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/parser"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/platform"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/runner/runcontext"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
@@ -72,6 +73,7 @@ func Process(configs parser.SkaffoldConfigSet, validateConfig Options) error {
 		cfgErrs = append(cfgErrs, validatePortForwardResources(config.PortForward)...)
 		cfgErrs = append(cfgErrs, validateJibPluginTypes(config.Build.Artifacts)...)
 		cfgErrs = append(cfgErrs, validateLogPrefix(config.Deploy.Logs)...)
+		cfgErrs = append(cfgErrs, validatePlatforms(config.Build)...)
 		cfgErrs = append(cfgErrs, validateArtifactTypes(config.Build)...)
 		cfgErrs = append(cfgErrs, validateTaggingPolicy(config.Build)...)
 		cfgErrs = append(cfgErrs, validateCustomTest(config.Test)...)
@@ -489,6 +491,7 @@ func validatePortForwardResources(pfrs []*latestV1.PortForwardResource) []error
 		"daemonset":             {},
 		"cronjob":               {},
 		"job":                   {},
+		"container":             {},
 	}
 	for _, pfr := range pfrs {
 		resourceType := strings.ToLower(string(pfr.Type))
@@ -519,7 +522,7 @@ func validateArtifactTypes(bc latestV1.BuildConfig) (errs []error) {
 	switch {
 	case bc.LocalBuild != nil:
 		for _, a := range bc.Artifacts {
-			if misc.ArtifactType(a) == misc.Kaniko {
+			if at := misc.ArtifactType(a); at == misc.Kaniko || at == misc.BuildKit {
 				errs = append(errs, fmt.Errorf("found a '%s' artifact, which is incompatible with the 'local' builder:\n\n%s\n\nTo use the '%s' builder, add the 'cluster' stanza to the 'build' section of your configuration. For information, see https://skaffold.dev/docs/pipeline-stages/builders/", misc.ArtifactType(a), misc.FormatArtifact(a), misc.ArtifactType(a)))
 			}
 		}
@@ -532,7 +535,7 @@ func validateArtifactTypes(bc latestV1.BuildConfig) (errs []error) {
 		}
 	case bc.Cluster != nil:
 		for _, a := range bc.Artifacts {
-			if misc.ArtifactType(a) != misc.Kaniko && misc.ArtifactType(a) != misc.Custom {
+			if at := misc.ArtifactType(a); at != misc.Kaniko && at != misc.BuildKit && at != misc.Custom {
 				errs = append(errs, fmt.Errorf("found a '%s' artifact, which is incompatible with the 'cluster' builder:\n\n%s\n\nTo use the '%s' builder, remove the 'cluster' stanza from the 'build' section of your configuration. For information, see https://skaffold.dev/docs/pipeline-stages/builders/", misc.ArtifactType(a), misc.FormatArtifact(a), misc.ArtifactType(a)))
 			}
 		}
@@ -551,6 +554,19 @@ func validateLogPrefix(lc latestV1.LogsConfig) []error {
 	return nil
 }
 
+// validatePlatforms checks that the build platforms are of the form `os/arch[/variant]`.
+func validatePlatforms(b latestV1.BuildConfig) (errs []error) {
+	if _, err := platform.ParseAll(b.Platforms); err != nil {
+		errs = append(errs, err)
+	}
+	for _, a := range b.Artifacts {
+		if _, err := platform.ParseAll(a.Platforms); err != nil {
+			errs = append(errs, fmt.Errorf("artifact %s: %w", a.ImageName, err))
+		}
+	}
+	return
+}
+
 func validateSingleKubeContext(configs parser.SkaffoldConfigSet) []error {
 	if len(configs) < 2 {
 		return nil
@@ -1118,6 +1118,45 @@ func TestValidateLogsConfig(t *testing.T) {
 	}
 }
 
+func TestValidatePlatforms(t *testing.T) {
+	tests := []struct {
+		description string
+		build       latestV1.BuildConfig
+		shouldErr   bool
+	}{
+		{
+			description: "valid platforms",
+			build: latestV1.BuildConfig{
+				Platforms: []string{"linux/amd64", "linux/arm64"},
+				Artifacts: []*latestV1.Artifact{{ImageName: "img", Platforms: []string{"linux/arm/v7"}}},
+			},
+		},
+		{
+			description: "invalid pipeline platform",
+			build:       latestV1.BuildConfig{Platforms: []string{"arm64"}},
+			shouldErr:   true,
+		},
+		{
+			description: "invalid artifact platform",
+			build:       latestV1.BuildConfig{Artifacts: []*latestV1.Artifact{{ImageName: "img", Platforms: []string{"linux/"}}}},
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			// disable yamltags validation
+			t.Override(&validateYamltags, func(interface{}) error { return nil })
+
+			err := Process(parser.SkaffoldConfigSet{&parser.SkaffoldConfigEntry{
+				SkaffoldConfig: &latestV1.SkaffoldConfig{
+					Pipeline: latestV1.Pipeline{Build: test.build},
+				}}}, Options{CheckDeploySource: false})
+
+			t.CheckError(test.shouldErr, err)
+		})
+	}
+}
+
 func TestValidateAcyclicDependencies(t *testing.T) {
 	tests := []struct {
 		description string
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+// ContainerSyncer syncs files into the containers deployed to the local Docker daemon.
+type ContainerSyncer struct {
+	client  docker.LocalDaemon
+	tracker *tracker.ContainerTracker
+}
+
+func NewContainerSyncer(client docker.LocalDaemon, tracker *tracker.ContainerTracker) *ContainerSyncer {
+	return &ContainerSyncer{
+		client:  client,
+		tracker: tracker,
+	}
+}
+
+func (s *ContainerSyncer) Sync(ctx context.Context, out io.Writer, item *Item) error {
+	container, found := s.tracker.DeployedContainerForImage(item.Artifact.ImageName)
+	if !found {
+		return errors.New("didn't sync any files")
+	}
+
+	if len(item.Copy) > 0 {
+		logrus.Infoln("Copying files:", item.Copy, "to", item.Image)
+
+		if err := s.copyFiles(ctx, container.ID, item.Copy); err != nil {
+			return fmt.Errorf("copying files: %w", err)
+		}
+	}
+
+	if len(item.Delete) > 0 {
+		logrus.Infoln("Deleting files:", item.Delete, "from", item.Image)
+
+		if err := s.deleteFiles(ctx, container.ID, item.Delete); err != nil {
+			return fmt.Errorf("deleting files: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ContainerSyncer) copyFiles(ctx context.Context, id string, files syncMap) error {
+	reader, writer := io.Pipe()
+	go func() {
+		if err := util.CreateMappedTar(writer, "/", files); err != nil {
+			writer.CloseWithError(err)
+		} else {
+			writer.Close()
+		}
+	}()
+
+	return s.client.CopyToContainer(ctx, id, "/", reader)
+}
+
+func (s *ContainerSyncer) deleteFiles(ctx context.Context, id string, files syncMap) error {
+	args := make([]string, 0, 3+len(files))
+	args = append(args, "rm", "-rf", "--")
+	for _, dsts := range files {
+		args = append(args, dsts...)
+	}
+	return s.client.ContainerExec(ctx, ioutil.Discard, id, args)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker/tracker"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+type fakeDaemon struct {
+	docker.LocalDaemon
+	copied map[string]string // container path -> content
+	execs  [][]string
+}
+
+func (f *fakeDaemon) CopyToContainer(_ context.Context, _ string, _ string, content io.Reader) error {
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		f.copied[hdr.Name] = string(b)
+	}
+}
+
+func (f *fakeDaemon) ContainerExec(_ context.Context, _ io.Writer, _ string, cmd []string) error {
+	f.execs = append(f.execs, cmd)
+	return nil
+}
+
+func TestContainerSyncer(t *testing.T) {
+	tests := []struct {
+		description    string
+		image          string
+		copy           syncMap
+		delete         syncMap
+		expectedCopied map[string]string
+		expectedExecs  [][]string
+		shouldErr      bool
+	}{
+		{
+			description:    "copy and delete",
+			image:          "image",
+			copy:           syncMap{"file.txt": {"/app/file.txt"}},
+			delete:         syncMap{"old.txt": {"/app/old.txt"}},
+			expectedCopied: map[string]string{"/app/file.txt": "content"},
+			expectedExecs:  [][]string{{"rm", "-rf", "--", "/app/old.txt"}},
+		},
+		{
+			description:    "no container deployed for image",
+			image:          "other",
+			copy:           syncMap{"file.txt": {"/app/file.txt"}},
+			expectedCopied: map[string]string{},
+			shouldErr:      true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.NewTempDir().Write("file.txt", "content").Chdir()
+			client := &fakeDaemon{copied: map[string]string{}}
+			containerTracker := tracker.NewContainerTracker()
+			containerTracker.Add(graph.Artifact{ImageName: "image", Tag: "image:tag"}, tracker.Container{Name: "image", ID: "id"})
+
+			syncer := NewContainerSyncer(client, containerTracker)
+			err := syncer.Sync(context.Background(), ioutil.Discard, &Item{
+				Image:    test.image + ":tag",
+				Artifact: &latestV1.Artifact{ImageName: test.image},
+				Copy:     test.copy,
+				Delete:   test.delete,
+			})
+
+			t.CheckError(test.shouldErr, err)
+			t.CheckDeepEqual(test.expectedCopied, client.copied)
+			t.CheckDeepEqual(test.expectedExecs, client.execs)
+		})
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	gosync "sync"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	utilexec "k8s.io/client-go/util/exec"
+
+	eventV2 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/event/v2"
+	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	kubectx "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/context"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
+)
+
+const (
+	// KubectlSyncerType syncs files by running `kubectl exec` processes.
+	KubectlSyncerType = "kubectl"
+	// NativeSyncerType syncs files from within Skaffold, using the Kubernetes exec API.
+	NativeSyncerType = "native"
+
+	// exit code of the sync script when `tar` isn't available in the container
+	tarNotFoundExitCode = 127
+)
+
+// For testing
+var (
+	newExecutor = func() execFunc { return (&spdyExecutor{}).exec }
+)
+
+// execFunc runs a command in a container.
+type execFunc func(ctx context.Context, pod v1.Pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error
+
+// Config is the configuration needed to choose a Syncer.
+type Config interface {
+	FileSyncer() string
+	GetRunID() string
+}
+
+// NativePodSyncer syncs files into running pods through the Kubernetes exec API, without spawning `kubectl` processes.
+// Copies and deletes are sent to each container in a single exec call, streaming a tar archive of the copied files.
+type NativePodSyncer struct {
+	kubectl         *pkgkubectl.CLI // used to run container lifecycle hooks
+	namespaces      *[]string
+	runID           string
+	execInContainer execFunc
+}
+
+func NewNativePodSyncer(cli *pkgkubectl.CLI, namespaces *[]string, runID string) *NativePodSyncer {
+	return &NativePodSyncer{
+		kubectl:         cli,
+		namespaces:      namespaces,
+		runID:           runID,
+		execInContainer: newExecutor(),
+	}
+}
+
+func (s *NativePodSyncer) Sync(ctx context.Context, out io.Writer, item *Item) error {
+	if !item.HasChanges() {
+		return nil
+	}
+	hooksRunner, err := syncHooksRunner(s.kubectl, s.runID, *s.namespaces, item)
+	if err != nil {
+		return err
+	}
+	if err := hooksRunner.RunPreHooks(ctx, out); err != nil {
+		return fmt.Errorf("running pre-sync hooks: %w", err)
+	}
+
+	if len(item.Copy) > 0 {
+		logrus.Infoln("Copying files:", item.Copy, "to", item.Image)
+	}
+	if len(item.Delete) > 0 {
+		logrus.Infoln("Deleting files:", item.Delete, "from", item.Image)
+	}
+
+	copies, deletes := destinations(item.Copy), destinations(item.Delete)
+	for _, f := range append(copies, deletes...) {
+		eventV2.FileSyncInProgress(f, item.Image)
+	}
+
+	err = syncContainers(ctx, item.Image, *s.namespaces, func(ctx context.Context, p v1.Pod, c v1.Container) func() error {
+		return func() error {
+			return s.syncContainer(ctx, p, c, item.Copy, deletes)
+		}
+	})
+
+	for _, f := range append(copies, deletes...) {
+		if err != nil {
+			eventV2.FileSyncFailed(f, item.Image, err)
+		} else {
+			eventV2.FileSyncSucceeded(f, item.Image)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("syncing files: %w", err)
+	}
+
+	if err := hooksRunner.RunPostHooks(ctx, out); err != nil {
+		return fmt.Errorf("running post-sync hooks: %w", err)
+	}
+	return nil
+}
+
+// syncContainer deletes files from, and copies files to a container with a single exec call.
+func (s *NativePodSyncer) syncContainer(ctx context.Context, pod v1.Pod, container v1.Container, copies syncMap, deletes []string) error {
+	var stdin io.Reader
+	if len(copies) > 0 {
+		reader, writer := io.Pipe()
+		// unblocks the writer if the archive isn't fully read
+		defer reader.Close()
+		go func() {
+			if err := util.CreateMappedTar(writer, "/", copies); err != nil {
+				writer.CloseWithError(err)
+			} else {
+				writer.Close()
+			}
+		}()
+		stdin = reader
+	}
+
+	var stderr bytes.Buffer
+	err := s.execInContainer(ctx, pod, container.Name, []string{"sh", "-c", syncScript(deletes, len(copies) > 0)}, stdin, ioutil.Discard, &stderr)
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitStatus() == tarNotFoundExitCode {
+		logrus.Debugf("tar is not available in container %s of pod %s, copying files one by one", container.Name, pod.Name)
+		return s.copyFilesWithoutTar(ctx, pod, container, copies)
+	}
+	if err != nil {
+		return fmt.Errorf("syncing files to container %s of pod %s: %w: %s", container.Name, pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// copyFilesWithoutTar copies files one by one, for containers that don't have `tar`.
+func (s *NativePodSyncer) copyFilesWithoutTar(ctx context.Context, pod v1.Pod, container v1.Container, copies syncMap) error {
+	for src, dsts := range copies {
+		for _, dst := range dsts {
+			if err := s.copyFileWithoutTar(ctx, pod, container, src, dst); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *NativePodSyncer) copyFileWithoutTar(ctx context.Context, pod v1.Pod, container v1.Container, src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", src, err)
+	}
+	defer f.Close()
+
+	script := fmt.Sprintf("mkdir -p %s && cat > %s", shellQuote(path.Dir(dst)), shellQuote(dst))
+	var stderr bytes.Buffer
+	if err := s.execInContainer(ctx, pod, container.Name, []string{"sh", "-c", script}, f, ioutil.Discard, &stderr); err != nil {
+		return fmt.Errorf("copying %s to container %s of pod %s: %w: %s", src, container.Name, pod.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// syncScript returns the shell script that deletes the given files, and extracts the archive read from stdin.
+func syncScript(deletes []string, extract bool) string {
+	var script []string
+	if len(deletes) > 0 {
+		quoted := make([]string, len(deletes))
+		for i, d := range deletes {
+			quoted[i] = shellQuote(d)
+		}
+		script = append(script, "rm -rf -- "+strings.Join(quoted, " "))
+	}
+	if extract {
+		// Use "m" flag to touch the files as they are copied.
+		script = append(script,
+			fmt.Sprintf("command -v tar >/dev/null 2>&1 || exit %d", tarNotFoundExitCode),
+			"tar xmf - -C / --no-same-owner")
+	}
+	return "set -e; " + strings.Join(script, "; ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// destinations returns the sorted destinations of a sync map.
+func destinations(files syncMap) []string {
+	var dsts []string
+	for _, d := range files {
+		dsts = append(dsts, d...)
+	}
+	sort.Strings(dsts)
+	return dsts
+}
+
+// spdyExecutor runs commands in containers, using the exec subresource of the pods.
+// The Kubernetes client is created once, and reused by the following syncs.
+type spdyExecutor struct {
+	lock   gosync.Mutex
+	config *rest.Config
+	client kubernetes.Interface
+}
+
+func (e *spdyExecutor) clientAndConfig() (kubernetes.Interface, *rest.Config, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.client == nil {
+		config, err := kubectx.GetRestClientConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting client config for Kubernetes client: %w", err)
+		}
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting Kubernetes client: %w", err)
+		}
+		e.config, e.client = config, client
+	}
+	return e.client, e.config, nil
+}
+
+// exec runs a command in a container. The exec stream is closed when the context is cancelled.
+func (e *spdyExecutor) exec(ctx context.Context, pod v1.Pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	client, config, err := e.clientAndConfig()
+	if err != nil {
+		return err
+	}
+
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return fmt.Errorf("creating exec stream: %w", err)
+	}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(transport, contextUpgrader{Upgrader: upgrader, ctx: ctx}, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("creating exec stream: %w", err)
+	}
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// contextUpgrader closes the connections it upgrades when the context is cancelled,
+// which interrupts the streams of a command that would otherwise never return.
+type contextUpgrader struct {
+	spdy.Upgrader
+	ctx context.Context
+}
+
+func (u contextUpgrader) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := u.Upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-u.ctx.Done():
+			conn.Close()
+		case <-conn.CloseChan():
+		}
+	}()
+	return conn, nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	utilexec "k8s.io/client-go/util/exec"
+
+	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+	testEvent "github.com/GoogleContainerTools/skaffold/testutil/event"
+)
+
+func TestSyncScript(t *testing.T) {
+	tests := []struct {
+		description string
+		deletes     []string
+		extract     bool
+		expected    string
+	}{
+		{
+			description: "copy only",
+			extract:     true,
+			expected:    "set -e; command -v tar >/dev/null 2>&1 || exit 127; tar xmf - -C / --no-same-owner",
+		},
+		{
+			description: "delete only",
+			deletes:     []string{"/app/a.go", "/app/b.go"},
+			expected:    "set -e; rm -rf -- '/app/a.go' '/app/b.go'",
+		},
+		{
+			description: "copy and delete",
+			deletes:     []string{"/app/it's.go"},
+			extract:     true,
+			expected:    `set -e; rm -rf -- '/app/it'\''s.go'; command -v tar >/dev/null 2>&1 || exit 127; tar xmf - -C / --no-same-owner`,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			t.CheckDeepEqual(test.expected, syncScript(test.deletes, test.extract))
+		})
+	}
+}
+
+type execCall struct {
+	pod       string
+	container string
+	script    string
+	files     []string
+}
+
+func TestNativePodSyncerSync(t *testing.T) {
+	tests := []struct {
+		description string
+		copies      syncMap
+		deletes     syncMap
+		noTar       bool
+		execErr     error
+		shouldErr   bool
+		expected    []execCall
+	}{
+		{
+			description: "copies and deletes in a single call",
+			copies:      syncMap{"main.go": {"/app/main.go"}},
+			deletes:     syncMap{"old.go": {"/app/old.go"}},
+			expected: []execCall{{
+				pod:       "podname",
+				container: "container_name",
+				script:    "set -e; rm -rf -- '/app/old.go'; command -v tar >/dev/null 2>&1 || exit 127; tar xmf - -C / --no-same-owner",
+				files:     []string{"/app/main.go"},
+			}},
+		},
+		{
+			description: "deletes only",
+			deletes:     syncMap{"old.go": {"/app/old.go"}},
+			expected: []execCall{{
+				pod:       "podname",
+				container: "container_name",
+				script:    "set -e; rm -rf -- '/app/old.go'",
+			}},
+		},
+		{
+			description: "copies files one by one without tar",
+			copies:      syncMap{"main.go": {"/app/main.go"}},
+			noTar:       true,
+			expected: []execCall{
+				{
+					pod:       "podname",
+					container: "container_name",
+					script:    "set -e; command -v tar >/dev/null 2>&1 || exit 127; tar xmf - -C / --no-same-owner",
+				},
+				{
+					pod:       "podname",
+					container: "container_name",
+					script:    "mkdir -p '/app' && cat > '/app/main.go'",
+				},
+			},
+		},
+		{
+			description: "exec error",
+			copies:      syncMap{"main.go": {"/app/main.go"}},
+			execErr:     errors.New("connection refused"),
+			shouldErr:   true,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			testEvent.InitializeState([]latestV1.Pipeline{{}})
+			tmpDir := t.NewTempDir().Write("main.go", "package main").Chdir()
+			t.Override(&client.Client, func() (kubernetes.Interface, error) {
+				return fake.NewSimpleClientset(pod), nil
+			})
+
+			var calls []execCall
+			var lock sync.Mutex
+			fakeExec := func(_ context.Context, p v1.Pod, container string, command []string, stdin io.Reader, _, _ io.Writer) error {
+				call := execCall{pod: p.Name, container: container, script: command[2]}
+				if test.execErr != nil {
+					return test.execErr
+				}
+				if test.noTar && strings.Contains(call.script, "tar") {
+					lock.Lock()
+					calls = append(calls, call)
+					lock.Unlock()
+					return utilexec.CodeExitError{Err: errors.New("exit 127"), Code: 127}
+				}
+				if stdin != nil && strings.Contains(call.script, "tar") {
+					tr := tar.NewReader(stdin)
+					for {
+						hdr, err := tr.Next()
+						if err == io.EOF {
+							break
+						}
+						if err != nil {
+							return err
+						}
+						call.files = append(call.files, hdr.Name)
+					}
+				}
+				lock.Lock()
+				calls = append(calls, call)
+				lock.Unlock()
+				return nil
+			}
+			t.Override(&newExecutor, func() execFunc { return fakeExec })
+
+			copies := syncMap{}
+			for src, dsts := range test.copies {
+				copies[tmpDir.Path(src)] = dsts
+			}
+			item := &Item{Image: "gcr.io/k8s-skaffold:123", Copy: copies, Delete: test.deletes}
+			err := NewNativePodSyncer(&pkgkubectl.CLI{}, &[]string{""}, "run_id").Sync(context.Background(), ioutil.Discard, item)
+
+			t.CheckError(test.shouldErr, err)
+			if !test.shouldErr {
+				t.CheckDeepEqual(test.expected, calls, cmp.AllowUnexported(execCall{}))
+			}
+		})
+	}
+}
+
+type fakeConnection struct {
+	httpstream.Connection
+	closed chan bool
+}
+
+func (c *fakeConnection) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func (c *fakeConnection) CloseChan() <-chan bool {
+	return c.closed
+}
+
+type fakeUpgrader struct {
+	conn *fakeConnection
+}
+
+func (u fakeUpgrader) NewConnection(*http.Response) (httpstream.Connection, error) {
+	return u.conn, nil
+}
+
+func TestContextUpgraderClosesConnectionOnCancel(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		conn := &fakeConnection{closed: make(chan bool)}
+
+		upgraded, err := contextUpgrader{Upgrader: fakeUpgrader{conn: conn}, ctx: ctx}.NewConnection(nil)
+		t.CheckNoError(err)
+		cancel()
+
+		select {
+		case <-upgraded.CloseChan():
+		case <-time.After(10 * time.Second):
+			t.Fatal("connection wasn't closed when the context was cancelled")
+		}
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/bmatcuk/doublestar"
@@ -38,6 +39,8 @@ import (
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/filemon"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/graph"
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/hooks"
+	pkgkubectl "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubectl"
 	kubernetesclient "github.com/GoogleContainerTools/skaffold/pkg/skaffold/kubernetes/client"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/util"
@@ -159,6 +162,9 @@ func syncMapForArtifact(a *latestV1.Artifact, cfg docker.Config) (map[string][]s
 	case a.KanikoArtifact != nil:
 		return docker.SyncMap(a.Workspace, a.KanikoArtifact.DockerfilePath, a.KanikoArtifact.BuildArgs, cfg)
 
+	case a.BuildKitArtifact != nil:
+		return docker.SyncMap(a.Workspace, a.BuildKitArtifact.DockerfilePath, a.BuildKitArtifact.BuildArgs, cfg)
+
 	default:
 		return nil, build.ErrSyncMapNotSupported{}
 	}
@@ -254,6 +260,14 @@ func matchSyncRules(syncRules []*latestV1.SyncRule, relPath, containerWd string)
 }
 
 func (s *PodSyncer) Sync(ctx context.Context, out io.Writer, item *Item) error {
+	hooksRunner, err := syncHooksRunner(s.kubectl, s.runID, *s.namespaces, item)
+	if err != nil {
+		return err
+	}
+	if err := hooksRunner.RunPreHooks(ctx, out); err != nil {
+		return fmt.Errorf("running pre-sync hooks: %w", err)
+	}
+
 	if len(item.Copy) > 0 {
 		logrus.Infoln("Copying files:", item.Copy, "to", item.Image)
 
@@ -270,14 +284,49 @@ func (s *PodSyncer) Sync(ctx context.Context, out io.Writer, item *Item) error {
 		}
 	}
 
+	if err := hooksRunner.RunPostHooks(ctx, out); err != nil {
+		return fmt.Errorf("running post-sync hooks: %w", err)
+	}
 	return nil
 }
 
+// syncHooksRunner returns the runner of the sync lifecycle hooks defined on the artifact of a sync item.
+func syncHooksRunner(cli *pkgkubectl.CLI, runID string, namespaces []string, item *Item) (hooks.Runner, error) {
+	if item.Artifact == nil || item.Artifact.Sync == nil {
+		return hooks.SyncRunner(cli, item.Image, runID, namespaces, latestV1.SyncHooks{}, hooks.SyncEnvOpts{}), nil
+	}
+	opts, err := hooks.NewSyncEnvOpts(item.Artifact, item.Image, sortedKeys(item.Copy), sortedKeys(item.Delete), namespaces, cli.KubeContext)
+	if err != nil {
+		return nil, err
+	}
+	return hooks.SyncRunner(cli, item.Image, runID, namespaces, item.Artifact.Sync.LifecycleHooks, opts), nil
+}
+
+func sortedKeys(files syncMap) []string {
+	var keys []string
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func Perform(ctx context.Context, image string, files syncMap, cmdFn func(context.Context, v1.Pod, v1.Container, syncMap) *exec.Cmd, namespaces []string) error {
 	if len(files) == 0 {
 		return nil
 	}
 
+	return syncContainers(ctx, image, namespaces, func(ctx context.Context, p v1.Pod, c v1.Container) func() error {
+		cmd := cmdFn(ctx, p, c, files)
+		return func() error {
+			_, err := util.RunCmdOut(cmd)
+			return err
+		}
+	})
+}
+
+// syncContainers runs, in parallel, the sync function returned by syncFn for each running container of the given image.
+func syncContainers(ctx context.Context, image string, namespaces []string, syncFn func(context.Context, v1.Pod, v1.Container) func() error) error {
 	errs, ctx := errgroup.WithContext(ctx)
 
 	client, err := kubernetesclient.Client()
@@ -302,11 +351,7 @@ func Perform(ctx context.Context, image string, files syncMap, cmdFn func(contex
 					continue
 				}
 
-				cmd := cmdFn(ctx, p, c, files)
-				errs.Go(func() error {
-					_, err := util.RunCmdOut(cmd)
-					return err
-				})
+				errs.Go(syncFn(ctx, p, c))
 				numSynced++
 			}
 		}
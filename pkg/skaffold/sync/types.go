@@ -40,12 +40,14 @@ type Syncer interface {
 type PodSyncer struct {
 	kubectl    *pkgkubectl.CLI
 	namespaces *[]string
+	runID      string
 }
 
-func NewPodSyncer(cli *pkgkubectl.CLI, namespaces *[]string) *PodSyncer {
+func NewPodSyncer(cli *pkgkubectl.CLI, namespaces *[]string, runID string) *PodSyncer {
 	return &PodSyncer{
 		kubectl:    cli,
 		namespaces: namespaces,
+		runID:      runID,
 	}
 }
 
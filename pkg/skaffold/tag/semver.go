@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+)
+
+type bump int
+
+const (
+	bumpNone bump = iota
+	bumpPatch
+	bumpMinor
+	bumpMajor
+)
+
+var bumps = map[string]bump{
+	"patch": bumpPatch,
+	"minor": bumpMinor,
+	"major": bumpMajor,
+}
+
+var (
+	conventionalHeader   = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?:`)
+	conventionalBreaking = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+type bumpRule struct {
+	pattern *regexp.Regexp
+	bump    bump
+}
+
+type version struct {
+	major, minor, patch int
+}
+
+func (v version) bump(b bump) version {
+	switch b {
+	case bumpMajor:
+		return version{major: v.major + 1}
+	case bumpMinor:
+		return version{major: v.major, minor: v.minor + 1}
+	case bumpPatch:
+		return version{major: v.major, minor: v.minor, patch: v.patch + 1}
+	default:
+		return v
+	}
+}
+
+func (v version) less(o version) bool {
+	if v.major != o.major {
+		return v.major < o.major
+	}
+	if v.minor != o.minor {
+		return v.minor < o.minor
+	}
+	return v.patch < o.patch
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// SemverTagger tags an image with a semantic version computed from the git history.
+type SemverTagger struct {
+	prefix        string
+	tagPattern    *regexp.Regexp
+	rules         []bumpRule
+	preRelease    string
+	ignoreChanges bool
+}
+
+// NewSemverTagger creates a new semver tagger. It fails if a bump rule is invalid.
+func NewSemverTagger(t *latestV1.SemverTagger) (*SemverTagger, error) {
+	var rules []bumpRule
+	for _, r := range t.Rules {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver rule pattern %q: %w", r.Pattern, err)
+		}
+		b, found := bumps[strings.ToLower(r.Bump)]
+		if !found {
+			return nil, fmt.Errorf("%q is not a valid semver bump, expected one of major, minor or patch", r.Bump)
+		}
+		rules = append(rules, bumpRule{pattern: pattern, bump: b})
+	}
+
+	return &SemverTagger{
+		prefix:        t.Prefix,
+		tagPattern:    regexp.MustCompile(`^` + regexp.QuoteMeta(t.TagPrefix) + `v?(\d+)\.(\d+)\.(\d+)$`),
+		rules:         rules,
+		preRelease:    t.PreRelease,
+		ignoreChanges: t.IgnoreChanges,
+	}, nil
+}
+
+// GenerateTag generates a tag from the latest version tag and the commits made since.
+func (t *SemverTagger) GenerateTag(image latestV1.Artifact) (string, error) {
+	latestTag, current, err := t.latestVersion(image.Workspace)
+	if err != nil {
+		return "", fmt.Errorf("finding latest version tag: %w", err)
+	}
+
+	revisions := "HEAD"
+	if latestTag != "" {
+		revisions = latestTag + "..HEAD"
+	}
+	// only the commits that change the artifact's workspace are considered.
+	log, err := runGit(image.Workspace, "log", "--format=%B%x00", revisions, "--", ".")
+	if err != nil {
+		return "", fmt.Errorf("listing commits since %s: %w", current, err)
+	}
+
+	var messages []string
+	for _, m := range strings.Split(log, "\x00") {
+		if m = strings.TrimSpace(m); m != "" {
+			messages = append(messages, m)
+		}
+	}
+
+	next := current
+	var suffix []string
+	if len(messages) > 0 {
+		next = current.bump(t.bumpFor(messages))
+		if t.preRelease != "" {
+			suffix = append(suffix, t.preRelease, strconv.Itoa(len(messages)))
+		}
+	}
+
+	if !t.ignoreChanges {
+		changes, err := runGit(image.Workspace, "status", ".", "--porcelain")
+		if err != nil {
+			return "", fmt.Errorf("getting git status: %w", err)
+		}
+		if len(changes) > 0 {
+			suffix = append(suffix, "dirty")
+		}
+	}
+
+	tag := next.String()
+	if len(suffix) > 0 {
+		tag += "-" + strings.Join(suffix, ".")
+	}
+	return t.prefix + tag, nil
+}
+
+// latestVersion returns the highest version tag reachable from HEAD, or 0.0.0 if there is none.
+func (t *SemverTagger) latestVersion(workingDir string) (string, version, error) {
+	tags, err := runGit(workingDir, "tag", "--merged", "HEAD")
+	if err != nil {
+		return "", version{}, err
+	}
+
+	var latestTag string
+	var latest version
+	for _, tag := range strings.Fields(tags) {
+		m := t.tagPattern.FindStringSubmatch(tag)
+		if m == nil {
+			continue
+		}
+		major, _ := strconv.Atoi(m[1])
+		minor, _ := strconv.Atoi(m[2])
+		patch, _ := strconv.Atoi(m[3])
+		if v := (version{major, minor, patch}); latestTag == "" || latest.less(v) {
+			latestTag, latest = tag, v
+		}
+	}
+	return latestTag, latest, nil
+}
+
+// bumpFor returns the highest bump required by the given commit messages.
+func (t *SemverTagger) bumpFor(messages []string) bump {
+	highest := bumpPatch
+	for _, m := range messages {
+		if b := t.bumpForMessage(m); b > highest {
+			highest = b
+		}
+	}
+	return highest
+}
+
+func (t *SemverTagger) bumpForMessage(message string) bump {
+	if len(t.rules) > 0 {
+		highest := bumpNone
+		for _, r := range t.rules {
+			if r.bump > highest && r.pattern.MatchString(message) {
+				highest = r.bump
+			}
+		}
+		return highest
+	}
+
+	if conventionalBreaking.MatchString(message) {
+		return bumpMajor
+	}
+	header := conventionalHeader.FindStringSubmatch(message)
+	switch {
+	case header == nil:
+		return bumpPatch
+	case header[3] == "!":
+		return bumpMajor
+	case strings.ToLower(header[1]) == "feat":
+		return bumpMinor
+	default:
+		return bumpPatch
+	}
+}
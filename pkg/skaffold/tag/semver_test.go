@@ -0,0 +1,291 @@
+/*
+Copyright 2021 The Skaffold Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"testing"
+
+	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
+	"github.com/GoogleContainerTools/skaffold/testutil"
+)
+
+func TestSemverTagger_GenerateTag(t *testing.T) {
+	tests := []struct {
+		description   string
+		tagger        latestV1.SemverTagger
+		createGitRepo func(string)
+		subDir        string
+		expected      string
+	}{
+		{
+			description: "no version tag",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("feat: initial")
+			},
+			expected: "0.1.0",
+		},
+		{
+			description: "tagged commit",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3")
+			},
+			expected: "1.2.3",
+		},
+		{
+			description: "fix bumps patch",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "fixed").
+					add("source.go").
+					commit("fix: handle empty input")
+			},
+			expected: "1.2.4",
+		},
+		{
+			description: "feat bumps minor",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "feature").
+					add("source.go").
+					commit("feat(api): add endpoint").
+					write("source.go", "fixed").
+					add("source.go").
+					commit("fix: typo")
+			},
+			expected: "1.3.0",
+		},
+		{
+			description: "breaking change bumps major",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "breaking").
+					add("source.go").
+					commit("refactor: drop v1 api\n\nBREAKING CHANGE: v1 clients are not supported anymore")
+			},
+			expected: "2.0.0",
+		},
+		{
+			description: "highest version tag wins",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.10.0").
+					tag("v1.9.0").
+					tag("latest")
+			},
+			expected: "1.10.0",
+		},
+		{
+			description: "tag prefix",
+			tagger:      latestV1.SemverTagger{TagPrefix: "web/", Prefix: "v"},
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("web/v0.4.0").
+					tag("v3.0.0").
+					write("source.go", "fixed").
+					add("source.go").
+					commit("fix: web")
+			},
+			expected: "v0.4.1",
+		},
+		{
+			description: "custom rules",
+			tagger: latestV1.SemverTagger{Rules: []latestV1.SemverBumpRule{
+				{Pattern: `\[major\]`, Bump: "major"},
+				{Pattern: `\[minor\]`, Bump: "minor"},
+			}},
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("1.2.3").
+					write("source.go", "feature").
+					add("source.go").
+					commit("Add endpoint [minor]").
+					write("source.go", "fixed").
+					add("source.go").
+					commit("feat!: not a conventional commit repo")
+			},
+			expected: "1.3.0",
+		},
+		{
+			description: "pre-release with distance",
+			tagger:      latestV1.SemverTagger{PreRelease: "rc"},
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "feature").
+					add("source.go").
+					commit("feat: one").
+					write("source.go", "fixed").
+					add("source.go").
+					commit("fix: two")
+			},
+			expected: "1.3.0-rc.2",
+		},
+		{
+			description: "pre-release with dirty marker",
+			tagger:      latestV1.SemverTagger{PreRelease: "dev"},
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "fixed").
+					add("source.go").
+					commit("fix: one").
+					write("source.go", "uncommitted")
+			},
+			expected: "1.2.4-dev.1.dirty",
+		},
+		{
+			description: "dirty tagged commit",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "uncommitted")
+			},
+			expected: "1.2.3-dirty",
+		},
+		{
+			description: "only commits changing the workspace",
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					mkdir("web").
+					mkdir("api").
+					write("web/source.go", "code").
+					write("api/source.go", "code").
+					add("web/source.go", "api/source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("api/source.go", "feature").
+					add("api/source.go").
+					commit("feat: api endpoint").
+					write("web/source.go", "fixed").
+					add("web/source.go").
+					commit("fix: web page")
+			},
+			subDir:   "web",
+			expected: "1.2.4",
+		},
+		{
+			description: "ignore changes",
+			tagger:      latestV1.SemverTagger{IgnoreChanges: true},
+			createGitRepo: func(dir string) {
+				gitInit(t, dir).
+					write("source.go", "code").
+					add("source.go").
+					commit("initial").
+					tag("v1.2.3").
+					write("source.go", "uncommitted")
+			},
+			expected: "1.2.3",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			tmpDir := t.NewTempDir()
+			test.createGitRepo(tmpDir.Root())
+
+			tagger, err := NewSemverTagger(&test.tagger)
+			t.CheckNoError(err)
+
+			tag, err := tagger.GenerateTag(latestV1.Artifact{Workspace: tmpDir.Path(test.subDir)})
+			t.CheckErrorAndDeepEqual(false, err, test.expected, tag)
+		})
+	}
+}
+
+func TestSemverTagger_CustomTemplate(t *testing.T) {
+	testutil.Run(t, "", func(t *testutil.T) {
+		tmpDir := t.NewTempDir()
+		gitInit(t.T, tmpDir.Root()).
+			write("source.go", "code").
+			add("source.go").
+			commit("initial").
+			tag("v1.2.3")
+
+		semver, err := NewSemverTagger(&latestV1.SemverTagger{})
+		t.CheckNoError(err)
+		tagger, err := NewCustomTemplateTagger("{{.VERSION}}-{{.SUFFIX}}", map[string]Tagger{
+			"VERSION": semver,
+			"SUFFIX":  &CustomTag{Tag: "alpine"},
+		})
+		t.CheckNoError(err)
+
+		tag, err := tagger.GenerateTag(latestV1.Artifact{Workspace: tmpDir.Root()})
+		t.CheckErrorAndDeepEqual(false, err, "1.2.3-alpine", tag)
+	})
+}
+
+func TestNewSemverTagger_InvalidRules(t *testing.T) {
+	tests := []struct {
+		description string
+		rule        latestV1.SemverBumpRule
+		expectedErr string
+	}{
+		{
+			description: "invalid pattern",
+			rule:        latestV1.SemverBumpRule{Pattern: "(", Bump: "minor"},
+			expectedErr: "invalid semver rule pattern",
+		},
+		{
+			description: "invalid bump",
+			rule:        latestV1.SemverBumpRule{Pattern: "feat", Bump: "huge"},
+			expectedErr: `"huge" is not a valid semver bump`,
+		},
+	}
+	for _, test := range tests {
+		testutil.Run(t, test.description, func(t *testutil.T) {
+			_, err := NewSemverTagger(&latestV1.SemverTagger{Rules: []latestV1.SemverBumpRule{test.rule}})
+			t.CheckErrorContains(test.expectedErr, err)
+		})
+	}
+}
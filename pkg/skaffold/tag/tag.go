@@ -19,6 +19,7 @@ package tag
 import (
 	"fmt"
 
+	"github.com/GoogleContainerTools/skaffold/pkg/skaffold/docker"
 	latestV1 "github.com/GoogleContainerTools/skaffold/pkg/skaffold/schema/latest/v1"
 )
 
@@ -34,16 +35,19 @@ type Tagger interface {
 // GenerateFullyQualifiedImageName resolves the fully qualified image name for an artifact.
 // The workingDir is the root directory of the artifact with respect to the Skaffold root,
 // and imageName is the base name of the image.
+// Prefixes such as `ko://` are removed from the image name.
 func GenerateFullyQualifiedImageName(t Tagger, image latestV1.Artifact) (string, error) {
 	tag, err := t.GenerateTag(image)
 	if err != nil {
 		return "", fmt.Errorf("generating tag: %w", err)
 	}
 
+	imageName := docker.SanitizeImageName(image.ImageName)
+
 	// Do not append :tag to imageName if tag is empty.
 	if tag == "" {
-		return image.ImageName, nil
+		return imageName, nil
 	}
 
-	return fmt.Sprintf("%s:%s", image.ImageName, tag), nil
+	return fmt.Sprintf("%s:%s", imageName, tag), nil
 }
@@ -63,6 +63,12 @@ func TestTagger_GenerateFullyQualifiedImageName(t *testing.T) {
 			tagger:      &ChecksumTagger{},
 			expected:    "test:tag",
 		},
+		{
+			description: "ko prefix",
+			imageName:   "ko://example.com/cmd/app",
+			tagger:      envTemplateExample,
+			expected:    "example.com/cmd/app:BAR",
+		},
 		{
 			description: "envTemplate",
 			imageName:   "test",
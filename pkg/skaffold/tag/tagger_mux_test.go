@@ -31,6 +31,7 @@ func TestCreateComponents(t *testing.T) {
 	digestExample, _ := NewInputDigestTagger(runCtx, graph.ToArtifactGraph(runCtx.Artifacts()))
 	gitExample, _ := NewGitCommit("", "", false)
 	envExample, _ := NewEnvTemplateTagger("test")
+	semverExample, _ := NewSemverTagger(&latestV1.SemverTagger{})
 
 	tests := []struct {
 		description          string
@@ -47,6 +48,7 @@ func TestCreateComponents(t *testing.T) {
 					{Name: "BAR", Component: latestV1.TagPolicy{EnvTemplateTagger: &latestV1.EnvTemplateTagger{Template: "test"}}},
 					{Name: "BAT", Component: latestV1.TagPolicy{DateTimeTagger: &latestV1.DateTimeTagger{}}},
 					{Name: "BAS", Component: latestV1.TagPolicy{InputDigest: &latestV1.InputDigest{}}},
+					{Name: "BAZ", Component: latestV1.TagPolicy{SemverTagger: &latestV1.SemverTagger{}}},
 				},
 			},
 			expected: map[string]Tagger{
@@ -55,6 +57,7 @@ func TestCreateComponents(t *testing.T) {
 				"BAR": envExample,
 				"BAT": NewDateTimeTagger("", ""),
 				"BAS": digestExample,
+				"BAZ": semverExample,
 			},
 		},
 		{
@@ -66,6 +69,15 @@ func TestCreateComponents(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			description: "invalid semver component",
+			customTemplateTagger: &latestV1.CustomTemplateTagger{
+				Components: []latestV1.TaggerComponent{
+					{Name: "FOO", Component: latestV1.TagPolicy{SemverTagger: &latestV1.SemverTagger{Rules: []latestV1.SemverBumpRule{{Pattern: "feat", Bump: "huge"}}}}},
+				},
+			},
+			shouldErr: true,
+		},
 		{
 			description: "recurring names",
 			customTemplateTagger: &latestV1.CustomTemplateTagger{